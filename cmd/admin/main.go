@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	external "github.com/thiagoluis88git/tech1/internal/integrations"
+	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
+	"github.com/thiagoluis88git/tech1/pkg/database"
+	"github.com/thiagoluis88git/tech1/pkg/environment"
+)
+
+var (
+	name  = flag.String("name", "", "admin name")
+	cpf   = flag.String("cpf", "", "admin CPF")
+	email = flag.String("email", "", "admin email, where the login codes are sent")
+)
+
+// The '/auth/admin/signup' is only for the admins, so the first admin is created by this command.
+// It runs with the same environment variables of the API:
+//
+//	go run cmd/admin/main.go -name "Admin" -cpf 17107972073 -email admin@fastfood.com
+func main() {
+	environment.LoadEnvironmentVariables()
+
+	if *name == "" || *cpf == "" || *email == "" {
+		log.Fatal("the -name, -cpf and -email flags are required")
+	}
+
+	db := database.ConfigDatabase(external.LocalIdentityModels...)
+
+	var cognitoRemote remote.CognitoRemoteDataSource
+
+	if environment.IsLocalIdentityProvider() {
+		signingKey, err := external.NewLocalSigningKey(environment.GetLocalIdentitySigningKey())
+
+		if err != nil {
+			log.Fatalf("could not load the local identity signing key: %v", err.Error())
+		}
+
+		cognitoRemote = external.NewLocalIdentityProvider(
+			db,
+			signingKey,
+			environment.GetCognitoGroupUser(),
+			environment.GetCognitoGroupAdmin(),
+		)
+	} else {
+		cognitoRemote = remote.NewCognitoRemoteDataSource(
+			environment.GetRegion(),
+			environment.GetCognitoUserPoolID(),
+			environment.GetCognitoClientID(),
+			environment.GetCognitoGroupUser(),
+			environment.GetCognitoGroupAdmin(),
+			environment.GetCognitoUnknownUserPassword(),
+		)
+	}
+
+	userRepo := repositories.NewUserAdminRepository(db, cognitoRemote)
+	createUserUseCase := usecases.NewCreateUserUseCase(usecases.NewValidateCPFUseCase(), userRepo)
+
+	response, err := createUserUseCase.Execute(context.Background(), dto.UserAdmin{
+		Name:  *name,
+		CPF:   *cpf,
+		Email: *email,
+	})
+
+	if err != nil {
+		log.Fatalf("could not create the admin: %v", err.Error())
+	}
+
+	log.Printf("admin %v created", response.Id)
+}
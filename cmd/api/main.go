@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/data/repositories"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
 	"github.com/thiagoluis88git/tech1/internal/core/handler"
 	"github.com/thiagoluis88git/tech1/internal/core/webhook"
@@ -50,7 +55,9 @@ func main() {
 		DocsPath:    "/docs",
 	}
 
-	db := database.ConfigDatabase()
+	db := database.ConfigDatabase(external.LocalIdentityModels...)
+
+	handler.RegisterValidations()
 
 	router := chi.NewRouter()
 	router.Use(chiMiddleware.RequestID)
@@ -60,8 +67,6 @@ func main() {
 	httpClient := httpserver.NewHTTPClient()
 
 	paymentRepo := repositories.NewPaymentRepository(db)
-	paymentGateway := external.NewPaymentGateway()
-	payOrderUseCase := usecases.NewPayOrderUseCase(paymentRepo, paymentGateway)
 	getPaymentTypesUseCase := usecases.NewGetPaymentTypesUseCasee(paymentRepo)
 
 	productRepo := repositories.NewProductRepository(db)
@@ -73,43 +78,139 @@ func main() {
 	updateProductUseCase := usecases.NewUpdateProductUseCase(productRepo)
 	createProductUseCase := usecases.NewCreateProductUseCase(validateProductCategoryUseCase, productRepo)
 
-	cognitoRemote := remote.NewCognitoRemoteDataSource(
-		environment.GetRegion(),
-		environment.GetCognitoUserPoolID(),
-		environment.GetCognitoClientID(),
-		environment.GetCognitoGroupUser(),
-		environment.GetCognitoGroupAdmin(),
-	)
+	var cognitoRemote remote.CognitoRemoteDataSource
+	var keySetRepo repository.KeySetRepository
+	var tokenIssuer, tokenClientID string
+
+	if environment.IsLocalIdentityProvider() {
+		signingKey, err := external.NewLocalSigningKey(environment.GetLocalIdentitySigningKey())
+
+		if err != nil {
+			panic(fmt.Sprintf("could not load the local identity signing key: %v", err.Error()))
+		}
+
+		localIdentityProvider := external.NewLocalIdentityProvider(
+			db,
+			signingKey,
+			environment.GetCognitoGroupUser(),
+			environment.GetCognitoGroupAdmin(),
+		)
+
+		cognitoRemote = localIdentityProvider
+		keySetRepo = localIdentityProvider.KeySet()
+		tokenIssuer = entity.LocalIdentityIssuer
+		tokenClientID = entity.LocalIdentityClientID
+	} else {
+		cognitoRemote = remote.NewCognitoRemoteDataSource(
+			environment.GetRegion(),
+			environment.GetCognitoUserPoolID(),
+			environment.GetCognitoClientID(),
+			environment.GetCognitoGroupUser(),
+			environment.GetCognitoGroupAdmin(),
+			environment.GetCognitoUnknownUserPassword(),
+		)
+
+		tokenIssuer = entity.CognitoIssuer(environment.GetRegion(), environment.GetCognitoUserPoolID())
+		tokenClientID = environment.GetCognitoClientID()
+
+		// The public keys are fetched with the default client, which verifies the TLS certificate of Cognito
+		keySetRemote := remote.NewKeySetRemoteDataSource(
+			&http.Client{Timeout: 10 * time.Second},
+			entity.CognitoKeySetURL(tokenIssuer),
+		)
+		keySetRepo = extRepo.NewCachedKeySetRepository(keySetRemote)
+	}
+
 	customerRepo := repositories.NewCustomerRepository(db, cognitoRemote)
 	userRepo := repositories.NewUserAdminRepository(db, cognitoRemote)
 	validateCPFUseCase := usecases.NewValidateCPFUseCase()
-	loginCustomerUseCase := usecases.NewLoginCustomerUseCase(customerRepo)
+	authRepo := repositories.NewAuthRepository(cognitoRemote)
+	revokedTokenRepo := repositories.NewRevokedTokenRepository(db)
+
+	var loginCodeNotifier repository.LoginCodeNotifier
+
+	// The log notifier lets anyone who reads the log log in, so it is only for the local development
+	if sender := environment.GetLoginCodeEmailSender(); sender != "" {
+		loginCodeNotifier = extRepo.NewSESLoginCodeNotifier(environment.GetRegion(), sender)
+	} else if environment.IsLocalIdentityProvider() {
+		loginCodeNotifier = external.NewLogLoginCodeNotifier()
+	} else {
+		panic(fmt.Sprintf("the %v is required without the local identity provider", environment.LoginCodeEmailSender))
+	}
+
+	if environment.IsLoginCodeSMS() {
+		loginCodeNotifier = external.NewChannelLoginCodeNotifier(
+			loginCodeNotifier,
+			extRepo.NewSNSLoginCodeNotifier(environment.GetRegion()),
+		)
+	}
+
+	loginCustomerUseCase := usecases.NewLoginCustomerUseCase(customerRepo, authRepo, loginCodeNotifier)
 	loginUnknownCustomerUseCase := usecases.NewLoginUnknownCustomerUseCase(customerRepo)
 	createCustomerUseCase := usecases.NewCreateCustomerUseCase(validateCPFUseCase, customerRepo)
 	updateCustomerUseCase := usecases.NewUpdateCustomerUseCase(validateCPFUseCase, customerRepo)
 	getCustomerByIdUseCase := usecases.NewGetCustomerByIdUseCase(customerRepo)
-	getCustomerByCPFUseCase := usecases.NewGetCustomerByCPFUseCase(validateCPFUseCase, customerRepo)
 
-	loginUserUseCase := usecases.NewLoginUserUseCase(userRepo)
+	loginUserUseCase := usecases.NewLoginUserUseCase(userRepo, authRepo, loginCodeNotifier)
+	verifyLoginCodeUseCase := usecases.NewVerifyLoginCodeUseCase(authRepo)
+	refreshTokenUseCase := usecases.NewRefreshTokenUseCase(authRepo)
+	logoutUseCase := usecases.NewLogoutUseCase(authRepo, revokedTokenRepo)
+	deleteExpiredRevokedTokensUseCase := usecases.NewDeleteExpiredRevokedTokensUseCase(revokedTokenRepo)
 	createUserUseCase := usecases.NewCreateUserUseCase(validateCPFUseCase, userRepo)
 	updateUserUseCase := usecases.NewUpdateUserUseCase(validateCPFUseCase, userRepo)
 	getUserByIdUseCase := usecases.NewGetUserByIdUseCase(userRepo)
-	getUserByCPFUseCase := usecases.NewGetUserByCPFUseCase(validateCPFUseCase, userRepo)
 
 	orderRepo := repositories.NewOrderRespository(db)
-	validateToPreare := usecases.NewValidateOrderToPrepareUseCase(orderRepo)
-	validateToDone := usecases.NewValidateOrderToDoneUseCase(orderRepo)
-	validateToDeliveredOrNot := usecases.NewValidateOrderToDeliveredOrNotUseCase(orderRepo)
+	orderEventRepo := repositories.NewOrderEventRepository(db)
+	orderStateMachine := entity.NewOrderStateMachine()
+	validateOrderTransition := usecases.NewValidateOrderTransitionUseCase(orderRepo, orderStateMachine)
 	sortOrders := usecases.NewSortOrdersUseCase()
+	calculateOrderPrice := usecases.NewCalculateOrderPriceUseCase(productRepo)
+
+	promotionRepo := repositories.NewPromotionRepository(db)
+	createPromotionUseCase := usecases.NewCreatePromotionUseCase(promotionRepo)
+	getPromotionsUseCase := usecases.NewGetPromotionsUseCase(promotionRepo)
+	getPromotionByIdUseCase := usecases.NewGetPromotionByIdUseCase(promotionRepo)
+	updatePromotionUseCase := usecases.NewUpdatePromotionUseCase(promotionRepo)
+	deletePromotionUseCase := usecases.NewDeletePromotionUseCase(promotionRepo)
+	verifyCustomerTokenUseCase := usecases.NewVerifyCustomerTokenUseCase(customerRepo)
+	applyPromotionsUseCase := usecases.NewApplyPromotionsUseCase(promotionRepo, verifyCustomerTokenUseCase)
+
+	loyaltyRepo := repositories.NewLoyaltyRepository(db)
+	applyLoyaltyPointsUseCase := usecases.NewApplyLoyaltyPointsUseCase(loyaltyRepo, verifyCustomerTokenUseCase)
+	getCustomerLoyaltyUseCase := usecases.NewGetCustomerLoyaltyUseCase(customerRepo, loyaltyRepo)
+
+	getOrderPriceUseCase := usecases.NewGetOrderPriceUseCase(calculateOrderPrice, applyPromotionsUseCase, applyLoyaltyPointsUseCase)
+
 	createOrderUseCase := usecases.NewCreateOrderUseCase(
 		orderRepo,
+		orderEventRepo,
 		customerRepo,
-		validateToPreare,
-		validateToDone,
-		validateToDeliveredOrNot,
+		paymentRepo,
+		calculateOrderPrice,
+		applyPromotionsUseCase,
+		applyLoyaltyPointsUseCase,
 		sortOrders,
 	)
 	getOrderByIdUseCase := usecases.NewGetOrderByIdUseCase(orderRepo)
+	getCustomerOrdersUseCase := usecases.NewGetCustomerOrdersUseCase(customerRepo, orderRepo)
+	mergeGuestOrdersUseCase := usecases.NewMergeGuestOrdersUseCase(validateCPFUseCase, customerRepo, orderRepo)
+
+	privacyRepo := repositories.NewPrivacyRepository(db, cognitoRemote)
+	exportCustomerDataUseCase := usecases.NewExportCustomerDataUseCase(
+		customerRepo,
+		orderRepo,
+		loyaltyRepo,
+		privacyRepo,
+		environment.GetCognitoGroupAdmin(),
+	)
+	eraseCustomerDataUseCase := usecases.NewEraseCustomerDataUseCase(customerRepo, privacyRepo, environment.GetCognitoGroupAdmin())
+
+	getPaymentByIdUseCase := usecases.NewGetPaymentByIdUseCase(paymentRepo, orderRepo)
+	getOrderStatusHistoryUseCase := usecases.NewGetOrderStatusHistoryUseCase(orderRepo)
+	streamOrderEventsUseCase := usecases.NewStreamOrderEventsUseCase(orderRepo, orderEventRepo)
+	deleteExpiredOrderEventsUseCase := usecases.NewDeleteExpiredOrderEventsUseCase(orderEventRepo)
+	getOrdersUseCase := usecases.NewGetOrdersUseCase(orderRepo, orderStateMachine)
 	getOrdersToPrepareUseCase := usecases.NewGetOrdersToPrepareUseCase(
 		orderRepo,
 		sortOrders,
@@ -124,35 +225,145 @@ func main() {
 	)
 	updateToPreparingUseCase := usecases.NewUpdateToPreparingUseCase(
 		orderRepo,
-		validateToPreare,
+		orderEventRepo,
+		validateOrderTransition,
 	)
 	updateToDoneUseCase := usecases.NewUpdateToDoneUseCase(
 		orderRepo,
-		validateToDone,
+		orderEventRepo,
+		validateOrderTransition,
 	)
 	updateToDeliveredUseCase := usecases.NewUpdateToDeliveredUseCase(
 		orderRepo,
-		validateToDeliveredOrNot,
+		orderEventRepo,
+		validateOrderTransition,
 	)
 	updateToNotDeliveredUseCase := usecases.NewUpdateToNotDeliveredUseCase(
 		orderRepo,
-		validateToDeliveredOrNot,
+		orderEventRepo,
+		validateOrderTransition,
 	)
 
 	qrCodeRemoteDataSource := remote.NewMercadoLivreDataSource(httpClient)
 	extQRCodeGeneratorRepository := extRepo.NewMercadoLivreRepository(qrCodeRemoteDataSource)
+	verifyWebhookSignatureUseCase := usecases.NewVerifyWebhookSignatureUseCase(environment.GetWebhookMercadoLivreSecret())
+
+	paymentProviders := external.NewPaymentProviderRegistry()
+
+	if environment.IsPaymentSandbox() {
+		sandboxPaymentProvider := external.NewSandboxPaymentProvider()
+
+		for _, paymentType := range paymentRepo.GetPaymentTypes() {
+			paymentProviders.Register(paymentType, sandboxPaymentProvider)
+		}
+	} else {
+		paymentProviders.Register(entity.PaymentCreditType, external.NewPaymentGateway())
+		paymentProviders.Register(entity.PaymentQRCodeType, extRepo.NewMercadoPagoPaymentProvider(
+			extQRCodeGeneratorRepository,
+			environment.GetQRCodeGatewayToken(),
+			verifyWebhookSignatureUseCase.Execute,
+		))
+
+		if environment.GetPIXKey() != "" {
+			paymentProviders.Register(entity.PaymentPIXType, external.NewPIXPaymentProvider(
+				environment.GetPIXKey(),
+				environment.GetPIXMerchantName(),
+				environment.GetPIXMerchantCity(),
+				environment.GetPIXWebhookSecret(),
+			))
+		}
+	}
+
+	payOrderUseCase := usecases.NewPayOrderUseCase(paymentRepo, paymentProviders)
+	refundOrderPaymentsUseCase := usecases.NewRefundOrderPaymentsUseCase(paymentRepo, paymentProviders)
 	generateQRCodePaymentUseCase := usecases.NewGenerateQRCodePaymentUseCase(
-		extQRCodeGeneratorRepository,
+		paymentProviders,
+		orderRepo,
+		paymentRepo,
+		calculateOrderPrice,
+		applyPromotionsUseCase,
+		applyLoyaltyPointsUseCase,
+	)
+	handlePaymentWebhookUseCase := usecases.NewHandlePaymentWebhookUseCase(
+		paymentProviders,
 		orderRepo,
 		paymentRepo,
+		orderEventRepo,
+		refundOrderPaymentsUseCase,
 	)
 
 	finishOrderForQRCodeUseCase := usecases.NewFinishOrderForQRCodeUseCase(
 		extQRCodeGeneratorRepository,
 		orderRepo,
-		paymentRepo,
+		orderEventRepo,
+		refundOrderPaymentsUseCase,
+	)
+	reconcileQRCodePaymentsUseCase := usecases.NewReconcileQRCodePaymentsUseCase(
+		extQRCodeGeneratorRepository,
+		orderRepo,
+		orderEventRepo,
+		refundOrderPaymentsUseCase,
+		environment.GetQRCodeGatewayToken(),
 	)
 
+	// The sandbox QR Codes do not exist in Mercado Livre
+	if !environment.IsPaymentSandbox() {
+		go reconcileQRCodePaymentsUseCase.Start(context.Background(), entity.QRCodeReconciliationInterval)
+	}
+
+	cancelOrderUseCase := usecases.NewCancelOrderUseCase(
+		orderRepo,
+		orderEventRepo,
+		refundOrderPaymentsUseCase,
+		orderStateMachine,
+	)
+
+	reportRepo := repositories.NewReportRepository(db)
+	getRevenueByDayUseCase := usecases.NewGetRevenueByDayUseCase(reportRepo)
+	getRevenueByCategoryUseCase := usecases.NewGetRevenueByCategoryUseCase(reportRepo)
+	getRevenueByProductUseCase := usecases.NewGetRevenueByProductUseCase(reportRepo)
+	getOrderTimesUseCase := usecases.NewGetOrderTimesUseCase(reportRepo)
+	getNotDeliveredUseCase := usecases.NewGetNotDeliveredUseCase(reportRepo)
+	getHourlyVolumeUseCase := usecases.NewGetHourlyVolumeUseCase(reportRepo)
+
+	outboxRepo := repositories.NewOutboxRepository(db)
+	var eventPublisher repository.EventPublisher = external.NewMemoryEventPublisher()
+
+	if webhookURL := environment.GetOutboxWebhookURL(); webhookURL != "" {
+		eventPublisher = extRepo.NewWebhookEventPublisher(httpClient, webhookURL)
+	}
+
+	relayOutboxEventsUseCase := usecases.NewRelayOutboxEventsUseCase(outboxRepo, eventPublisher)
+
+	go relayOutboxEventsUseCase.Start(context.Background(), entity.OutboxRelayInterval)
+
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	idempotentRequestUseCase := usecases.NewIdempotentRequestUseCase(idempotencyRepo)
+	deleteExpiredIdempotencyKeysUseCase := usecases.NewDeleteExpiredIdempotencyKeysUseCase(idempotencyRepo)
+
+	go deleteExpiredIdempotencyKeysUseCase.Start(context.Background(), entity.IdempotencyKeyCleanupInterval)
+
+	go deleteExpiredRevokedTokensUseCase.Start(context.Background(), entity.RevokedTokenCleanupInterval)
+
+	go deleteExpiredOrderEventsUseCase.Start(context.Background(), entity.OrderEventCleanupInterval)
+
+	authenticateUseCase := usecases.NewAuthenticateUseCase(
+		keySetRepo,
+		revokedTokenRepo,
+		tokenIssuer,
+		tokenClientID,
+	)
+	authorizeCustomerUseCase := usecases.NewAuthorizeCustomerUseCase(customerRepo, environment.GetCognitoGroupAdmin())
+	authorizeOrderUseCase := usecases.NewAuthorizeOrderUseCase(orderRepo, environment.GetCognitoGroupAdmin())
+	authorizePaymentUseCase := usecases.NewAuthorizePaymentUseCase(paymentRepo, environment.GetCognitoGroupAdmin())
+
+	router.Use(handler.Authenticate(authenticateUseCase))
+
+	requireAdmin := handler.RequireGroup(environment.GetCognitoGroupAdmin())
+	requireCustomerOwner := handler.RequireCustomerOwner(authorizeCustomerUseCase)
+	requireOrderOwner := handler.RequireOrderOwner(authorizeOrderUseCase)
+	requirePaymentOwner := handler.RequirePaymentOwner(authorizePaymentUseCase)
+
 	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		httpserver.SendResponseSuccess(w, &responses.BusinessResponse{
 			StatusCode: 200,
@@ -162,40 +373,68 @@ func main() {
 
 	router.Post("/auth/login", handler.LoginCustomerHandler(loginCustomerUseCase))
 	router.Post("/auth/login/unknown", handler.LoginUnknownCustomerHandler(loginUnknownCustomerUseCase))
+	router.Post("/auth/login/verify", handler.VerifyLoginCodeHandler(verifyLoginCodeUseCase, mergeGuestOrdersUseCase))
+	router.Post("/auth/token/refresh", handler.RefreshTokenHandler(refreshTokenUseCase))
+	router.Post("/auth/logout", handler.LogoutHandler(logoutUseCase))
 	router.Post("/auth/admin/login", handler.LoginUserHandler(loginUserUseCase))
-	router.Post("/auth/signup", handler.CreateCustomerHandler(createCustomerUseCase))
-	router.Post("/auth/admin/signup", handler.CreateUserHandler(createUserUseCase))
+	router.Post("/auth/signup", handler.CreateCustomerHandler(createCustomerUseCase, mergeGuestOrdersUseCase))
+	router.With(requireAdmin).Post("/auth/admin/signup", handler.CreateUserHandler(createUserUseCase))
+
+	router.Post("/api/qrcode/generate", handler.Idempotent(idempotentRequestUseCase, handler.GenerateQRCodeHandler(generateQRCodePaymentUseCase)))
+	router.Post("/api/webhook/ml/payment", webhook.PostExternalPaymentEventWebhook(verifyWebhookSignatureUseCase, finishOrderForQRCodeUseCase))
+	router.Post("/api/webhook/payments/{provider}", webhook.PostPaymentProviderWebhook(handlePaymentWebhookUseCase))
 
-	router.Post("/api/qrcode/generate", handler.GenerateQRCodeHandler(generateQRCodePaymentUseCase))
-	router.Post("/api/webhook/ml/payment", webhook.PostExternalPaymentEventWebhook(finishOrderForQRCodeUseCase))
+	router.With(requireAdmin).Get("/api/admin/reconciliation/qrcode", handler.GetQRCodeReconciliationHandler(reconcileQRCodePaymentsUseCase))
 
-	router.Put("/api/admin/customers/{id}", handler.UpdateCustomerHandler(updateCustomerUseCase))
-	router.Get("/api/customers/{id}", handler.GetCustomerByIdHandler(getCustomerByIdUseCase))
-	router.Post("/api/customers/login", handler.GetCustomerByCPFHandler(getCustomerByCPFUseCase))
+	router.With(requireAdmin).Put("/api/admin/customers/{id}", handler.UpdateCustomerHandler(updateCustomerUseCase))
+	router.With(requireCustomerOwner).Get("/api/customers/{id}", handler.GetCustomerByIdHandler(getCustomerByIdUseCase))
+	router.With(requireCustomerOwner).Get("/api/customers/{id}/orders", handler.GetCustomerOrdersHandler(getCustomerOrdersUseCase))
+	router.With(requireCustomerOwner).Get("/api/customers/{id}/loyalty", handler.GetCustomerLoyaltyHandler(getCustomerLoyaltyUseCase))
+	router.With(requireCustomerOwner).Get("/api/customers/{id}/data-export", handler.ExportCustomerDataHandler(exportCustomerDataUseCase))
+	router.With(requireCustomerOwner).Delete("/api/customers/{id}", handler.EraseCustomerDataHandler(eraseCustomerDataUseCase))
 
-	router.Put("/api/users/{id}", handler.UpdateUserHandler(updateUserUseCase))
-	router.Get("/api/users/{id}", handler.GetUserByIdHandler(getUserByIdUseCase))
-	router.Post("/api/users/login", handler.GetUserByCPFHandler(getUserByCPFUseCase))
+	router.With(requireAdmin).Put("/api/users/{id}", handler.UpdateUserHandler(updateUserUseCase))
+	router.With(requireAdmin).Get("/api/users/{id}", handler.GetUserByIdHandler(getUserByIdUseCase))
 
-	router.Post("/api/admin/products", handler.CreateProductHandler(createProductUseCase))
-	router.Delete("/api/admin/products/{id}", handler.DeleteProductHandler(deleteProductUseCase))
-	router.Put("/api/admin/products/{id}", handler.UpdateProductHandler(updateProductUseCase))
+	router.With(requireAdmin).Post("/api/admin/products", handler.CreateProductHandler(createProductUseCase))
+	router.With(requireAdmin).Delete("/api/admin/products/{id}", handler.DeleteProductHandler(deleteProductUseCase))
+	router.With(requireAdmin).Put("/api/admin/products/{id}", handler.UpdateProductHandler(updateProductUseCase))
 	router.Get("/api/products/{id}", handler.GetProductsByIdHandler(getProductByIdUseCase))
 	router.Get("/api/products/categories", handler.GetCategoriesHandler(getCategoriesUseCase))
 	router.Get("/api/products/categories/{category}", handler.GetProductsByCategoryHandler(getProductsUseCase))
 
 	router.Get("/api/payments/types", handler.GetPaymentTypeHandler(getPaymentTypesUseCase))
-	router.Post("/api/payments", handler.CreatePaymentHandler(payOrderUseCase))
-
-	router.Post("/api/orders", handler.CreateOrderHandler(createOrderUseCase))
-	router.Get("/api/orders/{id}", handler.GetOrderByIdHandler(getOrderByIdUseCase))
-	router.Get("/api/orders/to-prepare", handler.GetOrdersToPrepareHandler(getOrdersToPrepareUseCase))
+	router.Post("/api/payments", handler.Idempotent(idempotentRequestUseCase, handler.CreatePaymentHandler(payOrderUseCase)))
+	router.With(requirePaymentOwner).Get("/api/payments/{id}", handler.GetPaymentByIdHandler(getPaymentByIdUseCase))
+
+	router.With(requireAdmin).Post("/api/admin/promotions", handler.CreatePromotionHandler(createPromotionUseCase))
+	router.With(requireAdmin).Get("/api/admin/promotions", handler.GetPromotionsHandler(getPromotionsUseCase))
+	router.With(requireAdmin).Get("/api/admin/promotions/{id}", handler.GetPromotionByIdHandler(getPromotionByIdUseCase))
+	router.With(requireAdmin).Put("/api/admin/promotions/{id}", handler.UpdatePromotionHandler(updatePromotionUseCase))
+	router.With(requireAdmin).Delete("/api/admin/promotions/{id}", handler.DeletePromotionHandler(deletePromotionUseCase))
+
+	router.With(requireAdmin).Get("/api/admin/orders", handler.GetOrdersHandler(getOrdersUseCase))
+	router.Post("/api/orders/price", handler.GetOrderPriceHandler(getOrderPriceUseCase))
+	router.Post("/api/orders", handler.Idempotent(idempotentRequestUseCase, handler.CreateOrderHandler(createOrderUseCase)))
+	router.With(requireOrderOwner).Get("/api/orders/{id}", handler.GetOrderByIdHandler(getOrderByIdUseCase))
+	router.With(requireOrderOwner).Get("/api/orders/{id}/history", handler.GetOrderStatusHistoryHandler(getOrderStatusHistoryUseCase))
+	router.Get("/api/orders/stream", handler.OrdersStreamHandler(streamOrderEventsUseCase))
+	router.With(requireOrderOwner).Get("/api/orders/{id}/stream", handler.OrderStreamHandler(streamOrderEventsUseCase))
+	router.With(requireAdmin).Get("/api/orders/to-prepare", handler.GetOrdersToPrepareHandler(getOrdersToPrepareUseCase))
 	router.Get("/api/orders/follow", handler.GetOrdersToFollowHandler(getOrdersToFollowUseCase))
-	router.Get("/api/orders/waiting-payment", handler.GetOrdersWaitingPaymentHandler(getOrdersWaitingPaymentUseCase))
-	router.Put("/api/orders/{id}/preparing", handler.UpdateOrderPreparingHandler(updateToPreparingUseCase))
-	router.Put("/api/orders/{id}/done", handler.UpdateOrderDoneHandler(updateToDoneUseCase))
-	router.Put("/api/orders/{id}/delivered", handler.UpdateOrderDeliveredHandler(updateToDeliveredUseCase))
-	router.Put("/api/orders/{id}/not-delivered", handler.UpdateOrderNotDeliveredandler(updateToNotDeliveredUseCase))
+	router.With(requireAdmin).Get("/api/orders/waiting-payment", handler.GetOrdersWaitingPaymentHandler(getOrdersWaitingPaymentUseCase))
+	router.With(requireAdmin).Put("/api/orders/{id}/preparing", handler.UpdateOrderPreparingHandler(updateToPreparingUseCase))
+	router.With(requireAdmin).Put("/api/orders/{id}/done", handler.UpdateOrderDoneHandler(updateToDoneUseCase))
+	router.With(requireAdmin).Put("/api/orders/{id}/delivered", handler.UpdateOrderDeliveredHandler(updateToDeliveredUseCase))
+	router.With(requireAdmin).Put("/api/orders/{id}/not-delivered", handler.UpdateOrderNotDeliveredandler(updateToNotDeliveredUseCase))
+	router.With(requireAdmin).Put("/api/orders/{id}/cancel", handler.CancelOrderHandler(cancelOrderUseCase))
+
+	router.With(requireAdmin).Get("/api/admin/reports/revenue/daily", handler.GetRevenueByDayHandler(getRevenueByDayUseCase))
+	router.With(requireAdmin).Get("/api/admin/reports/revenue/categories", handler.GetRevenueByCategoryHandler(getRevenueByCategoryUseCase))
+	router.With(requireAdmin).Get("/api/admin/reports/revenue/products", handler.GetRevenueByProductHandler(getRevenueByProductUseCase))
+	router.With(requireAdmin).Get("/api/admin/reports/order-times", handler.GetOrderTimesHandler(getOrderTimesUseCase))
+	router.With(requireAdmin).Get("/api/admin/reports/not-delivered", handler.GetNotDeliveredHandler(getNotDeliveredUseCase))
+	router.With(requireAdmin).Get("/api/admin/reports/hourly-volume", handler.GetHourlyVolumeHandler(getHourlyVolumeUseCase))
 
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:3210/swagger/doc.json"),
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+const (
+	defineAuthChallengeTrigger = "DefineAuthChallenge_Authentication"
+	createAuthChallengeTrigger = "CreateAuthChallenge_Authentication"
+	verifyAuthChallengeTrigger = "VerifyAuthChallengeResponse_Authentication"
+)
+
+// The custom auth challenge Lambdas of the user pool, in a single function set as the three triggers.
+// The API keeps the hash of the login code in the user and sends the code by email or SMS, so the
+// challenge only compares the answer with it
+func main() {
+	lambda.Start(handleAuthChallenge)
+}
+
+func handleAuthChallenge(ctx context.Context, payload json.RawMessage) (any, error) {
+	var header events.CognitoEventUserPoolsHeader
+
+	err := json.Unmarshal(payload, &header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch header.TriggerSource {
+	case defineAuthChallengeTrigger:
+		var event events.CognitoEventUserPoolsDefineAuthChallenge
+
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+
+		defineAuthChallenge(&event)
+
+		return event, nil
+	case createAuthChallengeTrigger:
+		var event events.CognitoEventUserPoolsCreateAuthChallenge
+
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+
+		createAuthChallenge(&event)
+
+		return event, nil
+	case verifyAuthChallengeTrigger:
+		var event events.CognitoEventUserPoolsVerifyAuthChallenge
+
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+
+		verifyAuthChallenge(&event)
+
+		return event, nil
+	}
+
+	return nil, fmt.Errorf("the trigger %v is not an auth challenge", header.TriggerSource)
+}
+
+// defineAuthChallenge asks for the code once. The tokens are issued after the right answer and the
+// login fails after a wrong one, so a new code must be requested
+func defineAuthChallenge(event *events.CognitoEventUserPoolsDefineAuthChallenge) {
+	session := event.Request.Session
+
+	switch {
+	case event.Request.UserNotFound:
+		event.Response.FailAuthentication = true
+	case len(session) == 0:
+		event.Response.ChallengeName = entity.LoginCodeChallenge
+	case session[len(session)-1].ChallengeName == entity.LoginCodeChallenge && session[len(session)-1].ChallengeResult:
+		event.Response.IssueTokens = true
+	default:
+		event.Response.FailAuthentication = true
+	}
+}
+
+func createAuthChallenge(event *events.CognitoEventUserPoolsCreateAuthChallenge) {
+	event.Response.PublicChallengeParameters = map[string]string{}
+	event.Response.PrivateChallengeParameters = map[string]string{
+		"loginCodeHash": event.Request.UserAttributes[entity.LoginCodeAttribute],
+	}
+	event.Response.ChallengeMetadata = "LOGIN_CODE"
+}
+
+func verifyAuthChallenge(event *events.CognitoEventUserPoolsVerifyAuthChallenge) {
+	answer, _ := event.Request.ChallengeAnswer.(string)
+
+	event.Response.AnswerCorrect = entity.VerifyLoginCode(answer, event.Request.PrivateChallengeParameters["loginCodeHash"])
+}
@@ -24,9 +24,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/customers/login": {
-            "post": {
-                "description": "Get customer by CPF. This Endpoint can be used as a Login",
+        "/api/admin/customers/{id}": {
+            "put": {
+                "description": "Update customer",
                 "consumes": [
                     "application/json"
                 ],
@@ -36,24 +36,31 @@ const docTemplate = `{
                 "tags": [
                     "Customer"
                 ],
-                "summary": "Get customer by CPF",
+                "summary": "Update customer",
                 "parameters": [
                     {
-                        "description": "customerForm",
-                        "name": "customer",
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "customer",
+                        "name": "product",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CustomerForm"
+                            "$ref": "#/definitions/dto.Customer"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.Customer"
-                        }
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Customer has required fields"
                     },
                     "404": {
                         "description": "Customer not found"
@@ -61,9 +68,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/customers/{id}": {
+        "/api/admin/orders": {
             "get": {
-                "description": "Get customer by ID",
+                "description": "List the orders with filters and cursor pagination. This endpoint will be used by the admin to search orders.\nUse the 'nextCursor' of the response as the 'cursor' param to get the next page. When it is null, there are no more orders",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,32 +78,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customer"
+                    "Order"
                 ],
-                "summary": "Get customer by ID",
+                "summary": "List orders",
                 "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Order status. Can be repeated or comma separated",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "customerId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Ticket number",
+                        "name": "ticketNumber",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "12",
-                        "name": "Id",
-                        "in": "path",
-                        "required": true
+                        "description": "Payment type",
+                        "name": "paymentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02)",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates)",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-createdAt",
+                        "description": "createdAt, ticketNumber or totalPrice. Use the '-' prefix to sort descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, up to 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Customer"
+                            "$ref": "#/definitions/dto.OrderPage"
                         }
                     },
-                    "404": {
-                        "description": "Customer not found"
+                    "400": {
+                        "description": "Invalid filter"
                     }
                 }
-            },
-            "put": {
-                "description": "Update customer",
+            }
+        },
+        "/api/admin/products": {
+            "post": {
+                "description": "Create new product",
                 "consumes": [
                     "application/json"
                 ],
@@ -104,43 +166,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customer"
+                    "Product"
                 ],
-                "summary": "Update customer",
+                "summary": "Create new product",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "12",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "customer",
+                        "description": "product",
                         "name": "product",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.Customer"
+                            "$ref": "#/definitions/dto.ProductForm"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProductResponse"
+                        }
                     },
                     "400": {
-                        "description": "Customer has required fields"
+                        "description": "Product has required fields"
                     },
-                    "404": {
-                        "description": "Customer not found"
+                    "409": {
+                        "description": "This Product is already added"
                     }
                 }
             }
         },
-        "/api/orders": {
-            "post": {
-                "description": "Create new order. To make an order the payment needs to be completed\nA new Ticket will be generated by the Order Date starting from 1\nIn the next day the Ticket number will starts from 1 and so on",
+        "/api/admin/products/{id}": {
+            "put": {
+                "description": "Update a product by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -148,36 +206,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Product"
                 ],
-                "summary": "Create new order",
+                "summary": "Update a product",
                 "parameters": [
                     {
-                        "description": "order",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.Order"
-                        }
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.OrderResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Order has required fields"
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            }
-        },
-        "/api/orders/status": {
-            "get": {
-                "description": "Get all orders status by the waiter and the customer. This endpoint will be used by the waiter and customer",
+            },
+            "delete": {
+                "description": "Delete a product by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,25 +233,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Product"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all orders status different to prepare",
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.OrderResponse"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/api/orders/to-prepare": {
+        "/api/admin/promotions": {
             "get": {
-                "description": "Get all orders already payed that needs to be prepared. This endpoint will be used by the kitchen",
+                "description": "List all promotions, active or not",
                 "consumes": [
                     "application/json"
                 ],
@@ -211,25 +262,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Promotion"
                 ],
-                "summary": "Get all orders to prepare",
+                "summary": "List all promotions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.OrderResponse"
+                                "$ref": "#/definitions/dto.PromotionResponse"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/api/orders/waiting-payment": {
-            "get": {
-                "description": "Get all orders with waiting payment by the owner.\nThis endpoint will be used by the owner to know it the Mercado Livre QR Code was paid",
+            },
+            "post": {
+                "description": "Create a promotion. Without code it is applied automatically to the orders, otherwise\nonly when the customer types the coupon code. The type can be PERCENTAGE, FIXED_AMOUNT or BUY_X_GET_Y",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,25 +286,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Promotion"
+                ],
+                "summary": "Create new promotion",
+                "parameters": [
+                    {
+                        "description": "promotion",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PromotionForm"
+                        }
+                    }
                 ],
-                "summary": "Get all orders with waiting payment status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.OrderResponse"
-                            }
+                            "$ref": "#/definitions/dto.PromotionCreationResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Promotion has invalid rules"
+                    },
+                    "409": {
+                        "description": "This coupon code is already used"
                     }
                 }
             }
         },
-        "/api/orders/{id}": {
+        "/api/admin/promotions/{id}": {
             "get": {
-                "description": "Get an order by Id",
+                "description": "Get promotion by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -263,9 +326,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Promotion"
                 ],
-                "summary": "Get order by Id",
+                "summary": "Get promotion by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -279,18 +342,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.OrderResponse"
+                            "$ref": "#/definitions/dto.PromotionResponse"
                         }
                     },
-                    "400": {
-                        "description": "Order has required fields"
+                    "404": {
+                        "description": "Promotion not found"
                     }
                 }
-            }
-        },
-        "/api/orders/{id}/delivered": {
+            },
             "put": {
-                "description": "Update an order. This service wil be used by the waiter to close the order informing that user got its order",
+                "description": "Update all the rules of a promotion by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,9 +359,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Promotion"
                 ],
-                "summary": "Update an order to DELIVERED",
+                "summary": "Update a promotion",
                 "parameters": [
                     {
                         "type": "integer",
@@ -308,24 +369,31 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "promotion",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PromotionForm"
+                        }
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
-                    "404": {
-                        "description": "Order not found"
+                    "400": {
+                        "description": "Promotion has invalid rules"
                     },
-                    "428": {
-                        "description": "Precondition failed: Need to be with status Finalizado"
+                    "404": {
+                        "description": "Promotion not found"
                     }
                 }
-            }
-        },
-        "/api/orders/{id}/done": {
-            "put": {
-                "description": "Update an order. This service wil be used by the kitchen to notify a customer and the waiter that the order is done",
+            },
+            "delete": {
+                "description": "Delete a promotion by ID. The orders keep the discounts already given",
                 "consumes": [
                     "application/json"
                 ],
@@ -333,9 +401,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
+                    "Promotion"
                 ],
-                "summary": "Update an order to DONE",
+                "summary": "Delete a promotion",
                 "parameters": [
                     {
                         "type": "integer",
@@ -350,207 +418,245 @@ const docTemplate = `{
                         "description": "No Content"
                     },
                     "404": {
-                        "description": "Order not found"
-                    },
-                    "428": {
-                        "description": "Precondition failed: Need to be with status Preparando"
+                        "description": "Promotion not found"
                     }
                 }
             }
         },
-        "/api/orders/{id}/not-delivered": {
-            "put": {
-                "description": "Update an order. This service wil be used by the waiter to close the order informing that user didn't get the order",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/reconciliation/qrcode": {
+            "get": {
+                "description": "Get the result of the last run of the QR Code reconciliation. It checks in Mercado Livre the orders\nwaiting payment whose webhook did not arrive, finishing the paid ones and canceling the expired ones.\nThis endpoint will be used by the admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Order"
-                ],
-                "summary": "Update an order to NOT_DELIVERED",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "12",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Payment"
                 ],
+                "summary": "Last QR Code reconciliation",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QRCodeReconciliation"
+                        }
                     },
                     "404": {
-                        "description": "Order not found"
-                    },
-                    "428": {
-                        "description": "Precondition failed: Need to be with status Finalizado"
+                        "description": "The reconciliation did not run yet"
                     }
                 }
             }
         },
-        "/api/orders/{id}/preparing": {
-            "put": {
-                "description": "Update an order. This service wil be used by the kitchen to notify a customer that the order is being prepared",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/reports/hourly-volume": {
+            "get": {
+                "description": "Get the number of orders created in each hour of the day. All the 24 hours are returned.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Order"
+                    "Report"
                 ],
-                "summary": "Update an order to PREPARING",
+                "summary": "Hourly order volume",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "12",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "404": {
-                        "description": "Order not found"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.HourlyVolume"
+                            }
+                        }
                     },
-                    "428": {
-                        "description": "Precondition failed: Need to be with status Criado"
+                    "400": {
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/payments": {
-            "post": {
-                "description": "Create a payment and return its ID. With it, we can proceed with a Order Creation",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/reports/not-delivered": {
+            "get": {
+                "description": "Get the number of delivered and not delivered orders and the rate of the not delivered ones.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Payment"
+                    "Report"
                 ],
-                "summary": "Create new payment",
+                "summary": "Not delivered rate",
                 "parameters": [
                     {
-                        "description": "payment",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.Payment"
-                        }
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PaymentResponse"
+                            "$ref": "#/definitions/dto.NotDeliveredReport"
                         }
                     },
                     "400": {
-                        "description": "Payment has required fields"
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/payments/type": {
+        "/api/admin/reports/order-times": {
             "get": {
-                "description": "Get payment type, like [DEBIT, CREDIT, QR Code (Mercado Pago)]",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the average and the p95 of the wait, preparation and pickup times in seconds.\nWait is until the kitchen starts the order, preparation is until it is done and pickup is until the waiter finishes it.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Payment"
+                    "Report"
+                ],
+                "summary": "Kitchen times",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get payment types",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.OrderTimesReport"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/products": {
-            "post": {
-                "description": "Create new product",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/admin/reports/revenue/categories": {
+            "get": {
+                "description": "Get the quantity sold and the revenue of each product category, including the modifiers. Canceled orders are not considered.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Product"
+                    "Report"
                 ],
-                "summary": "Create new product",
+                "summary": "Revenue per category",
                 "parameters": [
                     {
-                        "description": "product",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.ProductForm"
-                        }
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ProductResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RevenueByCategory"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Product has required fields"
-                    },
-                    "409": {
-                        "description": "This Product is already added"
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/products/categories": {
+        "/api/admin/reports/revenue/daily": {
             "get": {
-                "description": "Get all categories to filter in products by category",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the number of paid orders and the revenue of each day. Canceled orders are not considered.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Product"
+                    "Report"
                 ],
-                "summary": "Get all categories",
+                "summary": "Revenue per day",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "12",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -559,33 +665,45 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/dto.RevenueByDay"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/products/categories/{category}": {
+        "/api/admin/reports/revenue/products": {
             "get": {
-                "description": "List all products by a category",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the quantity sold and the revenue of each product, including the modifiers. Canceled orders are not considered.\nThis endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Product"
+                    "Report"
                 ],
-                "summary": "List all products by a category",
+                "summary": "Revenue per product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Lanches",
-                        "name": "category",
-                        "in": "path",
-                        "required": true
+                        "description": "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -594,16 +712,19 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.ProductResponse"
+                                "$ref": "#/definitions/dto.RevenueByProduct"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Invalid period"
                     }
                 }
             }
         },
-        "/api/products/{id}": {
+        "/api/customers/{id}": {
             "get": {
-                "description": "Get product by ID",
+                "description": "Get customer by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -611,14 +732,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Customer"
                 ],
-                "summary": "Get product by ID",
+                "summary": "Get customer by ID",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "12",
-                        "name": "id",
+                        "name": "Id",
                         "in": "path",
                         "required": true
                     }
@@ -627,13 +748,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ProductResponse"
+                            "$ref": "#/definitions/dto.Customer"
                         }
+                    },
+                    "404": {
+                        "description": "Customer not found"
                     }
                 }
             },
-            "put": {
-                "description": "Update a product by ID",
+            "delete": {
+                "description": "Erase the customer, as required by the LGPD. The customer is anonymized and removed from Cognito,\nand its orders are kept without the customer, so the sales totals do not change. Each erasure is kept in an audit record",
                 "consumes": [
                     "application/json"
                 ],
@@ -641,9 +765,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Customer"
                 ],
-                "summary": "Update a product",
+                "summary": "Erase customer data",
                 "parameters": [
                     {
                         "type": "integer",
@@ -651,16 +775,34 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer or admin login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own data"
+                    },
+                    "404": {
+                        "description": "Customer not found"
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a product by ID",
+            }
+        },
+        "/api/customers/{id}/data-export": {
+            "get": {
+                "description": "Export all the data kept about the customer, as required by the LGPD: the profile, the orders,\nthe payments and the loyalty points. Each export is kept in an audit record",
                 "consumes": [
                     "application/json"
                 ],
@@ -668,9 +810,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Customer"
                 ],
-                "summary": "Delete a product",
+                "summary": "Export customer data",
                 "parameters": [
                     {
                         "type": "integer",
@@ -678,18 +820,37 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer or admin login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerDataExport"
+                        }
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own data"
+                    },
+                    "404": {
+                        "description": "Customer not found"
                     }
                 }
             }
         },
-        "/api/qrcode/generate": {
-            "post": {
-                "description": "Generate a QR Code. This can be used to get the QR Code data, transform in a image and\npay with a Mercado Livre test account to activate a Webhook to proccess the order.",
+        "/api/customers/{id}/loyalty": {
+            "get": {
+                "description": "Get the points balance of the customer and the history of the points earned with the delivered orders,\nredeemed as discount and reversed by the canceled or not delivered orders",
                 "consumes": [
                     "application/json"
                 ],
@@ -697,33 +858,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "QRCode"
+                    "Customer"
                 ],
-                "summary": "Generate a QR Code",
+                "summary": "Get customer loyalty points",
                 "parameters": [
                     {
-                        "description": "qrCodeOrder",
-                        "name": "qrCodeOrder",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.QRCodeOrder"
-                        }
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.QRCodeDataResponse"
+                            "$ref": "#/definitions/dto.LoyaltyResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Customer not found"
                     }
                 }
             }
         },
-        "/api/users/login": {
-            "post": {
-                "description": "Get user by CPF. This Endpoint can be used as a Login",
+        "/api/customers/{id}/orders": {
+            "get": {
+                "description": "Get the orders of the logged customer with their products, status dates and payments, the newest first.\nUse the 'nextCursor' of the response as the 'cursor' param to get the next page. When it is null, there are no more orders",
                 "consumes": [
                     "application/json"
                 ],
@@ -731,69 +893,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "UserAdmin"
+                    "Customer"
                 ],
-                "summary": "Get user by CPF",
+                "summary": "Get customer orders",
                 "parameters": [
                     {
-                        "description": "UserAdminForm",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserAdminForm"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserAdmin"
-                        }
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
-                    "404": {
-                        "description": "User not found"
-                    }
-                }
-            }
-        },
-        "/api/users/{id}": {
-            "get": {
-                "description": "Get user by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "UserAdmin"
-                ],
-                "summary": "Get user by ID",
-                "parameters": [
                     {
                         "type": "string",
-                        "description": "12",
-                        "name": "Id",
-                        "in": "path",
+                        "description": "Bearer token of the customer login",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, up to 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAdmin"
+                            "$ref": "#/definitions/dto.OrderPage"
                         }
                     },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own data"
+                    },
                     "404": {
-                        "description": "User not found"
+                        "description": "Customer not found"
                     }
                 }
-            },
-            "put": {
-                "description": "Update user",
+            }
+        },
+        "/api/orders": {
+            "post": {
+                "description": "Create new order. To make an order the payment needs to be completed\nA new Ticket will be generated by the Order Date starting from 1\nIn the next day the Ticket number will starts from 1 and so on\nThe totalPrice is the products total. The promotions and the couponCode discounts are taken from it",
                 "consumes": [
                     "application/json"
                 ],
@@ -801,43 +954,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "UserAdmin"
+                    "Order"
                 ],
-                "summary": "Update user",
+                "summary": "Create new order",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "12",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "customer",
+                        "description": "order",
                         "name": "product",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.Customer"
+                            "$ref": "#/definitions/dto.Order"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
                     },
                     "400": {
-                        "description": "User has required fields"
-                    },
-                    "404": {
-                        "description": "User not found"
+                        "description": "Order has required fields"
                     }
                 }
             }
         },
-        "/api/webhook/ml/payment": {
+        "/api/orders/price": {
             "post": {
-                "description": "Payment Webhook. This endpoint will be called when the user pays\nthe QRCode generated by /api/qrcode/generate [post]",
+                "description": "Get the order price with the promotions and the couponCode discounts, before paying the order.\nThe totalPrice is the products total, and the payments must sum the returned totalPrice",
                 "consumes": [
                     "application/json"
                 ],
@@ -845,33 +991,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Webhook"
+                    "Order"
                 ],
-                "summary": "Payment Webhook",
+                "summary": "Get order price",
                 "parameters": [
                     {
-                        "description": "externalPaymentEvent",
-                        "name": "externalPaymentEvent",
+                        "description": "order",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.ExternalPaymentEvent"
+                            "$ref": "#/definitions/dto.OrderPriceForm"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderPriceResponse"
+                        }
                     },
-                    "406": {
-                        "description": "StatusNotAcceptable - Topic is not 'merchant_order'"
+                    "422": {
+                        "description": "The products or the coupon are not valid"
                     }
                 }
             }
         },
-        "/auth/admin/login": {
-            "post": {
-                "description": "Login the user by its CPF",
+        "/api/orders/status": {
+            "get": {
+                "description": "Get all orders status by the waiter and the customer. This endpoint will be used by the waiter and customer",
                 "consumes": [
                     "application/json"
                 ],
@@ -879,36 +1028,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "UserAdmin"
+                    "Order"
                 ],
-                "summary": "Login",
-                "parameters": [
-                    {
-                        "description": "user form",
-                        "name": "customer",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get all orders status different to prepare",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAdminForm"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OrderResponse"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/api/orders/stream": {
+            "get": {
+                "description": "Stream the status changes of all orders with Server-Sent Events. This endpoint will be used by the kitchen\nand the customer pickup monitor instead of polling the order lists. The browser resends the last received\nevent id in the Last-Event-ID header when it reconnects, so the missed events are sent first",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Stream the status changes of all orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Last received event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Token"
+                            "$ref": "#/definitions/dto.OrderEvent"
                         }
-                    },
-                    "404": {
-                        "description": "User not found"
                     }
                 }
             }
         },
-        "/auth/admin/signup": {
-            "post": {
-                "description": "Create new customer. This process is not required to make an order",
+        "/api/orders/to-prepare": {
+            "get": {
+                "description": "Get all orders already payed that needs to be prepared. This endpoint will be used by the kitchen",
                 "consumes": [
                     "application/json"
                 ],
@@ -916,39 +1082,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "UserAdmin"
+                    "Order"
                 ],
-                "summary": "Create new user admin",
-                "parameters": [
-                    {
-                        "description": "user admin",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get all orders to prepare",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAdmin"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OrderResponse"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/api/orders/waiting-payment": {
+            "get": {
+                "description": "Get all orders with waiting payment by the owner.\nThis endpoint will be used by the owner to know it the Mercado Livre QR Code was paid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
                 ],
+                "summary": "Get all orders with waiting payment status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAdminResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OrderResponse"
+                            }
                         }
-                    },
-                    "400": {
-                        "description": "Customer has required fields"
-                    },
-                    "409": {
-                        "description": "This user is already added"
                     }
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Login the customer by its CPF",
+        "/api/orders/{id}": {
+            "get": {
+                "description": "Get an order by Id",
                 "consumes": [
                     "application/json"
                 ],
@@ -956,36 +1134,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customer"
+                    "Order"
                 ],
-                "summary": "Login",
+                "summary": "Get order by Id",
                 "parameters": [
                     {
-                        "description": "customer form",
-                        "name": "customer",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CustomerForm"
-                        }
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest",
+                        "name": "Authorization",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Token"
+                            "$ref": "#/definitions/dto.OrderResponse"
                         }
                     },
-                    "404": {
-                        "description": "Customer not found"
+                    "400": {
+                        "description": "Order has required fields"
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own orders and payments"
                     }
                 }
             }
         },
-        "/auth/signup": {
-            "post": {
-                "description": "Create new customer. This process is not required to make an order",
+        "/api/orders/{id}/cancel": {
+            "put": {
+                "description": "Cancel an order. The order can only be canceled while it is in Em pagamento, Criado or Preparando status.\nIf the order was already paid, the payment will be refunded. Otherwise, the payment will be voided",
                 "consumes": [
                     "application/json"
                 ],
@@ -993,118 +1181,1330 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Customer"
+                    "Order"
                 ],
-                "summary": "Create new customer",
+                "summary": "Cancel an order",
                 "parameters": [
                     {
-                        "description": "customer",
-                        "name": "product",
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "orderCancelForm",
+                        "name": "orderCancelForm",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.Customer"
+                            "$ref": "#/definitions/dto.OrderCancelForm"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.CustomerResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Customer has required fields"
+                    "404": {
+                        "description": "Order not found"
                     },
-                    "409": {
-                        "description": "This Customer is already added"
+                    "428": {
+                        "description": "Precondition failed: Need to be with status Em pagamento, Criado or Preparando"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.Customer": {
-            "type": "object",
-            "required": [
-                "cpf",
-                "email",
-                "name"
-            ],
-            "properties": {
-                "cpf": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
+        },
+        "/api/orders/{id}/delivered": {
+            "put": {
+                "description": "Update an order. This service wil be used by the waiter to close the order informing that user got its order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Update an order to DELIVERED",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "428": {
+                        "description": "Precondition failed: Need to be with status Finalizado"
+                    }
+                }
+            }
+        },
+        "/api/orders/{id}/done": {
+            "put": {
+                "description": "Update an order. This service wil be used by the kitchen to notify a customer and the waiter that the order is done",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Update an order to DONE",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "428": {
+                        "description": "Precondition failed: Need to be with status Preparando"
+                    }
+                }
+            }
+        },
+        "/api/orders/{id}/history": {
+            "get": {
+                "description": "Get all status transitions of an order with its date, actor and reason.\nThis can be used to audit who moved the order and when",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Get order status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OrderStatusHistoryResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own orders and payments"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    }
+                }
+            }
+        },
+        "/api/orders/{id}/not-delivered": {
+            "put": {
+                "description": "Update an order. This service wil be used by the waiter to close the order informing that user didn't get the order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Update an order to NOT_DELIVERED",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "428": {
+                        "description": "Precondition failed: Need to be with status Finalizado"
+                    }
+                }
+            }
+        },
+        "/api/orders/{id}/preparing": {
+            "put": {
+                "description": "Update an order. This service wil be used by the kitchen to notify a customer that the order is being prepared",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Update an order to PREPARING",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    },
+                    "428": {
+                        "description": "Precondition failed: Need to be with status Criado"
+                    }
+                }
+            }
+        },
+        "/api/orders/{id}/stream": {
+            "get": {
+                "description": "Stream the status changes of a single order with Server-Sent Events. This endpoint will be used by the customer\nto know when the order is ready without polling it",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Order"
+                ],
+                "summary": "Stream the status changes of an order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last received event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderEvent"
+                        }
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own orders and payments"
+                    },
+                    "404": {
+                        "description": "Order not found"
+                    }
+                }
+            }
+        },
+        "/api/payments": {
+            "post": {
+                "description": "Create a payment and return its ID. With it, we can proceed with a Order Creation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Create new payment",
+                "parameters": [
+                    {
+                        "description": "payment",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Payment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Payment has required fields"
+                    }
+                }
+            }
+        },
+        "/api/payments/type": {
+            "get": {
+                "description": "Get payment type, like [DEBIT, CREDIT, QR Code (Mercado Pago)]",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get payment types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/payments/{id}": {
+            "get": {
+                "description": "Get a payment by Id with the order paid by it. The order is null while the payment was not used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get payment by Id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentOrderResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The customer can only access their own orders and payments"
+                    },
+                    "404": {
+                        "description": "Payment not found"
+                    }
+                }
+            }
+        },
+        "/api/products/categories": {
+            "get": {
+                "description": "Get all categories to filter in products by category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get all categories",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/categories/{category}": {
+            "get": {
+                "description": "List all products by a category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "List all products by a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lanches",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ProductResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/{id}": {
+            "get": {
+                "description": "Get product by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProductResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/qrcode/generate": {
+            "post": {
+                "description": "Generate a QR Code. This can be used to get the QR Code data, transform in a image and\npay with a Mercado Livre test account to activate a Webhook to proccess the order.\nSend the paymentType 'PIX' to get a PIX BR Code with its PNG image, encoded in base64.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QRCode"
+                ],
+                "summary": "Generate a QR Code",
+                "parameters": [
+                    {
+                        "description": "qrCodeOrder",
+                        "name": "qrCodeOrder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.QRCodeOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QRCodeDataResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "description": "Get user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserAdmin"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "12",
+                        "name": "Id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAdmin"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found"
+                    }
+                }
+            },
+            "put": {
+                "description": "Update user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserAdmin"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "12",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "customer",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Customer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "User has required fields"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    }
+                }
+            }
+        },
+        "/api/webhook/ml/payment": {
+            "post": {
+                "description": "Payment Webhook. This endpoint will be called when the user pays\nthe QRCode generated by /api/qrcode/generate [post]. The notification must be signed by Mercado Livre\nin the 'x-signature' header. The paid, expired, canceled and rejected payments finish the order and the payment,\nand the duplicated notifications are ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Payment Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ts=\u003ctimestamp\u003e,v1=\u003chash\u003e",
+                        "name": "x-signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notification request id",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "description": "externalPaymentEvent",
+                        "name": "externalPaymentEvent",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExternalPaymentEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Invalid signature"
+                    },
+                    "406": {
+                        "description": "StatusNotAcceptable - Topic is not 'merchant_order'"
+                    },
+                    "422": {
+                        "description": "Invalid external reference"
+                    }
+                }
+            }
+        },
+        "/api/webhook/payments/{provider}": {
+            "post": {
+                "description": "Payment provider Webhook. Each registered payment provider sends its notifications to this endpoint\nwith its own name, like 'sandbox' or 'mercadopago', and reads them in its own format.\nThe finished payments finish the paying orders and the duplicated notifications are ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Payment provider Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sandbox",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid notification"
+                    },
+                    "404": {
+                        "description": "Payment provider not registered"
+                    },
+                    "422": {
+                        "description": "The payment does not belong to the order"
+                    }
+                }
+            }
+        },
+        "/auth/admin/login": {
+            "post": {
+                "description": "Start the login of the user by its CPF. A one-time code is sent to the user email,\nand it must be sent with the 'session' to the '/auth/login/verify' to get the tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserAdmin"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "user form",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAdminForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginChallenge"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found"
+                    }
+                }
+            }
+        },
+        "/auth/admin/signup": {
+            "post": {
+                "description": "Create new admin. Only the admins can create other admins, and the first one is created by the 'cmd/admin' command",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserAdmin"
+                ],
+                "summary": "Create new user admin",
+                "parameters": [
+                    {
+                        "description": "user admin",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAdmin"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the admin login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAdminResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Customer has required fields"
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    },
+                    "403": {
+                        "description": "The user is not allowed to access this resource"
+                    },
+                    "409": {
+                        "description": "This user is already added"
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login with unknown user. This is important if the user doesn't want to create an account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customer"
+                ],
+                "summary": "Login with unknown user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Token"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found"
+                    }
+                }
+            }
+        },
+        "/auth/login/verify": {
+            "post": {
+                "description": "Finish the login of a customer or an admin with the code sent by the '/auth/login' or '/auth/admin/login'.\nThe 'refreshToken' gets new access tokens in the '/auth/token/refresh' until the logout.\nWith the token of a guest, the orders created by the guest are given to the customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify login code",
+                "parameters": [
+                    {
+                        "description": "login code form",
+                        "name": "form",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginCodeForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Token"
+                        }
+                    },
+                    "401": {
+                        "description": "The login code is not valid or the session is expired"
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the refresh token and the access tokens of the login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "refresh token form",
+                        "name": "form",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "The Bearer token is required"
+                    }
+                }
+            }
+        },
+        "/auth/signup": {
+            "post": {
+                "description": "Create new customer. This process is not required to make an order.\nWith the token of a guest, the orders created by the guest are given to the new customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customer"
+                ],
+                "summary": "Create new customer",
+                "parameters": [
+                    {
+                        "description": "customer",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Customer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Customer has required fields"
+                    },
+                    "409": {
+                        "description": "This Customer is already added"
+                    }
+                }
+            }
+        },
+        "/auth/token/refresh": {
+            "post": {
+                "description": "Get a new access token with the refresh token of the login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh token",
+                "parameters": [
+                    {
+                        "description": "refresh token form",
+                        "name": "form",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Token"
+                        }
+                    },
+                    "401": {
+                        "description": "The refresh token is not valid or was revoked"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.Customer": {
+            "type": "object",
+            "required": [
+                "cpf",
+                "email",
+                "name"
+            ],
+            "properties": {
+                "cpf": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerDataExport": {
+            "type": "object",
+            "properties": {
+                "customer": {
+                    "$ref": "#/definitions/dto.Customer"
+                },
+                "exportedAt": {
+                    "type": "string"
+                },
+                "loyaltyTransactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoyaltyTransactionResponse"
+                    }
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderResponse"
+                    }
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PaymentDetails"
+                    }
+                }
+            }
+        },
+        "dto.CustomerForm": {
+            "type": "object",
+            "required": [
+                "cpf"
+            ],
+            "properties": {
+                "cpf": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ExternalPaymentEvent": {
+            "type": "object",
+            "properties": {
+                "resource": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HourlyVolume": {
+            "type": "object",
+            "properties": {
+                "hour": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.LoginChallenge": {
+            "type": "object",
+            "properties": {
+                "destination": {
+                    "type": "string"
+                },
+                "session": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginCodeForm": {
+            "type": "object",
+            "required": [
+                "code",
+                "cpf",
+                "session"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "cpf": {
+                    "type": "string"
+                },
+                "session": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoyaltyResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "balanceValue": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "customerId": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoyaltyTransactionResponse"
+                    }
+                }
+            }
+        },
+        "dto.LoyaltyTransactionResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "orderId": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NotDeliveredReport": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "type": "integer"
+                },
+                "notDelivered": {
+                    "type": "integer"
+                },
+                "notDeliveredRate": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.Order": {
+            "type": "object",
+            "required": [
+                "orderProducts",
+                "paymentId",
+                "totalPrice"
+            ],
+            "properties": {
+                "couponCode": {
+                    "type": "string"
                 },
-                "id": {
+                "customerId": {
                     "type": "integer"
                 },
-                "name": {
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderDiscount"
+                    }
+                },
+                "loyaltyDiscount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "loyaltyPoints": {
+                    "type": "integer"
+                },
+                "orderProducts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderProduct"
+                    }
+                },
+                "orderStatus": {
                     "type": "string"
+                },
+                "paymentId": {
+                    "type": "integer"
+                },
+                "paymentIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ticketNumber": {
+                    "type": "integer"
+                },
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
-        "dto.CustomerForm": {
+        "dto.OrderCancelForm": {
             "type": "object",
             "required": [
-                "cpf"
+                "reason"
             ],
             "properties": {
-                "cpf": {
+                "reason": {
                     "type": "string"
                 }
             }
         },
-        "dto.CustomerResponse": {
+        "dto.OrderDiscount": {
             "type": "object",
             "properties": {
-                "id": {
+                "amount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "promotionId": {
                     "type": "integer"
                 }
             }
         },
-        "dto.ExternalPaymentEvent": {
+        "dto.OrderEvent": {
             "type": "object",
             "properties": {
-                "resource": {
+                "actor": {
                     "type": "string"
                 },
-                "topic": {
+                "changedAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "fromStatus": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "integer"
+                },
+                "toStatus": {
                     "type": "string"
                 }
             }
         },
-        "dto.Order": {
+        "dto.OrderPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderResponse"
+                    }
+                }
+            }
+        },
+        "dto.OrderPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "paymentId": {
+                    "type": "integer"
+                },
+                "paymentStatus": {
+                    "type": "string"
+                },
+                "paymentType": {
+                    "type": "string"
+                },
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.OrderPriceForm": {
             "type": "object",
             "required": [
                 "orderProducts",
-                "paymentId",
                 "totalPrice"
             ],
             "properties": {
+                "couponCode": {
+                    "type": "string"
+                },
                 "customerId": {
                     "type": "integer"
                 },
+                "loyaltyPoints": {
+                    "type": "integer"
+                },
                 "orderProducts": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/dto.OrderProduct"
                     }
                 },
-                "orderStatus": {
-                    "type": "string"
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.OrderPriceResponse": {
+            "type": "object",
+            "properties": {
+                "discountPrice": {
+                    "$ref": "#/definitions/entity.Money"
                 },
-                "paymentId": {
-                    "type": "integer"
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderDiscount"
+                    }
                 },
-                "ticketNumber": {
-                    "type": "integer"
+                "loyaltyDiscount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "productsPrice": {
+                    "$ref": "#/definitions/entity.Money"
                 },
                 "totalPrice": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
@@ -1115,11 +2515,54 @@ const docTemplate = `{
                 "productPrice"
             ],
             "properties": {
+                "modifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderProductModifier"
+                    }
+                },
                 "productId": {
                     "type": "integer"
                 },
                 "productPrice": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.OrderProductModifier": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "productId": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OrderProductModifierResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
@@ -1132,64 +2575,201 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "modifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderProductModifierResponse"
+                    }
+                },
                 "name": {
                     "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unitPrice": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "canceledAt": {
+                    "type": "string"
+                },
+                "customerName": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "discountPrice": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderDiscount"
+                    }
+                },
+                "doneAt": {
+                    "type": "string"
+                },
+                "loyaltyDiscount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "loyaltyPoints": {
+                    "type": "integer"
+                },
+                "notDeliveredAt": {
+                    "type": "string"
+                },
+                "orderDate": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "integer"
+                },
+                "orderProducts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderProductResponse"
+                    }
+                },
+                "orderStatus": {
+                    "type": "string"
+                },
+                "paymentId": {
+                    "type": "integer"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderPaymentResponse"
+                    }
+                },
+                "preparingAt": {
+                    "type": "string"
+                },
+                "ticketNumber": {
+                    "type": "integer"
+                },
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.OrderStatusHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "changedAt": {
+                    "type": "string"
+                },
+                "fromStatus": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "toStatus": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OrderTimesReport": {
+            "type": "object",
+            "properties": {
+                "averagePickupSeconds": {
+                    "type": "number"
+                },
+                "averagePreparationSeconds": {
+                    "type": "number"
+                },
+                "averageWaitSeconds": {
+                    "type": "number"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "p95PickupSeconds": {
+                    "type": "number"
+                },
+                "p95PreparationSeconds": {
+                    "type": "number"
+                },
+                "p95WaitSeconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.Payment": {
+            "type": "object",
+            "required": [
+                "paymentType",
+                "totalPrice"
+            ],
+            "properties": {
+                "customerId": {
+                    "type": "integer"
+                },
+                "paymentType": {
+                    "type": "string"
+                },
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
-        "dto.OrderResponse": {
+        "dto.PaymentDetails": {
             "type": "object",
             "properties": {
-                "customerName": {
-                    "type": "string"
-                },
-                "deliveredAt": {
-                    "type": "string"
-                },
-                "doneAt": {
-                    "type": "string"
-                },
-                "notDeliveredAt": {
-                    "type": "string"
+                "customerId": {
+                    "type": "integer"
                 },
-                "orderDate": {
+                "paymentGatewayId": {
                     "type": "string"
                 },
-                "orderId": {
+                "paymentId": {
                     "type": "integer"
                 },
-                "orderProducts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.OrderProductResponse"
-                    }
-                },
-                "orderStatus": {
+                "paymentStatus": {
                     "type": "string"
                 },
-                "preparingAt": {
+                "paymentType": {
                     "type": "string"
                 },
-                "ticketNumber": {
-                    "type": "integer"
+                "totalPrice": {
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
-        "dto.Payment": {
+        "dto.PaymentOrderResponse": {
             "type": "object",
-            "required": [
-                "paymentType",
-                "totalPrice"
-            ],
             "properties": {
                 "customerId": {
                     "type": "integer"
                 },
+                "order": {
+                    "$ref": "#/definitions/dto.OrderResponse"
+                },
+                "paymentGatewayId": {
+                    "type": "string"
+                },
+                "paymentId": {
+                    "type": "integer"
+                },
+                "paymentStatus": {
+                    "type": "string"
+                },
                 "paymentType": {
                     "type": "string"
                 },
                 "totalPrice": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
@@ -1253,7 +2833,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "price": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
@@ -1292,7 +2872,137 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "price": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.PromotionCreationResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PromotionForm": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "buyQuantity": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "freeQuantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "type": "integer"
+                },
+                "productId": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "usageLimitPerCustomer": {
+                    "type": "integer"
+                },
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dto.PromotionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "$ref": "#/definitions/entity.Money"
+                },
+                "buyQuantity": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "freeQuantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "type": "integer"
+                },
+                "productId": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "usageLimitPerCustomer": {
+                    "type": "integer"
+                },
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
@@ -1301,6 +3011,12 @@ const docTemplate = `{
             "properties": {
                 "data": {
                     "type": "string"
+                },
+                "png": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
@@ -1311,9 +3027,15 @@ const docTemplate = `{
                 "totalPrice"
             ],
             "properties": {
+                "couponCode": {
+                    "type": "string"
+                },
                 "customerId": {
                     "type": "integer"
                 },
+                "loyaltyPoints": {
+                    "type": "integer"
+                },
                 "orderProducts": {
                     "type": "array",
                     "items": {
@@ -1326,11 +3048,131 @@ const docTemplate = `{
                 "paymentID": {
                     "type": "integer"
                 },
+                "paymentIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "paymentType": {
+                    "type": "string"
+                },
                 "ticketNumber": {
                     "type": "integer"
                 },
                 "totalPrice": {
-                    "type": "number"
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.QRCodeReconciliation": {
+            "type": "object",
+            "properties": {
+                "canceled": {
+                    "type": "integer"
+                },
+                "checked": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.QRCodeReconciliationOrder"
+                    }
+                },
+                "paid": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.QRCodeReconciliationOrder": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "integer"
+                },
+                "paymentId": {
+                    "type": "integer"
+                },
+                "result": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RefreshTokenForm": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RevenueByCategory": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.RevenueByDay": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "$ref": "#/definitions/entity.Money"
+                }
+            }
+        },
+        "dto.RevenueByProduct": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "productId": {
+                    "type": "integer"
+                },
+                "productName": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "$ref": "#/definitions/entity.Money"
                 }
             }
         },
@@ -1339,6 +3181,15 @@ const docTemplate = `{
             "properties": {
                 "accessToken": {
                     "type": "string"
+                },
+                "expiresIn": {
+                    "type": "integer"
+                },
+                "guestId": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
                 }
             }
         },
@@ -1382,6 +3233,17 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "entity.Money": {
+            "type": "object",
+            "properties": {
+                "cents": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
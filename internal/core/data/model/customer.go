@@ -7,4 +7,5 @@ type Customer struct {
 	Name  string
 	CPF   string `gorm:"index;unique"`
 	Email string `gorm:"unique"`
+	Phone *string
 }
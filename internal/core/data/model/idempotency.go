@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// IdempotencyKey has no soft delete, so an expired key can be used again
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primarykey"`
+	Scope        string `gorm:"uniqueIndex:idx_idempotency_keys_scope_key"`
+	Key          string `gorm:"uniqueIndex:idx_idempotency_keys_scope_key"`
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	Owner        string    `gorm:"index"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,15 @@
+package model
+
+import "gorm.io/gorm"
+
+// LoyaltyTransaction is an entry of the customer points ledger. The Points are negative when
+// redeemed or reversed, so the balance is their sum. The unique index allows a single entry of
+// each type by order, so an order never earns, redeems or reverses its points twice
+type LoyaltyTransaction struct {
+	gorm.Model
+	CustomerID  uint   `gorm:"index"`
+	OrderID     *uint  `gorm:"uniqueIndex:idx_loyalty_transactions_order_type,where:deleted_at IS NULL"`
+	Type        string `gorm:"uniqueIndex:idx_loyalty_transactions_order_type,where:deleted_at IS NULL"`
+	Points      int
+	Description string
+}
@@ -0,0 +1,29 @@
+package model
+
+import "fmt"
+
+// MoneyMigrations convert the amounts stored in reais, as decimal columns, to the cents stored by entity.Money.
+// They run before the AutoMigrate, which would only cast 12.90 to 13, and do nothing once the column is bigint
+var MoneyMigrations = []string{
+	moneyMigration("products", "price"),
+	moneyMigration("orders", "total_price"),
+	moneyMigration("order_products", "unit_price"),
+	moneyMigration("order_product_modifiers", "price"),
+	moneyMigration("payments", "total_price"),
+}
+
+func moneyMigration(table string, column string) string {
+	return fmt.Sprintf(`
+	DO $$
+	BEGIN
+		IF EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema()
+				AND table_name = '%[1]v'
+				AND column_name = '%[2]v'
+				AND data_type <> 'bigint'
+		) THEN
+			ALTER TABLE %[1]v ALTER COLUMN %[2]v TYPE bigint USING ROUND(%[2]v * 100);
+		END IF;
+	END $$`, table, column)
+}
@@ -3,31 +3,106 @@ package model
 import (
 	"time"
 
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"gorm.io/gorm"
 )
 
 const (
-	OrderStatusPaying       = "Em pagamento"
-	OrderStatusCreated      = "Criado"
-	OrderStatusPreparing    = "Preparando"
-	OrderStatusDone         = "Finalizado"
-	OrderStatusDelivered    = "Entregue"
-	OrderStatusNotDelivered = "Não entregue"
+	OrderStatusPaying       = entity.OrderStatusPaying
+	OrderStatusCreated      = entity.OrderStatusCreated
+	OrderStatusPreparing    = entity.OrderStatusPreparing
+	OrderStatusDone         = entity.OrderStatusDone
+	OrderStatusDelivered    = entity.OrderStatusDelivered
+	OrderStatusNotDelivered = entity.OrderStatusNotDelivered
+	OrderStatusCanceled     = entity.OrderStatusCanceled
 )
 
+// OrderIndexes are created after the migration because the 'created_at' column comes from gorm.Model.
+// They are used by the order lists, which always filter by status and sort by the creation date
+var OrderIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_orders_order_status_created_at ON orders (order_status, created_at)",
+	"CREATE INDEX IF NOT EXISTS idx_orders_created_at_id ON orders (created_at, id)",
+}
+
+// OrderPaymentsBackfill links the orders created before the split payments to their single payment
+const OrderPaymentsBackfill = `
+	INSERT INTO order_payments (created_at, updated_at, order_id, payment_id)
+	SELECT o.created_at, o.created_at, o.id, o.payment_id
+	FROM orders o
+	WHERE o.deleted_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM order_payments op WHERE op.order_id = o.id AND op.deleted_at IS NULL
+		)`
+
+// Order can be split in many payments, which are linked by the OrderPayment. The PaymentID is the
+// main payment, the one paid by the QR Code when it exists, because the QR Code finishes the order.
+// The unique index ignores the orders deleted when the QR Code generation fails. The LoyaltyPoints
+// are the points redeemed by the customer, which give the LoyaltyDiscount. The GuestID is the unknown
+// customer who created the order, so the order goes to the customer when the guest identifies itself
 type Order struct {
 	gorm.Model
-	OrderStatus    string
-	TotalPrice     float64
-	PaymentID      uint
-	CustomerID     *uint
-	Customer       *Customer
-	TicketNumber   int
-	PreparingAt    *time.Time
-	DoneAt         *time.Time
-	DeliveredAt    *time.Time
-	NotDeliveredAt *time.Time
-	OrderProduct   []OrderProduct
+	OrderStatus     string
+	TotalPrice      entity.Money
+	PaymentID       uint `gorm:"uniqueIndex:idx_orders_payment_id,where:deleted_at IS NULL"`
+	Payment         *Payment
+	Payments        []OrderPayment
+	CustomerID      *uint `gorm:"index"`
+	Customer        *Customer
+	GuestID         *string `gorm:"index"`
+	TicketNumber    int
+	PreparingAt     *time.Time
+	DoneAt          *time.Time
+	DeliveredAt     *time.Time
+	NotDeliveredAt  *time.Time
+	CanceledAt      *time.Time
+	OrderProduct    []OrderProduct
+	Discounts       []OrderDiscount
+	LoyaltyPoints   int
+	LoyaltyDiscount entity.Money
+	Currency        string `gorm:"size:3;not null;default:'BRL'"`
+	Erased          bool   `gorm:"not null;default:false"`
+}
+
+// BeforeSave keeps the currency of the amounts, since the bigint columns have only the cents
+func (order *Order) BeforeSave(tx *gorm.DB) (err error) {
+	order.Currency, err = entity.CommonCurrency(order.TotalPrice, order.LoyaltyDiscount)
+	return err
+}
+
+func (order *Order) AfterFind(tx *gorm.DB) error {
+	order.TotalPrice = order.TotalPrice.In(order.Currency)
+	order.LoyaltyDiscount = order.LoyaltyDiscount.In(order.Currency)
+	return nil
+}
+
+// OrderPayment is a payment used by the order. Each payment pays only one order
+type OrderPayment struct {
+	gorm.Model
+	OrderID   uint `gorm:"index"`
+	PaymentID uint `gorm:"uniqueIndex:idx_order_payments_payment_id,where:deleted_at IS NULL"`
+	Payment   *Payment
+}
+
+// OrderDiscount is a promotion applied to the order. The Description and the Code are copied,
+// so the order keeps showing them after the promotion changes
+type OrderDiscount struct {
+	gorm.Model
+	OrderID     uint `gorm:"index"`
+	PromotionID uint `gorm:"index"`
+	Description string
+	Code        *string
+	Amount      entity.Money
+	Currency    string `gorm:"size:3;not null;default:'BRL'"`
+}
+
+func (discount *OrderDiscount) BeforeSave(tx *gorm.DB) (err error) {
+	discount.Currency, err = entity.CommonCurrency(discount.Amount)
+	return err
+}
+
+func (discount *OrderDiscount) AfterFind(tx *gorm.DB) error {
+	discount.Amount = discount.Amount.In(discount.Currency)
+	return nil
 }
 
 type OrderProduct struct {
@@ -35,9 +110,64 @@ type OrderProduct struct {
 	OrderID   uint
 	ProductID uint
 	Product   Product
+	Quantity  int `gorm:"default:1"`
+	UnitPrice entity.Money
+	Currency  string `gorm:"size:3;not null;default:'BRL'"`
+	Modifiers []OrderProductModifier
+}
+
+func (orderProduct *OrderProduct) BeforeSave(tx *gorm.DB) (err error) {
+	orderProduct.Currency, err = entity.CommonCurrency(orderProduct.UnitPrice)
+	return err
+}
+
+func (orderProduct *OrderProduct) AfterFind(tx *gorm.DB) error {
+	orderProduct.UnitPrice = orderProduct.UnitPrice.In(orderProduct.Currency)
+	return nil
+}
+
+type OrderProductModifier struct {
+	gorm.Model
+	OrderProductID uint `gorm:"index"`
+	Type           string
+	Description    string
+	ProductID      *uint
+	Price          entity.Money
+	Currency       string `gorm:"size:3;not null;default:'BRL'"`
+}
+
+func (modifier *OrderProductModifier) BeforeSave(tx *gorm.DB) (err error) {
+	modifier.Currency, err = entity.CommonCurrency(modifier.Price)
+	return err
+}
+
+func (modifier *OrderProductModifier) AfterFind(tx *gorm.DB) error {
+	modifier.Price = modifier.Price.In(modifier.Currency)
+	return nil
 }
 
 type OrderTicketNumber struct {
 	Date         int64 `gorm:"index;unique"`
 	TicketNumber int
 }
+
+type OrderStatusHistory struct {
+	gorm.Model
+	OrderID    uint `gorm:"index"`
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	Reason     string
+}
+
+type OrderEvent struct {
+	gorm.Model
+	OrderID    uint `gorm:"index"`
+	FromStatus string
+	ToStatus   string
+	Actor      string
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
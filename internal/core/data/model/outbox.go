@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is written in the same transaction as the order or payment change, so an
+// event is never lost or published for a change which was rolled back
+type OutboxEvent struct {
+	gorm.Model
+	EventType     string
+	AggregateType string
+	AggregateID   uint   `gorm:"index"`
+	Payload       string `gorm:"type:jsonb"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	PublishedAt   *time.Time
+	LastError     string
+}
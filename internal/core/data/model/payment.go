@@ -1,21 +1,41 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"gorm.io/gorm"
+)
 
 const (
-	PaymentPayingStatus = "Pagando"
-	PaymentPayedStatus  = "Pago"
-	PaymentErrorStatus  = "Erro"
+	PaymentPayingStatus   = entity.PaymentPayingStatus
+	PaymentPayedStatus    = entity.PaymentPayedStatus
+	PaymentErrorStatus    = entity.PaymentErrorStatus
+	PaymentRefundedStatus = entity.PaymentRefundedStatus
+	PaymentVoidedStatus   = entity.PaymentVoidedStatus
 
-	PaymentCreditType = "Crédito"
-	PaymentQRCodeType = "QR Code (Mercado Pago)"
+	PaymentCreditType = entity.PaymentCreditType
+	PaymentQRCodeType = entity.PaymentQRCodeType
+	PaymentPIXType    = entity.PaymentPIXType
 )
 
 type Payment struct {
 	gorm.Model
-	CustomerID    *uint
-	Customer      *Customer
-	TotalPrice    float64
-	PaymentStatus string
-	PaymentType   string
+	CustomerID       *uint
+	Customer         *Customer
+	TotalPrice       entity.Money
+	Currency         string `gorm:"size:3;not null;default:'BRL'"`
+	PaymentStatus    string
+	PaymentType      string
+	GatewayPaymentID string
+	Erased           bool `gorm:"not null;default:false"`
+}
+
+// BeforeSave keeps the currency of the amount, since the bigint column has only the cents
+func (payment *Payment) BeforeSave(tx *gorm.DB) (err error) {
+	payment.Currency, err = entity.CommonCurrency(payment.TotalPrice)
+	return err
+}
+
+func (payment *Payment) AfterFind(tx *gorm.DB) error {
+	payment.TotalPrice = payment.TotalPrice.In(payment.Currency)
+	return nil
 }
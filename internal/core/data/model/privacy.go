@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// DataSubjectRequest is the audit of each export and erasure of the customer data. It is kept
+// after the erasure, with only the id of the anonymized customer
+type DataSubjectRequest struct {
+	gorm.Model
+	CustomerID  uint `gorm:"index"`
+	Type        string
+	Actor       string
+	RequestedBy string
+}
@@ -1,13 +1,16 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"gorm.io/gorm"
+)
 
 const (
-	CategorySnack    = "Lanche"
-	CategoryBeverage = "Bebida"
-	CategoryDesert   = "Sobremesa"
-	CategoryToppings = "Acompanhamento"
-	CategoryCombo    = "Combo"
+	CategorySnack    = entity.CategorySnack
+	CategoryBeverage = entity.CategoryBeverage
+	CategoryDesert   = entity.CategoryDesert
+	CategoryToppings = entity.CategoryToppings
+	CategoryCombo    = entity.CategoryCombo
 )
 
 type Product struct {
@@ -15,11 +18,23 @@ type Product struct {
 	Name         string `gorm:"unique"`
 	Description  string
 	Category     string
-	Price        float64
+	Price        entity.Money
+	Currency     string `gorm:"size:3;not null;default:'BRL'"`
 	ProductImage []ProductImage
 	ComboProduct []ComboProduct
 }
 
+// BeforeSave keeps the currency of the price, since the bigint column has only the cents
+func (product *Product) BeforeSave(tx *gorm.DB) (err error) {
+	product.Currency, err = entity.CommonCurrency(product.Price)
+	return err
+}
+
+func (product *Product) AfterFind(tx *gorm.DB) error {
+	product.Price = product.Price.In(product.Currency)
+	return nil
+}
+
 type ProductImage struct {
 	gorm.Model
 	ProductID uint
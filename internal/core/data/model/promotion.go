@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"gorm.io/gorm"
+)
+
+// Promotion keeps the Weekdays as a comma separated list, like '2,4'. The unique Code ignores
+// the deleted promotions, so a coupon code can be used again
+type Promotion struct {
+	gorm.Model
+	Name                  string
+	Code                  *string `gorm:"uniqueIndex:idx_promotions_code,where:deleted_at IS NULL"`
+	Type                  string
+	Percentage            int
+	Amount                entity.Money
+	BuyQuantity           int
+	FreeQuantity          int
+	Category              string
+	ProductID             *uint
+	StartsAt              *time.Time
+	EndsAt                *time.Time
+	Weekdays              string
+	StartTime             string
+	EndTime               string
+	UsageLimitPerCustomer int
+	Active                bool   `gorm:"index"`
+	Currency              string `gorm:"size:3;not null;default:'BRL'"`
+}
+
+// BeforeSave keeps the currency of the fixed amount, since the bigint column has only the cents
+func (promotion *Promotion) BeforeSave(tx *gorm.DB) (err error) {
+	promotion.Currency, err = entity.CommonCurrency(promotion.Amount)
+	return err
+}
+
+func (promotion *Promotion) AfterFind(tx *gorm.DB) error {
+	promotion.Amount = promotion.Amount.In(promotion.Currency)
+	return nil
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// RevokedToken is the login of an access token revoked by the logout. It is kept until all the
+// access tokens of the login are expired
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey"`
+	TokenID   string    `gorm:"uniqueIndex"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,10 @@
+package model
+
+import "gorm.io/gorm"
+
+// ProcessedWebhookEvent is written in the same transaction as the changes made by the webhook
+// notification, so a duplicated notification finds it and changes nothing
+type ProcessedWebhookEvent struct {
+	gorm.Model
+	EventKey string `gorm:"uniqueIndex"`
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/internal/integrations/model"
+	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type AuthRepository struct {
+	cognitoRemote remote.CognitoRemoteDataSource
+}
+
+func NewAuthRepository(cognitoRemote remote.CognitoRemoteDataSource) repository.AuthRepository {
+	return &AuthRepository{
+		cognitoRemote: cognitoRemote,
+	}
+}
+
+func (repository *AuthRepository) StartLogin(ctx context.Context, cpf string, code string) (string, error) {
+	session, err := repository.cognitoRemote.StartLogin(cpf, code)
+
+	if err != nil {
+		return "", getCognitoError(err)
+	}
+
+	return session, nil
+}
+
+func (repository *AuthRepository) RespondToLogin(ctx context.Context, form dto.LoginCodeForm) (dto.Token, error) {
+	authentication, err := repository.cognitoRemote.RespondToLogin(form.CPF, form.Session, form.Code)
+
+	if err != nil {
+		return dto.Token{}, getCognitoError(err)
+	}
+
+	return populateToken(authentication), nil
+}
+
+// RefreshToken returns a new access token. The refresh token is the same until the logout
+func (repository *AuthRepository) RefreshToken(ctx context.Context, refreshToken string) (dto.Token, error) {
+	authentication, err := repository.cognitoRemote.RefreshToken(refreshToken)
+
+	if err != nil {
+		return dto.Token{}, getCognitoError(err)
+	}
+
+	token := populateToken(authentication)
+	token.RefreshToken = refreshToken
+
+	return token, nil
+}
+
+func (repository *AuthRepository) RevokeToken(ctx context.Context, refreshToken string) error {
+	err := repository.cognitoRemote.RevokeToken(refreshToken)
+
+	if err != nil {
+		return getCognitoError(err)
+	}
+
+	return nil
+}
+
+func populateToken(authentication model.CognitoAuthentication) dto.Token {
+	return dto.Token{
+		AccessToken:  authentication.AccessToken,
+		RefreshToken: authentication.RefreshToken,
+		ExpiresIn:    authentication.ExpiresIn,
+	}
+}
+
+// getCognitoError keeps the errors already created by the data source, like the wrong login code
+func getCognitoError(err error) error {
+	var networkError *responses.NetworkError
+
+	if errors.As(err, &networkError) {
+		return err
+	}
+
+	return responses.GetCognitoError(err)
+}
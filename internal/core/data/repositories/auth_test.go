@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	external "github.com/thiagoluis88git/tech1/internal/integrations"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// The login does not use the database, so it runs with the fake Cognito, without the suite container
+func TestAuthRepository(t *testing.T) {
+	t.Run("got tokens, refreshed token and revoked token when logging in with the code", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeCognitoRemoteDataSource()
+		assert.NoError(t, fake.SignUp(&model.Customer{CPF: "17107972073"}))
+
+		repo := NewAuthRepository(fake)
+		ctx := context.TODO()
+
+		session, err := repo.StartLogin(ctx, "17107972073", "123456")
+		assert.NoError(t, err)
+
+		token, err := repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "123456"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token.AccessToken)
+		assert.NotEmpty(t, token.RefreshToken)
+		assert.Equal(t, int64(3600), token.ExpiresIn)
+
+		refreshed, err := repo.RefreshToken(ctx, token.RefreshToken)
+		assert.NoError(t, err)
+		assert.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+		assert.Equal(t, token.RefreshToken, refreshed.RefreshToken)
+
+		assert.NoError(t, repo.RevokeToken(ctx, token.RefreshToken))
+
+		_, err = repo.RefreshToken(ctx, token.RefreshToken)
+		assertStatusCode(t, http.StatusUnauthorized, err)
+	})
+
+	t.Run("got error when logging in with wrong code or unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeCognitoRemoteDataSource()
+		assert.NoError(t, fake.SignUp(&model.Customer{CPF: "17107972073"}))
+
+		repo := NewAuthRepository(fake)
+		ctx := context.TODO()
+
+		_, err := repo.StartLogin(ctx, "07073286083", "123456")
+		assertStatusCode(t, http.StatusNotFound, err)
+
+		session, err := repo.StartLogin(ctx, "17107972073", "123456")
+		assert.NoError(t, err)
+
+		_, err = repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "654321"})
+		assertStatusCode(t, http.StatusUnauthorized, err)
+
+		// The session ends after the wrong code, so the right code does not work anymore
+		_, err = repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "123456"})
+		assertStatusCode(t, http.StatusUnauthorized, err)
+	})
+}
+
+func (suite *RepositoryTestSuite) TestLoginWithLocalIdentityProvider() {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.NoError(err)
+
+	provider := external.NewLocalIdentityProvider(suite.db, signingKey, "user", "admin")
+	repo := NewAuthRepository(provider)
+	ctx := context.TODO()
+
+	suite.NoError(provider.SignUpAdmin(&model.UserAdmin{CPF: "17107972073", Email: "admin@email.com"}))
+
+	err = provider.SignUpAdmin(&model.UserAdmin{CPF: "17107972073"})
+	suite.Error(err)
+	var conflict *responses.NetworkError
+	suite.True(errors.As(getCognitoError(err), &conflict))
+	suite.Equal(http.StatusConflict, conflict.Code)
+
+	session, err := repo.StartLogin(ctx, "17107972073", "123456")
+	suite.NoError(err)
+
+	_, err = repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "654321"})
+	assertStatusCode(suite.T(), http.StatusUnauthorized, err)
+
+	_, err = repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "123456"})
+	assertStatusCode(suite.T(), http.StatusUnauthorized, err)
+
+	session, err = repo.StartLogin(ctx, "17107972073", "123456")
+	suite.NoError(err)
+
+	token, err := repo.RespondToLogin(ctx, dto.LoginCodeForm{CPF: "17107972073", Session: session, Code: "123456"})
+	suite.NoError(err)
+	suite.NotEmpty(token.RefreshToken)
+
+	accessToken, err := entity.ParseAccessToken(token.AccessToken)
+	suite.NoError(err)
+
+	publicKey, err := provider.KeySet().GetPublicKey(ctx, accessToken.Header.KeyID)
+	suite.NoError(err)
+	suite.NoError(accessToken.VerifySignature(publicKey))
+	suite.NoError(accessToken.Claims.Validate(entity.LocalIdentityIssuer, entity.LocalIdentityClientID, time.Now()))
+	suite.Equal([]string{"admin"}, accessToken.Claims.Groups)
+
+	refreshed, err := repo.RefreshToken(ctx, token.RefreshToken)
+	suite.NoError(err)
+
+	refreshedToken, err := entity.ParseAccessToken(refreshed.AccessToken)
+	suite.NoError(err)
+	suite.Equal(accessToken.Claims.TokenID(), refreshedToken.Claims.TokenID())
+
+	suite.NoError(repo.RevokeToken(ctx, token.RefreshToken))
+	suite.NoError(repo.RevokeToken(ctx, token.RefreshToken))
+
+	_, err = repo.RefreshToken(ctx, token.RefreshToken)
+	assertStatusCode(suite.T(), http.StatusUnauthorized, err)
+
+	unknownToken, err := provider.LoginUnknown()
+	suite.NoError(err)
+
+	unknown, err := entity.ParseAccessToken(unknownToken)
+	suite.NoError(err)
+	suite.Equal([]string{"user"}, unknown.Claims.Groups)
+}
+
+func assertStatusCode(t *testing.T, statusCode int, err error) {
+	var networkError *responses.NetworkError
+	assert.Equal(t, true, errors.As(err, &networkError))
+	assert.Equal(t, statusCode, networkError.Code)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 )
 
 func TestComboRepository(t *testing.T) {
@@ -26,7 +27,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Category",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -43,7 +44,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 		Name:        "New Product Created 2",
 		Description: "New Description Product Created 2",
 		Category:    "Category",
-		Price:       990,
+		Price:       entity.NewMoney(99000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl 2",
@@ -60,7 +61,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 		Name:        "New Product Created 3",
 		Description: "New Description Product Created 3",
 		Category:    "Category",
-		Price:       1990,
+		Price:       entity.NewMoney(199000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -82,7 +83,7 @@ func (suite *RepositoryTestSuite) TestCreateComboWithSuccess() {
 		Name:        "New Combo",
 		Description: "New Description Combo",
 		Category:    "Combo",
-		Price:       1990,
+		Price:       entity.NewMoney(199000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
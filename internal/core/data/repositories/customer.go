@@ -29,6 +29,7 @@ func (repository *CustomerRepository) CreateCustomer(ctx context.Context, custom
 		Name:  customer.Name,
 		CPF:   customer.CPF,
 		Email: customer.Email,
+		Phone: customer.Phone,
 	}
 
 	err := repository.cognitoRemote.SignUp(customerEntity)
@@ -52,6 +53,7 @@ func (repository *CustomerRepository) UpdateCustomer(ctx context.Context, custom
 		Name:  customer.Name,
 		CPF:   customer.CPF,
 		Email: customer.Email,
+		Phone: customer.Phone,
 	}
 
 	err := repository.db.WithContext(ctx).Save(&customerEntity).Error
@@ -100,19 +102,10 @@ func (repository *CustomerRepository) populateCustomer(customerEntity model.Cust
 		Name:  customerEntity.Name,
 		CPF:   customerEntity.CPF,
 		Email: customerEntity.Email,
+		Phone: customerEntity.Phone,
 	}
 }
 
-func (repository *CustomerRepository) Login(ctx context.Context, cpf string) (string, error) {
-	token, err := repository.cognitoRemote.Login(cpf)
-
-	if err != nil {
-		return "", responses.GetDatabaseError(err)
-	}
-
-	return token, nil
-}
-
 func (repository *CustomerRepository) LoginUnknown() (string, error) {
 	token, err := repository.cognitoRemote.LoginUnknown()
 
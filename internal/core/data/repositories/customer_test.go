@@ -80,3 +80,29 @@ func (suite *RepositoryTestSuite) TestGetCustomerByCPFWithSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), customer.ID)
 }
+
+func (suite *RepositoryTestSuite) TestCreateCustomerWithPhone() {
+	mockCognito := new(MockCognitoRemoteDataSource)
+	repo := NewCustomerRepository(suite.db, mockCognito)
+
+	phone := "+5511987654321"
+
+	mockCognito.On("SignUp", &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+		Phone: &phone,
+	}).Return(nil)
+
+	newId, err := repo.CreateCustomer(suite.ctx, dto.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+		Phone: &phone,
+	})
+	suite.NoError(err)
+
+	customer, err := repo.GetCustomerById(suite.ctx, newId)
+	suite.NoError(err)
+	suite.Equal(phone, *customer.Phone)
+}
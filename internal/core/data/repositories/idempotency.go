@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) repository.IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+// Reserve inserts the key in a single statement, so only one of the concurrent requests with
+// the same key is processed. An expired key, or a key without response for longer than the
+// lock timeout because its request crashed, is taken over as if it did not exist
+func (repository *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	request dto.IdempotentRequest,
+	expiresAt time.Time,
+	lockTimeout time.Duration,
+) (bool, error) {
+	now := time.Now()
+
+	result := repository.db.WithContext(ctx).
+		Exec(`INSERT INTO idempotency_keys (scope, key, request_hash, status_code, expires_at, created_at, updated_at)
+			VALUES (?, ?, ?, 0, ?, ?, ?)
+			ON CONFLICT (scope, key) DO UPDATE SET
+				request_hash = EXCLUDED.request_hash,
+				status_code = 0,
+				content_type = NULL,
+				response_body = NULL,
+				expires_at = EXCLUDED.expires_at,
+				created_at = EXCLUDED.created_at,
+				updated_at = EXCLUDED.updated_at
+			WHERE idempotency_keys.expires_at <= ?
+				OR (idempotency_keys.status_code = 0 AND idempotency_keys.updated_at <= ?)`,
+			request.Scope,
+			request.Key,
+			request.RequestHash,
+			expiresAt,
+			now,
+			now,
+			now,
+			now.Add(-lockTimeout),
+		)
+
+	if result.Error != nil {
+		return false, responses.GetDatabaseError(result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repository *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, scope string, key string) (dto.IdempotencyKey, error) {
+	var keyEntity model.IdempotencyKey
+
+	err := repository.db.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		First(&keyEntity).
+		Error
+
+	if err != nil {
+		return dto.IdempotencyKey{}, responses.GetDatabaseError(err)
+	}
+
+	idempotencyKey := dto.IdempotencyKey{
+		Scope:       keyEntity.Scope,
+		Key:         keyEntity.Key,
+		RequestHash: keyEntity.RequestHash,
+	}
+
+	if keyEntity.StatusCode != 0 {
+		idempotencyKey.Response = &dto.IdempotentResponse{
+			StatusCode:  keyEntity.StatusCode,
+			ContentType: keyEntity.ContentType,
+			Body:        keyEntity.ResponseBody,
+		}
+	}
+
+	return idempotencyKey, nil
+}
+
+func (repository *IdempotencyRepository) SaveResponse(ctx context.Context, request dto.IdempotentRequest, response dto.IdempotentResponse) error {
+	err := repository.db.WithContext(ctx).
+		Model(&model.IdempotencyKey{}).
+		Where("scope = ? AND key = ? AND request_hash = ?", request.Scope, request.Key, request.RequestHash).
+		Updates(map[string]any{
+			"status_code":   response.StatusCode,
+			"content_type":  response.ContentType,
+			"response_body": response.Body,
+			"owner":         request.Owner,
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Release deletes a key which has no response yet, so the client can retry the request
+func (repository *IdempotencyRepository) Release(ctx context.Context, request dto.IdempotentRequest) error {
+	err := repository.db.WithContext(ctx).
+		Where("scope = ? AND key = ? AND status_code = 0", request.Scope, request.Key).
+		Delete(&model.IdempotencyKey{}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := repository.db.WithContext(ctx).
+		Where("expires_at <= ?", now).
+		Delete(&model.IdempotencyKey{})
+
+	if result.Error != nil {
+		return 0, responses.GetDatabaseError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+func (suite *RepositoryTestSuite) TestReserveIdempotencyKeyWithSuccess() {
+	repo := NewIdempotencyRepository(suite.db)
+
+	request := dto.IdempotentRequest{
+		Scope:       "POST /api/orders",
+		Key:         "kiosk-1-order-1",
+		RequestHash: "hash",
+	}
+
+	reserved, err := repo.Reserve(suite.ctx, request, time.Now().Add(time.Hour), time.Minute)
+	suite.NoError(err)
+	suite.True(reserved)
+
+	reserved, err = repo.Reserve(suite.ctx, request, time.Now().Add(time.Hour), time.Minute)
+	suite.NoError(err)
+	suite.False(reserved)
+
+	// The same key can be used in another endpoint
+	reserved, err = repo.Reserve(suite.ctx, dto.IdempotentRequest{
+		Scope:       "POST /api/payments",
+		Key:         request.Key,
+		RequestHash: "hash",
+	}, time.Now().Add(time.Hour), time.Minute)
+	suite.NoError(err)
+	suite.True(reserved)
+
+	err = repo.SaveResponse(suite.ctx, request, dto.IdempotentResponse{
+		StatusCode:  http.StatusOK,
+		ContentType: "application/json",
+		Body:        []byte(`{"orderId":1}`),
+	})
+	suite.NoError(err)
+
+	stored, err := repo.GetIdempotencyKey(suite.ctx, request.Scope, request.Key)
+	suite.NoError(err)
+	suite.Equal("hash", stored.RequestHash)
+	suite.NotNil(stored.Response)
+	suite.Equal(http.StatusOK, stored.Response.StatusCode)
+	suite.Equal(`{"orderId":1}`, string(stored.Response.Body))
+
+	deleted, err := repo.DeleteExpired(suite.ctx, time.Now().Add(2*time.Hour))
+	suite.NoError(err)
+	suite.Equal(int64(2), deleted)
+
+	reserved, err = repo.Reserve(suite.ctx, request, time.Now().Add(time.Hour), time.Minute)
+	suite.NoError(err)
+	suite.True(reserved)
+}
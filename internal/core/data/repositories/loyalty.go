@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoyaltyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyRepository(db *gorm.DB) repository.LoyaltyRepository {
+	return &LoyaltyRepository{
+		db: db,
+	}
+}
+
+func (repository *LoyaltyRepository) GetBalance(ctx context.Context, customerID uint) (int, error) {
+	balance, err := getLoyaltyBalance(repository.db.WithContext(ctx), customerID)
+
+	if err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return balance, nil
+}
+
+func (repository *LoyaltyRepository) GetTransactions(ctx context.Context, customerID uint) ([]dto.LoyaltyTransactionResponse, error) {
+	var transactionEntity []model.LoyaltyTransaction
+
+	err := repository.
+		db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC, id DESC").
+		Find(&transactionEntity).
+		Error
+
+	if err != nil {
+		return []dto.LoyaltyTransactionResponse{}, responses.GetDatabaseError(err)
+	}
+
+	transactions := []dto.LoyaltyTransactionResponse{}
+
+	for _, value := range transactionEntity {
+		transactions = append(transactions, dto.LoyaltyTransactionResponse{
+			Id:          value.ID,
+			OrderID:     value.OrderID,
+			Type:        value.Type,
+			Points:      value.Points,
+			Description: value.Description,
+			CreatedAt:   value.CreatedAt,
+		})
+	}
+
+	return transactions, nil
+}
+
+func getLoyaltyBalance(tx *gorm.DB, customerID uint) (int, error) {
+	var balance int
+
+	err := tx.Model(&model.LoyaltyTransaction{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("customer_id = ?", customerID).
+		Scan(&balance).
+		Error
+
+	return balance, err
+}
+
+// redeemLoyaltyPointsInTransaction locks the customer until the order is created, so two orders
+// created at the same time can not redeem the same points
+func redeemLoyaltyPointsInTransaction(tx *gorm.DB, order model.Order) error {
+	if order.LoyaltyPoints == 0 || order.CustomerID == nil {
+		return nil
+	}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&model.Customer{}, *order.CustomerID).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	balance, err := getLoyaltyBalance(tx, *order.CustomerID)
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	if balance < order.LoyaltyPoints {
+		return &responses.LocalError{
+			Code:    responses.LOGIC_ERROR,
+			Message: fmt.Sprintf("The customer has %v points, not %v", balance, order.LoyaltyPoints),
+		}
+	}
+
+	err = tx.Create(&model.LoyaltyTransaction{
+		CustomerID:  *order.CustomerID,
+		OrderID:     &order.ID,
+		Type:        entity.LoyaltyTransactionRedeem,
+		Points:      -order.LoyaltyPoints,
+		Description: fmt.Sprintf("Resgate no pedido %v", order.ID),
+	}).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// earnLoyaltyPointsInTransaction credits the points of the delivered order. The unique index
+// ignores a second credit of the same order
+func earnLoyaltyPointsInTransaction(tx *gorm.DB, order model.Order) error {
+	points := entity.LoyaltyPointsEarned(order.TotalPrice)
+
+	if points == 0 || order.CustomerID == nil {
+		return nil
+	}
+
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.LoyaltyTransaction{
+			CustomerID:  *order.CustomerID,
+			OrderID:     &order.ID,
+			Type:        entity.LoyaltyTransactionEarn,
+			Points:      points,
+			Description: fmt.Sprintf("Pontos do pedido %v", order.ID),
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// reverseLoyaltyPointsInTransaction undoes the points of the order which was not delivered, giving
+// back the redeemed points and taking the earned ones
+func reverseLoyaltyPointsInTransaction(tx *gorm.DB, order model.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	var points int
+
+	err := tx.Model(&model.LoyaltyTransaction{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("order_id = ?", order.ID).
+		Scan(&points).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	if points == 0 {
+		return nil
+	}
+
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.LoyaltyTransaction{
+			CustomerID:  *order.CustomerID,
+			OrderID:     &order.ID,
+			Type:        entity.LoyaltyTransactionReversal,
+			Points:      -points,
+			Description: fmt.Sprintf("Estorno dos pontos do pedido %v", order.ID),
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
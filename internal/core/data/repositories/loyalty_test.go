@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestLoyaltyRepository(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
+
+func (suite *RepositoryTestSuite) TestLoyaltyPointsEarnedRedeemedAndReversed() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    entity.CategorySnack,
+		Price:       entity.NewMoney(1590),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repoOrder := NewOrderRespository(suite.db)
+	repo := NewLoyaltyRepository(suite.db)
+
+	orderResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(1590),
+		CustomerID:   &customer.ID,
+		PaymentID:    suite.createPayment(entity.NewMoney(1590)),
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: entity.NewMoney(1590),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	for _, transition := range [][]string{
+		{model.OrderStatusCreated, model.OrderStatusPreparing},
+		{model.OrderStatusPreparing, model.OrderStatusDone},
+		{model.OrderStatusDone, model.OrderStatusDelivered},
+	} {
+		err = repoOrder.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+			OrderID:    orderResponse.OrderId,
+			FromStatus: transition[0],
+			ToStatus:   transition[1],
+			Actor:      entity.OrderActorKitchen,
+		})
+		suite.NoError(err)
+	}
+
+	balance, err := repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(15, balance)
+
+	redeemingResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:      entity.NewMoney(1540),
+		CustomerID:      &customer.ID,
+		PaymentID:       suite.createPayment(entity.NewMoney(1540)),
+		TicketNumber:    2,
+		LoyaltyPoints:   10,
+		LoyaltyDiscount: entity.NewMoney(50),
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: entity.NewMoney(1590),
+			},
+		},
+	})
+	suite.NoError(err)
+	suite.Equal(entity.NewMoney(50), redeemingResponse.LoyaltyDiscount)
+
+	balance, err = repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(5, balance)
+
+	err = repoOrder.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+		OrderID:    redeemingResponse.OrderId,
+		FromStatus: model.OrderStatusCreated,
+		ToStatus:   model.OrderStatusCanceled,
+		Actor:      entity.OrderActorCustomer,
+	})
+	suite.NoError(err)
+
+	balance, err = repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(15, balance)
+
+	transactions, err := repo.GetTransactions(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Len(transactions, 3)
+	suite.Equal(entity.LoyaltyTransactionReversal, transactions[0].Type)
+	suite.Equal(10, transactions[0].Points)
+}
+
+func (suite *RepositoryTestSuite) TestMergeGuestOrdersWithLoyaltyPoints() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    entity.CategorySnack,
+		Price:       entity.NewMoney(1590),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repoOrder := NewOrderRespository(suite.db)
+	repo := NewLoyaltyRepository(suite.db)
+
+	guestID := "4f2a9c1b-guest"
+	otherGuestID := "8d3e7f0a-guest"
+	orderIDs := []uint{}
+
+	for index, guest := range []*string{&guestID, &guestID, &otherGuestID} {
+		orderResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+			TotalPrice:   entity.NewMoney(1590),
+			GuestID:      guest,
+			PaymentID:    suite.createPayment(entity.NewMoney(1590)),
+			TicketNumber: index + 1,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID:    productId,
+					ProductPrice: entity.NewMoney(1590),
+				},
+			},
+		})
+		suite.NoError(err)
+
+		orderIDs = append(orderIDs, orderResponse.OrderId)
+	}
+
+	// The first guest order is delivered before the guest identifies itself, so it earns nothing yet
+	for _, transition := range [][]string{
+		{model.OrderStatusCreated, model.OrderStatusPreparing},
+		{model.OrderStatusPreparing, model.OrderStatusDone},
+		{model.OrderStatusDone, model.OrderStatusDelivered},
+	} {
+		err = repoOrder.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+			OrderID:    orderIDs[0],
+			FromStatus: transition[0],
+			ToStatus:   transition[1],
+			Actor:      entity.OrderActorKitchen,
+		})
+		suite.NoError(err)
+	}
+
+	balance, err := repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(0, balance)
+
+	merged, err := repoOrder.MergeGuestOrders(suite.ctx, guestID, customer.ID)
+	suite.NoError(err)
+	suite.Equal(2, merged)
+
+	balance, err = repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(15, balance)
+
+	// The orders already merged are not merged again
+	merged, err = repoOrder.MergeGuestOrders(suite.ctx, guestID, customer.ID)
+	suite.NoError(err)
+	suite.Equal(0, merged)
+
+	page, err := repoOrder.GetCustomerOrders(suite.ctx, dto.CustomerOrdersFilter{CustomerID: customer.ID, Limit: 10})
+	suite.NoError(err)
+	suite.Len(page.Orders, 2)
+
+	// The second order earns its points when delivered, like the orders of the identified customers
+	for _, transition := range [][]string{
+		{model.OrderStatusCreated, model.OrderStatusPreparing},
+		{model.OrderStatusPreparing, model.OrderStatusDone},
+		{model.OrderStatusDone, model.OrderStatusDelivered},
+	} {
+		err = repoOrder.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+			OrderID:    orderIDs[1],
+			FromStatus: transition[0],
+			ToStatus:   transition[1],
+			Actor:      entity.OrderActorKitchen,
+		})
+		suite.NoError(err)
+	}
+
+	balance, err = repo.GetBalance(suite.ctx, customer.ID)
+	suite.NoError(err)
+	suite.Equal(30, balance)
+}
+
+func (suite *RepositoryTestSuite) TestCreateOrderRedeemingMoreLoyaltyPointsThanBalance() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoOrder := NewOrderRespository(suite.db)
+
+	_, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:      entity.NewMoney(1090),
+		CustomerID:      &customer.ID,
+		PaymentID:       suite.createPayment(entity.NewMoney(1090)),
+		TicketNumber:    1,
+		LoyaltyPoints:   100,
+		LoyaltyDiscount: entity.NewMoney(500),
+	})
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.LOGIC_ERROR, localError.Code)
+
+	var orders int64
+	suite.NoError(suite.db.Model(&model.Order{}).Count(&orders).Error)
+	suite.Equal(int64(0), orders)
+}
@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/stretchr/testify/mock"
@@ -10,6 +13,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	extModel "github.com/thiagoluis88git/tech1/internal/integrations/model"
 	pg "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -40,8 +46,8 @@ func (mock *MockCognitoRemoteDataSource) SignUpAdmin(user *model.UserAdmin) erro
 	return nil
 }
 
-func (mock *MockCognitoRemoteDataSource) Login(cpf string) (string, error) {
-	args := mock.Called(cpf)
+func (mock *MockCognitoRemoteDataSource) LoginUnknown() (string, error) {
+	args := mock.Called()
 	err := args.Error(1)
 
 	if err != nil {
@@ -51,15 +57,160 @@ func (mock *MockCognitoRemoteDataSource) Login(cpf string) (string, error) {
 	return args.Get(0).(string), nil
 }
 
-func (mock *MockCognitoRemoteDataSource) LoginUnknown() (string, error) {
-	args := mock.Called()
-	err := args.Error(1)
+func (mock *MockCognitoRemoteDataSource) StartLogin(cpf string, code string) (string, error) {
+	args := mock.Called(cpf, code)
+	return args.String(0), args.Error(1)
+}
 
-	if err != nil {
-		return "", err
+func (mock *MockCognitoRemoteDataSource) RespondToLogin(cpf string, session string, code string) (extModel.CognitoAuthentication, error) {
+	args := mock.Called(cpf, session, code)
+	return args.Get(0).(extModel.CognitoAuthentication), args.Error(1)
+}
+
+func (mock *MockCognitoRemoteDataSource) RefreshToken(refreshToken string) (extModel.CognitoAuthentication, error) {
+	args := mock.Called(refreshToken)
+	return args.Get(0).(extModel.CognitoAuthentication), args.Error(1)
+}
+
+func (mock *MockCognitoRemoteDataSource) RevokeToken(refreshToken string) error {
+	args := mock.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (mock *MockCognitoRemoteDataSource) DeleteUser(cpf string) error {
+	args := mock.Called(cpf)
+	return args.Error(0)
+}
+
+// FakeCognitoRemoteDataSource keeps the users and the login sessions in memory, answering
+// like the Cognito custom auth challenge with a single attempt for each code
+type FakeCognitoRemoteDataSource struct {
+	mutex         sync.Mutex
+	users         map[string]bool
+	sessions      map[string]fakeCognitoSession
+	refreshTokens map[string]string
+	sequence      int
+}
+
+type fakeCognitoSession struct {
+	cpf  string
+	code string
+}
+
+func NewFakeCognitoRemoteDataSource() *FakeCognitoRemoteDataSource {
+	return &FakeCognitoRemoteDataSource{
+		users:         map[string]bool{},
+		sessions:      map[string]fakeCognitoSession{},
+		refreshTokens: map[string]string{},
 	}
+}
 
-	return args.Get(0).(string), nil
+func (fake *FakeCognitoRemoteDataSource) SignUp(user *model.Customer) error {
+	return fake.signUp(user.CPF)
+}
+
+func (fake *FakeCognitoRemoteDataSource) SignUpAdmin(user *model.UserAdmin) error {
+	return fake.signUp(user.CPF)
+}
+
+func (fake *FakeCognitoRemoteDataSource) signUp(cpf string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	if fake.users[cpf] {
+		return errors.New("UsernameExistsException: User already exists")
+	}
+
+	fake.users[cpf] = true
+
+	return nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) StartLogin(cpf string, code string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	if !fake.users[cpf] {
+		return "", errors.New("UserNotFoundException: User does not exist")
+	}
+
+	fake.sequence++
+	session := fmt.Sprintf("session-%v", fake.sequence)
+	fake.sessions[session] = fakeCognitoSession{cpf: cpf, code: code}
+
+	return session, nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) RespondToLogin(cpf string, session string, code string) (extModel.CognitoAuthentication, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	login, ok := fake.sessions[session]
+	delete(fake.sessions, session)
+
+	if !ok || login.cpf != cpf {
+		return extModel.CognitoAuthentication{}, errors.New("NotAuthorizedException: Invalid session for the user")
+	}
+
+	if login.code != code {
+		return extModel.CognitoAuthentication{}, errors.New("NotAuthorizedException: Incorrect username or password")
+	}
+
+	fake.sequence++
+	refreshToken := fmt.Sprintf("refresh-%v", fake.sequence)
+	fake.refreshTokens[refreshToken] = cpf
+
+	return extModel.CognitoAuthentication{
+		AccessToken:  fmt.Sprintf("access-%v-%v", cpf, fake.sequence),
+		RefreshToken: refreshToken,
+		ExpiresIn:    3600,
+	}, nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) RefreshToken(refreshToken string) (extModel.CognitoAuthentication, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	cpf, ok := fake.refreshTokens[refreshToken]
+
+	if !ok {
+		return extModel.CognitoAuthentication{}, errors.New("NotAuthorizedException: Refresh Token has been revoked")
+	}
+
+	fake.sequence++
+
+	return extModel.CognitoAuthentication{
+		AccessToken: fmt.Sprintf("access-%v-%v", cpf, fake.sequence),
+		ExpiresIn:   3600,
+	}, nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) RevokeToken(refreshToken string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	delete(fake.refreshTokens, refreshToken)
+
+	return nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) LoginUnknown() (string, error) {
+	return "access-unknown-user", nil
+}
+
+func (fake *FakeCognitoRemoteDataSource) DeleteUser(cpf string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	delete(fake.users, cpf)
+
+	for refreshToken, user := range fake.refreshTokens {
+		if user == cpf {
+			delete(fake.refreshTokens, refreshToken)
+		}
+	}
+
+	return nil
 }
 
 type RepositoryTestSuite struct {
@@ -111,12 +262,48 @@ func (suite *RepositoryTestSuite) SetupTest() {
 		&model.Product{},
 		&model.ProductImage{},
 		&model.ComboProduct{},
+		&model.Promotion{},
 		&model.Order{},
+		&model.OrderPayment{},
 		&model.OrderProduct{},
+		&model.OrderProductModifier{},
+		&model.OrderDiscount{},
+		&model.LoyaltyTransaction{},
 		&model.OrderTicketNumber{},
+		&model.OrderStatusHistory{},
+		&model.OrderEvent{},
 		&model.Customer{},
+		&model.Payment{},
+		&model.OutboxEvent{},
+		&model.IdempotencyKey{},
+		&model.ProcessedWebhookEvent{},
+		&model.RevokedToken{},
+		&model.DataSubjectRequest{},
+		&extModel.LocalIdentityUser{},
+		&extModel.LocalIdentityLoginSession{},
+		&extModel.LocalIdentityRefreshToken{},
 	)
 	suite.NoError(err)
+
+	for _, index := range model.OrderIndexes {
+		suite.NoError(suite.db.Exec(index).Error)
+	}
+}
+
+// createPayment creates the paid payment required by each order
+func (suite *RepositoryTestSuite) createPayment(totalPrice entity.Money) uint {
+	paymentRepo := NewPaymentRepository(suite.db)
+
+	payment, err := paymentRepo.CreatePaymentOrder(suite.ctx, dto.Payment{
+		TotalPrice:  totalPrice,
+		PaymentType: model.PaymentCreditType,
+	})
+	suite.NoError(err)
+
+	err = paymentRepo.FinishPaymentWithSuccess(suite.ctx, payment.PaymentId, "1234")
+	suite.NoError(err)
+
+	return payment.PaymentId
 }
 
 func (suite *RepositoryTestSuite) TearDownTest() {
@@ -125,6 +312,22 @@ func (suite *RepositoryTestSuite) TearDownTest() {
 	suite.db.Exec("DROP TABLE IF EXISTS product_images CASCADE;")
 	suite.db.Exec("DROP TABLE IF EXISTS combo_products CASCADE;")
 	suite.db.Exec("DROP TABLE IF EXISTS orders CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS order_payments CASCADE;")
 	suite.db.Exec("DROP TABLE IF EXISTS order_products CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS order_product_modifiers CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS order_discounts CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS promotions CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS loyalty_transactions CASCADE;")
 	suite.db.Exec("DROP TABLE IF EXISTS order_ticket_numbers CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS order_status_history CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS order_events CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS payments CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS outbox_events CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS idempotency_keys CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS processed_webhook_events CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS revoked_tokens CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS data_subject_requests CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS local_identity_users CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS local_identity_login_sessions CASCADE;")
+	suite.db.Exec("DROP TABLE IF EXISTS local_identity_refresh_tokens CASCADE;")
 }
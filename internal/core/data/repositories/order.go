@@ -2,14 +2,19 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRespository struct {
@@ -43,11 +48,14 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 	}
 
 	orderEntity := &model.Order{
-		OrderStatus:  status,
-		TotalPrice:   order.TotalPrice,
-		CustomerID:   order.CustomerID,
-		PaymentID:    order.PaymentID,
-		TicketNumber: order.TicketNumber,
+		OrderStatus:     status,
+		TotalPrice:      order.TotalPrice,
+		CustomerID:      order.CustomerID,
+		GuestID:         order.GuestID,
+		PaymentID:       order.PaymentID,
+		TicketNumber:    order.TicketNumber,
+		LoyaltyPoints:   order.LoyaltyPoints,
+		LoyaltyDiscount: order.LoyaltyDiscount,
 	}
 
 	err := tx.Create(orderEntity).Error
@@ -57,12 +65,61 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 		return dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
+	err = redeemLoyaltyPointsInTransaction(tx, *orderEntity)
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, err
+	}
+
+	err = checkCustomerUsagesInTransaction(tx, *orderEntity, order.Discounts)
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, err
+	}
+
+	orderPaymentsEntity := []*model.OrderPayment{
+		{
+			OrderID:   orderEntity.ID,
+			PaymentID: order.PaymentID,
+		},
+	}
+
+	for _, paymentID := range order.PaymentIDs {
+		orderPaymentsEntity = append(orderPaymentsEntity, &model.OrderPayment{
+			OrderID:   orderEntity.ID,
+			PaymentID: paymentID,
+		})
+	}
+
+	err = tx.Create(orderPaymentsEntity).Error
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, responses.GetDatabaseError(err)
+	}
+
 	orderProductsEntity := []*model.OrderProduct{}
 
 	for _, value := range order.OrderProduct {
+		modifiers := []model.OrderProductModifier{}
+
+		for _, modifier := range value.Modifiers {
+			modifiers = append(modifiers, model.OrderProductModifier{
+				Type:        modifier.Type,
+				Description: modifier.Description,
+				ProductID:   modifier.ProductID,
+				Price:       modifier.Price,
+			})
+		}
+
 		orderProductsEntity = append(orderProductsEntity, &model.OrderProduct{
 			ProductID: value.ProductID,
 			OrderID:   orderEntity.ID,
+			Quantity:  value.Quantity,
+			UnitPrice: value.ProductPrice,
+			Modifiers: modifiers,
 		})
 	}
 
@@ -73,6 +130,51 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 		return dto.OrderResponse{}, responses.GetDatabaseError(err)
 	}
 
+	orderDiscountsEntity := []model.OrderDiscount{}
+
+	for _, discount := range order.Discounts {
+		orderDiscountsEntity = append(orderDiscountsEntity, model.OrderDiscount{
+			OrderID:     orderEntity.ID,
+			PromotionID: discount.PromotionID,
+			Description: discount.Description,
+			Code:        discount.Code,
+			Amount:      discount.Amount,
+		})
+	}
+
+	// Most orders have no discounts, and an empty slice can not be created
+	if len(orderDiscountsEntity) > 0 {
+		err = tx.Create(&orderDiscountsEntity).Error
+
+		if err != nil {
+			tx.Rollback()
+			return dto.OrderResponse{}, responses.GetDatabaseError(err)
+		}
+	}
+
+	err = repository.createStatusHistory(tx, dto.OrderStatusTransition{
+		OrderID:  orderEntity.ID,
+		ToStatus: status,
+		Actor:    entity.OrderActorCustomer,
+		Reason:   "Order created",
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, responses.GetDatabaseError(err)
+	}
+
+	err = repository.createOrderOutboxEvent(tx, *orderEntity, dto.OrderStatusTransition{
+		OrderID:  orderEntity.ID,
+		ToStatus: status,
+		Actor:    entity.OrderActorCustomer,
+	})
+
+	if err != nil {
+		tx.Rollback()
+		return dto.OrderResponse{}, responses.GetDatabaseError(err)
+	}
+
 	err = tx.Commit().Error
 
 	if err != nil {
@@ -81,9 +183,14 @@ func (repository *OrderRespository) createOrder(ctx context.Context, order dto.O
 	}
 
 	return dto.OrderResponse{
-		OrderId:      orderEntity.ID,
-		OrderDate:    orderEntity.CreatedAt,
-		TicketNumber: orderEntity.TicketNumber,
+		OrderId:         orderEntity.ID,
+		OrderDate:       orderEntity.CreatedAt,
+		TicketNumber:    orderEntity.TicketNumber,
+		TotalPrice:      orderEntity.TotalPrice,
+		DiscountPrice:   repository.sumOrderDiscounts(orderDiscountsEntity).Add(orderEntity.LoyaltyDiscount),
+		Discounts:       repository.buildOrderDiscounts(orderDiscountsEntity),
+		LoyaltyPoints:   orderEntity.LoyaltyPoints,
+		LoyaltyDiscount: orderEntity.LoyaltyDiscount,
 	}, nil
 }
 
@@ -99,7 +206,58 @@ func (repository *OrderRespository) DeleteOrder(ctx context.Context, orderID uin
 		return responses.GetDatabaseError(err)
 	}
 
-	err := tx.Where("order_id = ?", orderID).Delete(&model.OrderProduct{}).Error
+	err := tx.
+		Where("order_product_id IN (?)", tx.Model(&model.OrderProduct{}).Select("id").Where("order_id = ?", orderID)).
+		Delete(&model.OrderProductModifier{}).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Where("order_id = ?", orderID).Delete(&model.OrderProduct{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Where("order_id = ?", orderID).Delete(&model.OrderDiscount{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	// The points redeemed by the order are given back
+	err = tx.Where("order_id = ?", orderID).Delete(&model.LoyaltyTransaction{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Where("order_id = ?", orderID).Delete(&model.OrderStatusHistory{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	// The parts already paid of a split order can be used again
+	err = tx.Where("order_id = ?", orderID).Delete(&model.OrderPayment{}).Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	// The events not published yet would tell the other services about an order which does not exist
+	err = tx.
+		Where("aggregate_type = ? AND aggregate_id = ? AND published_at IS NULL", entity.OutboxAggregateOrder, orderID).
+		Delete(&model.OutboxEvent{}).
+		Error
 
 	if err != nil {
 		tx.Rollback()
@@ -123,19 +281,90 @@ func (repository *OrderRespository) DeleteOrder(ctx context.Context, orderID uin
 	return nil
 }
 
-func (repository *OrderRespository) FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID uint) error {
-	err := repository.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderID).
-		Update("payment_id", paymentID).
-		Update("order_status", model.OrderStatusCreated).
-		Error
+// FinishOrderPayment changes the payment and the paying order in a single transaction. It returns
+// false, changing nothing, when the notification of the result was already processed. A paid order
+// whose parts do not cover its total keeps the payment and stays paying, also returning false
+func (repository *OrderRespository) FinishOrderPayment(ctx context.Context, result dto.OrderPaymentResult) (bool, error) {
+	tx := repository.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return false, responses.GetDatabaseError(err)
+	}
+
+	processed := tx.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ProcessedWebhookEvent{EventKey: result.EventKey})
+
+	if processed.Error != nil {
+		tx.Rollback()
+		return false, responses.GetDatabaseError(processed.Error)
+	}
+
+	if processed.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	paymentFields := map[string]any{"payment_status": result.PaymentStatus}
+
+	if result.GatewayPaymentID != "" {
+		paymentFields["gateway_payment_id"] = result.GatewayPaymentID
+	}
+
+	err := updatePaymentStatusInTransaction(tx, result.PaymentID, paymentFields)
 
 	if err != nil {
-		return responses.GetDatabaseError(err)
+		tx.Rollback()
+		return false, responses.GetDatabaseError(err)
 	}
 
-	return nil
+	if result.OrderStatus == model.OrderStatusCreated {
+		var fullyPaid bool
+		fullyPaid, err = repository.isOrderFullyPaid(tx, result.OrderID)
+
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+
+		if !fullyPaid {
+			err = tx.Commit().Error
+
+			if err != nil {
+				tx.Rollback()
+				return false, responses.GetDatabaseError(err)
+			}
+
+			return false, nil
+		}
+	}
+
+	err = repository.updateOrderStatusInTransaction(tx, dto.OrderStatusTransition{
+		OrderID:    result.OrderID,
+		FromStatus: model.OrderStatusPaying,
+		ToStatus:   result.OrderStatus,
+		Actor:      entity.OrderActorPaymentGateway,
+		Reason:     result.Reason,
+	}, map[string]any{"payment_id": result.PaymentID})
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return false, responses.GetDatabaseError(err)
+	}
+
+	return true, nil
 }
 
 func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
@@ -144,7 +373,10 @@ func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId ui
 		db.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
 		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts").
 		Where("id = ?", orderId).
 		Find(&orderEntity).
 		Limit(1).
@@ -161,15 +393,7 @@ func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId ui
 		}
 	}
 
-	orderProduct := []dto.OrderProductResponse{}
-
-	for _, value := range orderEntity.OrderProduct {
-		orderProduct = append(orderProduct, dto.OrderProductResponse{
-			ProductID:   value.ProductID,
-			ProductName: value.Product.Name,
-			Description: value.Product.Description,
-		})
-	}
+	orderProduct := repository.buildOrderProducts(orderEntity.OrderProduct)
 
 	var customerName *string
 
@@ -178,26 +402,87 @@ func (repository *OrderRespository) GetOrderById(ctx context.Context, orderId ui
 	}
 
 	return dto.OrderResponse{
-		OrderId:        orderEntity.ID,
-		OrderDate:      orderEntity.CreatedAt,
-		PreparingAt:    orderEntity.PreparingAt,
-		DoneAt:         orderEntity.DoneAt,
-		DeliveredAt:    orderEntity.DeliveredAt,
-		NotDeliveredAt: orderEntity.NotDeliveredAt,
-		TicketNumber:   orderEntity.TicketNumber,
-		OrderStatus:    orderEntity.OrderStatus,
-		OrderProduct:   orderProduct,
-		CustomerName:   customerName,
+		OrderId:         orderEntity.ID,
+		OrderDate:       orderEntity.CreatedAt,
+		PreparingAt:     orderEntity.PreparingAt,
+		DoneAt:          orderEntity.DoneAt,
+		DeliveredAt:     orderEntity.DeliveredAt,
+		NotDeliveredAt:  orderEntity.NotDeliveredAt,
+		CanceledAt:      orderEntity.CanceledAt,
+		TicketNumber:    orderEntity.TicketNumber,
+		TotalPrice:      orderEntity.TotalPrice,
+		DiscountPrice:   repository.sumOrderDiscounts(orderEntity.Discounts).Add(orderEntity.LoyaltyDiscount),
+		Discounts:       repository.buildOrderDiscounts(orderEntity.Discounts),
+		LoyaltyPoints:   orderEntity.LoyaltyPoints,
+		LoyaltyDiscount: orderEntity.LoyaltyDiscount,
+		PaymentID:       orderEntity.PaymentID,
+		Payments:        repository.buildOrderPayments(orderEntity.Payments),
+		OrderStatus:     orderEntity.OrderStatus,
+		OrderProduct:    orderProduct,
+		CustomerName:    customerName,
 	}, nil
 }
 
+// GetOrderOwner returns the customer or the guest who created the order, without the order data
+func (repository *OrderRespository) GetOrderOwner(ctx context.Context, orderID uint) (dto.ResourceOwner, error) {
+	var orderEntity model.Order
+	err := repository.
+		db.WithContext(ctx).
+		Preload("Customer").
+		Where("id = ?", orderID).
+		Limit(1).
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		return dto.ResourceOwner{}, responses.GetDatabaseError(err)
+	}
+
+	if orderEntity.ID == uint(0) {
+		return dto.ResourceOwner{}, &responses.LocalError{
+			Message: "Order not found",
+			Code:    responses.NOT_FOUND_ERROR,
+		}
+	}
+
+	return buildResourceOwner(orderEntity.Customer, orderEntity.GuestID, orderEntity.Erased), nil
+}
+
+// GetOrderByPaymentId finds the order paid by the payment, even when it is not the main payment of a split order
+func (repository *OrderRespository) GetOrderByPaymentId(ctx context.Context, paymentID uint) (dto.OrderResponse, error) {
+	var orderPaymentEntity model.OrderPayment
+
+	err := repository.
+		db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Limit(1).
+		Find(&orderPaymentEntity).
+		Error
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetDatabaseError(err)
+	}
+
+	if orderPaymentEntity.ID == uint(0) {
+		return dto.OrderResponse{}, &responses.LocalError{
+			Message: "Order not found",
+			Code:    responses.NOT_FOUND_ERROR,
+		}
+	}
+
+	return repository.GetOrderById(ctx, orderPaymentEntity.OrderID)
+}
+
 func (repository *OrderRespository) GetOrdersToPrepare(ctx context.Context) ([]dto.OrderResponse, error) {
 	var orderEntity []model.Order
 	err := repository.
 		db.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
 		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts").
 		Where("order_status = ?", model.OrderStatusCreated).
 		Order("created_at").
 		Find(&orderEntity).
@@ -216,7 +501,10 @@ func (repository *OrderRespository) GetOrdersToFollow(ctx context.Context) ([]dt
 		db.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
 		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts").
 		Where("order_status in (?, ?,?)",
 			model.OrderStatusCreated,
 			model.OrderStatusPreparing,
@@ -239,8 +527,39 @@ func (repository *OrderRespository) GetOrdersWaitingPayment(ctx context.Context)
 		db.WithContext(ctx).
 		Model(&model.Order{}).
 		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
+		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts").
+		Where("order_status = ?", model.OrderStatusPaying).
+		Order("created_at").
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		return []dto.OrderResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildOrdersList(orderEntity), nil
+}
+
+// GetOrdersWaitingPaymentByType returns the paying orders whose payment is of the type, the oldest first
+func (repository *OrderRespository) GetOrdersWaitingPaymentByType(ctx context.Context, paymentType string) ([]dto.OrderResponse, error) {
+	var orderEntity []model.Order
+	err := repository.
+		db.WithContext(ctx).
+		Model(&model.Order{}).
+		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
 		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts").
 		Where("order_status = ?", model.OrderStatusPaying).
+		Where("payment_id IN (?)", repository.db.
+			Model(&model.Payment{}).
+			Select("id").
+			Where("payment_type = ?", paymentType),
+		).
 		Order("created_at").
 		Find(&orderEntity).
 		Error
@@ -252,19 +571,243 @@ func (repository *OrderRespository) GetOrdersWaitingPayment(ctx context.Context)
 	return repository.buildOrdersList(orderEntity), nil
 }
 
+var orderSortColumns = map[string]string{
+	entity.OrderSortCreatedAt:    "created_at",
+	entity.OrderSortTicketNumber: "ticket_number",
+	entity.OrderSortTotalPrice:   "total_price",
+}
+
+// orderCursor keeps the sort values of the last order of a page. The next page starts right after
+// it using the '(column, id)' keyset, so the pages don't skip or repeat orders when new ones are created
+type orderCursor struct {
+	SortBy       string       `json:"s"`
+	Descending   bool         `json:"d"`
+	ID           uint         `json:"i"`
+	CreatedAt    time.Time    `json:"c"`
+	TicketNumber int          `json:"t"`
+	TotalPrice   entity.Money `json:"p"`
+}
+
+func (cursor orderCursor) value() any {
+	switch cursor.SortBy {
+	case entity.OrderSortTicketNumber:
+		return cursor.TicketNumber
+	case entity.OrderSortTotalPrice:
+		return cursor.TotalPrice
+	}
+
+	return cursor.CreatedAt
+}
+
+func (repository *OrderRespository) GetOrders(ctx context.Context, filter dto.OrderFilter) (dto.OrderPage, error) {
+	column, ok := orderSortColumns[filter.SortBy]
+
+	if !ok {
+		filter.SortBy = entity.OrderSortCreatedAt
+		column = orderSortColumns[filter.SortBy]
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = entity.OrderPageDefaultLimit
+	}
+
+	direction := "ASC"
+	comparator := ">"
+
+	if filter.Descending {
+		direction = "DESC"
+		comparator = "<"
+	}
+
+	query := repository.
+		db.WithContext(ctx).
+		Model(&model.Order{}).
+		Preload("OrderProduct.Product").
+		Preload("OrderProduct.Modifiers").
+		Preload("Customer").
+		Preload("Payments.Payment").
+		Preload("Discounts")
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("order_status IN ?", filter.Statuses)
+	}
+
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+
+	if filter.TicketNumber != nil {
+		query = query.Where("ticket_number = ?", *filter.TicketNumber)
+	}
+
+	// A split order is found by the type of any of its parts
+	if filter.PaymentType != nil {
+		query = query.Where("id IN (?)", repository.db.
+			Model(&model.OrderPayment{}).
+			Select("order_payments.order_id").
+			Joins("JOIN payments ON payments.id = order_payments.payment_id").
+			Where("payments.payment_type = ?", *filter.PaymentType),
+		)
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := repository.decodeOrderCursor(filter)
+
+		if err != nil {
+			return dto.OrderPage{}, err
+		}
+
+		query = query.Where(fmt.Sprintf("(%v, id) %v (?, ?)", column, comparator), cursor.value(), cursor.ID)
+	}
+
+	var orderEntity []model.Order
+	err := query.
+		Order(fmt.Sprintf("%v %v, id %v", column, direction, direction)).
+		Limit(filter.Limit + 1).
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		return dto.OrderPage{}, responses.GetDatabaseError(err)
+	}
+
+	var nextCursor *string
+
+	if len(orderEntity) > filter.Limit {
+		orderEntity = orderEntity[:filter.Limit]
+		cursor := repository.encodeOrderCursor(filter, orderEntity[len(orderEntity)-1])
+		nextCursor = &cursor
+	}
+
+	return dto.OrderPage{
+		Orders:     repository.buildOrdersList(orderEntity),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetCustomerOrders pages the orders of the customer with the same cursor of the admin search,
+// sorted by the creation date, the newest first
+func (repository *OrderRespository) GetCustomerOrders(ctx context.Context, filter dto.CustomerOrdersFilter) (dto.OrderPage, error) {
+	return repository.GetOrders(ctx, dto.OrderFilter{
+		CustomerID: &filter.CustomerID,
+		SortBy:     entity.OrderSortCreatedAt,
+		Descending: true,
+		Cursor:     filter.Cursor,
+		Limit:      filter.Limit,
+	})
+}
+
+// MergeGuestOrders gives the orders of the guest to the customer. The orders already delivered earn
+// their points now, and the others earn them when delivered. The orders are locked, so an order
+// delivered at the same time earns its points only once
+func (repository *OrderRespository) MergeGuestOrders(ctx context.Context, guestID string, customerID uint) (int, error) {
+	tx := repository.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	var orderEntity []model.Order
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("guest_id = ? AND customer_id IS NULL", guestID).
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		tx.Rollback()
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	for _, order := range orderEntity {
+		err = tx.Model(&order).Update("customer_id", customerID).Error
+
+		if err != nil {
+			tx.Rollback()
+			return 0, responses.GetDatabaseError(err)
+		}
+
+		if order.OrderStatus != model.OrderStatusDelivered {
+			continue
+		}
+
+		order.CustomerID = &customerID
+
+		err = earnLoyaltyPointsInTransaction(tx, order)
+
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return len(orderEntity), nil
+}
+
+func (repository *OrderRespository) encodeOrderCursor(filter dto.OrderFilter, last model.Order) string {
+	value, _ := json.Marshal(orderCursor{
+		SortBy:       filter.SortBy,
+		Descending:   filter.Descending,
+		ID:           last.ID,
+		CreatedAt:    last.CreatedAt,
+		TicketNumber: last.TicketNumber,
+		TotalPrice:   last.TotalPrice,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func (repository *OrderRespository) decodeOrderCursor(filter dto.OrderFilter) (orderCursor, error) {
+	var cursor orderCursor
+
+	value, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+
+	if err == nil {
+		err = json.Unmarshal(value, &cursor)
+	}
+
+	if err != nil {
+		return orderCursor{}, &responses.LocalError{
+			Code:    responses.LOGIC_ERROR,
+			Message: "The cursor is not valid",
+		}
+	}
+
+	if cursor.SortBy != filter.SortBy || cursor.Descending != filter.Descending {
+		return orderCursor{}, &responses.LocalError{
+			Code:    responses.LOGIC_ERROR,
+			Message: "The cursor belongs to another sort",
+		}
+	}
+
+	return cursor, nil
+}
+
 func (repository *OrderRespository) buildOrdersList(orderEntity []model.Order) []dto.OrderResponse {
 	orders := []dto.OrderResponse{}
 
 	for _, value := range orderEntity {
-		orderProduct := []dto.OrderProductResponse{}
-
-		for _, value := range value.OrderProduct {
-			orderProduct = append(orderProduct, dto.OrderProductResponse{
-				ProductID:   value.ProductID,
-				ProductName: value.Product.Name,
-				Description: value.Product.Description,
-			})
-		}
+		orderProduct := repository.buildOrderProducts(value.OrderProduct)
 
 		var customerName *string
 
@@ -273,125 +816,339 @@ func (repository *OrderRespository) buildOrdersList(orderEntity []model.Order) [
 		}
 
 		orders = append(orders, dto.OrderResponse{
-			OrderId:        value.ID,
-			OrderDate:      value.CreatedAt,
-			PreparingAt:    value.PreparingAt,
-			DoneAt:         value.DoneAt,
-			DeliveredAt:    value.DeliveredAt,
-			NotDeliveredAt: value.NotDeliveredAt,
-			TicketNumber:   value.TicketNumber,
-			OrderStatus:    value.OrderStatus,
-			OrderProduct:   orderProduct,
-			CustomerName:   customerName,
+			OrderId:         value.ID,
+			OrderDate:       value.CreatedAt,
+			PreparingAt:     value.PreparingAt,
+			DoneAt:          value.DoneAt,
+			DeliveredAt:     value.DeliveredAt,
+			NotDeliveredAt:  value.NotDeliveredAt,
+			CanceledAt:      value.CanceledAt,
+			TicketNumber:    value.TicketNumber,
+			TotalPrice:      value.TotalPrice,
+			DiscountPrice:   repository.sumOrderDiscounts(value.Discounts).Add(value.LoyaltyDiscount),
+			Discounts:       repository.buildOrderDiscounts(value.Discounts),
+			LoyaltyPoints:   value.LoyaltyPoints,
+			LoyaltyDiscount: value.LoyaltyDiscount,
+			PaymentID:       value.PaymentID,
+			Payments:        repository.buildOrderPayments(value.Payments),
+			OrderStatus:     value.OrderStatus,
+			OrderProduct:    orderProduct,
+			CustomerName:    customerName,
 		})
 	}
 
 	return orders
 }
 
-func (repository *OrderRespository) UpdateToPreparing(ctx context.Context, orderId uint) error {
-	err := repository.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusPreparing).
-		Update("preparing_at", time.Now()).
-		Error
+func (repository *OrderRespository) buildOrderPayments(orderPaymentEntity []model.OrderPayment) []dto.OrderPaymentResponse {
+	payments := []dto.OrderPaymentResponse{}
 
-	if err != nil {
-		return responses.GetDatabaseError(err)
+	for _, value := range orderPaymentEntity {
+		if value.Payment == nil {
+			continue
+		}
+
+		payments = append(payments, dto.OrderPaymentResponse{
+			PaymentID:     value.PaymentID,
+			PaymentType:   value.Payment.PaymentType,
+			PaymentStatus: value.Payment.PaymentStatus,
+			TotalPrice:    value.Payment.TotalPrice,
+		})
 	}
 
-	return nil
+	return payments
 }
 
-func (repository *OrderRespository) UpdateToDone(ctx context.Context, orderId uint) error {
-	err := repository.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusDone).
-		Update("done_at", time.Now()).
-		Error
+func (repository *OrderRespository) buildOrderDiscounts(orderDiscountEntity []model.OrderDiscount) []dto.OrderDiscount {
+	discounts := []dto.OrderDiscount{}
+
+	for _, value := range orderDiscountEntity {
+		discounts = append(discounts, dto.OrderDiscount{
+			PromotionID: value.PromotionID,
+			Description: value.Description,
+			Code:        value.Code,
+			Amount:      value.Amount,
+		})
+	}
+
+	return discounts
+}
+
+func (repository *OrderRespository) sumOrderDiscounts(orderDiscountEntity []model.OrderDiscount) entity.Money {
+	discountPrice := entity.NewMoney(0)
+
+	for _, value := range orderDiscountEntity {
+		discountPrice = discountPrice.Add(value.Amount)
+	}
+
+	return discountPrice
+}
+
+func (repository *OrderRespository) buildOrderProducts(orderProductEntity []model.OrderProduct) []dto.OrderProductResponse {
+	orderProduct := []dto.OrderProductResponse{}
+
+	for _, value := range orderProductEntity {
+		modifiers := []dto.OrderProductModifierResponse{}
+
+		for _, modifier := range value.Modifiers {
+			modifiers = append(modifiers, dto.OrderProductModifierResponse{
+				Type:        modifier.Type,
+				Description: modifier.Description,
+				Price:       modifier.Price,
+			})
+		}
+
+		orderProduct = append(orderProduct, dto.OrderProductResponse{
+			ProductID:   value.ProductID,
+			ProductName: value.Product.Name,
+			Description: value.Product.Description,
+			Quantity:    value.Quantity,
+			UnitPrice:   value.UnitPrice,
+			Modifiers:   modifiers,
+		})
+	}
+
+	return orderProduct
+}
+
+func (repository *OrderRespository) UpdateOrderStatus(ctx context.Context, transition dto.OrderStatusTransition) error {
+	return repository.updateOrderStatus(ctx, transition, map[string]any{})
+}
+
+// updateOrderStatus only changes the order if it is still in the transition 'FromStatus'.
+// This prevents two concurrent requests to move the same order twice. The history and the
+// outbox event are written in the same transaction as the status change
+func (repository *OrderRespository) updateOrderStatus(
+	ctx context.Context,
+	transition dto.OrderStatusTransition,
+	fields map[string]any,
+) error {
+	tx := repository.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	err := repository.updateOrderStatusInTransaction(tx, transition, fields)
 
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
 	return nil
 }
 
-func (repository *OrderRespository) UpdateToDelivered(ctx context.Context, orderId uint) error {
-	err := repository.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusDelivered).
-		Update("delivered_at", time.Now()).
+// isOrderFullyPaid sums the paid parts of the order, so a split order is only created
+// when its payments cover the whole total
+func (repository *OrderRespository) isOrderFullyPaid(tx *gorm.DB, orderID uint) (bool, error) {
+	var orderEntity model.Order
+
+	err := tx.Select("id", "total_price", "currency").First(&orderEntity, orderID).Error
+
+	if err != nil {
+		return false, responses.GetDatabaseError(err)
+	}
+
+	var paidPrice entity.Money
+
+	err = tx.Model(&model.OrderPayment{}).
+		Select("COALESCE(SUM(payments.total_price), 0)").
+		Joins("JOIN payments ON payments.id = order_payments.payment_id").
+		Where("order_payments.order_id = ? AND payments.payment_status = ?", orderID, model.PaymentPayedStatus).
+		Scan(&paidPrice).
 		Error
 
 	if err != nil {
-		return responses.GetDatabaseError(err)
+		return false, responses.GetDatabaseError(err)
 	}
 
-	return nil
+	return paidPrice.Cents >= orderEntity.TotalPrice.Cents, nil
 }
 
-func (repository *OrderRespository) UpdateToNotDelivered(ctx context.Context, orderId uint) error {
-	err := repository.db.WithContext(ctx).
-		Model(&model.Order{}).
-		Where("id = ?", orderId).
-		Update("order_status", model.OrderStatusNotDelivered).
-		Update("not_delivered_at", time.Now()).
-		Error
+func (repository *OrderRespository) updateOrderStatusInTransaction(
+	tx *gorm.DB,
+	transition dto.OrderStatusTransition,
+	fields map[string]any,
+) error {
+	fields["order_status"] = transition.ToStatus
+
+	if column := repository.statusTimestampColumn(transition.ToStatus); column != "" {
+		fields[column] = time.Now()
+	}
+
+	var orderEntity model.Order
+
+	result := tx.Model(&orderEntity).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND order_status = ?", transition.OrderID, transition.FromStatus).
+		Updates(fields)
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: fmt.Sprintf("Order is not in %v status anymore", transition.FromStatus),
+		}
+	}
+
+	err := repository.createStatusHistory(tx, transition)
 
 	if err != nil {
 		return responses.GetDatabaseError(err)
 	}
 
-	return nil
+	err = repository.createOrderOutboxEvent(tx, orderEntity, transition)
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return repository.updateLoyaltyPoints(tx, orderEntity, transition.ToStatus)
 }
 
-func (repository *OrderRespository) GetNextTicketNumber(ctx context.Context, date int64) int {
-	var orderTicketNumber model.OrderTicketNumber
-	err := repository.db.WithContext(ctx).
-		Model(&model.OrderTicketNumber{}).
-		Where("date = ?", date).
-		Find(&orderTicketNumber).
-		Limit(1).
-		Error
+// updateLoyaltyPoints credits the points when the order is delivered and gives back the redeemed
+// points when it is canceled or not delivered
+func (repository *OrderRespository) updateLoyaltyPoints(tx *gorm.DB, order model.Order, status string) error {
+	switch status {
+	case model.OrderStatusDelivered:
+		return earnLoyaltyPointsInTransaction(tx, order)
+	case model.OrderStatusNotDelivered, model.OrderStatusCanceled:
+		return reverseLoyaltyPointsInTransaction(tx, order)
+	}
+
+	return nil
+}
 
-	if err != nil || orderTicketNumber.Date == 0 {
-		return repository.createNewTicketForDate(ctx, date)
+func (repository *OrderRespository) statusTimestampColumn(status string) string {
+	switch status {
+	case model.OrderStatusPreparing:
+		return "preparing_at"
+	case model.OrderStatusDone:
+		return "done_at"
+	case model.OrderStatusDelivered:
+		return "delivered_at"
+	case model.OrderStatusNotDelivered:
+		return "not_delivered_at"
+	case model.OrderStatusCanceled:
+		return "canceled_at"
 	}
 
-	newTicketNumber := orderTicketNumber.TicketNumber + 1
+	return ""
+}
 
-	return repository.updateTicketForDate(ctx, date, newTicketNumber)
+func (repository *OrderRespository) createStatusHistory(tx *gorm.DB, transition dto.OrderStatusTransition) error {
+	return tx.Create(&model.OrderStatusHistory{
+		OrderID:    transition.OrderID,
+		FromStatus: transition.FromStatus,
+		ToStatus:   transition.ToStatus,
+		Actor:      transition.Actor,
+		Reason:     transition.Reason,
+	}).Error
 }
 
-func (repository *OrderRespository) createNewTicketForDate(ctx context.Context, date int64) int {
-	orderTicketNumber := model.OrderTicketNumber{
-		Date:         date,
-		TicketNumber: 1,
+func (repository *OrderRespository) createOrderOutboxEvent(tx *gorm.DB, order model.Order, transition dto.OrderStatusTransition) error {
+	eventType := entity.OrderDomainEventType(transition.FromStatus, transition.ToStatus)
+
+	if eventType == "" {
+		return nil
 	}
 
-	errCreate := repository.db.WithContext(ctx).Create(&orderTicketNumber).Error
+	return createOutboxEvent(tx, eventType, entity.OutboxAggregateOrder, order.ID, dto.OrderEventPayload{
+		OrderID:        order.ID,
+		TicketNumber:   order.TicketNumber,
+		Status:         transition.ToStatus,
+		PreviousStatus: transition.FromStatus,
+		Actor:          transition.Actor,
+		Reason:         transition.Reason,
+		TotalPrice:     order.TotalPrice,
+		PaymentID:      order.PaymentID,
+		CustomerID:     order.CustomerID,
+	})
+}
+
+func (repository *OrderRespository) GetOrderStatusHistory(ctx context.Context, orderID uint) ([]dto.OrderStatusHistoryResponse, error) {
+	var historyEntity []model.OrderStatusHistory
+	err := repository.
+		db.WithContext(ctx).
+		Model(&model.OrderStatusHistory{}).
+		Where("order_id = ?", orderID).
+		Order("created_at").
+		Order("id").
+		Find(&historyEntity).
+		Error
 
-	if errCreate != nil {
-		return 999
+	if err != nil {
+		return []dto.OrderStatusHistoryResponse{}, responses.GetDatabaseError(err)
 	}
 
-	return 1
+	if len(historyEntity) == 0 {
+		return []dto.OrderStatusHistoryResponse{}, &responses.LocalError{
+			Message: "Order not found",
+			Code:    responses.NOT_FOUND_ERROR,
+		}
+	}
+
+	history := []dto.OrderStatusHistoryResponse{}
+
+	for _, value := range historyEntity {
+		history = append(history, dto.OrderStatusHistoryResponse{
+			FromStatus: value.FromStatus,
+			ToStatus:   value.ToStatus,
+			Actor:      value.Actor,
+			Reason:     value.Reason,
+			ChangedAt:  value.CreatedAt,
+		})
+	}
+
+	return history, nil
 }
 
-func (repository *OrderRespository) updateTicketForDate(ctx context.Context, date int64, newTicketNumber int) int {
+// GetNextTicketNumber allocates the ticket in a single upsert statement. Postgres locks the
+// date row during the conflict update, so concurrent requests, even from different replicas,
+// will never get the same ticket number
+func (repository *OrderRespository) GetNextTicketNumber(ctx context.Context, date int64) (int, error) {
+	var ticketNumber int
+
 	err := repository.db.WithContext(ctx).
-		Model(&model.OrderTicketNumber{}).
-		Where("date = ?", date).
-		Update("ticket_number", newTicketNumber).
+		Raw(`INSERT INTO order_ticket_numbers (date, ticket_number) VALUES (?, 1)
+			ON CONFLICT (date) DO UPDATE SET ticket_number = order_ticket_numbers.ticket_number + 1
+			RETURNING ticket_number`, date).
+		Scan(&ticketNumber).
 		Error
 
 	if err != nil {
-		return 999
+		return 0, responses.GetDatabaseError(err)
 	}
 
-	return newTicketNumber
+	return ticketNumber, nil
+}
+
+func buildResourceOwner(customer *model.Customer, guestID *string, erased bool) dto.ResourceOwner {
+	var customerCPF *string
+
+	if customer != nil {
+		customerCPF = &customer.CPF
+	}
+
+	return dto.ResourceOwner{
+		CustomerCPF: customerCPF,
+		GuestID:     guestID,
+		Erased:      erased,
+	}
 }
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+const (
+	orderEventsChannel          = "order_events"
+	orderEventsReplayLimit      = 500
+	orderEventsSubscriberBuffer = 32
+	orderEventsReconnectDelay   = 2 * time.Second
+)
+
+// OrderEventRepository persists every order event and sends it through Postgres NOTIFY.
+// Each replica LISTENs to the same channel and delivers the events to its own subscribers,
+// so a stream connected to any replica receives the changes made by all of them
+type OrderEventRepository struct {
+	db          *gorm.DB
+	mutex       sync.RWMutex
+	subscribers map[chan dto.OrderEvent]*uint
+	lastEventID int64
+}
+
+func NewOrderEventRepository(db *gorm.DB) repository.OrderEventRepository {
+	repository := &OrderEventRepository{
+		db:          db,
+		subscribers: map[chan dto.OrderEvent]*uint{},
+	}
+
+	go repository.listen(context.Background())
+
+	return repository
+}
+
+func (repository *OrderEventRepository) Publish(ctx context.Context, event dto.OrderEvent) error {
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		eventEntity := &model.OrderEvent{
+			OrderID:    event.OrderID,
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			Actor:      event.Actor,
+		}
+
+		err := tx.Create(eventEntity).Error
+
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(repository.buildEvent(*eventEntity))
+
+		if err != nil {
+			return err
+		}
+
+		// NOTIFY is only delivered when the transaction commits
+		return tx.Exec("SELECT pg_notify(?, ?)", orderEventsChannel, string(payload)).Error
+	})
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *OrderEventRepository) GetEventsAfter(ctx context.Context, orderID *uint, lastEventID int64) ([]dto.OrderEvent, error) {
+	var eventEntity []model.OrderEvent
+
+	query := repository.
+		db.WithContext(ctx).
+		Model(&model.OrderEvent{}).
+		Where("id > ?", lastEventID)
+
+	if orderID != nil {
+		query = query.Where("order_id = ?", *orderID)
+	}
+
+	err := query.
+		Order("id").
+		Limit(orderEventsReplayLimit).
+		Find(&eventEntity).
+		Error
+
+	if err != nil {
+		return []dto.OrderEvent{}, responses.GetDatabaseError(err)
+	}
+
+	events := []dto.OrderEvent{}
+
+	for _, value := range eventEntity {
+		events = append(events, repository.buildEvent(value))
+	}
+
+	return events, nil
+}
+
+// Subscribe returns the channel with the events of this replica. When the orderID is informed,
+// only the events of this order will be delivered. A subscriber that can not keep up with the
+// events has its channel closed, so the client reconnects and replays what it missed
+func (repository *OrderEventRepository) Subscribe(orderID *uint) (<-chan dto.OrderEvent, func()) {
+	events := make(chan dto.OrderEvent, orderEventsSubscriberBuffer)
+
+	repository.mutex.Lock()
+	repository.subscribers[events] = orderID
+	repository.mutex.Unlock()
+
+	unsubscribe := func() {
+		repository.mutex.Lock()
+		defer repository.mutex.Unlock()
+
+		if _, ok := repository.subscribers[events]; ok {
+			delete(repository.subscribers, events)
+			close(events)
+		}
+	}
+
+	return events, unsubscribe
+}
+
+// DeleteBefore removes the events older than the streams need to replay. They have no soft delete
+// use, so they are removed from the table
+func (repository *OrderEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := repository.db.WithContext(ctx).
+		Unscoped().
+		Where("created_at < ?", before).
+		Delete(&model.OrderEvent{})
+
+	if result.Error != nil {
+		return 0, responses.GetDatabaseError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repository *OrderEventRepository) broadcast(event dto.OrderEvent) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	// Concurrent transactions can commit out of order, so an older event is still delivered
+	if event.EventID > repository.lastEventID {
+		repository.lastEventID = event.EventID
+	}
+
+	for events, orderID := range repository.subscribers {
+		if orderID != nil && *orderID != event.OrderID {
+			continue
+		}
+
+		select {
+		case events <- event:
+		default:
+			delete(repository.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+func (repository *OrderEventRepository) listen(ctx context.Context) {
+	for {
+		err := repository.waitForNotifications(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Print("order events listener", map[string]interface{}{
+			"error": err.Error(),
+		})
+
+		time.Sleep(orderEventsReconnectDelay)
+	}
+}
+
+func (repository *OrderEventRepository) waitForNotifications(ctx context.Context) error {
+	sqlDB, err := repository.db.DB()
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+
+		if !ok {
+			return errors.New("the database driver does not support LISTEN")
+		}
+
+		pgxConn := stdlibConn.Conn()
+
+		_, err := pgxConn.Exec(ctx, "LISTEN "+orderEventsChannel)
+
+		// The connection may be listening to the channel, so it can not go back to the pool
+		if err != nil {
+			return fmt.Errorf("listening order events: %w: %w", driver.ErrBadConn, err)
+		}
+
+		// The events published while this replica was not listening are delivered now
+		repository.catchUp(ctx)
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+
+			// The connection is still listening to the channel, so it can not go back to the pool
+			if err != nil {
+				return fmt.Errorf("waiting order events: %w: %w", driver.ErrBadConn, err)
+			}
+
+			var event dto.OrderEvent
+
+			err = json.Unmarshal([]byte(notification.Payload), &event)
+
+			if err != nil {
+				continue
+			}
+
+			repository.broadcast(event)
+		}
+	})
+}
+
+func (repository *OrderEventRepository) catchUp(ctx context.Context) {
+	repository.mutex.RLock()
+	lastEventID := repository.lastEventID
+	repository.mutex.RUnlock()
+
+	if lastEventID == 0 {
+		return
+	}
+
+	events, err := repository.GetEventsAfter(ctx, nil, lastEventID)
+
+	if err != nil {
+		return
+	}
+
+	for _, event := range events {
+		repository.broadcast(event)
+	}
+}
+
+func (repository *OrderEventRepository) buildEvent(value model.OrderEvent) dto.OrderEvent {
+	return dto.OrderEvent{
+		EventID:    int64(value.ID),
+		OrderID:    value.OrderID,
+		FromStatus: value.FromStatus,
+		ToStatus:   value.ToStatus,
+		Actor:      value.Actor,
+		ChangedAt:  value.CreatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+func (suite *RepositoryTestSuite) TestPublishOrderEventWithSuccess() {
+	repo := NewOrderEventRepository(suite.db)
+
+	orderId := uint(1)
+	events, unsubscribe := repo.Subscribe(&orderId)
+	defer unsubscribe()
+
+	// wait the listener to LISTEN the channel
+	time.Sleep(500 * time.Millisecond)
+
+	err := repo.Publish(suite.ctx, dto.OrderEvent{
+		OrderID:    uint(2),
+		FromStatus: "Criado",
+		ToStatus:   "Preparando",
+		Actor:      "kitchen",
+	})
+	suite.NoError(err)
+
+	err = repo.Publish(suite.ctx, dto.OrderEvent{
+		OrderID:    orderId,
+		FromStatus: "Preparando",
+		ToStatus:   "Finalizado",
+		Actor:      "kitchen",
+	})
+	suite.NoError(err)
+
+	select {
+	case event := <-events:
+		suite.Equal(orderId, event.OrderID)
+		suite.Equal("Finalizado", event.ToStatus)
+		suite.Equal(int64(2), event.EventID)
+	case <-time.After(5 * time.Second):
+		suite.Fail("order event was not delivered")
+	}
+
+	replay, err := repo.GetEventsAfter(suite.ctx, nil, 0)
+	suite.NoError(err)
+	suite.Equal(2, len(replay))
+
+	replay, err = repo.GetEventsAfter(suite.ctx, &orderId, 0)
+	suite.NoError(err)
+	suite.Equal(1, len(replay))
+	suite.Equal("Finalizado", replay[0].ToStatus)
+}
+
+func (suite *RepositoryTestSuite) TestDeleteOrderEventsBeforeWithSuccess() {
+	repo := NewOrderEventRepository(suite.db)
+
+	err := repo.Publish(suite.ctx, dto.OrderEvent{
+		OrderID:    uint(1),
+		FromStatus: "Criado",
+		ToStatus:   "Preparando",
+		Actor:      "kitchen",
+	})
+	suite.NoError(err)
+
+	deleted, err := repo.DeleteBefore(suite.ctx, time.Now().Add(-time.Hour))
+	suite.NoError(err)
+	suite.Equal(int64(0), deleted)
+
+	deleted, err = repo.DeleteBefore(suite.ctx, time.Now().Add(time.Minute))
+	suite.NoError(err)
+	suite.Equal(int64(1), deleted)
+
+	replay, err := repo.GetEventsAfter(suite.ctx, nil, 0)
+	suite.NoError(err)
+	suite.Empty(replay)
+}
@@ -1,11 +1,19 @@
 package repositories
 
 import (
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 func TestOrderRepository(t *testing.T) {
@@ -24,7 +32,7 @@ func (suite *RepositoryTestSuite) TestCreateOrderWithSuccess() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Category",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -38,8 +46,8 @@ func (suite *RepositoryTestSuite) TestCreateOrderWithSuccess() {
 
 	repo := NewOrderRespository(suite.db)
 	newOrder := dto.Order{
-		TotalPrice:   5090,
-		PaymentID:    uint(12),
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    suite.createPayment(entity.NewMoney(509000)),
 		TicketNumber: 12,
 		OrderProduct: []dto.OrderProduct{
 			{
@@ -51,3 +59,722 @@ func (suite *RepositoryTestSuite) TestCreateOrderWithSuccess() {
 	suite.NoError(err)
 	suite.Equal(uint(1), orderResponse.OrderId)
 }
+
+func (suite *RepositoryTestSuite) TestCreateOrderWithPaymentAlreadyUsed() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+	newOrder := dto.Order{
+		TotalPrice:   entity.NewMoney(299000),
+		PaymentID:    suite.createPayment(entity.NewMoney(299000)),
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: productId,
+			},
+		},
+	}
+
+	_, err = repo.GetOrderByPaymentId(suite.ctx, newOrder.PaymentID)
+	suite.Error(err)
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	order, err := repo.GetOrderByPaymentId(suite.ctx, newOrder.PaymentID)
+	suite.NoError(err)
+	suite.Equal(orderResponse.OrderId, order.OrderId)
+
+	newOrder.TicketNumber = 2
+	_, err = repo.CreateOrder(suite.ctx, newOrder)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.True(errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	// The payment must exist
+	newOrder.PaymentID = 999
+	_, err = repo.CreateOrder(suite.ctx, newOrder)
+	suite.Error(err)
+}
+
+func (suite *RepositoryTestSuite) TestCreateOrderWithSplitPayments() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+	newOrder := dto.Order{
+		TotalPrice:   entity.NewMoney(299000),
+		PaymentID:    suite.createPayment(entity.NewMoney(99000)),
+		PaymentIDs:   []uint{suite.createPayment(entity.NewMoney(200000))},
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: productId,
+			},
+		},
+	}
+
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(newOrder.PaymentID, order.PaymentID)
+	suite.Len(order.Payments, 2)
+
+	order, err = repo.GetOrderByPaymentId(suite.ctx, newOrder.PaymentIDs[0])
+	suite.NoError(err)
+	suite.Equal(orderResponse.OrderId, order.OrderId)
+
+	// The paid part can not be used by another order
+	_, err = repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(200000),
+		PaymentID:    newOrder.PaymentIDs[0],
+		TicketNumber: 2,
+		OrderProduct: newOrder.OrderProduct,
+	})
+	suite.Error(err)
+
+	// Deleting the order releases the paid parts
+	err = repo.DeleteOrder(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+
+	_, err = repo.GetOrderByPaymentId(suite.ctx, newOrder.PaymentIDs[0])
+	suite.Error(err)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateOrderStatusWithHistory() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+	newOrder := dto.Order{
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    suite.createPayment(entity.NewMoney(509000)),
+		TicketNumber: 12,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: productId,
+			},
+		},
+	}
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+		OrderID:    orderResponse.OrderId,
+		FromStatus: model.OrderStatusCreated,
+		ToStatus:   model.OrderStatusPreparing,
+		Actor:      "kitchen",
+		Reason:     "Preparing",
+	})
+	suite.NoError(err)
+
+	order, err := repo.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusPreparing, order.OrderStatus)
+	suite.NotNil(order.PreparingAt)
+
+	history, err := repo.GetOrderStatusHistory(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(2, len(history))
+	suite.Equal(model.OrderStatusCreated, history[0].ToStatus)
+	suite.Equal(model.OrderStatusCreated, history[1].FromStatus)
+	suite.Equal(model.OrderStatusPreparing, history[1].ToStatus)
+	suite.Equal("kitchen", history[1].Actor)
+}
+
+func (suite *RepositoryTestSuite) TestCreateOrderWithQuantityAndModifiers() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Lanche",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	toppingId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Bacon",
+		Description: "Bacon",
+		Category:    "Acompanhamento",
+		Price:       entity.NewMoney(50000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+	orderResponse, err := repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(698000),
+		PaymentID:    suite.createPayment(entity.NewMoney(698000)),
+		TicketNumber: 12,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: entity.NewMoney(299000),
+				Quantity:     2,
+				Modifiers: []dto.OrderProductModifier{
+					{
+						Type:        "remove",
+						Description: "Onion",
+					},
+					{
+						Type:        "extra",
+						Description: "Bacon",
+						ProductID:   &toppingId,
+						Price:       entity.NewMoney(50000),
+					},
+				},
+			},
+		},
+	})
+	suite.NoError(err)
+
+	orders, err := repo.GetOrdersToPrepare(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(1, len(orders))
+	suite.Equal(orderResponse.OrderId, orders[0].OrderId)
+	suite.Equal(2, orders[0].OrderProduct[0].Quantity)
+	suite.Equal(entity.NewMoney(299000), orders[0].OrderProduct[0].UnitPrice)
+	suite.Equal(2, len(orders[0].OrderProduct[0].Modifiers))
+	suite.Equal("Onion", orders[0].OrderProduct[0].Modifiers[0].Description)
+	suite.Equal(entity.NewMoney(50000), orders[0].OrderProduct[0].Modifiers[1].Price)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersWithCursorPagination() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+
+	for ticket := 1; ticket <= 5; ticket++ {
+		_, err = repo.CreateOrder(suite.ctx, dto.Order{
+			TotalPrice:   entity.NewMoney(299000),
+			PaymentID:    suite.createPayment(entity.NewMoney(299000)),
+			TicketNumber: ticket,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: productId,
+				},
+			},
+		})
+		suite.NoError(err)
+	}
+
+	_, err = repo.CreatePayingOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(299000),
+		PaymentID:    suite.createPayment(entity.NewMoney(299000)),
+		TicketNumber: 6,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: productId,
+			},
+		},
+	})
+	suite.NoError(err)
+
+	filter := dto.OrderFilter{
+		Statuses:   []string{model.OrderStatusCreated},
+		SortBy:     "ticketNumber",
+		Descending: true,
+		Limit:      2,
+	}
+
+	tickets := []int{}
+
+	for {
+		page, err := repo.GetOrders(suite.ctx, filter)
+		suite.NoError(err)
+
+		for _, order := range page.Orders {
+			tickets = append(tickets, order.TicketNumber)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+
+		filter.Cursor = *page.NextCursor
+	}
+
+	suite.Equal([]int{5, 4, 3, 2, 1}, tickets)
+
+	ticketNumber := 6
+	page, err := repo.GetOrders(suite.ctx, dto.OrderFilter{
+		TicketNumber: &ticketNumber,
+		SortBy:       "createdAt",
+	})
+	suite.NoError(err)
+	suite.Equal(1, len(page.Orders))
+	suite.Equal(model.OrderStatusPaying, page.Orders[0].OrderStatus)
+	suite.Nil(page.NextCursor)
+
+	_, err = repo.GetOrders(suite.ctx, dto.OrderFilter{
+		SortBy: "createdAt",
+		Cursor: "invalid",
+	})
+	suite.Error(err)
+}
+
+func (suite *RepositoryTestSuite) TestGetCustomerOrdersNewestFirst() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+
+	for ticket := 1; ticket <= 4; ticket++ {
+		order := dto.Order{
+			TotalPrice:   entity.NewMoney(299000),
+			PaymentID:    suite.createPayment(entity.NewMoney(299000)),
+			TicketNumber: ticket,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: productId,
+				},
+			},
+		}
+
+		// The last order is from an unknown customer
+		if ticket < 4 {
+			order.CustomerID = &customer.ID
+		}
+
+		_, err = repo.CreateOrder(suite.ctx, order)
+		suite.NoError(err)
+	}
+
+	filter := dto.CustomerOrdersFilter{
+		CustomerID: customer.ID,
+		Limit:      2,
+	}
+
+	tickets := []int{}
+
+	for {
+		page, err := repo.GetCustomerOrders(suite.ctx, filter)
+		suite.NoError(err)
+
+		for _, order := range page.Orders {
+			tickets = append(tickets, order.TicketNumber)
+			suite.Len(order.OrderProduct, 1)
+			suite.Len(order.Payments, 1)
+			suite.Equal(model.PaymentCreditType, order.Payments[0].PaymentType)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+
+		filter.Cursor = *page.NextCursor
+	}
+
+	suite.Equal([]int{3, 2, 1}, tickets)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateOrderStatusWithStaleStatus() {
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    "Category",
+		Price:       entity.NewMoney(299000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewOrderRespository(suite.db)
+	newOrder := dto.Order{
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    suite.createPayment(entity.NewMoney(509000)),
+		TicketNumber: 12,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: productId,
+			},
+		},
+	}
+	orderResponse, err := repo.CreateOrder(suite.ctx, newOrder)
+	suite.NoError(err)
+
+	err = repo.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+		OrderID:    orderResponse.OrderId,
+		FromStatus: model.OrderStatusPreparing,
+		ToStatus:   model.OrderStatusDone,
+		Actor:      "kitchen",
+	})
+	suite.Error(err)
+
+	history, err := repo.GetOrderStatusHistory(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(1, len(history))
+}
+
+func (suite *RepositoryTestSuite) TestGetNextTicketNumberWithSuccess() {
+	repo := NewOrderRespository(suite.db)
+	today := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local).UnixMilli()
+	tomorrow := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local).UnixMilli()
+
+	ticket, err := repo.GetNextTicketNumber(suite.ctx, today)
+	suite.NoError(err)
+	suite.Equal(1, ticket)
+
+	ticket, err = repo.GetNextTicketNumber(suite.ctx, today)
+	suite.NoError(err)
+	suite.Equal(2, ticket)
+
+	ticket, err = repo.GetNextTicketNumber(suite.ctx, tomorrow)
+	suite.NoError(err)
+	suite.Equal(1, ticket)
+}
+
+func (suite *RepositoryTestSuite) TestGetNextTicketNumberConcurrently() {
+	// Each connection pool simulates an API replica sharing the same database
+	replicas := []repository.OrderRepository{}
+
+	for i := 0; i < 3; i++ {
+		db, err := gorm.Open(pg.Open(suite.pgConnectionString), &gorm.Config{})
+		suite.NoError(err)
+
+		sqlDB, err := db.DB()
+		suite.NoError(err)
+		sqlDB.SetMaxOpenConns(20)
+		defer sqlDB.Close()
+
+		replicas = append(replicas, NewOrderRespository(db))
+	}
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local).UnixMilli()
+	total := 500
+
+	var waitGroup sync.WaitGroup
+	tickets := make(chan int, total)
+	errs := make(chan error, total)
+
+	for i := 0; i < total; i++ {
+		waitGroup.Add(1)
+
+		go func(repo repository.OrderRepository) {
+			defer waitGroup.Done()
+
+			ticket, err := repo.GetNextTicketNumber(suite.ctx, date)
+
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			tickets <- ticket
+		}(replicas[i%len(replicas)])
+	}
+
+	waitGroup.Wait()
+	close(tickets)
+	close(errs)
+
+	for err := range errs {
+		suite.NoError(err)
+	}
+
+	seen := map[int]bool{}
+
+	for ticket := range tickets {
+		suite.False(seen[ticket], "ticket %v was allocated twice", ticket)
+		seen[ticket] = true
+	}
+
+	suite.Equal(total, len(seen))
+
+	for ticket := 1; ticket <= total; ticket++ {
+		suite.True(seen[ticket], "ticket %v was skipped", ticket)
+	}
+}
+
+func (suite *RepositoryTestSuite) TestFinishOrderPaymentOnlyOnce() {
+	paymentRepo := NewPaymentRepository(suite.db)
+	repo := NewOrderRespository(suite.db)
+
+	payment, err := paymentRepo.CreatePaymentOrder(suite.ctx, dto.Payment{
+		TotalPrice:  entity.NewMoney(509000),
+		PaymentType: model.PaymentQRCodeType,
+	})
+	suite.NoError(err)
+
+	order, err := repo.CreatePayingOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    payment.PaymentId,
+		TicketNumber: 7,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	result := dto.OrderPaymentResult{
+		EventKey:         "merchant_order:20203112410:paid",
+		OrderID:          order.OrderId,
+		PaymentID:        payment.PaymentId,
+		PaymentStatus:    model.PaymentPayedStatus,
+		GatewayPaymentID: "81030312426",
+		OrderStatus:      model.OrderStatusCreated,
+		Reason:           "Payment confirmed",
+	}
+
+	applied, err := repo.FinishOrderPayment(suite.ctx, result)
+	suite.NoError(err)
+	suite.True(applied)
+
+	applied, err = repo.FinishOrderPayment(suite.ctx, result)
+	suite.NoError(err)
+	suite.False(applied)
+
+	orderResponse, err := repo.GetOrderById(suite.ctx, order.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusCreated, orderResponse.OrderStatus)
+
+	paymentResponse, err := paymentRepo.GetPaymentById(suite.ctx, payment.PaymentId)
+	suite.NoError(err)
+	suite.Equal(model.PaymentPayedStatus, paymentResponse.PaymentStatus)
+	suite.Equal("81030312426", paymentResponse.PaymentGatewayId)
+
+	// The payment is not changed when the order is not paying anymore
+	_, err = repo.FinishOrderPayment(suite.ctx, dto.OrderPaymentResult{
+		EventKey:      "merchant_order:20203112410:expired",
+		OrderID:       order.OrderId,
+		PaymentID:     payment.PaymentId,
+		PaymentStatus: model.PaymentVoidedStatus,
+		OrderStatus:   model.OrderStatusCanceled,
+		Reason:        "QR Code payment expired",
+	})
+	suite.Error(err)
+
+	paymentResponse, err = paymentRepo.GetPaymentById(suite.ctx, payment.PaymentId)
+	suite.NoError(err)
+	suite.Equal(model.PaymentPayedStatus, paymentResponse.PaymentStatus)
+}
+
+func (suite *RepositoryTestSuite) TestFinishOrderPaymentWithSplitOrderNotFullyPaid() {
+	paymentRepo := NewPaymentRepository(suite.db)
+	repo := NewOrderRespository(suite.db)
+
+	payment, err := paymentRepo.CreatePaymentOrder(suite.ctx, dto.Payment{
+		TotalPrice:  entity.NewMoney(3000),
+		PaymentType: model.PaymentQRCodeType,
+	})
+	suite.NoError(err)
+
+	order, err := repo.CreatePayingOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(5000),
+		PaymentID:    payment.PaymentId,
+		PaymentIDs:   []uint{suite.createPayment(entity.NewMoney(1000))},
+		TicketNumber: 8,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	applied, err := repo.FinishOrderPayment(suite.ctx, dto.OrderPaymentResult{
+		EventKey:         "merchant_order:20203112411:paid",
+		OrderID:          order.OrderId,
+		PaymentID:        payment.PaymentId,
+		PaymentStatus:    model.PaymentPayedStatus,
+		GatewayPaymentID: "81030312427",
+		OrderStatus:      model.OrderStatusCreated,
+		Reason:           "Payment confirmed",
+	})
+	suite.NoError(err)
+	suite.False(applied)
+
+	// The payment is kept and the order waits for the rest of its total
+	paymentResponse, err := paymentRepo.GetPaymentById(suite.ctx, payment.PaymentId)
+	suite.NoError(err)
+	suite.Equal(model.PaymentPayedStatus, paymentResponse.PaymentStatus)
+
+	orderResponse, err := repo.GetOrderById(suite.ctx, order.OrderId)
+	suite.NoError(err)
+	suite.Equal(model.OrderStatusPaying, orderResponse.OrderStatus)
+
+	// The split order is found by the type of its other part too
+	paymentType := model.PaymentCreditType
+	page, err := repo.GetOrders(suite.ctx, dto.OrderFilter{
+		PaymentType: &paymentType,
+		SortBy:      "createdAt",
+	})
+	suite.NoError(err)
+	suite.Len(page.Orders, 1)
+	suite.Equal(order.OrderId, page.Orders[0].OrderId)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrdersWaitingPaymentByType() {
+	paymentRepo := NewPaymentRepository(suite.db)
+	repo := NewOrderRespository(suite.db)
+
+	for index, paymentType := range []string{model.PaymentQRCodeType, model.PaymentPIXType} {
+		payment, err := paymentRepo.CreatePaymentOrder(suite.ctx, dto.Payment{
+			TotalPrice:  entity.NewMoney(509000),
+			PaymentType: paymentType,
+		})
+		suite.NoError(err)
+
+		_, err = repo.CreatePayingOrder(suite.ctx, dto.Order{
+			TotalPrice:   entity.NewMoney(509000),
+			PaymentID:    payment.PaymentId,
+			TicketNumber: index + 1,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID: uint(1),
+				},
+			},
+		})
+		suite.NoError(err)
+	}
+
+	orders, err := repo.GetOrdersWaitingPaymentByType(suite.ctx, model.PaymentQRCodeType)
+	suite.NoError(err)
+	suite.Len(orders, 1)
+	suite.Equal(model.PaymentQRCodeType, orders[0].Payments[0].PaymentType)
+}
+
+func (suite *RepositoryTestSuite) TestGetOrderAndPaymentOwner() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repo := NewOrderRespository(suite.db)
+	paymentRepo := NewPaymentRepository(suite.db)
+	guestID := "4f2a9c1b-guest"
+
+	customerPaymentID := suite.createPayment(entity.NewMoney(299000))
+	customerOrder, err := repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(299000),
+		CustomerID:   &customer.ID,
+		PaymentID:    customerPaymentID,
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	guestPaymentID := suite.createPayment(entity.NewMoney(299000))
+	guestOrder, err := repo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(299000),
+		GuestID:      &guestID,
+		PaymentID:    guestPaymentID,
+		TicketNumber: 2,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	owner, err := repo.GetOrderOwner(suite.ctx, customerOrder.OrderId)
+	suite.NoError(err)
+	suite.Equal("12312312312", *owner.CustomerCPF)
+	suite.Nil(owner.GuestID)
+
+	owner, err = paymentRepo.GetPaymentOwner(suite.ctx, customerPaymentID)
+	suite.NoError(err)
+	suite.Equal("12312312312", *owner.CustomerCPF)
+
+	owner, err = repo.GetOrderOwner(suite.ctx, guestOrder.OrderId)
+	suite.NoError(err)
+	suite.Nil(owner.CustomerCPF)
+	suite.Equal(guestID, *owner.GuestID)
+
+	owner, err = paymentRepo.GetPaymentOwner(suite.ctx, guestPaymentID)
+	suite.NoError(err)
+	suite.Equal(guestID, *owner.GuestID)
+
+	_, err = repo.GetOrderOwner(suite.ctx, 999)
+	suite.Error(err)
+}
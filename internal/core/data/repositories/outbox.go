@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// ClaimPendingEvents uses SKIP LOCKED, so the relays of different replicas claim different events.
+// The attempt is counted in the claim, so an event which crashes the relay still backs off
+func (repository *OutboxRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]dto.OutboxEvent, error) {
+	var eventsEntity []model.OutboxEvent
+
+	now := time.Now()
+
+	err := repository.db.WithContext(ctx).
+		Raw(`UPDATE outbox_events SET next_attempt_at = ?, attempts = attempts + 1, updated_at = ?
+			WHERE id IN (
+				SELECT id FROM outbox_events
+				WHERE published_at IS NULL AND deleted_at IS NULL AND next_attempt_at <= ?
+				ORDER BY id
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			now.Add(lease),
+			now,
+			now,
+			limit,
+		).
+		Scan(&eventsEntity).
+		Error
+
+	if err != nil {
+		return []dto.OutboxEvent{}, responses.GetDatabaseError(err)
+	}
+
+	// RETURNING does not keep the subquery order
+	sort.Slice(eventsEntity, func(i, j int) bool {
+		return eventsEntity[i].ID < eventsEntity[j].ID
+	})
+
+	events := []dto.OutboxEvent{}
+
+	for _, value := range eventsEntity {
+		events = append(events, dto.OutboxEvent{
+			ID:            value.ID,
+			EventType:     value.EventType,
+			AggregateType: value.AggregateType,
+			AggregateID:   value.AggregateID,
+			Payload:       json.RawMessage(value.Payload),
+			OccurredAt:    value.CreatedAt,
+			Attempts:      value.Attempts,
+		})
+	}
+
+	return events, nil
+}
+
+func (repository *OutboxRepository) MarkAsPublished(ctx context.Context, eventID uint) error {
+	err := repository.db.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]any{
+			"published_at": time.Now(),
+			"last_error":   "",
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *OutboxRepository) MarkAsFailed(ctx context.Context, eventID uint, nextAttemptAt time.Time, reason string) error {
+	err := repository.db.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]any{
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      reason,
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// createOutboxEvent must receive the transaction of the change which originated the event
+func createOutboxEvent(tx *gorm.DB, eventType string, aggregateType string, aggregateID uint, payload any) error {
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(body),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
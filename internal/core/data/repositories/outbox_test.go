@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+func (suite *RepositoryTestSuite) TestOutboxEventsWrittenWithOrderChanges() {
+	orderRepo := NewOrderRespository(suite.db)
+	outboxRepo := NewOutboxRepository(suite.db)
+
+	order, err := orderRepo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    suite.createPayment(entity.NewMoney(509000)),
+		TicketNumber: 12,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	err = orderRepo.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+		OrderID:    order.OrderId,
+		FromStatus: entity.OrderStatusCreated,
+		ToStatus:   entity.OrderStatusPreparing,
+		Actor:      entity.OrderActorKitchen,
+	})
+	suite.NoError(err)
+
+	// A rolled back change must not write its event
+	err = orderRepo.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+		OrderID:    order.OrderId,
+		FromStatus: entity.OrderStatusCreated,
+		ToStatus:   entity.OrderStatusCanceled,
+		Actor:      entity.OrderActorCustomer,
+	})
+	suite.Error(err)
+
+	events, err := outboxRepo.ClaimPendingEvents(suite.ctx, 10, time.Minute)
+	suite.NoError(err)
+	suite.Equal(2, len(events))
+	suite.Equal(entity.OrderCreatedEvent, events[0].EventType)
+	suite.Equal(entity.OrderPreparingEvent, events[1].EventType)
+	suite.Equal(1, events[1].Attempts)
+
+	var payload dto.OrderEventPayload
+	suite.NoError(json.Unmarshal(events[1].Payload, &payload))
+	suite.Equal(order.OrderId, payload.OrderID)
+	suite.Equal(12, payload.TicketNumber)
+	suite.Equal(entity.OrderStatusCreated, payload.PreviousStatus)
+
+	// The claimed events are leased
+	claimed, err := outboxRepo.ClaimPendingEvents(suite.ctx, 10, time.Minute)
+	suite.NoError(err)
+	suite.Empty(claimed)
+
+	suite.NoError(outboxRepo.MarkAsPublished(suite.ctx, events[0].ID))
+	suite.NoError(outboxRepo.MarkAsFailed(suite.ctx, events[1].ID, time.Now().Add(-time.Second), "webhook returned 503"))
+
+	retried, err := outboxRepo.ClaimPendingEvents(suite.ctx, 10, time.Minute)
+	suite.NoError(err)
+	suite.Equal(1, len(retried))
+	suite.Equal(events[1].ID, retried[0].ID)
+	suite.Equal(2, retried[0].Attempts)
+}
+
+func (suite *RepositoryTestSuite) TestOutboxEventsDeletedWithOrder() {
+	orderRepo := NewOrderRespository(suite.db)
+	outboxRepo := NewOutboxRepository(suite.db)
+
+	order, err := orderRepo.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(509000),
+		PaymentID:    suite.createPayment(entity.NewMoney(509000)),
+		TicketNumber: 13,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: uint(1),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	suite.NoError(orderRepo.DeleteOrder(suite.ctx, order.OrderId))
+
+	events, err := outboxRepo.ClaimPendingEvents(suite.ctx, 10, time.Minute)
+	suite.NoError(err)
+
+	for _, event := range events {
+		suite.NotEqual(entity.OutboxAggregateOrder, event.AggregateType)
+	}
+}
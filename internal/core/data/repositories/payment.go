@@ -5,10 +5,12 @@ import (
 
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -25,6 +27,7 @@ func (repository *PaymentRepository) GetPaymentTypes() []string {
 	return []string{
 		model.PaymentQRCodeType,
 		model.PaymentCreditType,
+		model.PaymentPIXType,
 	}
 }
 
@@ -66,22 +69,163 @@ func (repository *PaymentRepository) CreatePaymentOrder(ctx context.Context, pay
 	}, nil
 }
 
+func (repository *PaymentRepository) GetPaymentById(ctx context.Context, paymentId uint) (dto.PaymentDetails, error) {
+	var paymentEntity model.Payment
+
+	err := repository.
+		db.WithContext(ctx).
+		First(&paymentEntity, paymentId).
+		Error
+
+	if err != nil {
+		return dto.PaymentDetails{}, responses.GetDatabaseError(err)
+	}
+
+	return dto.PaymentDetails{
+		PaymentId:        paymentEntity.ID,
+		CustomerID:       paymentEntity.CustomerID,
+		TotalPrice:       paymentEntity.TotalPrice,
+		PaymentStatus:    paymentEntity.PaymentStatus,
+		PaymentType:      paymentEntity.PaymentType,
+		PaymentGatewayId: paymentEntity.GatewayPaymentID,
+	}, nil
+}
+
+// GetPaymentOwner returns the customer of the payment or, when the payment has none, the customer
+// or the guest of the order paid by it
+func (repository *PaymentRepository) GetPaymentOwner(ctx context.Context, paymentId uint) (dto.ResourceOwner, error) {
+	var paymentEntity model.Payment
+
+	err := repository.
+		db.WithContext(ctx).
+		Preload("Customer").
+		First(&paymentEntity, paymentId).
+		Error
+
+	if err != nil {
+		return dto.ResourceOwner{}, responses.GetDatabaseError(err)
+	}
+
+	if paymentEntity.Customer != nil {
+		return buildResourceOwner(paymentEntity.Customer, nil, false), nil
+	}
+
+	var orderPaymentEntity model.OrderPayment
+
+	err = repository.
+		db.WithContext(ctx).
+		Where("payment_id = ?", paymentId).
+		Limit(1).
+		Find(&orderPaymentEntity).
+		Error
+
+	if err != nil {
+		return dto.ResourceOwner{}, responses.GetDatabaseError(err)
+	}
+
+	if orderPaymentEntity.ID == uint(0) {
+		return dto.ResourceOwner{Erased: paymentEntity.Erased}, nil
+	}
+
+	var orderEntity model.Order
+
+	err = repository.
+		db.WithContext(ctx).
+		Preload("Customer").
+		Where("id = ?", orderPaymentEntity.OrderID).
+		Limit(1).
+		Find(&orderEntity).
+		Error
+
+	if err != nil {
+		return dto.ResourceOwner{}, responses.GetDatabaseError(err)
+	}
+
+	return buildResourceOwner(orderEntity.Customer, orderEntity.GuestID, orderEntity.Erased || paymentEntity.Erased), nil
+}
+
 func (repository *PaymentRepository) FinishPaymentWithError(ctx context.Context, paymentId uint) error {
-	err := repository.db.WithContext(ctx).Model(&model.Payment{}).Where("id = ?", paymentId).Update("payment_status", model.PaymentPayedStatus).Error
+	return repository.updatePaymentStatus(ctx, paymentId, map[string]any{
+		"payment_status": model.PaymentErrorStatus,
+	})
+}
+
+func (repository *PaymentRepository) FinishPaymentWithSuccess(ctx context.Context, paymentId uint, gatewayPaymentId string) error {
+	return repository.updatePaymentStatus(ctx, paymentId, map[string]any{
+		"payment_status":     model.PaymentPayedStatus,
+		"gateway_payment_id": gatewayPaymentId,
+	})
+}
+
+func (repository *PaymentRepository) RefundPayment(ctx context.Context, paymentId uint) error {
+	return repository.updatePaymentStatus(ctx, paymentId, map[string]any{
+		"payment_status": model.PaymentRefundedStatus,
+	})
+}
+
+func (repository *PaymentRepository) VoidPayment(ctx context.Context, paymentId uint) error {
+	return repository.updatePaymentStatus(ctx, paymentId, map[string]any{
+		"payment_status": model.PaymentVoidedStatus,
+	})
+}
+
+// updatePaymentStatus writes the outbox event in the same transaction as the status change
+func (repository *PaymentRepository) updatePaymentStatus(ctx context.Context, paymentId uint, fields map[string]any) error {
+	tx := repository.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	err := updatePaymentStatusInTransaction(tx, paymentId, fields)
 
 	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
 		return responses.GetDatabaseError(err)
 	}
 
 	return nil
 }
 
-func (repository *PaymentRepository) FinishPaymentWithSuccess(ctx context.Context, paymentId uint) error {
-	err := repository.db.WithContext(ctx).Model(&model.Payment{}).Where("id = ?", paymentId).Update("payment_status", model.PaymentErrorStatus).Error
+// updatePaymentStatusInTransaction is shared with the order repository, which finishes the
+// paying orders and their payments together
+func updatePaymentStatusInTransaction(tx *gorm.DB, paymentId uint, fields map[string]any) error {
+	var paymentEntity model.Payment
+
+	err := tx.Model(&paymentEntity).
+		Clauses(clause.Returning{}).
+		Where("id = ?", paymentId).
+		Updates(fields).
+		Error
 
 	if err != nil {
-		return responses.GetDatabaseError(err)
+		return err
 	}
 
-	return nil
+	eventType := entity.PaymentDomainEventType(paymentEntity.PaymentStatus)
+
+	if eventType == "" {
+		return nil
+	}
+
+	return createOutboxEvent(tx, eventType, entity.OutboxAggregatePayment, paymentEntity.ID, dto.PaymentEventPayload{
+		PaymentID:        paymentEntity.ID,
+		Status:           paymentEntity.PaymentStatus,
+		PaymentType:      paymentEntity.PaymentType,
+		TotalPrice:       paymentEntity.TotalPrice,
+		GatewayPaymentID: paymentEntity.GatewayPaymentID,
+		CustomerID:       paymentEntity.CustomerID,
+	})
 }
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type PrivacyRepository struct {
+	db            *gorm.DB
+	cognitoRemote remote.CognitoRemoteDataSource
+}
+
+func NewPrivacyRepository(db *gorm.DB, cognitoRemote remote.CognitoRemoteDataSource) repository.PrivacyRepository {
+	return &PrivacyRepository{
+		db:            db,
+		cognitoRemote: cognitoRemote,
+	}
+}
+
+// GetCustomerPayments returns the payments of the customer and the payments of its orders,
+// the oldest first
+func (repository *PrivacyRepository) GetCustomerPayments(ctx context.Context, customerID uint) ([]dto.PaymentDetails, error) {
+	var paymentEntity []model.Payment
+
+	err := repository.
+		db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Or("id IN (?)", repository.db.
+			Model(&model.OrderPayment{}).
+			Select("order_payments.payment_id").
+			Joins("JOIN orders ON orders.id = order_payments.order_id").
+			Where("orders.customer_id = ?", customerID),
+		).
+		Order("created_at, id").
+		Find(&paymentEntity).
+		Error
+
+	if err != nil {
+		return []dto.PaymentDetails{}, responses.GetDatabaseError(err)
+	}
+
+	payments := []dto.PaymentDetails{}
+
+	for _, value := range paymentEntity {
+		payments = append(payments, dto.PaymentDetails{
+			PaymentId:        value.ID,
+			CustomerID:       value.CustomerID,
+			TotalPrice:       value.TotalPrice,
+			PaymentStatus:    value.PaymentStatus,
+			PaymentType:      value.PaymentType,
+			PaymentGatewayId: value.GatewayPaymentID,
+		})
+	}
+
+	return payments, nil
+}
+
+func (repository *PrivacyRepository) CreateDataSubjectRequest(ctx context.Context, request dto.DataSubjectRequest) error {
+	err := repository.db.WithContext(ctx).Create(dataSubjectRequestEntity(request)).Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+// EraseCustomer removes the customer from Cognito before the database, so a failure can be retried.
+// The customer is anonymized and deleted, and its orders and payments are kept without the customer,
+// so the sales totals do not change, and marked as erased, so only the admins can see them. The loyalty
+// points and the stored idempotent responses are personal data and are removed
+func (repository *PrivacyRepository) EraseCustomer(ctx context.Context, customer dto.Customer, request dto.DataSubjectRequest) error {
+	err := repository.cognitoRemote.DeleteUser(customer.CPF)
+
+	if err != nil {
+		return responses.GetCognitoError(err)
+	}
+
+	tx := repository.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	err = repository.eraseCustomerInTransaction(tx, customer, request)
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	err = tx.Commit().Error
+
+	if err != nil {
+		tx.Rollback()
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *PrivacyRepository) eraseCustomerInTransaction(tx *gorm.DB, customer dto.Customer, request dto.DataSubjectRequest) error {
+	customerID := customer.ID
+
+	err := tx.Model(&model.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"name":  entity.ErasedCustomerName,
+			"cpf":   entity.ErasedCustomerCPF(customerID),
+			"email": entity.ErasedCustomerEmail(customerID),
+			"phone": nil,
+		}).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Unscoped().
+		Model(&model.Order{}).
+		Where("customer_id = ?", customerID).
+		Updates(map[string]any{
+			"customer_id": nil,
+			"guest_id":    nil,
+			"erased":      true,
+		}).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Unscoped().
+		Model(&model.Payment{}).
+		Where("customer_id = ?", customerID).
+		Updates(map[string]any{
+			"customer_id": nil,
+			"erased":      true,
+		}).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	// The stored responses of the customer requests, which are replayed to the retries, have its data
+	err = tx.
+		Where("owner = ?", customer.CPF).
+		Delete(&model.IdempotencyKey{}).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Unscoped().
+		Where("customer_id = ?", customerID).
+		Delete(&model.LoyaltyTransaction{}).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(dataSubjectRequestEntity(request)).Error
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Delete(&model.Customer{}, customerID).Error
+}
+
+func dataSubjectRequestEntity(request dto.DataSubjectRequest) *model.DataSubjectRequest {
+	return &model.DataSubjectRequest{
+		CustomerID:  request.CustomerID,
+		Type:        request.Type,
+		Actor:       request.Actor,
+		RequestedBy: request.RequestedBy,
+	}
+}
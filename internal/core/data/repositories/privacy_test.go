@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+func TestPrivacyRepository(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
+
+func (suite *RepositoryTestSuite) TestEraseCustomerKeepingOrdersTotals() {
+	fakeCognito := NewFakeCognitoRemoteDataSource()
+	repoCustomer := NewCustomerRepository(suite.db, fakeCognito)
+
+	customerID, err := repoCustomer.CreateCustomer(suite.ctx, dto.Customer{
+		Name:  "Teste",
+		CPF:   "17107972073",
+		Email: "teste@teste.com",
+	})
+	suite.NoError(err)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    entity.CategorySnack,
+		Price:       entity.NewMoney(1590),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repoOrder := NewOrderRespository(suite.db)
+	paymentID := suite.createPayment(entity.NewMoney(1590))
+
+	orderResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(1590),
+		CustomerID:   &customerID,
+		PaymentID:    paymentID,
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: entity.NewMoney(1590),
+			},
+		},
+	})
+	suite.NoError(err)
+
+	for _, transition := range [][]string{
+		{model.OrderStatusCreated, model.OrderStatusPreparing},
+		{model.OrderStatusPreparing, model.OrderStatusDone},
+		{model.OrderStatusDone, model.OrderStatusDelivered},
+	} {
+		err = repoOrder.UpdateOrderStatus(suite.ctx, dto.OrderStatusTransition{
+			OrderID:    orderResponse.OrderId,
+			FromStatus: transition[0],
+			ToStatus:   transition[1],
+			Actor:      entity.OrderActorKitchen,
+		})
+		suite.NoError(err)
+	}
+
+	repo := NewPrivacyRepository(suite.db, fakeCognito)
+
+	payments, err := repo.GetCustomerPayments(suite.ctx, customerID)
+	suite.NoError(err)
+	suite.Len(payments, 1)
+	suite.Equal(paymentID, payments[0].PaymentId)
+
+	customer, err := repoCustomer.GetCustomerById(suite.ctx, customerID)
+	suite.NoError(err)
+
+	repoIdempotency := NewIdempotencyRepository(suite.db)
+	idempotentRequest := dto.IdempotentRequest{
+		Scope:       "POST /api/orders sub:5c1e2a4d-customer",
+		Key:         "erased-key",
+		RequestHash: "hash",
+		Owner:       customer.CPF,
+	}
+
+	reserved, err := repoIdempotency.Reserve(suite.ctx, idempotentRequest, time.Now().Add(time.Hour), time.Minute)
+	suite.NoError(err)
+	suite.True(reserved)
+	suite.NoError(repoIdempotency.SaveResponse(suite.ctx, idempotentRequest, dto.IdempotentResponse{
+		StatusCode:  200,
+		ContentType: "application/json",
+		Body:        []byte(`{"customerName":"Teste"}`),
+	}))
+
+	err = repo.EraseCustomer(suite.ctx, customer, dto.DataSubjectRequest{
+		CustomerID:  customerID,
+		Type:        entity.DataSubjectRequestErasure,
+		Actor:       entity.DataSubjectActorCustomer,
+		RequestedBy: "5c1e2a4d-customer",
+	})
+	suite.NoError(err)
+
+	_, err = repoCustomer.GetCustomerById(suite.ctx, customerID)
+	suite.Error(err)
+
+	var erasedCustomer model.Customer
+	suite.NoError(suite.db.Unscoped().First(&erasedCustomer, customerID).Error)
+	suite.Equal(entity.ErasedCustomerName, erasedCustomer.Name)
+	suite.Equal(entity.ErasedCustomerCPF(customerID), erasedCustomer.CPF)
+	suite.Equal(entity.ErasedCustomerEmail(customerID), erasedCustomer.Email)
+
+	// The order is kept with its total, without the customer
+	order, err := repoOrder.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Equal(entity.NewMoney(1590), order.TotalPrice)
+
+	var orderEntity model.Order
+	suite.NoError(suite.db.First(&orderEntity, orderResponse.OrderId).Error)
+	suite.Nil(orderEntity.CustomerID)
+	suite.True(orderEntity.Erased)
+
+	// The erased order is not an open kiosk order
+	owner, err := repoOrder.GetOrderOwner(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.True(owner.Erased)
+
+	_, err = repoIdempotency.GetIdempotencyKey(suite.ctx, idempotentRequest.Scope, idempotentRequest.Key)
+	suite.Error(err)
+
+	var loyaltyTransactions int64
+	suite.NoError(suite.db.Unscoped().Model(&model.LoyaltyTransaction{}).Where("customer_id = ?", customerID).Count(&loyaltyTransactions).Error)
+	suite.Equal(int64(0), loyaltyTransactions)
+
+	var requests []model.DataSubjectRequest
+	suite.NoError(suite.db.Where("customer_id = ?", customerID).Find(&requests).Error)
+	suite.Len(requests, 1)
+	suite.Equal(entity.DataSubjectRequestErasure, requests[0].Type)
+
+	// The customer can sign up again, since Cognito does not have it anymore
+	_, err = repoCustomer.CreateCustomer(suite.ctx, dto.Customer{
+		Name:  "Teste",
+		CPF:   "17107972073",
+		Email: "teste@teste.com",
+	})
+	suite.NoError(err)
+}
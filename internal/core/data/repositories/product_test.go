@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
@@ -27,7 +28,7 @@ func (suite *RepositoryTestSuite) TestGetProductsWithSuccess() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Lanches",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -58,7 +59,7 @@ func (suite *RepositoryTestSuite) TestGetProductByIdWithSuccess() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Lanches",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -87,7 +88,7 @@ func (suite *RepositoryTestSuite) TestCreateProductWithSuccess() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Category",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -120,7 +121,7 @@ func (suite *RepositoryTestSuite) TestUpdateProductWithSuccess() {
 		Name:        "New Product",
 		Description: "New Description Product",
 		Category:    "Category",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -145,7 +146,7 @@ func (suite *RepositoryTestSuite) TestUpdateProductWithSuccess() {
 		Name:        "Updated Product",
 		Description: "Updated Description Product",
 		Category:    "Category",
-		Price:       3990,
+		Price:       entity.NewMoney(399000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -179,7 +180,7 @@ func (suite *RepositoryTestSuite) TestCreateProductWithConflictError() {
 		Name:        "New Product Created",
 		Description: "New Description Product Created",
 		Category:    "Category",
-		Price:       2990,
+		Price:       entity.NewMoney(299000),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "NewImageUrl",
@@ -199,3 +200,25 @@ func (suite *RepositoryTestSuite) TestCreateProductWithConflictError() {
 	suite.Equal(true, errors.As(err, &businessError))
 	suite.Equal(responses.DATABASE_CONFLICT_ERROR, businessError.Code)
 }
+
+func (suite *RepositoryTestSuite) TestCreateProductKeepsPriceCurrency() {
+	repo := NewProductRepository(suite.db)
+
+	newId, err := repo.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "Imported Product",
+		Description: "Imported Description",
+		Category:    "Lanches",
+		Price:       entity.NewMoneyIn(1290, "USD"),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	product, err := repo.GetProductById(suite.ctx, newId)
+
+	suite.NoError(err)
+	suite.Equal(entity.NewMoneyIn(1290, "USD"), product.Price)
+}
@@ -0,0 +1,275 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) repository.PromotionRepository {
+	return &PromotionRepository{
+		db: db,
+	}
+}
+
+func (repository *PromotionRepository) CreatePromotion(ctx context.Context, promotion dto.PromotionForm) (uint, error) {
+	promotionEntity := repository.buildPromotionEntity(promotion)
+
+	err := repository.db.WithContext(ctx).Create(&promotionEntity).Error
+
+	if err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return promotionEntity.ID, nil
+}
+
+func (repository *PromotionRepository) GetPromotions(ctx context.Context) ([]dto.PromotionResponse, error) {
+	var promotionEntity []model.Promotion
+
+	err := repository.
+		db.WithContext(ctx).
+		Order("id").
+		Find(&promotionEntity).
+		Error
+
+	if err != nil {
+		return []dto.PromotionResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildPromotions(promotionEntity), nil
+}
+
+func (repository *PromotionRepository) GetPromotionById(ctx context.Context, id uint) (dto.PromotionResponse, error) {
+	var promotionEntity model.Promotion
+
+	err := repository.db.WithContext(ctx).First(&promotionEntity, id).Error
+
+	if err != nil {
+		return dto.PromotionResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildPromotion(promotionEntity), nil
+}
+
+// UpdatePromotion replaces every rule of the promotion, so a rule can also be removed, like the Category
+func (repository *PromotionRepository) UpdatePromotion(ctx context.Context, promotion dto.PromotionForm) error {
+	result := repository.
+		db.WithContext(ctx).
+		Model(&model.Promotion{}).
+		Where("id = ?", promotion.Id).
+		Select("*").
+		Omit("id", "created_at", "deleted_at").
+		Updates(repository.buildPromotionEntity(promotion))
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Promotion not found",
+		}
+	}
+
+	return nil
+}
+
+// DeletePromotion keeps the promotion in the database, since the orders discounts refer to it
+func (repository *PromotionRepository) DeletePromotion(ctx context.Context, id uint) error {
+	result := repository.db.WithContext(ctx).Delete(&model.Promotion{}, id)
+
+	if result.Error != nil {
+		return responses.GetDatabaseError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Promotion not found",
+		}
+	}
+
+	return nil
+}
+
+// GetActivePromotions returns the automatic promotions and, when the couponCode is given, its promotion.
+// The dates and hours are not filtered here, since they depend on the kiosk time
+func (repository *PromotionRepository) GetActivePromotions(ctx context.Context, couponCode *string) ([]dto.PromotionResponse, error) {
+	var promotionEntity []model.Promotion
+
+	query := repository.
+		db.WithContext(ctx).
+		Where("active = ?", true)
+
+	if couponCode != nil {
+		query = query.Where("code IS NULL OR code = ?", *couponCode)
+	} else {
+		query = query.Where("code IS NULL")
+	}
+
+	err := query.Order("id").Find(&promotionEntity).Error
+
+	if err != nil {
+		return []dto.PromotionResponse{}, responses.GetDatabaseError(err)
+	}
+
+	return repository.buildPromotions(promotionEntity), nil
+}
+
+// CountCustomerUsages counts the orders of the customer with the promotion. The canceled orders
+// give the promotion back to the customer
+func (repository *PromotionRepository) CountCustomerUsages(ctx context.Context, promotionID uint, customerID uint) (int64, error) {
+	count, err := countCustomerUsages(repository.db.WithContext(ctx), promotionID, customerID)
+
+	if err != nil {
+		return 0, responses.GetDatabaseError(err)
+	}
+
+	return count, nil
+}
+
+// checkCustomerUsagesInTransaction locks the customer until the order is created, so two orders
+// created at the same time can not use the promotion more times than its limit
+func checkCustomerUsagesInTransaction(tx *gorm.DB, order model.Order, discounts []dto.OrderDiscount) error {
+	if order.CustomerID == nil || len(discounts) == 0 {
+		return nil
+	}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&model.Customer{}, *order.CustomerID).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	for _, discount := range discounts {
+		var promotion model.Promotion
+
+		err := tx.Unscoped().
+			Select("id", "usage_limit_per_customer").
+			First(&promotion, discount.PromotionID).
+			Error
+
+		if err != nil {
+			return responses.GetDatabaseError(err)
+		}
+
+		if promotion.UsageLimitPerCustomer == 0 {
+			continue
+		}
+
+		usages, err := countCustomerUsages(tx, promotion.ID, *order.CustomerID)
+
+		if err != nil {
+			return responses.GetDatabaseError(err)
+		}
+
+		if usages >= int64(promotion.UsageLimitPerCustomer) {
+			return &responses.LocalError{
+				Code:    responses.LOGIC_ERROR,
+				Message: fmt.Sprintf("The promotion %v was already used by the customer", discount.Description),
+			}
+		}
+	}
+
+	return nil
+}
+
+func countCustomerUsages(db *gorm.DB, promotionID uint, customerID uint) (int64, error) {
+	var count int64
+
+	err := db.
+		Model(&model.OrderDiscount{}).
+		Joins("JOIN orders ON orders.id = order_discounts.order_id AND orders.deleted_at IS NULL").
+		Where("order_discounts.promotion_id = ?", promotionID).
+		Where("orders.customer_id = ?", customerID).
+		Where("orders.order_status <> ?", model.OrderStatusCanceled).
+		Count(&count).
+		Error
+
+	return count, err
+}
+
+func (repository *PromotionRepository) buildPromotionEntity(promotion dto.PromotionForm) model.Promotion {
+	weekdays := []string{}
+
+	for _, weekday := range promotion.Weekdays {
+		weekdays = append(weekdays, strconv.Itoa(weekday))
+	}
+
+	return model.Promotion{
+		Name:                  promotion.Name,
+		Code:                  promotion.Code,
+		Type:                  promotion.Type,
+		Percentage:            promotion.Percentage,
+		Amount:                promotion.Amount,
+		BuyQuantity:           promotion.BuyQuantity,
+		FreeQuantity:          promotion.FreeQuantity,
+		Category:              promotion.Category,
+		ProductID:             promotion.ProductID,
+		StartsAt:              promotion.StartsAt,
+		EndsAt:                promotion.EndsAt,
+		Weekdays:              strings.Join(weekdays, ","),
+		StartTime:             promotion.StartTime,
+		EndTime:               promotion.EndTime,
+		UsageLimitPerCustomer: promotion.UsageLimitPerCustomer,
+		Active:                promotion.Active,
+	}
+}
+
+func (repository *PromotionRepository) buildPromotions(promotionEntity []model.Promotion) []dto.PromotionResponse {
+	promotions := []dto.PromotionResponse{}
+
+	for _, value := range promotionEntity {
+		promotions = append(promotions, repository.buildPromotion(value))
+	}
+
+	return promotions
+}
+
+func (repository *PromotionRepository) buildPromotion(value model.Promotion) dto.PromotionResponse {
+	weekdays := []int{}
+
+	for _, weekday := range strings.Split(value.Weekdays, ",") {
+		if day, err := strconv.Atoi(weekday); err == nil {
+			weekdays = append(weekdays, day)
+		}
+	}
+
+	return dto.PromotionResponse{
+		Id:                    value.ID,
+		Name:                  value.Name,
+		Code:                  value.Code,
+		Type:                  value.Type,
+		Percentage:            value.Percentage,
+		Amount:                value.Amount,
+		BuyQuantity:           value.BuyQuantity,
+		FreeQuantity:          value.FreeQuantity,
+		Category:              value.Category,
+		ProductID:             value.ProductID,
+		StartsAt:              value.StartsAt,
+		EndsAt:                value.EndsAt,
+		Weekdays:              weekdays,
+		StartTime:             value.StartTime,
+		EndTime:               value.EndTime,
+		UsageLimitPerCustomer: value.UsageLimitPerCustomer,
+		Active:                value.Active,
+	}
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestPromotionRepository(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
+
+func (suite *RepositoryTestSuite) TestCreatePromotionWithDuplicatedCode() {
+	repo := NewPromotionRepository(suite.db)
+
+	code := "BEMVINDO"
+	coupon := dto.PromotionForm{
+		Name:     "Cupom de boas-vindas",
+		Code:     &code,
+		Type:     entity.PromotionTypeFixedAmount,
+		Amount:   entity.NewMoney(500),
+		Weekdays: []int{2, 4},
+		Active:   true,
+	}
+
+	promotionId, err := repo.CreatePromotion(suite.ctx, coupon)
+	suite.NoError(err)
+
+	promotion, err := repo.GetPromotionById(suite.ctx, promotionId)
+	suite.NoError(err)
+	suite.Equal(entity.NewMoney(500), promotion.Amount)
+	suite.Equal([]int{2, 4}, promotion.Weekdays)
+
+	_, err = repo.CreatePromotion(suite.ctx, coupon)
+	suite.Error(err)
+
+	var localError *responses.LocalError
+	suite.Equal(true, errors.As(err, &localError))
+	suite.Equal(responses.DATABASE_CONFLICT_ERROR, localError.Code)
+
+	// The code of a deleted promotion can be used again
+	err = repo.DeletePromotion(suite.ctx, promotionId)
+	suite.NoError(err)
+
+	_, err = repo.CreatePromotion(suite.ctx, coupon)
+	suite.NoError(err)
+}
+
+func (suite *RepositoryTestSuite) TestUpdatePromotionRemovingRules() {
+	repo := NewPromotionRepository(suite.db)
+
+	promotion := dto.PromotionForm{
+		Name:       "Bebidas com 10% de desconto",
+		Type:       entity.PromotionTypePercentage,
+		Percentage: 10,
+		Category:   entity.CategoryBeverage,
+		Active:     true,
+	}
+
+	promotionId, err := repo.CreatePromotion(suite.ctx, promotion)
+	suite.NoError(err)
+
+	promotion.Id = promotionId
+	promotion.Category = ""
+	promotion.Active = false
+
+	err = repo.UpdatePromotion(suite.ctx, promotion)
+	suite.NoError(err)
+
+	updated, err := repo.GetPromotionById(suite.ctx, promotionId)
+	suite.NoError(err)
+	suite.Equal("", updated.Category)
+	suite.Equal(false, updated.Active)
+
+	promotion.Id = 999
+	err = repo.UpdatePromotion(suite.ctx, promotion)
+	suite.Error(err)
+}
+
+func (suite *RepositoryTestSuite) TestGetActivePromotionsWithCoupon() {
+	repo := NewPromotionRepository(suite.db)
+
+	welcome := "BEMVINDO"
+	friday := "SEXTOU"
+
+	for _, promotion := range []dto.PromotionForm{
+		{Name: "Automatica", Type: entity.PromotionTypePercentage, Percentage: 10, Active: true},
+		{Name: "Inativa", Type: entity.PromotionTypePercentage, Percentage: 20, Active: false},
+		{Name: "Boas-vindas", Code: &welcome, Type: entity.PromotionTypePercentage, Percentage: 5, Active: true},
+		{Name: "Sexta", Code: &friday, Type: entity.PromotionTypePercentage, Percentage: 15, Active: true},
+	} {
+		_, err := repo.CreatePromotion(suite.ctx, promotion)
+		suite.NoError(err)
+	}
+
+	promotions, err := repo.GetActivePromotions(suite.ctx, nil)
+	suite.NoError(err)
+	suite.Len(promotions, 1)
+	suite.Equal("Automatica", promotions[0].Name)
+
+	promotions, err = repo.GetActivePromotions(suite.ctx, &welcome)
+	suite.NoError(err)
+	suite.Len(promotions, 2)
+	suite.Equal("Boas-vindas", promotions[1].Name)
+}
+
+func (suite *RepositoryTestSuite) TestCountCustomerUsagesIgnoringCanceledOrders() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    entity.CategorySnack,
+		Price:       entity.NewMoney(2000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewPromotionRepository(suite.db)
+
+	code := "BEMVINDO"
+	promotionId, err := repo.CreatePromotion(suite.ctx, dto.PromotionForm{
+		Name:                  "Cupom de boas-vindas",
+		Code:                  &code,
+		Type:                  entity.PromotionTypeFixedAmount,
+		Amount:                entity.NewMoney(500),
+		UsageLimitPerCustomer: 1,
+		Active:                true,
+	})
+	suite.NoError(err)
+
+	repoOrder := NewOrderRespository(suite.db)
+	orderResponse, err := repoOrder.CreateOrder(suite.ctx, dto.Order{
+		TotalPrice:   entity.NewMoney(1500),
+		CustomerID:   &customer.ID,
+		PaymentID:    suite.createPayment(entity.NewMoney(1500)),
+		TicketNumber: 1,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID:    productId,
+				ProductPrice: entity.NewMoney(2000),
+			},
+		},
+		Discounts: []dto.OrderDiscount{
+			{
+				PromotionID: promotionId,
+				Description: "Cupom de boas-vindas",
+				Code:        &code,
+				Amount:      entity.NewMoney(500),
+			},
+		},
+	})
+	suite.NoError(err)
+	suite.Equal(entity.NewMoney(500), orderResponse.DiscountPrice)
+
+	order, err := repoOrder.GetOrderById(suite.ctx, orderResponse.OrderId)
+	suite.NoError(err)
+	suite.Len(order.Discounts, 1)
+	suite.Equal(entity.NewMoney(500), order.DiscountPrice)
+	suite.Equal(entity.NewMoney(1500), order.TotalPrice)
+
+	usages, err := repo.CountCustomerUsages(suite.ctx, promotionId, customer.ID)
+	suite.NoError(err)
+	suite.Equal(int64(1), usages)
+
+	err = suite.db.Model(&model.Order{}).
+		Where("id = ?", orderResponse.OrderId).
+		Update("order_status", model.OrderStatusCanceled).
+		Error
+	suite.NoError(err)
+
+	usages, err = repo.CountCustomerUsages(suite.ctx, promotionId, customer.ID)
+	suite.NoError(err)
+	suite.Equal(int64(0), usages)
+}
+
+func (suite *RepositoryTestSuite) TestCreateOrderOverPromotionCustomerLimit() {
+	customer := &model.Customer{
+		Name:  "Teste",
+		CPF:   "12312312312",
+		Email: "teste@teste.com",
+	}
+	suite.NoError(suite.db.Create(customer).Error)
+
+	repoProduct := NewProductRepository(suite.db)
+	productId, err := repoProduct.CreateProduct(suite.ctx, dto.ProductForm{
+		Name:        "New Product Created",
+		Description: "New Description Product Created",
+		Category:    entity.CategorySnack,
+		Price:       entity.NewMoney(2000),
+		Images: []dto.ProducImage{
+			{
+				ImageUrl: "NewImageUrl",
+			},
+		},
+	})
+	suite.NoError(err)
+
+	repo := NewPromotionRepository(suite.db)
+
+	code := "BEMVINDO"
+	promotionId, err := repo.CreatePromotion(suite.ctx, dto.PromotionForm{
+		Name:                  "Cupom de boas-vindas",
+		Code:                  &code,
+		Type:                  entity.PromotionTypeFixedAmount,
+		Amount:                entity.NewMoney(500),
+		UsageLimitPerCustomer: 1,
+		Active:                true,
+	})
+	suite.NoError(err)
+
+	repoOrder := NewOrderRespository(suite.db)
+
+	// The second order was priced before the first one was created, like two kiosks at the same time
+	for ticketNumber := 1; ticketNumber <= 2; ticketNumber++ {
+		_, err = repoOrder.CreateOrder(suite.ctx, dto.Order{
+			TotalPrice:   entity.NewMoney(1500),
+			CustomerID:   &customer.ID,
+			PaymentID:    suite.createPayment(entity.NewMoney(1500)),
+			TicketNumber: ticketNumber,
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID:    productId,
+					ProductPrice: entity.NewMoney(2000),
+				},
+			},
+			Discounts: []dto.OrderDiscount{
+				{
+					PromotionID: promotionId,
+					Description: "Cupom de boas-vindas",
+					Code:        &code,
+					Amount:      entity.NewMoney(500),
+				},
+			},
+		})
+	}
+	suite.Error(err)
+
+	usages, err := repo.CountCustomerUsages(suite.ctx, promotionId, customer.ID)
+	suite.NoError(err)
+	suite.Equal(int64(1), usages)
+
+	var orders int64
+	suite.NoError(suite.db.Model(&model.Order{}).Where("customer_id = ?", customer.ID).Count(&orders).Error)
+	suite.Equal(int64(1), orders)
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+// paidOrdersQuery only considers the orders with a confirmed payment which were not canceled.
+// It expects the payment status, the canceled status and the [from, to) range as params
+const paidOrdersQuery = `
+	FROM orders o
+	JOIN payments p ON p.id = o.payment_id AND p.payment_status = ?
+	WHERE o.order_status <> ?
+		AND o.deleted_at IS NULL
+		AND o.created_at >= ?
+		AND o.created_at < ?`
+
+// orderProductsJoin joins the paid orders with their products. The products may have been
+// deleted from the catalog, so the price snapshot of the order is used when it exists
+const orderProductsJoin = `
+	JOIN order_products op ON op.order_id = o.id
+	LEFT JOIN products pr ON pr.id = op.product_id`
+
+const lineRevenue = `
+	op.quantity * (
+		COALESCE(NULLIF(op.unit_price, 0), pr.price, 0) +
+		COALESCE((
+			SELECT SUM(m.price)
+			FROM order_product_modifiers m
+			WHERE m.order_product_id = op.id AND m.deleted_at IS NULL
+		), 0)
+	)`
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) repository.ReportRepository {
+	return &ReportRepository{
+		db: db,
+	}
+}
+
+func (repository *ReportRepository) GetRevenueByDay(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByDay, error) {
+	report := []dto.RevenueByDay{}
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				TO_CHAR(DATE(o.created_at), 'YYYY-MM-DD') AS day,
+				COUNT(*) AS orders,
+				COALESCE(SUM(o.total_price), 0) AS revenue`+
+			paidOrdersQuery+`
+			GROUP BY DATE(o.created_at)
+			ORDER BY DATE(o.created_at)`,
+			repository.paidOrdersParams(filter)...,
+		).
+		Scan(&report).
+		Error
+
+	if err != nil {
+		return []dto.RevenueByDay{}, responses.GetDatabaseError(err)
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) GetRevenueByCategory(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByCategory, error) {
+	report := []dto.RevenueByCategory{}
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				COALESCE(pr.category, '') AS category,
+				SUM(op.quantity) AS quantity,
+				SUM(`+lineRevenue+`) AS revenue
+			FROM (SELECT o.* `+paidOrdersQuery+`) o`+
+			orderProductsJoin+`
+			WHERE op.deleted_at IS NULL
+			GROUP BY pr.category
+			ORDER BY revenue DESC`,
+			repository.paidOrdersParams(filter)...,
+		).
+		Scan(&report).
+		Error
+
+	if err != nil {
+		return []dto.RevenueByCategory{}, responses.GetDatabaseError(err)
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) GetRevenueByProduct(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByProduct, error) {
+	report := []dto.RevenueByProduct{}
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				op.product_id AS product_id,
+				COALESCE(pr.name, '') AS product_name,
+				COALESCE(pr.category, '') AS category,
+				SUM(op.quantity) AS quantity,
+				SUM(`+lineRevenue+`) AS revenue
+			FROM (SELECT o.* `+paidOrdersQuery+`) o`+
+			orderProductsJoin+`
+			WHERE op.deleted_at IS NULL
+			GROUP BY op.product_id, pr.name, pr.category
+			ORDER BY revenue DESC`,
+			repository.paidOrdersParams(filter)...,
+		).
+		Scan(&report).
+		Error
+
+	if err != nil {
+		return []dto.RevenueByProduct{}, responses.GetDatabaseError(err)
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) GetOrderTimes(ctx context.Context, filter dto.ReportFilter) (dto.OrderTimesReport, error) {
+	var report dto.OrderTimesReport
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				COUNT(*) AS orders,
+				COALESCE(AVG(t.wait), 0) AS average_wait_seconds,
+				COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY t.wait), 0) AS p95_wait_seconds,
+				COALESCE(AVG(t.preparation), 0) AS average_preparation_seconds,
+				COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY t.preparation), 0) AS p95_preparation_seconds,
+				COALESCE(AVG(t.pickup), 0) AS average_pickup_seconds,
+				COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY t.pickup), 0) AS p95_pickup_seconds
+			FROM (
+				SELECT
+					EXTRACT(EPOCH FROM (preparing_at - created_at)) AS wait,
+					EXTRACT(EPOCH FROM (done_at - preparing_at)) AS preparation,
+					EXTRACT(EPOCH FROM (COALESCE(delivered_at, not_delivered_at) - done_at)) AS pickup
+				FROM orders
+				WHERE deleted_at IS NULL
+					AND preparing_at IS NOT NULL
+					AND created_at >= ?
+					AND created_at < ?
+			) t`,
+			filter.From,
+			filter.To,
+		).
+		Scan(&report).
+		Error
+
+	if err != nil {
+		return dto.OrderTimesReport{}, responses.GetDatabaseError(err)
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) GetNotDelivered(ctx context.Context, filter dto.ReportFilter) (dto.NotDeliveredReport, error) {
+	var report dto.NotDeliveredReport
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				COUNT(*) FILTER (WHERE order_status = ?) AS delivered,
+				COUNT(*) FILTER (WHERE order_status = ?) AS not_delivered
+			FROM orders
+			WHERE deleted_at IS NULL
+				AND created_at >= ?
+				AND created_at < ?`,
+			model.OrderStatusDelivered,
+			model.OrderStatusNotDelivered,
+			filter.From,
+			filter.To,
+		).
+		Scan(&report).
+		Error
+
+	if err != nil {
+		return dto.NotDeliveredReport{}, responses.GetDatabaseError(err)
+	}
+
+	if finished := report.Delivered + report.NotDelivered; finished > 0 {
+		report.NotDeliveredRate = float64(report.NotDelivered) / float64(finished)
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) GetHourlyVolume(ctx context.Context, filter dto.ReportFilter) ([]dto.HourlyVolume, error) {
+	var volume []dto.HourlyVolume
+
+	err := repository.db.WithContext(ctx).
+		Raw(`SELECT
+				EXTRACT(HOUR FROM created_at)::int AS hour,
+				COUNT(*) AS orders
+			FROM orders
+			WHERE deleted_at IS NULL
+				AND order_status <> ?
+				AND created_at >= ?
+				AND created_at < ?
+			GROUP BY hour`,
+			model.OrderStatusCanceled,
+			filter.From,
+			filter.To,
+		).
+		Scan(&volume).
+		Error
+
+	if err != nil {
+		return []dto.HourlyVolume{}, responses.GetDatabaseError(err)
+	}
+
+	// All the hours are returned, so the charts don't need to fill the gaps
+	report := make([]dto.HourlyVolume, 24)
+
+	for hour := range report {
+		report[hour].Hour = hour
+	}
+
+	for _, value := range volume {
+		report[value.Hour].Orders = value.Orders
+	}
+
+	return report, nil
+}
+
+func (repository *ReportRepository) paidOrdersParams(filter dto.ReportFilter) []any {
+	return []any{
+		model.PaymentPayedStatus,
+		model.OrderStatusCanceled,
+		filter.From,
+		filter.To,
+	}
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+func (suite *RepositoryTestSuite) TestGetNotDeliveredAndHourlyVolumeWithSuccess() {
+	repo := NewReportRepository(suite.db)
+
+	createdAt := time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)
+
+	for _, status := range []string{
+		model.OrderStatusDelivered,
+		model.OrderStatusDelivered,
+		model.OrderStatusDelivered,
+		model.OrderStatusNotDelivered,
+		model.OrderStatusCanceled,
+	} {
+		order := model.Order{
+			OrderStatus: status,
+			TotalPrice:  entity.NewMoney(1000),
+		}
+		order.CreatedAt = createdAt
+
+		err := suite.db.Create(&order).Error
+		suite.NoError(err)
+	}
+
+	filter := dto.ReportFilter{
+		From: createdAt.Add(-time.Hour),
+		To:   createdAt.Add(time.Hour),
+	}
+
+	notDelivered, err := repo.GetNotDelivered(suite.ctx, filter)
+	suite.NoError(err)
+	suite.Equal(3, notDelivered.Delivered)
+	suite.Equal(1, notDelivered.NotDelivered)
+	suite.Equal(0.25, notDelivered.NotDeliveredRate)
+
+	volume, err := repo.GetHourlyVolume(suite.ctx, filter)
+	suite.NoError(err)
+	suite.Equal(24, len(volume))
+	suite.Equal(4, volume[createdAt.Hour()].Orders)
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		db: db,
+	}
+}
+
+// Revoke ignores a login already revoked, so the logout can be repeated
+func (repository *RevokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	err := repository.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.RevokedToken{
+			TokenID:   tokenID,
+			ExpiresAt: expiresAt,
+		}).
+		Error
+
+	if err != nil {
+		return responses.GetDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (repository *RevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+
+	err := repository.db.WithContext(ctx).
+		Model(&model.RevokedToken{}).
+		Where("token_id = ?", tokenID).
+		Count(&count).
+		Error
+
+	if err != nil {
+		return false, responses.GetDatabaseError(err)
+	}
+
+	return count > 0, nil
+}
+
+func (repository *RevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := repository.db.WithContext(ctx).
+		Where("expires_at <= ?", now).
+		Delete(&model.RevokedToken{})
+
+	if result.Error != nil {
+		return 0, responses.GetDatabaseError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
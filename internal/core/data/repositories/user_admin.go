@@ -102,13 +102,3 @@ func (repository *UserAdminRepository) populateUser(userrEntity model.UserAdmin)
 		Email: userrEntity.Email,
 	}
 }
-
-func (repository *UserAdminRepository) Login(ctx context.Context, cpf string) (string, error) {
-	token, err := repository.cognitoRemote.Login(cpf)
-
-	if err != nil {
-		return "", responses.GetDatabaseError(err)
-	}
-
-	return token, nil
-}
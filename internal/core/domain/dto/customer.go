@@ -1,10 +1,13 @@
 package dto
 
+// Customer is the identified customer. The Phone is optional, in the E.164 format like '+5511987654321',
+// and when it is set the login codes are sent to it by SMS
 type Customer struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name" validate:"required"`
-	CPF   string `json:"cpf" validate:"required"`
-	Email string `json:"email" validate:"required"`
+	ID    uint    `json:"id"`
+	Name  string  `json:"name" validate:"required"`
+	CPF   string  `json:"cpf" validate:"required"`
+	Email string  `json:"email" validate:"required"`
+	Phone *string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
 type CustomerForm struct {
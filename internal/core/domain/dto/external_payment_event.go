@@ -4,3 +4,10 @@ type ExternalPaymentEvent struct {
 	Resource string `json:"resource"`
 	Topic    string `json:"topic"`
 }
+
+// WebhookSignature has the values signed by Mercado Livre in the 'x-signature' header
+type WebhookSignature struct {
+	Signature string
+	RequestID string
+	DataID    string
+}
@@ -11,4 +11,6 @@ type ExternalPaymentInformation struct {
 	LastUpdated       string
 	OrderStatus       string
 	ClientID          string
+	ApprovedPaymentID string
+	LastPaymentStatus string
 }
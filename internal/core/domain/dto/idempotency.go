@@ -0,0 +1,25 @@
+package dto
+
+// IdempotentRequest identifies a request by its key. The scope is the route, so the same key
+// can be used in different endpoints, and the hash detects a key reused with another body. The owner
+// is the username of the logged customer or admin, so its responses can be erased with its data
+type IdempotentRequest struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Owner       string
+}
+
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyKey has a nil Response while the original request is still being processed
+type IdempotencyKey struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Response    *IdempotentResponse
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// LoyaltyResponse has the points balance of the customer and the BalanceValue, the discount
+// it gives when redeemed. The Transactions are the newest first
+type LoyaltyResponse struct {
+	CustomerID   uint                         `json:"customerId"`
+	Balance      int                          `json:"balance"`
+	BalanceValue entity.Money                 `json:"balanceValue"`
+	Transactions []LoyaltyTransactionResponse `json:"transactions"`
+}
+
+// LoyaltyTransactionResponse is negative when the points were redeemed or reversed
+type LoyaltyTransactionResponse struct {
+	Id          uint      `json:"id"`
+	OrderID     *uint     `json:"orderId"`
+	Type        string    `json:"type"`
+	Points      int       `json:"points"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
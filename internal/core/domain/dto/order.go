@@ -1,45 +1,167 @@
 package dto
 
-import "time"
+import (
+	"time"
 
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// Order is paid by the PaymentID. A split order also has the other PaymentIDs, and the
+// payments must sum the TotalPrice. The client sends the products total, which is replaced
+// by the total with the Discounts of the promotions and the CouponCode. The LoyaltyPoints are
+// redeemed by the customer and also discounted, as the LoyaltyDiscount. The GuestID comes from the
+// token of the unknown customer and the AuthenticatedCPF from the token of the customer, not from the body
 type Order struct {
-	OrderStatus  string
-	TotalPrice   float64        `json:"totalPrice" validate:"required"`
-	CustomerID   *uint          `json:"customerId"`
-	PaymentID    uint           `json:"paymentId" validate:"required"`
-	OrderProduct []OrderProduct `json:"orderProducts" validate:"required"`
-	TicketNumber int
+	OrderStatus      string
+	TotalPrice       entity.Money   `json:"totalPrice" validate:"required"`
+	CustomerID       *uint          `json:"customerId"`
+	GuestID          *string        `json:"-"`
+	AuthenticatedCPF string         `json:"-"`
+	PaymentID        uint           `json:"paymentId" validate:"required"`
+	PaymentIDs       []uint         `json:"paymentIds"`
+	CouponCode       *string        `json:"couponCode"`
+	LoyaltyPoints    int            `json:"loyaltyPoints"`
+	OrderProduct     []OrderProduct `json:"orderProducts" validate:"required"`
+	TicketNumber     int
+	Discounts        []OrderDiscount
+	LoyaltyDiscount  entity.Money
 }
 
+// QRCodeOrder is paid by the QR Code. The PaymentIDs are the parts of a split order already paid,
+// so the QR Code only charges the rest of the TotalPrice
 type QRCodeOrder struct {
-	OrderStatus  string
-	TotalPrice   float64        `json:"totalPrice" validate:"required"`
-	CustomerID   *uint          `json:"customerId"`
-	OrderProduct []OrderProduct `json:"orderProducts" validate:"required"`
-	TicketNumber int
-	PaymentID    uint
+	OrderStatus      string
+	TotalPrice       entity.Money   `json:"totalPrice" validate:"required"`
+	CustomerID       *uint          `json:"customerId"`
+	GuestID          *string        `json:"-"`
+	AuthenticatedCPF string         `json:"-"`
+	OrderProduct     []OrderProduct `json:"orderProducts" validate:"required"`
+	TicketNumber     int
+	PaymentID        uint
+	PaymentType      string  `json:"paymentType"`
+	PaymentIDs       []uint  `json:"paymentIds"`
+	CouponCode       *string `json:"couponCode"`
+	LoyaltyPoints    int     `json:"loyaltyPoints"`
+}
+
+// OrderPaymentResponse is one of the payments of the order
+type OrderPaymentResponse struct {
+	PaymentID     uint         `json:"paymentId"`
+	PaymentType   string       `json:"paymentType"`
+	PaymentStatus string       `json:"paymentStatus"`
+	TotalPrice    entity.Money `json:"totalPrice"`
 }
 
+// OrderProduct is priced with the catalog. The ProductCategory also comes from the catalog,
+// to find the items of the category promotions
 type OrderProduct struct {
-	ProductID    uint    `json:"productId" validate:"required"`
-	ProductPrice float64 `json:"productPrice" validate:"required"`
+	ProductID       uint                   `json:"productId" validate:"required"`
+	ProductPrice    entity.Money           `json:"productPrice" validate:"required"`
+	Quantity        int                    `json:"quantity"`
+	Modifiers       []OrderProductModifier `json:"modifiers"`
+	ProductCategory string                 `json:"-"`
+}
+
+// OrderProductModifier customizes a single order item. The Type can be 'remove' (an ingredient),
+// 'extra' (a topping product with surcharge) or 'note' (free text for the kitchen)
+type OrderProductModifier struct {
+	Type        string       `json:"type" validate:"required"`
+	Description string       `json:"description"`
+	ProductID   *uint        `json:"productId"`
+	Price       entity.Money `json:"price"`
 }
 
 type OrderResponse struct {
-	OrderId        uint                   `json:"orderId"`
-	OrderDate      time.Time              `json:"orderDate"`
-	PreparingAt    *time.Time             `json:"preparingAt"`
-	DoneAt         *time.Time             `json:"doneAt"`
-	DeliveredAt    *time.Time             `json:"deliveredAt"`
-	NotDeliveredAt *time.Time             `json:"notDeliveredAt"`
-	TicketNumber   int                    `json:"ticketNumber"`
-	CustomerName   *string                `json:"customerName"`
-	OrderStatus    string                 `json:"orderStatus"`
-	OrderProduct   []OrderProductResponse `json:"orderProducts"`
+	OrderId         uint                   `json:"orderId"`
+	OrderDate       time.Time              `json:"orderDate"`
+	PreparingAt     *time.Time             `json:"preparingAt"`
+	DoneAt          *time.Time             `json:"doneAt"`
+	DeliveredAt     *time.Time             `json:"deliveredAt"`
+	NotDeliveredAt  *time.Time             `json:"notDeliveredAt"`
+	CanceledAt      *time.Time             `json:"canceledAt"`
+	TicketNumber    int                    `json:"ticketNumber"`
+	TotalPrice      entity.Money           `json:"totalPrice"`
+	DiscountPrice   entity.Money           `json:"discountPrice"`
+	Discounts       []OrderDiscount        `json:"discounts"`
+	LoyaltyPoints   int                    `json:"loyaltyPoints"`
+	LoyaltyDiscount entity.Money           `json:"loyaltyDiscount"`
+	PaymentID       uint                   `json:"paymentId"`
+	Payments        []OrderPaymentResponse `json:"payments"`
+	CustomerName    *string                `json:"customerName"`
+	OrderStatus     string                 `json:"orderStatus"`
+	OrderProduct    []OrderProductResponse `json:"orderProducts"`
+}
+
+// OrderFilter is used by the admin to search the orders. All the filters are optional and
+// the Cursor is the NextCursor returned by the previous page
+type OrderFilter struct {
+	Statuses     []string
+	CustomerID   *uint
+	TicketNumber *int
+	PaymentType  *string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	SortBy       string
+	Descending   bool
+	Cursor       string
+	Limit        int
+}
+
+// CustomerOrdersFilter pages the orders of a customer, the newest first. The Cursor is the
+// NextCursor returned by the previous page
+type CustomerOrdersFilter struct {
+	CustomerID uint
+	Cursor     string
+	Limit      int
+}
+
+type OrderPage struct {
+	Orders     []OrderResponse `json:"orders"`
+	NextCursor *string         `json:"nextCursor"`
+}
+
+type OrderCancelForm struct {
+	Reason string `json:"reason" validate:"required"`
 }
 
 type OrderProductResponse struct {
-	ProductID   uint   `json:"id"`
-	ProductName string `json:"name"`
-	Description string `json:"description"`
+	ProductID   uint                           `json:"id"`
+	ProductName string                         `json:"name"`
+	Description string                         `json:"description"`
+	Quantity    int                            `json:"quantity"`
+	UnitPrice   entity.Money                   `json:"unitPrice"`
+	Modifiers   []OrderProductModifierResponse `json:"modifiers"`
+}
+
+type OrderProductModifierResponse struct {
+	Type        string       `json:"type"`
+	Description string       `json:"description"`
+	Price       entity.Money `json:"price"`
+}
+
+type OrderStatusTransition struct {
+	OrderID    uint
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	Reason     string
+}
+
+// OrderEvent is pushed to the kitchen and customer screens every time an order changes its status.
+// The EventID is sequential, so the clients can resume the stream from the last event they received
+type OrderEvent struct {
+	EventID    int64     `json:"eventId"`
+	OrderID    uint      `json:"orderId"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	Actor      string    `json:"actor"`
+	ChangedAt  time.Time `json:"changedAt"`
+}
+
+type OrderStatusHistoryResponse struct {
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changedAt"`
 }
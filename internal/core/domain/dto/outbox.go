@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// OutboxEvent is the envelope sent to the publishers. The ID is the same in every delivery
+// attempt, so the consumers can discard the duplicated ones
+type OutboxEvent struct {
+	ID            uint            `json:"id"`
+	EventType     string          `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   uint            `json:"aggregateId"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	Attempts      int             `json:"-"`
+}
+
+type OrderEventPayload struct {
+	OrderID        uint         `json:"orderId"`
+	TicketNumber   int          `json:"ticketNumber"`
+	Status         string       `json:"status"`
+	PreviousStatus string       `json:"previousStatus,omitempty"`
+	Actor          string       `json:"actor"`
+	Reason         string       `json:"reason,omitempty"`
+	TotalPrice     entity.Money `json:"totalPrice"`
+	PaymentID      uint         `json:"paymentId,omitempty"`
+	CustomerID     *uint        `json:"customerId,omitempty"`
+}
+
+type PaymentEventPayload struct {
+	PaymentID        uint         `json:"paymentId"`
+	Status           string       `json:"status"`
+	PaymentType      string       `json:"paymentType"`
+	TotalPrice       entity.Money `json:"totalPrice"`
+	GatewayPaymentID string       `json:"gatewayPaymentId,omitempty"`
+	CustomerID       *uint        `json:"customerId,omitempty"`
+}
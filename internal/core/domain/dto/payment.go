@@ -1,11 +1,15 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
 
 type Payment struct {
-	CustomerID  *uint   `json:"customerId"`
-	TotalPrice  float64 `json:"totalPrice" validate:"required"`
-	PaymentType string  `json:"paymentType" validate:"required"`
+	CustomerID  *uint        `json:"customerId"`
+	TotalPrice  entity.Money `json:"totalPrice" validate:"required"`
+	PaymentType string       `json:"paymentType" validate:"required"`
 }
 
 type PaymentResponse struct {
@@ -13,3 +17,31 @@ type PaymentResponse struct {
 	PaymentGatewayId string    `json:"paymentGatewayId"`
 	PaymentDate      time.Time `json:"paymentDate"`
 }
+
+type PaymentDetails struct {
+	PaymentId        uint         `json:"paymentId"`
+	CustomerID       *uint        `json:"customerId"`
+	TotalPrice       entity.Money `json:"totalPrice"`
+	PaymentStatus    string       `json:"paymentStatus"`
+	PaymentType      string       `json:"paymentType"`
+	PaymentGatewayId string       `json:"paymentGatewayId"`
+}
+
+// PaymentOrderResponse is the payment with the order paid by it. The Order is null while the payment
+// was not used by any order
+type PaymentOrderResponse struct {
+	PaymentDetails
+	Order *OrderResponse `json:"order"`
+}
+
+// OrderPaymentResult finishes a paying order and its payment. The EventKey identifies the
+// notification which originated it, so a duplicated notification changes nothing
+type OrderPaymentResult struct {
+	EventKey         string
+	OrderID          uint
+	PaymentID        uint
+	PaymentStatus    string
+	GatewayPaymentID string
+	OrderStatus      string
+	Reason           string
+}
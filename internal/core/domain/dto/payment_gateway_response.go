@@ -1,8 +1,52 @@
 package dto
 
-import "time"
+import (
+	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// PaymentAuthorization starts a payment in the provider. The Order is sent only to the providers
+// which show the items to the customer, like the QR Code ones
+type PaymentAuthorization struct {
+	PaymentID   uint
+	OrderID     uint
+	CustomerID  *uint
+	TotalPrice  entity.Money
+	PaymentType string
+	Order       *Order
+}
+
+// PaymentGatewayResponse is the payment in the provider. The PaymentStatus is one of the payment statuses,
+// where Pagando means it was authorized and still needs to be captured or paid by the customer
 type PaymentGatewayResponse struct {
 	PaymentGatewayId string
+	PaymentStatus    string
 	PaymentDate      time.Time
+	QRCodeData       string
+	QRCodePNG        []byte
+}
+
+// PaymentWebhook is a notification sent by a provider. Only the provider knows how to read it
+type PaymentWebhook struct {
+	Header http.Header
+	Query  url.Values
+	Body   []byte
+}
+
+// PaymentWebhookEvent is the payment result read from a webhook. Finished is false while the
+// customer did not finish the payment, so nothing must change. The PaidAmount is zero when the
+// provider does not send it
+type PaymentWebhookEvent struct {
+	EventKey         string
+	OrderID          uint
+	PaymentID        uint
+	GatewayPaymentID string
+	PaymentStatus    string
+	OrderStatus      string
+	Reason           string
+	PaidAmount       entity.Money
+	Finished         bool
 }
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// DataSubjectRequest is the audit of an export or an erasure of the customer data. The RequestedBy
+// is the token subject, so the audit does not keep the CPF of the erased customer
+type DataSubjectRequest struct {
+	CustomerID  uint
+	Type        string
+	Actor       string
+	RequestedBy string
+}
+
+// CustomerDataExport has all the data kept about the customer
+type CustomerDataExport struct {
+	Customer            Customer                     `json:"customer"`
+	Orders              []OrderResponse              `json:"orders"`
+	Payments            []PaymentDetails             `json:"payments"`
+	LoyaltyTransactions []LoyaltyTransactionResponse `json:"loyaltyTransactions"`
+	ExportedAt          time.Time                    `json:"exportedAt"`
+}
@@ -1,11 +1,13 @@
 package dto
 
+import "github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+
 type ProductForm struct {
 	Id               uint          `json:"id"`
 	Name             string        `json:"name" validate:"required"`
 	Description      string        `json:"description" validate:"required"`
 	Category         string        `json:"category" validate:"required"`
-	Price            float64       `json:"price" validate:"required"`
+	Price            entity.Money  `json:"price" validate:"required"`
 	Images           []ProducImage `json:"images" validate:"required"`
 	ComboProductsIds *[]uint       `json:"comboProductsIds"`
 }
@@ -15,7 +17,7 @@ type ProductResponse struct {
 	Name          string             `json:"name" validate:"required"`
 	Description   string             `json:"description" validate:"required"`
 	Category      string             `json:"category" validate:"required"`
-	Price         float64            `json:"price" validate:"required"`
+	Price         entity.Money       `json:"price" validate:"required"`
 	Images        []ProducImage      `json:"images" validate:"required"`
 	ComboProducts *[]ProductResponse `json:"comboProducts"`
 }
@@ -29,17 +31,17 @@ type ProducImage struct {
 }
 
 type ComboForm struct {
-	Id          uint    `json:"id"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required"`
-	Products    []uint  `json:"products" validate:"required"`
+	Id          uint         `json:"id"`
+	Name        string       `json:"name" validate:"required"`
+	Description string       `json:"description" validate:"required"`
+	Price       entity.Money `json:"price" validate:"required"`
+	Products    []uint       `json:"products" validate:"required"`
 }
 
 type Combo struct {
 	Id          uint          `json:"id"`
 	Name        string        `json:"name"`
 	Description string        `json:"description"`
-	Price       float64       `json:"price"`
+	Price       entity.Money  `json:"price"`
 	Products    []ProductForm `json:"products"`
 }
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// PromotionForm is created by the admin. The Type can be 'PERCENTAGE', 'FIXED_AMOUNT' or 'BUY_X_GET_Y'.
+// The Weekdays go from 0 (Sunday) to 6 (Saturday), and a zero UsageLimitPerCustomer means no limit
+type PromotionForm struct {
+	Id                    uint         `json:"id"`
+	Name                  string       `json:"name" validate:"required"`
+	Code                  *string      `json:"code"`
+	Type                  string       `json:"type" validate:"required"`
+	Percentage            int          `json:"percentage"`
+	Amount                entity.Money `json:"amount"`
+	BuyQuantity           int          `json:"buyQuantity"`
+	FreeQuantity          int          `json:"freeQuantity"`
+	Category              string       `json:"category"`
+	ProductID             *uint        `json:"productId"`
+	StartsAt              *time.Time   `json:"startsAt"`
+	EndsAt                *time.Time   `json:"endsAt"`
+	Weekdays              []int        `json:"weekdays"`
+	StartTime             string       `json:"startTime"`
+	EndTime               string       `json:"endTime"`
+	UsageLimitPerCustomer int          `json:"usageLimitPerCustomer"`
+	Active                bool         `json:"active"`
+}
+
+type PromotionResponse struct {
+	Id                    uint         `json:"id"`
+	Name                  string       `json:"name"`
+	Code                  *string      `json:"code"`
+	Type                  string       `json:"type"`
+	Percentage            int          `json:"percentage"`
+	Amount                entity.Money `json:"amount"`
+	BuyQuantity           int          `json:"buyQuantity"`
+	FreeQuantity          int          `json:"freeQuantity"`
+	Category              string       `json:"category"`
+	ProductID             *uint        `json:"productId"`
+	StartsAt              *time.Time   `json:"startsAt"`
+	EndsAt                *time.Time   `json:"endsAt"`
+	Weekdays              []int        `json:"weekdays"`
+	StartTime             string       `json:"startTime"`
+	EndTime               string       `json:"endTime"`
+	UsageLimitPerCustomer int          `json:"usageLimitPerCustomer"`
+	Active                bool         `json:"active"`
+}
+
+type PromotionCreationResponse struct {
+	Id uint `json:"id"`
+}
+
+// OrderDiscount is a promotion applied to the order
+type OrderDiscount struct {
+	PromotionID uint         `json:"promotionId"`
+	Description string       `json:"description"`
+	Code        *string      `json:"code"`
+	Amount      entity.Money `json:"amount"`
+}
+
+// OrderPriceForm asks the order price before paying it, so the kiosk can charge the discounted total.
+// The TotalPrice is the products total, without the discounts. The AuthenticatedCPF comes from the token
+type OrderPriceForm struct {
+	TotalPrice       entity.Money   `json:"totalPrice" validate:"required"`
+	CustomerID       *uint          `json:"customerId"`
+	AuthenticatedCPF string         `json:"-"`
+	CouponCode       *string        `json:"couponCode"`
+	LoyaltyPoints    int            `json:"loyaltyPoints"`
+	OrderProduct     []OrderProduct `json:"orderProducts" validate:"required"`
+}
+
+// OrderPriceResponse has the DiscountPrice of the promotions and the redeemed points together
+type OrderPriceResponse struct {
+	ProductsPrice   entity.Money    `json:"productsPrice"`
+	DiscountPrice   entity.Money    `json:"discountPrice"`
+	TotalPrice      entity.Money    `json:"totalPrice"`
+	Discounts       []OrderDiscount `json:"discounts"`
+	LoyaltyDiscount entity.Money    `json:"loyaltyDiscount"`
+}
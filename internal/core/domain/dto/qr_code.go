@@ -21,6 +21,9 @@ type ItemForm struct {
 	TotalAmount int    `json:"totalAmount"`
 }
 
+// QRCodeDataResponse has the QR Code payload. The PNG is sent only by the providers which render
+// the QR Code, like PIX, and it is encoded in base64 in the JSON
 type QRCodeDataResponse struct {
 	Data string `json:"data"`
+	PNG  []byte `json:"png,omitempty"`
 }
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// QRCodeReconciliation is the result of one run of the QR Code reconciliation
+type QRCodeReconciliation struct {
+	StartedAt  time.Time                   `json:"startedAt"`
+	FinishedAt time.Time                   `json:"finishedAt"`
+	Checked    int                         `json:"checked"`
+	Paid       int                         `json:"paid"`
+	Canceled   int                         `json:"canceled"`
+	Pending    int                         `json:"pending"`
+	Failed     int                         `json:"failed"`
+	Error      string                      `json:"error,omitempty"`
+	Orders     []QRCodeReconciliationOrder `json:"orders"`
+}
+
+type QRCodeReconciliationOrder struct {
+	OrderID   uint   `json:"orderId"`
+	PaymentID uint   `json:"paymentId"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
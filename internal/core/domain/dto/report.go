@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
+
+// ReportFilter limits the reports to the orders created in [From, To)
+type ReportFilter struct {
+	From time.Time
+	To   time.Time
+}
+
+type RevenueByDay struct {
+	Day     string       `json:"day"`
+	Orders  int          `json:"orders"`
+	Revenue entity.Money `json:"revenue"`
+}
+
+type RevenueByCategory struct {
+	Category string       `json:"category"`
+	Quantity int          `json:"quantity"`
+	Revenue  entity.Money `json:"revenue"`
+}
+
+type RevenueByProduct struct {
+	ProductID   uint         `json:"productId"`
+	ProductName string       `json:"productName"`
+	Category    string       `json:"category"`
+	Quantity    int          `json:"quantity"`
+	Revenue     entity.Money `json:"revenue"`
+}
+
+// OrderTimesReport has the durations in seconds. Wait is from the order creation until the kitchen
+// starts it, Preparation is until the order is done and Pickup is until the waiter finishes it
+type OrderTimesReport struct {
+	Orders                    int     `json:"orders"`
+	AverageWaitSeconds        float64 `json:"averageWaitSeconds"`
+	P95WaitSeconds            float64 `json:"p95WaitSeconds"`
+	AveragePreparationSeconds float64 `json:"averagePreparationSeconds"`
+	P95PreparationSeconds     float64 `json:"p95PreparationSeconds"`
+	AveragePickupSeconds      float64 `json:"averagePickupSeconds"`
+	P95PickupSeconds          float64 `json:"p95PickupSeconds"`
+}
+
+type NotDeliveredReport struct {
+	Delivered        int     `json:"delivered"`
+	NotDelivered     int     `json:"notDelivered"`
+	NotDeliveredRate float64 `json:"notDeliveredRate"`
+}
+
+type HourlyVolume struct {
+	Hour   int `json:"hour"`
+	Orders int `json:"orders"`
+}
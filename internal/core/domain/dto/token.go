@@ -1,5 +1,56 @@
 package dto
 
+// Token is the result of the login. The RefreshToken gets new access tokens in the '/auth/token/refresh'
+// until the logout, and the ExpiresIn is the access token duration in seconds. The GuestID is only
+// returned to the unknown customer
 type Token struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int64  `json:"expiresIn,omitempty"`
+	GuestID      string `json:"guestId,omitempty"`
+}
+
+// TokenClaims are the claims of a validated access token, kept in the request context.
+// The Username is the CPF of the customer, and the GuestID is set only for the unknown customer
+type TokenClaims struct {
+	Subject  string
+	Username string
+	Groups   []string
+	TokenID  string
+	GuestID  string
+}
+
+// LoginChallenge is returned by the login. The code was sent to the Destination and must be sent
+// with the Session to the '/auth/login/verify'
+type LoginChallenge struct {
+	Session     string `json:"session"`
+	Destination string `json:"destination"`
+}
+
+type LoginCodeForm struct {
+	CPF     string `json:"cpf" validate:"required"`
+	Session string `json:"session" validate:"required"`
+	Code    string `json:"code" validate:"required"`
+}
+
+type RefreshTokenForm struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// LoginCodeMessage is the one-time code sent to the user by the notifier. The Phone is empty
+// when the user has none, and then the code is sent by email
+type LoginCodeMessage struct {
+	Name  string
+	Email string
+	Phone string
+	Code  string
+}
+
+// ResourceOwner is the customer, by the CPF, or the guest who created an order or a payment.
+// Both are nil for the kiosk orders without login and for the orders of an erased customer, which are
+// told apart by Erased
+type ResourceOwner struct {
+	CustomerCPF *string
+	GuestID     *string
+	Erased      bool
 }
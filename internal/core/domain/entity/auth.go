@@ -0,0 +1,297 @@
+package entity
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+const (
+	AccessTokenAlgorithm = "RS256"
+	AccessTokenUse       = "access"
+
+	// AccessTokenClockSkew tolerates small differences between the clocks of Cognito and the API
+	AccessTokenClockSkew = 1 * time.Minute
+
+	// KeySetCacheDuration is how long the Cognito public keys are kept before being fetched again.
+	// An unknown key id fetches them before, but not more than once each KeySetMinRefreshInterval
+	KeySetCacheDuration      = 1 * time.Hour
+	KeySetMinRefreshInterval = 1 * time.Minute
+
+	// AccessTokenMaxDuration is the longest validity Cognito gives to an access token, so a revoked
+	// token is refused until all the access tokens of its login are expired
+	AccessTokenMaxDuration      = 24 * time.Hour
+	RevokedTokenCleanupInterval = 1 * time.Hour
+
+	// LoginCodeLength is the size of the one-time code sent to the user. Cognito keeps the login
+	// session for LoginCodeDuration, so the code can not be used after it
+	LoginCodeLength   = 6
+	LoginCodeDuration = 3 * time.Minute
+
+	// LoginCodeAttribute is the Cognito custom attribute with the hash of the code of the last login. The
+	// custom auth challenge Lambdas do not receive the client metadata of the login, only the user attributes
+	LoginCodeAttribute = "custom:login_code"
+	LoginCodeChallenge = "CUSTOM_CHALLENGE"
+
+	// UnknownCustomerUsername is the shared user of the customers who do not identify themselves.
+	// Each of its logins is a guest, identified by the login 'origin_jti'
+	UnknownCustomerUsername = "unknown-user"
+
+	// The local identity provider replaces Cognito in the local development, signing its own tokens
+	LocalIdentityIssuer               = "tech1-local-identity"
+	LocalIdentityClientID             = "tech1-local"
+	LocalIdentityAccessTokenDuration  = 1 * time.Hour
+	LocalIdentityRefreshTokenDuration = 30 * 24 * time.Hour
+)
+
+// AccessToken is a signed JWT split in its parts. The SigningInput is the header and the payload
+// as sent, since the signature is over the encoded parts
+type AccessToken struct {
+	Header       AccessTokenHeader
+	Claims       AccessTokenClaims
+	SigningInput string
+	Signature    []byte
+}
+
+type AccessTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// AccessTokenClaims are the claims of the Cognito access token. The Username is the CPF of the customer
+type AccessTokenClaims struct {
+	Subject   string   `json:"sub"`
+	Username  string   `json:"username"`
+	Groups    []string `json:"cognito:groups"`
+	TokenUse  string   `json:"token_use"`
+	ClientID  string   `json:"client_id"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	JTI       string   `json:"jti"`
+	OriginJTI string   `json:"origin_jti"`
+}
+
+// CognitoIssuer is the 'iss' claim of the tokens of the user pool. The public keys are
+// at the '/.well-known/jwks.json' path of it
+func CognitoIssuer(region string, userPoolID string) string {
+	return fmt.Sprintf("https://cognito-idp.%v.amazonaws.com/%v", region, userPoolID)
+}
+
+func CognitoKeySetURL(issuer string) string {
+	return issuer + "/.well-known/jwks.json"
+}
+
+// ParseAccessToken decodes the parts of the token without validating it
+func ParseAccessToken(token string) (AccessToken, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return AccessToken{}, invalidTokenError("The Bearer token is not valid")
+	}
+
+	var header AccessTokenHeader
+
+	err := decodeTokenPart(parts[0], &header)
+
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	if header.Algorithm != AccessTokenAlgorithm {
+		return AccessToken{}, invalidTokenError(fmt.Sprintf("The Bearer token algorithm %v is not accepted", header.Algorithm))
+	}
+
+	var claims AccessTokenClaims
+
+	err = decodeTokenPart(parts[1], &claims)
+
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return AccessToken{}, invalidTokenError("The Bearer token is not valid")
+	}
+
+	return AccessToken{
+		Header:       header,
+		Claims:       claims,
+		SigningInput: parts[0] + "." + parts[1],
+		Signature:    signature,
+	}, nil
+}
+
+// Sign creates the RS256 token of the claims, like the ones issued by Cognito
+func (claims AccessTokenClaims) Sign(key *rsa.PrivateKey, keyID string) (string, error) {
+	header, err := json.Marshal(AccessTokenHeader{Algorithm: AccessTokenAlgorithm, KeyID: keyID})
+
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (token AccessToken) VerifySignature(key *rsa.PublicKey) error {
+	hash := sha256.Sum256([]byte(token.SigningInput))
+
+	err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], token.Signature)
+
+	if err != nil {
+		return invalidTokenError("The Bearer token signature is not valid")
+	}
+
+	return nil
+}
+
+// Validate checks the claims of a token already verified. Only the access tokens of the app client
+// are accepted, so an id token or a token of another client is refused
+func (claims AccessTokenClaims) Validate(issuer string, clientID string, now time.Time) error {
+	if claims.Issuer != issuer {
+		return invalidTokenError("The Bearer token issuer is not valid")
+	}
+
+	if claims.TokenUse != AccessTokenUse {
+		return invalidTokenError("The Bearer token must be an access token")
+	}
+
+	if claims.ClientID != clientID {
+		return invalidTokenError("The Bearer token client is not valid")
+	}
+
+	if claims.Username == "" {
+		return invalidTokenError("The Bearer token is not valid")
+	}
+
+	if claims.ExpiresAt == 0 || now.Add(-AccessTokenClockSkew).After(time.Unix(claims.ExpiresAt, 0)) {
+		return invalidTokenError("The Bearer token is expired")
+	}
+
+	if claims.NotBefore != 0 && now.Add(AccessTokenClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return invalidTokenError("The Bearer token is not valid yet")
+	}
+
+	return nil
+}
+
+// TokenID identifies the login of the token. All the access tokens of the same login, including
+// the refreshed ones, have the same 'origin_jti', so revoking it logs out all of them
+func (claims AccessTokenClaims) TokenID() string {
+	if claims.OriginJTI != "" {
+		return claims.OriginJTI
+	}
+
+	return claims.JTI
+}
+
+// GuestID identifies the guest of an unknown customer token, which is different for each of its logins.
+// The tokens of the identified customers have no guest
+func (claims AccessTokenClaims) GuestID() string {
+	if claims.Username != UnknownCustomerUsername {
+		return ""
+	}
+
+	return claims.TokenID()
+}
+
+// NewLoginCode returns a random numeric code of LoginCodeLength digits
+func NewLoginCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(LoginCodeLength), nil)
+
+	value, err := rand.Int(rand.Reader, max)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", LoginCodeLength, value), nil
+}
+
+// HashLoginCode keeps the code out of the user attributes, which are in the id tokens of the user
+func HashLoginCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// VerifyLoginCode compares the answer of the challenge with the hash of the code sent to the user
+func VerifyLoginCode(answer string, hash string) bool {
+	if answer == "" || hash == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(HashLoginCode(answer)), []byte(hash)) == 1
+}
+
+// MaskEmail hides the email sent to the client, like 'j***@email.com'
+func MaskEmail(email string) string {
+	name, domain, found := strings.Cut(email, "@")
+
+	if !found || name == "" {
+		return "***"
+	}
+
+	return name[:1] + "***@" + domain
+}
+
+// MaskPhone hides the phone sent to the client, keeping only its last 4 digits, like '*********4321'
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return "***"
+	}
+
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
+func decodeTokenPart(part string, value any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(part)
+
+	if err != nil {
+		return invalidTokenError("The Bearer token is not valid")
+	}
+
+	err = json.Unmarshal(decoded, value)
+
+	if err != nil {
+		return invalidTokenError("The Bearer token is not valid")
+	}
+
+	return nil
+}
+
+func invalidTokenError(message string) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnauthorized,
+		Message:    message,
+	}
+}
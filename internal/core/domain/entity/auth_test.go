@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessToken(t *testing.T) {
+	issuer := CognitoIssuer("us-east-1", "us-east-1_test")
+	now := time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC)
+
+	claims := AccessTokenClaims{
+		Username:  "17107972073",
+		TokenUse:  AccessTokenUse,
+		ClientID:  "client",
+		Issuer:    issuer,
+		ExpiresAt: now.Unix(),
+	}
+
+	t.Run("got issuer and key set URL of the user pool", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test", issuer)
+		assert.Equal(t, "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test/.well-known/jwks.json", CognitoKeySetURL(issuer))
+	})
+
+	t.Run("got expired token only after the clock skew", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, claims.Validate(issuer, "client", now.Add(AccessTokenClockSkew)))
+		assert.Error(t, claims.Validate(issuer, "client", now.Add(AccessTokenClockSkew+time.Second)))
+	})
+
+	t.Run("got error when parsing token with other algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		// {"alg":"none","kid":"key"}.{"username":"17107972073"}.
+		_, err := ParseAccessToken("eyJhbGciOiJub25lIiwia2lkIjoia2V5In0.eyJ1c2VybmFtZSI6IjE3MTA3OTcyMDczIn0.")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got guest only for the unknown customer token", func(t *testing.T) {
+		t.Parallel()
+
+		guest := AccessTokenClaims{Username: UnknownCustomerUsername, JTI: "jti", OriginJTI: "origin"}
+		customer := AccessTokenClaims{Username: "17107972073", JTI: "jti", OriginJTI: "origin"}
+
+		assert.Equal(t, "origin", guest.GuestID())
+		assert.Empty(t, customer.GuestID())
+	})
+}
+
+func TestLoginCode(t *testing.T) {
+	t.Run("got numeric code with the login code length", func(t *testing.T) {
+		t.Parallel()
+
+		code, err := NewLoginCode()
+
+		assert.NoError(t, err)
+		assert.Regexp(t, "^[0-9]{6}$", code)
+	})
+
+	t.Run("got login code verified only with the hashed code", func(t *testing.T) {
+		hash := HashLoginCode("042137")
+
+		assert.NotEqual(t, "042137", hash)
+		assert.True(t, VerifyLoginCode("042137", hash))
+		assert.False(t, VerifyLoginCode("042138", hash))
+		assert.False(t, VerifyLoginCode("", HashLoginCode("")))
+		assert.False(t, VerifyLoginCode("042137", ""))
+	})
+
+	t.Run("got masked email", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "t***@teste.com", MaskEmail("teste@teste.com"))
+		assert.Equal(t, "***", MaskEmail("invalid"))
+	})
+
+	t.Run("got masked phone", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "**********4321", MaskPhone("+5511987654321"))
+		assert.Equal(t, "***", MaskPhone("4321"))
+	})
+}
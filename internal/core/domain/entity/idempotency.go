@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+const (
+	IdempotencyKeyHeader          = "Idempotency-Key"
+	IdempotencyKeyMaxLength       = 255
+	IdempotencyKeyTTL             = 24 * time.Hour
+	IdempotencyKeyLockTimeout     = 1 * time.Minute
+	IdempotencyKeyCleanupInterval = 1 * time.Hour
+)
@@ -0,0 +1,27 @@
+package entity
+
+const (
+	// LoyaltyPointsPerReal is credited for each whole real paid by a delivered order
+	LoyaltyPointsPerReal = 1
+	// LoyaltyPointValue is the discount of each redeemed point, so 100 points are R$ 5,00
+	LoyaltyPointValue = 5
+
+	LoyaltyTransactionEarn     = "EARN"
+	LoyaltyTransactionRedeem   = "REDEEM"
+	LoyaltyTransactionReversal = "REVERSAL"
+)
+
+// LoyaltyPointsEarned returns the points of an order paid with the total. The cents are not
+// credited, so a R$ 12,90 order earns 12 points
+func LoyaltyPointsEarned(totalPrice Money) int {
+	if totalPrice.Cents <= 0 {
+		return 0
+	}
+
+	return int(totalPrice.Cents/100) * LoyaltyPointsPerReal
+}
+
+// LoyaltyPointsDiscount is the discount given by the redeemed points
+func LoyaltyPointsDiscount(points int) Money {
+	return NewMoney(LoyaltyPointValue).Times(points)
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoyaltyPoints(t *testing.T) {
+	t.Run("got only whole reais when earning points", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 12, LoyaltyPointsEarned(NewMoney(1290)))
+		assert.Equal(t, 0, LoyaltyPointsEarned(NewMoney(99)))
+		assert.Equal(t, 0, LoyaltyPointsEarned(NewMoney(0)))
+	})
+
+	t.Run("got discount of the redeemed points", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, NewMoney(500), LoyaltyPointsDiscount(100))
+		assert.Equal(t, NewMoney(0), LoyaltyPointsDiscount(0))
+	})
+}
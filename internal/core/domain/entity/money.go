@@ -0,0 +1,309 @@
+package entity
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// The kiosk sells in reais, so it is the currency of the amounts sent without one
+const CurrencyBRL = "BRL"
+
+var moneyMaxCents = int64(math.MaxInt64 / 100)
+
+// Money is an amount in integer cents of the Currency. The prices are summed and multiplied as cents, so a R$ 12,90
+// product is never charged as R$ 12 or R$ 12,899999. The JSON has the decimal amount and the currency, like
+// {"amount": 12.90, "currency": "BRL"}, while the database stores the cents in a bigint column and the
+// currency in the 'currency' column of the table
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+func NewMoney(cents int64) Money {
+	return NewMoneyIn(cents, CurrencyBRL)
+}
+
+func NewMoneyIn(cents int64, currency string) Money {
+	return Money{
+		Cents:    cents,
+		Currency: currency,
+	}
+}
+
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MoneyFromFloat rounds the amount to the nearest cent. It is used only for the amounts which
+// are not read from a decimal text, like the Postgres sums
+func MoneyFromFloat(amount float64) Money {
+	return NewMoney(int64(math.Round(amount * 100)))
+}
+
+// ParseMoney reads a decimal amount, like '12.9' or '12.90', without float rounding.
+// More than two decimal places are rejected because they can not be charged
+func ParseMoney(amount string) (Money, error) {
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	units, decimals, hasDecimals := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+
+	if units == "" || len(decimals) > 2 || (hasDecimals && decimals == "") ||
+		!isDigits(units) || !isDigits(decimals) {
+		return Money{}, invalidMoney(amount)
+	}
+
+	cents, err := strconv.ParseInt(units, 10, 64)
+
+	if err != nil || cents > moneyMaxCents {
+		return Money{}, invalidMoney(amount)
+	}
+
+	cents *= 100
+
+	if decimals != "" {
+		fraction, _ := strconv.ParseInt(decimals+strings.Repeat("0", 2-len(decimals)), 10, 64)
+		cents += fraction
+	}
+
+	if negative {
+		cents = -cents
+	}
+
+	return NewMoney(cents), nil
+}
+
+// Add panics when the currencies are different, since an amount in another currency is never
+// converted. The zero Money has no currency yet, so it takes the currency of the other amount
+func (money Money) Add(other Money) Money {
+	return NewMoneyIn(money.Cents+other.Cents, money.sameCurrency(other))
+}
+
+// Sub panics when the currencies are different, like the Add
+func (money Money) Sub(other Money) Money {
+	return NewMoneyIn(money.Cents-other.Cents, money.sameCurrency(other))
+}
+
+func (money Money) Times(quantity int) Money {
+	return NewMoneyIn(money.Cents*int64(quantity), money.Currency)
+}
+
+// In returns the amount in the currency, kept when the currency is empty
+func (money Money) In(currency string) Money {
+	if currency == "" {
+		return money
+	}
+
+	return NewMoneyIn(money.Cents, currency)
+}
+
+func (money Money) sameCurrency(other Money) string {
+	switch {
+	case money.Currency == "":
+		return other.Currency
+	case other.Currency == "" || other.Currency == money.Currency:
+		return money.Currency
+	default:
+		panic(fmt.Sprintf("can not mix the currencies %v and %v", money.Currency, other.Currency))
+	}
+}
+
+// CommonCurrency returns the currency of the amounts, ignoring the ones without currency. It is
+// BRL when no amount has a currency, and an error when they have different currencies
+func CommonCurrency(amounts ...Money) (string, error) {
+	currency := ""
+
+	for _, amount := range amounts {
+		if amount.Currency == "" || amount.Currency == currency {
+			continue
+		}
+
+		if currency != "" {
+			return "", fmt.Errorf("can not mix the currencies %v and %v", currency, amount.Currency)
+		}
+
+		currency = amount.Currency
+	}
+
+	if currency == "" {
+		return CurrencyBRL, nil
+	}
+
+	return currency, nil
+}
+
+// Equal compares the cents and the currencies. The zero Money has no currency, so it is equal to any zero amount
+func (money Money) Equal(other Money) bool {
+	return money.Cents == other.Cents &&
+		(money.Currency == other.Currency || money.Currency == "" || other.Currency == "")
+}
+
+func (money Money) IsZero() bool {
+	return money.Cents == 0
+}
+
+func (money Money) IsNegative() bool {
+	return money.Cents < 0
+}
+
+// Float64 is the amount in reais. It must only be used by the reports, never to sum amounts
+func (money Money) Float64() float64 {
+	return float64(money.Cents) / 100
+}
+
+// String formats the amount with two decimal places, like '12.90'
+func (money Money) String() string {
+	cents := money.Cents
+	sign := ""
+
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%v%d.%02d", sign, cents/100, cents%100)
+}
+
+// Number is the decimal amount as a JSON number, like 12.90, used by the payment gateways
+func (money Money) Number() json.Number {
+	return json.Number(money.String())
+}
+
+func (money Money) MarshalJSON() ([]byte, error) {
+	currency := money.Currency
+
+	if currency == "" {
+		currency = CurrencyBRL
+	}
+
+	return json.Marshal(moneyJSON{
+		Amount:   money.Number(),
+		Currency: currency,
+	})
+}
+
+// UnmarshalJSON reads the amount with its currency, like {"amount": 12.90, "currency": "BRL"}. The kiosks
+// also send only the decimal number, like 12.90, which is an amount in reais
+func (money *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	value := moneyJSON{
+		Amount:   json.Number(data),
+		Currency: CurrencyBRL,
+	}
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		err := decoder.Decode(&value)
+
+		if err != nil {
+			return invalidMoney(string(data))
+		}
+
+		if !isCurrency(value.Currency) {
+			return &responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("The currency %q is not valid. Use the ISO 4217 code, like BRL", value.Currency),
+			}
+		}
+	}
+
+	// JSON numbers like 1e2 are not amounts typed by a customer
+	parsed, err := ParseMoney(value.Amount.String())
+
+	if err != nil {
+		return err
+	}
+
+	*money = parsed.In(value.Currency)
+
+	return nil
+}
+
+// Value stores the cents in the bigint column. The currency is stored by the model, in the 'currency' column
+func (money Money) Value() (driver.Value, error) {
+	return money.Cents, nil
+}
+
+// Scan reads the bigint columns and the Postgres sums, which are numeric. The amount is in reais
+// until the model sets the currency of its row
+func (money *Money) Scan(value any) error {
+	switch value := value.(type) {
+	case nil:
+		*money = NewMoney(0)
+	case int64:
+		*money = NewMoney(value)
+	case float64:
+		*money = NewMoney(int64(math.Round(value)))
+	case []byte:
+		return money.scanText(string(value))
+	case string:
+		return money.scanText(value)
+	default:
+		return fmt.Errorf("can not scan %T into Money", value)
+	}
+
+	return nil
+}
+
+func (money *Money) scanText(value string) error {
+	cents, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return fmt.Errorf("can not scan %q into Money", value)
+	}
+
+	*money = NewMoney(int64(math.Round(cents)))
+
+	return nil
+}
+
+// GormDataType makes the migration create the columns as bigint
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+func invalidMoney(amount string) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusBadRequest,
+		Message:    fmt.Sprintf("The amount %q is not valid. Use up to two decimal places, like 12.90", amount),
+	}
+}
+
+func isCurrency(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+
+	for _, char := range value {
+		if char < 'A' || char > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
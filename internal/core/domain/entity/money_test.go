@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("got cents when parsing decimal amounts in money", func(t *testing.T) {
+		t.Parallel()
+
+		for amount, cents := range map[string]int64{
+			"12.90": 1290,
+			"12.9":  1290,
+			"12":    1200,
+			"0.05":  5,
+			"-3.5":  -350,
+		} {
+			money, err := ParseMoney(amount)
+
+			assert.NoError(t, err)
+			assert.Equal(t, NewMoney(cents), money)
+		}
+	})
+
+	t.Run("got error when parsing invalid amounts in money", func(t *testing.T) {
+		t.Parallel()
+
+		for _, amount := range []string{"", "12.", ".90", "12.901", "1e2", "abc", "12,90"} {
+			_, err := ParseMoney(amount)
+
+			assert.Error(t, err, amount)
+		}
+	})
+
+	t.Run("got exact totals when summing cents in money", func(t *testing.T) {
+		t.Parallel()
+
+		total := NewMoney(0)
+
+		for range 10 {
+			total = total.Add(NewMoney(10))
+		}
+
+		assert.Equal(t, NewMoney(100), total)
+		assert.Equal(t, NewMoney(3870), NewMoney(1290).Times(3))
+		assert.Equal(t, NewMoney(-10), NewMoney(1290).Sub(NewMoney(1300)))
+		assert.Equal(t, NewMoney(1290), MoneyFromFloat(12.9))
+	})
+
+	t.Run("got error when mixing currencies in money", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, NewMoneyIn(1290, "USD"), Money{}.Add(NewMoneyIn(1290, "USD")))
+		assert.Panics(t, func() { NewMoney(1290).Add(NewMoneyIn(100, "USD")) })
+		assert.Panics(t, func() { NewMoney(1290).Sub(NewMoneyIn(100, "USD")) })
+
+		currency, err := CommonCurrency(NewMoneyIn(100, "USD"), Money{}, NewMoneyIn(200, "USD"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", currency)
+
+		_, err = CommonCurrency(NewMoney(100), NewMoneyIn(200, "USD"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got decimal amount and currency in money json", func(t *testing.T) {
+		t.Parallel()
+
+		body, err := json.Marshal(map[string]Money{"price": NewMoney(1290)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"price":{"amount":12.90,"currency":"BRL"}}`, string(body))
+
+		var product struct {
+			Price Money `json:"price"`
+		}
+
+		err = json.Unmarshal([]byte(`{"price": 12.9}`), &product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewMoney(1290), product.Price)
+
+		err = json.Unmarshal([]byte(`{"price": {"amount": 3.5, "currency": "USD"}}`), &product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewMoneyIn(350, "USD"), product.Price)
+
+		for _, body := range []string{
+			`{"price": {"amount": 3.5, "currency": "usd"}}`,
+			`{"price": {"amount": 3.555, "currency": "BRL"}}`,
+			`{"price": {"amount": "abc"}}`,
+		} {
+			err = json.Unmarshal([]byte(body), &product)
+
+			assert.Error(t, err, body)
+		}
+	})
+
+	t.Run("got cents when scanning database values in money", func(t *testing.T) {
+		t.Parallel()
+
+		var money Money
+
+		assert.NoError(t, money.Scan(int64(1290)))
+		assert.Equal(t, NewMoney(1290), money)
+
+		assert.NoError(t, money.Scan([]byte("2580")))
+		assert.Equal(t, NewMoney(2580), money)
+
+		value, err := NewMoney(1290).Value()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1290), value)
+	})
+}
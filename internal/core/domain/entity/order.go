@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+const (
+	OrderStatusPaying       = "Em pagamento"
+	OrderStatusCreated      = "Criado"
+	OrderStatusPreparing    = "Preparando"
+	OrderStatusDone         = "Finalizado"
+	OrderStatusDelivered    = "Entregue"
+	OrderStatusNotDelivered = "Não entregue"
+	OrderStatusCanceled     = "Cancelado"
+
+	OrderActorCustomer       = "customer"
+	OrderActorKitchen        = "kitchen"
+	OrderActorWaiter         = "waiter"
+	OrderActorPaymentGateway = "payment-gateway"
+	OrderActorSystem         = "system"
+
+	OrderModifierRemove = "remove"
+	OrderModifierExtra  = "extra"
+	OrderModifierNote   = "note"
+
+	OrderSortCreatedAt    = "createdAt"
+	OrderSortTicketNumber = "ticketNumber"
+	OrderSortTotalPrice   = "totalPrice"
+
+	OrderPageDefaultLimit = 20
+	OrderPageMaxLimit     = 100
+
+	// OrderProductMaxQuantity keeps the price of an order product far from overflowing the cents
+	OrderProductMaxQuantity = 99
+
+	// OrderEventRetention is how long the order events are kept to be replayed to the streams which reconnect
+	OrderEventRetention       = 7 * 24 * time.Hour
+	OrderEventCleanupInterval = 1 * time.Hour
+)
+
+// orderTransitions is the single source of truth of the order lifecycle.
+// Each key is the current status and its values are the statuses the order can move to.
+var orderTransitions = map[string][]string{
+	OrderStatusPaying:    {OrderStatusCreated, OrderStatusCanceled},
+	OrderStatusCreated:   {OrderStatusPreparing, OrderStatusCanceled},
+	OrderStatusPreparing: {OrderStatusDone, OrderStatusCanceled},
+	OrderStatusDone:      {OrderStatusDelivered, OrderStatusNotDelivered},
+}
+
+// orderStatusOrder is used to keep the error messages deterministic
+var orderStatusOrder = []string{
+	OrderStatusPaying,
+	OrderStatusCreated,
+	OrderStatusPreparing,
+	OrderStatusDone,
+	OrderStatusDelivered,
+	OrderStatusNotDelivered,
+	OrderStatusCanceled,
+}
+
+var OrderSortOptions = []string{
+	OrderSortCreatedAt,
+	OrderSortTicketNumber,
+	OrderSortTotalPrice,
+}
+
+type OrderStateMachine struct {
+	transitions map[string][]string
+}
+
+func NewOrderStateMachine() *OrderStateMachine {
+	return &OrderStateMachine{
+		transitions: orderTransitions,
+	}
+}
+
+func (sm *OrderStateMachine) CanTransition(from string, to string) bool {
+	return slices.Contains(sm.transitions[from], to)
+}
+
+// Validate returns a 428 BusinessResponse when the order can not go from 'from' to 'to',
+// informing which statuses the order must be in to reach 'to'
+func (sm *OrderStateMachine) Validate(from string, to string) error {
+	if sm.CanTransition(from, to) {
+		return nil
+	}
+
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusPreconditionRequired,
+		Message:    fmt.Sprintf("The order must be in %v status", strings.Join(sm.sourcesOf(to), " or ")),
+	}
+}
+
+// IsFinal informs if the order lifecycle has ended and no more transitions are allowed
+func (sm *OrderStateMachine) IsFinal(status string) bool {
+	return len(sm.transitions[status]) == 0
+}
+
+// IsKnownStatus informs if the status is part of the order lifecycle
+func (sm *OrderStateMachine) IsKnownStatus(status string) bool {
+	return slices.Contains(orderStatusOrder, status)
+}
+
+func (sm *OrderStateMachine) sourcesOf(to string) []string {
+	sources := []string{}
+
+	for _, status := range orderStatusOrder {
+		if sm.CanTransition(status, to) {
+			sources = append(sources, status)
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestOrderStateMachine(t *testing.T) {
+	t.Run("got success when checking all legal transitions in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		assert.True(t, sut.CanTransition(OrderStatusPaying, OrderStatusCreated))
+		assert.True(t, sut.CanTransition(OrderStatusCreated, OrderStatusPreparing))
+		assert.True(t, sut.CanTransition(OrderStatusPreparing, OrderStatusDone))
+		assert.True(t, sut.CanTransition(OrderStatusDone, OrderStatusDelivered))
+		assert.True(t, sut.CanTransition(OrderStatusDone, OrderStatusNotDelivered))
+		assert.True(t, sut.CanTransition(OrderStatusPaying, OrderStatusCanceled))
+		assert.True(t, sut.CanTransition(OrderStatusCreated, OrderStatusCanceled))
+		assert.True(t, sut.CanTransition(OrderStatusPreparing, OrderStatusCanceled))
+	})
+
+	t.Run("got false when checking illegal transitions in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		assert.False(t, sut.CanTransition(OrderStatusPaying, OrderStatusPreparing))
+		assert.False(t, sut.CanTransition(OrderStatusCreated, OrderStatusDone))
+		assert.False(t, sut.CanTransition(OrderStatusDone, OrderStatusCanceled))
+		assert.False(t, sut.CanTransition(OrderStatusDelivered, OrderStatusNotDelivered))
+		assert.False(t, sut.CanTransition(OrderStatusCanceled, OrderStatusCreated))
+		assert.False(t, sut.CanTransition("", OrderStatusCreated))
+	})
+
+	t.Run("got success when checking final statuses in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		assert.True(t, sut.IsFinal(OrderStatusDelivered))
+		assert.True(t, sut.IsFinal(OrderStatusNotDelivered))
+		assert.True(t, sut.IsFinal(OrderStatusCanceled))
+		assert.False(t, sut.IsFinal(OrderStatusPaying))
+		assert.False(t, sut.IsFinal(OrderStatusDone))
+	})
+
+	t.Run("got nil when validating legal transition in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		err := sut.Validate(OrderStatusCreated, OrderStatusPreparing)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got precondition error when validating illegal transition in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		err := sut.Validate(OrderStatusCreated, OrderStatusDone)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+		assert.Equal(t, "The order must be in Preparando status", businessError.Message)
+	})
+
+	t.Run("got all source statuses in message when validating illegal cancellation in state machine", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewOrderStateMachine()
+
+		err := sut.Validate(OrderStatusDone, OrderStatusCanceled)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, "The order must be in Em pagamento or Criado or Preparando status", businessError.Message)
+	})
+}
@@ -0,0 +1,73 @@
+package entity
+
+import "time"
+
+const (
+	OutboxAggregateOrder   = "order"
+	OutboxAggregatePayment = "payment"
+
+	OrderCreatedEvent      = "OrderCreated"
+	OrderPaidEvent         = "OrderPaid"
+	OrderPreparingEvent    = "OrderPreparing"
+	OrderReadyEvent        = "OrderReady"
+	OrderDeliveredEvent    = "OrderDelivered"
+	OrderNotDeliveredEvent = "OrderNotDelivered"
+	OrderCanceledEvent     = "OrderCanceled"
+
+	PaymentConfirmedEvent = "PaymentConfirmed"
+	PaymentFailedEvent    = "PaymentFailed"
+	PaymentRefundedEvent  = "PaymentRefunded"
+	PaymentVoidedEvent    = "PaymentVoided"
+
+	OutboxRelayInterval  = 1 * time.Second
+	OutboxBatchSize      = 50
+	OutboxLease          = 1 * time.Minute
+	OutboxRetryBaseDelay = 2 * time.Second
+	OutboxRetryMaxDelay  = 10 * time.Minute
+)
+
+var orderStatusEvents = map[string]string{
+	OrderStatusCreated:      OrderCreatedEvent,
+	OrderStatusPreparing:    OrderPreparingEvent,
+	OrderStatusDone:         OrderReadyEvent,
+	OrderStatusDelivered:    OrderDeliveredEvent,
+	OrderStatusNotDelivered: OrderNotDeliveredEvent,
+	OrderStatusCanceled:     OrderCanceledEvent,
+}
+
+var paymentStatusEvents = map[string]string{
+	PaymentPayedStatus:    PaymentConfirmedEvent,
+	PaymentErrorStatus:    PaymentFailedEvent,
+	PaymentRefundedStatus: PaymentRefundedEvent,
+	PaymentVoidedStatus:   PaymentVoidedEvent,
+}
+
+// OrderDomainEventType returns the event of an order status change. An order leaving the
+// 'Em pagamento' status was paid, so it is not a new order for the other services. The status
+// without events, like 'Em pagamento', returns an empty string
+func OrderDomainEventType(fromStatus string, toStatus string) string {
+	if fromStatus == OrderStatusPaying && toStatus == OrderStatusCreated {
+		return OrderPaidEvent
+	}
+
+	return orderStatusEvents[toStatus]
+}
+
+func PaymentDomainEventType(status string) string {
+	return paymentStatusEvents[status]
+}
+
+// OutboxRetryDelay doubles the delay for every failed attempt, up to OutboxRetryMaxDelay
+func OutboxRetryDelay(attempts int) time.Duration {
+	delay := OutboxRetryBaseDelay
+
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+
+		if delay >= OutboxRetryMaxDelay {
+			return OutboxRetryMaxDelay
+		}
+	}
+
+	return delay
+}
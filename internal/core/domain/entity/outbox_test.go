@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Run("got event types when changing order status in outbox", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, OrderCreatedEvent, OrderDomainEventType("", OrderStatusCreated))
+		assert.Equal(t, OrderPaidEvent, OrderDomainEventType(OrderStatusPaying, OrderStatusCreated))
+		assert.Equal(t, OrderReadyEvent, OrderDomainEventType(OrderStatusPreparing, OrderStatusDone))
+		assert.Equal(t, OrderCanceledEvent, OrderDomainEventType(OrderStatusCreated, OrderStatusCanceled))
+		assert.Equal(t, "", OrderDomainEventType("", OrderStatusPaying))
+		assert.Equal(t, PaymentConfirmedEvent, PaymentDomainEventType(PaymentPayedStatus))
+		assert.Equal(t, "", PaymentDomainEventType(PaymentPayingStatus))
+	})
+
+	t.Run("got exponential delay when retrying in outbox", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 2*time.Second, OutboxRetryDelay(0))
+		assert.Equal(t, 2*time.Second, OutboxRetryDelay(1))
+		assert.Equal(t, 4*time.Second, OutboxRetryDelay(2))
+		assert.Equal(t, 16*time.Second, OutboxRetryDelay(4))
+		assert.Equal(t, OutboxRetryMaxDelay, OutboxRetryDelay(30))
+	})
+}
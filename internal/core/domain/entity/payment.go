@@ -0,0 +1,77 @@
+package entity
+
+const (
+	PaymentPayingStatus   = "Pagando"
+	PaymentPayedStatus    = "Pago"
+	PaymentErrorStatus    = "Erro"
+	PaymentRefundedStatus = "Estornado"
+	PaymentVoidedStatus   = "Cancelado"
+
+	PaymentCreditType = "Crédito"
+	PaymentQRCodeType = "QR Code (Mercado Pago)"
+	PaymentPIXType    = "PIX"
+)
+
+// IsQRCodePaymentType returns true for the payment types paid by the customer scanning a QR Code
+func IsQRCodePaymentType(paymentType string) bool {
+	return paymentType == PaymentQRCodeType || paymentType == PaymentPIXType
+}
+
+// The Mercado Livre merchant order and payment statuses used by the QR Code webhook
+const (
+	QRCodeOrderStatusPaid       = "paid"
+	QRCodeOrderStatusExpired    = "expired"
+	QRCodePaymentStatusRejected = "rejected"
+	QRCodePaymentStatusCanceled = "cancelled"
+)
+
+// QRCodePaymentOutcome is the terminal state of the payment and the order after the QR Code
+// payment is finished in Mercado Livre
+type QRCodePaymentOutcome struct {
+	Result        string
+	PaymentStatus string
+	OrderStatus   string
+	Reason        string
+}
+
+// ResolveQRCodePayment maps the merchant order to the terminal states. It returns false while
+// the customer did not finish the payment yet, so nothing must change
+func ResolveQRCodePayment(merchantOrderStatus string, orderStatus string, lastPaymentStatus string) (QRCodePaymentOutcome, bool) {
+	switch {
+	case orderStatus == QRCodeOrderStatusPaid:
+		return QRCodePaymentOutcome{
+			Result:        QRCodeOrderStatusPaid,
+			PaymentStatus: PaymentPayedStatus,
+			OrderStatus:   OrderStatusCreated,
+			Reason:        "Payment confirmed",
+		}, true
+	case orderStatus == QRCodeOrderStatusExpired || merchantOrderStatus == QRCodeOrderStatusExpired:
+		return ExpiredQRCodePayment(), true
+	case lastPaymentStatus == QRCodePaymentStatusCanceled:
+		return QRCodePaymentOutcome{
+			Result:        QRCodePaymentStatusCanceled,
+			PaymentStatus: PaymentVoidedStatus,
+			OrderStatus:   OrderStatusCanceled,
+			Reason:        "QR Code payment canceled",
+		}, true
+	case lastPaymentStatus == QRCodePaymentStatusRejected:
+		return QRCodePaymentOutcome{
+			Result:        QRCodePaymentStatusRejected,
+			PaymentStatus: PaymentErrorStatus,
+			OrderStatus:   OrderStatusCanceled,
+			Reason:        "QR Code payment rejected",
+		}, true
+	}
+
+	return QRCodePaymentOutcome{}, false
+}
+
+// ExpiredQRCodePayment cancels the order and the payment when the QR Code was not paid in time
+func ExpiredQRCodePayment() QRCodePaymentOutcome {
+	return QRCodePaymentOutcome{
+		Result:        QRCodeOrderStatusExpired,
+		PaymentStatus: PaymentVoidedStatus,
+		OrderStatus:   OrderStatusCanceled,
+		Reason:        "QR Code payment expired",
+	}
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// The names used in the /api/webhook/payments/{provider} URL
+const (
+	PaymentProviderSandbox     = "sandbox"
+	PaymentProviderCreditCard  = "credit-card"
+	PaymentProviderMercadoPago = "mercadopago"
+	PaymentProviderPIX         = "pix"
+)
+
+// ParseQRCodeExternalReference reads the '<order id>|<payment id>' reference sent when the QR Code was generated
+func ParseQRCodeExternalReference(reference string) (uint, uint, bool) {
+	ids := strings.Split(reference, "|")
+
+	if len(ids) != 2 {
+		return 0, 0, false
+	}
+
+	orderID, err := strconv.ParseUint(ids[0], 10, 64)
+
+	if err != nil || orderID == 0 {
+		return 0, 0, false
+	}
+
+	paymentID, err := strconv.ParseUint(ids[1], 10, 64)
+
+	if err != nil || paymentID == 0 {
+		return 0, 0, false
+	}
+
+	return uint(orderID), uint(paymentID), true
+}
+
+// QRCodeNotificationDataID reads the signed id from the query, as Mercado Livre sends it. The
+// notifications without it are signed with the id in the end of the resource URL
+func QRCodeNotificationDataID(query url.Values, resource string) string {
+	if dataID := query.Get("data.id"); dataID != "" {
+		return dataID
+	}
+
+	if dataID := query.Get("id"); dataID != "" {
+		return dataID
+	}
+
+	if resource == "" {
+		return ""
+	}
+
+	return path.Base(resource)
+}
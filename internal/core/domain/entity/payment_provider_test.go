@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentProvider(t *testing.T) {
+	t.Run("got order and payment from qr code external reference in payment provider", func(t *testing.T) {
+		t.Parallel()
+
+		orderID, paymentID, ok := ParseQRCodeExternalReference("10|20")
+
+		assert.True(t, ok)
+		assert.Equal(t, uint(10), orderID)
+		assert.Equal(t, uint(20), paymentID)
+	})
+
+	t.Run("got invalid qr code external reference in payment provider", func(t *testing.T) {
+		t.Parallel()
+
+		for _, reference := range []string{"", "10", "10|", "10|20|30", "a|20", "0|20", "10|-1"} {
+			_, _, ok := ParseQRCodeExternalReference(reference)
+			assert.False(t, ok, reference)
+		}
+	})
+
+	t.Run("got notification data id from query or resource in payment provider", func(t *testing.T) {
+		t.Parallel()
+
+		resource := "https://api.mercadolibre.com/merchant_orders/20203112410"
+
+		assert.Equal(t, "123", QRCodeNotificationDataID(url.Values{"data.id": {"123"}, "id": {"456"}}, resource))
+		assert.Equal(t, "456", QRCodeNotificationDataID(url.Values{"id": {"456"}}, resource))
+		assert.Equal(t, "20203112410", QRCodeNotificationDataID(url.Values{}, resource))
+		assert.Empty(t, QRCodeNotificationDataID(url.Values{}, ""))
+	})
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQRCodePayment(t *testing.T) {
+	t.Run("got paid when merchant order is paid in qr code payment", func(t *testing.T) {
+		t.Parallel()
+
+		// A rejected payment can be followed by an approved one
+		outcome, ok := ResolveQRCodePayment("closed", QRCodeOrderStatusPaid, "approved")
+
+		assert.True(t, ok)
+		assert.Equal(t, PaymentPayedStatus, outcome.PaymentStatus)
+		assert.Equal(t, OrderStatusCreated, outcome.OrderStatus)
+	})
+
+	t.Run("got terminal states when payment is not paid in qr code payment", func(t *testing.T) {
+		t.Parallel()
+
+		outcome, ok := ResolveQRCodePayment(QRCodeOrderStatusExpired, "payment_required", "")
+		assert.True(t, ok)
+		assert.Equal(t, PaymentVoidedStatus, outcome.PaymentStatus)
+		assert.Equal(t, OrderStatusCanceled, outcome.OrderStatus)
+
+		outcome, ok = ResolveQRCodePayment("opened", "payment_required", QRCodePaymentStatusCanceled)
+		assert.True(t, ok)
+		assert.Equal(t, PaymentVoidedStatus, outcome.PaymentStatus)
+		assert.Equal(t, OrderStatusCanceled, outcome.OrderStatus)
+
+		outcome, ok = ResolveQRCodePayment("opened", "payment_required", QRCodePaymentStatusRejected)
+		assert.True(t, ok)
+		assert.Equal(t, PaymentErrorStatus, outcome.PaymentStatus)
+		assert.Equal(t, OrderStatusCanceled, outcome.OrderStatus)
+	})
+
+	t.Run("got nothing when payment is pending in qr code payment", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := ResolveQRCodePayment("opened", "payment_in_process", "in_process")
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,212 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The BR Code fields defined by the Banco Central do Brasil, using the EMV QR Code format
+const (
+	pixPayloadFormatIndicator     = "00"
+	pixPointOfInitiationMethod    = "01"
+	pixMerchantAccountInformation = "26"
+	pixMerchantCategoryCode       = "52"
+	pixTransactionCurrency        = "53"
+	pixTransactionAmount          = "54"
+	pixCountryCode                = "58"
+	pixMerchantName               = "59"
+	pixMerchantCity               = "60"
+	pixAdditionalDataField        = "62"
+	pixCRC16                      = "63"
+
+	pixGUI          = "00"
+	pixKey          = "01"
+	pixDescription  = "02"
+	pixLocation     = "25"
+	pixTxID         = "05"
+	pixGUIValue     = "br.gov.bcb.pix"
+	pixCurrencyBRL  = "986"
+	pixStaticTxID   = "***"
+	pixSingleUse    = "12"
+	pixMaxFieldSize = 99
+
+	PIXMaxTxIDLength         = 25
+	PIXMaxMerchantNameLength = 25
+	PIXMaxMerchantCityLength = 15
+)
+
+var pixTxIDPattern = regexp.MustCompile(`^O(\d+)P(\d+)$`)
+
+// PIXBRCode is the 'copia e cola' payload of a PIX QR Code. The static BR Code has the PIX key
+// and can be paid more than once, while the dynamic one has the Location of the charge in the PSP
+// and is paid once. The Amount is optional only in the static BR Code
+type PIXBRCode struct {
+	Key          string
+	Location     string
+	Description  string
+	MerchantName string
+	MerchantCity string
+	Amount       Money
+	TxID         string
+}
+
+// Encode builds the BR Code with the CRC16 checksum in the end
+func (code PIXBRCode) Encode() (string, error) {
+	err := code.validate()
+
+	if err != nil {
+		return "", err
+	}
+
+	merchantAccount := pixField(pixGUI, pixGUIValue)
+
+	if code.Location != "" {
+		merchantAccount += pixField(pixLocation, code.Location)
+	} else {
+		merchantAccount += pixField(pixKey, code.Key)
+
+		if code.Description != "" {
+			merchantAccount += pixField(pixDescription, code.Description)
+		}
+	}
+
+	if len(merchantAccount) > pixMaxFieldSize {
+		return "", &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "The PIX key and description are too long",
+		}
+	}
+
+	txID := code.TxID
+
+	if txID == "" || code.Location != "" {
+		txID = pixStaticTxID
+	}
+
+	var payload strings.Builder
+
+	payload.WriteString(pixField(pixPayloadFormatIndicator, "01"))
+
+	if code.Location != "" {
+		payload.WriteString(pixField(pixPointOfInitiationMethod, pixSingleUse))
+	}
+
+	payload.WriteString(pixField(pixMerchantAccountInformation, merchantAccount))
+	payload.WriteString(pixField(pixMerchantCategoryCode, "0000"))
+	payload.WriteString(pixField(pixTransactionCurrency, pixCurrencyBRL))
+
+	if code.Amount.Cents > 0 {
+		payload.WriteString(pixField(pixTransactionAmount, code.Amount.String()))
+	}
+
+	payload.WriteString(pixField(pixCountryCode, "BR"))
+	payload.WriteString(pixField(pixMerchantName, pixText(code.MerchantName, PIXMaxMerchantNameLength)))
+	payload.WriteString(pixField(pixMerchantCity, pixText(code.MerchantCity, PIXMaxMerchantCityLength)))
+	payload.WriteString(pixField(pixAdditionalDataField, pixField(pixTxID, txID)))
+
+	// The checksum includes its own ID and length
+	payload.WriteString(pixCRC16 + "04")
+	payload.WriteString(fmt.Sprintf("%04X", CRC16(payload.String())))
+
+	return payload.String(), nil
+}
+
+func (code PIXBRCode) validate() error {
+	var message string
+
+	switch {
+	case code.Key == "" && code.Location == "":
+		message = "The PIX key or location is required"
+	case strings.TrimSpace(code.MerchantName) == "" || strings.TrimSpace(code.MerchantCity) == "":
+		message = "The PIX merchant name and city are required"
+	case len(code.TxID) > PIXMaxTxIDLength || strings.IndexFunc(code.TxID, isNotAlphanumeric) >= 0:
+		message = fmt.Sprintf("The PIX txid must have up to %v letters or numbers", PIXMaxTxIDLength)
+	case code.Amount.IsNegative():
+		message = "The PIX amount can not be negative"
+	case code.Amount.Currency != "" && code.Amount.Currency != CurrencyBRL:
+		message = "The PIX amount must be in reais"
+	}
+
+	if message == "" {
+		return nil
+	}
+
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    message,
+	}
+}
+
+// PIXTxID identifies the order and the payment in the PIX paid by the customer
+func PIXTxID(orderID uint, paymentID uint) string {
+	return fmt.Sprintf("O%vP%v", orderID, paymentID)
+}
+
+// ParsePIXTxID reads the order and the payment from a txid created by PIXTxID
+func ParsePIXTxID(txID string) (uint, uint, bool) {
+	ids := pixTxIDPattern.FindStringSubmatch(txID)
+
+	if ids == nil {
+		return 0, 0, false
+	}
+
+	return ParseQRCodeExternalReference(ids[1] + "|" + ids[2])
+}
+
+// CRC16 is the CRC16-CCITT-FALSE checksum (polynomial 0x1021 and initial value 0xFFFF) used by the BR Code
+func CRC16(payload string) uint16 {
+	crc := uint16(0xFFFF)
+
+	for i := 0; i < len(payload); i++ {
+		crc ^= uint16(payload[i]) << 8
+
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+func pixField(id string, value string) string {
+	return fmt.Sprintf("%v%02d%v", id, len(value), value)
+}
+
+// pixText removes the accents, because the BR Code only accepts ASCII in the merchant fields
+func pixText(value string, maxLength int) string {
+	text, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), value)
+
+	if err != nil {
+		text = value
+	}
+
+	text = strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return -1
+		}
+
+		return r
+	}, strings.TrimSpace(text))
+
+	if len(text) > maxLength {
+		text = text[:maxLength]
+	}
+
+	return text
+}
+
+func isNotAlphanumeric(r rune) bool {
+	return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIX(t *testing.T) {
+	t.Run("got static br code from the banco central manual in pix", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := PIXBRCode{
+			Key:          "123e4567-e12b-12d1-a456-426655440000",
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}.Encode()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D", payload)
+	})
+
+	t.Run("got br code with amount and txid in pix", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := PIXBRCode{
+			Key:          "fastfood@example.com",
+			Description:  "Pedido 12",
+			MerchantName: "Lanchonete São João do Açaí Ltda",
+			MerchantCity: "São Paulo",
+			Amount:       NewMoney(2590),
+			TxID:         PIXTxID(12, 34),
+		}.Encode()
+
+		assert.NoError(t, err)
+		assert.Contains(t, payload, "0120fastfood@example.com")
+		assert.Contains(t, payload, "0209Pedido 12")
+		assert.Contains(t, payload, "540525.90")
+		assert.Contains(t, payload, "5925Lanchonete Sao Joao do Ac")
+		assert.Contains(t, payload, "6009Sao Paulo")
+		assert.Contains(t, payload, "62100506O12P34")
+		assert.NotContains(t, payload, "010212")
+		assert.Equal(t, payload[len(payload)-4:], strings.ToUpper(payload[len(payload)-4:]))
+		assert.Equal(t, CRC16(payload[:len(payload)-4]), crcFromPayload(t, payload))
+	})
+
+	t.Run("got dynamic br code with location in pix", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := PIXBRCode{
+			Location:     "pix.example.com/qr/v2/9d36b84f",
+			MerchantName: "Fast Food",
+			MerchantCity: "Sao Paulo",
+			Amount:       NewMoney(1000),
+			TxID:         "ignored",
+		}.Encode()
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(payload, "000201010212"))
+		assert.Contains(t, payload, "2530pix.example.com/qr/v2/9d36b84f")
+		assert.Contains(t, payload, "62070503***")
+		assert.Equal(t, CRC16(payload[:len(payload)-4]), crcFromPayload(t, payload))
+	})
+
+	t.Run("got error when br code is not valid in pix", func(t *testing.T) {
+		t.Parallel()
+
+		invalidCodes := []PIXBRCode{
+			{MerchantName: "Fast Food", MerchantCity: "Sao Paulo"},
+			{Key: "fastfood@example.com", MerchantCity: "Sao Paulo"},
+			{Key: "fastfood@example.com", MerchantName: "Fast Food", MerchantCity: "Sao Paulo", TxID: "order-1"},
+			{Key: "fastfood@example.com", MerchantName: "Fast Food", MerchantCity: "Sao Paulo", TxID: strings.Repeat("A", 26)},
+			{Key: "fastfood@example.com", MerchantName: "Fast Food", MerchantCity: "Sao Paulo", Amount: NewMoney(-100)},
+			{Key: "fastfood@example.com", Description: strings.Repeat("A", 80), MerchantName: "Fast Food", MerchantCity: "Sao Paulo"},
+		}
+
+		for _, code := range invalidCodes {
+			_, err := code.Encode()
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("got order and payment from txid in pix", func(t *testing.T) {
+		t.Parallel()
+
+		orderID, paymentID, ok := ParsePIXTxID(PIXTxID(12, 34))
+
+		assert.True(t, ok)
+		assert.Equal(t, uint(12), orderID)
+		assert.Equal(t, uint(34), paymentID)
+
+		_, _, ok = ParsePIXTxID("***")
+		assert.False(t, ok)
+
+		_, _, ok = ParsePIXTxID("O0P34")
+		assert.False(t, ok)
+	})
+}
+
+func crcFromPayload(t *testing.T, payload string) uint16 {
+	var crc uint16
+
+	for _, char := range payload[len(payload)-4:] {
+		crc <<= 4
+
+		switch {
+		case char >= '0' && char <= '9':
+			crc |= uint16(char - '0')
+		case char >= 'A' && char <= 'F':
+			crc |= uint16(char-'A') + 10
+		default:
+			t.Fatalf("invalid crc %v", payload[len(payload)-4:])
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "fmt"
+
+const (
+	// The data subject requests of the LGPD. Each request is kept in an audit record
+	DataSubjectRequestExport  = "export"
+	DataSubjectRequestErasure = "erasure"
+
+	DataSubjectActorCustomer = "customer"
+	DataSubjectActorAdmin    = "admin"
+
+	// ErasedCustomerName replaces the name of the erased customer. The CPF and the email are
+	// replaced by values unique by customer, since both have unique indexes
+	ErasedCustomerName = "Cliente removido"
+)
+
+func ErasedCustomerCPF(customerID uint) string {
+	return fmt.Sprintf("removido-%v", customerID)
+}
+
+func ErasedCustomerEmail(customerID uint) string {
+	return fmt.Sprintf("removido-%v@removido.invalid", customerID)
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErasedCustomer(t *testing.T) {
+	t.Run("got erased CPF and email unique by customer", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "removido-12", ErasedCustomerCPF(12))
+		assert.Equal(t, "removido-12@removido.invalid", ErasedCustomerEmail(12))
+		assert.NotEqual(t, ErasedCustomerCPF(12), ErasedCustomerCPF(13))
+	})
+}
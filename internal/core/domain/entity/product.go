@@ -0,0 +1,9 @@
+package entity
+
+const (
+	CategorySnack    = "Lanche"
+	CategoryBeverage = "Bebida"
+	CategoryDesert   = "Sobremesa"
+	CategoryToppings = "Acompanhamento"
+	CategoryCombo    = "Combo"
+)
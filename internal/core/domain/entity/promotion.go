@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+const (
+	PromotionTypePercentage  = "PERCENTAGE"
+	PromotionTypeFixedAmount = "FIXED_AMOUNT"
+	PromotionTypeBuyXGetY    = "BUY_X_GET_Y"
+)
+
+// PromotionTimeLayout is the layout of the daily hours of a promotion, like '14:00'
+const PromotionTimeLayout = "15:04"
+
+// Promotion is a discount rule. A promotion without Code is applied automatically to every order,
+// while a coupon only when the customer types its Code. The Category and the ProductID limit the
+// items which receive the discount, and the dates, Weekdays and daily hours limit when it is valid
+type Promotion struct {
+	Type         string
+	Percentage   int
+	Amount       Money
+	BuyQuantity  int
+	FreeQuantity int
+	Category     string
+	ProductID    *uint
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	Weekdays     []time.Weekday
+	StartTime    string
+	EndTime      string
+}
+
+// PromotionItem is an ordered product, priced with its modifiers surcharge
+type PromotionItem struct {
+	ProductID uint
+	Category  string
+	UnitPrice Money
+	Quantity  int
+}
+
+// Validate rejects the rules that can never be applied, like a 150% discount
+func (promotion Promotion) Validate() error {
+	switch promotion.Type {
+	case PromotionTypePercentage:
+		if promotion.Percentage <= 0 || promotion.Percentage > 100 {
+			return invalidPromotion("The percentage must be between 1 and 100")
+		}
+	case PromotionTypeFixedAmount:
+		if promotion.Amount.Cents <= 0 {
+			return invalidPromotion("The amount must be greater than zero")
+		}
+	case PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.FreeQuantity <= 0 {
+			return invalidPromotion("The buy and free quantities must be greater than zero")
+		}
+	default:
+		return invalidPromotion(fmt.Sprintf("The promotion type %v is not valid", promotion.Type))
+	}
+
+	if promotion.Category != "" && !slices.Contains(promotionCategories, promotion.Category) {
+		return invalidPromotion(fmt.Sprintf("The category %v is not valid", promotion.Category))
+	}
+
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return invalidPromotion("The promotion must end after it starts")
+	}
+
+	for _, weekday := range promotion.Weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return invalidPromotion(fmt.Sprintf("The weekday %v is not valid. Use 0 (Sunday) to 6 (Saturday)", int(weekday)))
+		}
+	}
+
+	if (promotion.StartTime == "") != (promotion.EndTime == "") {
+		return invalidPromotion("The promotion hours must have both start and end times")
+	}
+
+	for _, value := range []string{promotion.StartTime, promotion.EndTime} {
+		if _, err := time.Parse(PromotionTimeLayout, value); value != "" && err != nil {
+			return invalidPromotion(fmt.Sprintf("The time %q is not valid. Use HH:MM, like 14:00", value))
+		}
+	}
+
+	return nil
+}
+
+// IsValidAt checks the dates, the weekdays and the daily hours of the promotion in the 'now' location.
+// The hours can cross midnight, like '22:00' to '02:00'
+func (promotion Promotion) IsValidAt(now time.Time) bool {
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return false
+	}
+
+	if promotion.EndsAt != nil && !now.Before(*promotion.EndsAt) {
+		return false
+	}
+
+	if len(promotion.Weekdays) > 0 && !slices.Contains(promotion.Weekdays, now.Weekday()) {
+		return false
+	}
+
+	if promotion.StartTime == "" {
+		return true
+	}
+
+	current := now.Format(PromotionTimeLayout)
+
+	if promotion.StartTime <= promotion.EndTime {
+		return current >= promotion.StartTime && current < promotion.EndTime
+	}
+
+	return current >= promotion.StartTime || current < promotion.EndTime
+}
+
+// Discount is the amount taken from the items the promotion applies to. It is never more than these items price.
+// The free items of a 'buy X get Y' are the cheapest ones, and the percentages are rounded down to the cent
+func (promotion Promotion) Discount(items []PromotionItem) Money {
+	eligible := []PromotionItem{}
+	subtotal := NewMoney(0)
+	units := 0
+
+	for _, item := range items {
+		if promotion.appliesTo(item) {
+			eligible = append(eligible, item)
+			subtotal = subtotal.Add(item.UnitPrice.Times(item.Quantity))
+			units += item.Quantity
+		}
+	}
+
+	switch promotion.Type {
+	case PromotionTypePercentage:
+		return NewMoney(subtotal.Cents * int64(promotion.Percentage) / 100)
+	case PromotionTypeFixedAmount:
+		return NewMoney(min(promotion.Amount.Cents, subtotal.Cents))
+	case PromotionTypeBuyXGetY:
+		free := units / (promotion.BuyQuantity + promotion.FreeQuantity) * promotion.FreeQuantity
+
+		slices.SortFunc(eligible, func(previous, next PromotionItem) int {
+			return cmp.Compare(previous.UnitPrice.Cents, next.UnitPrice.Cents)
+		})
+
+		discount := NewMoney(0)
+
+		for _, item := range eligible {
+			quantity := min(free, item.Quantity)
+			discount = discount.Add(item.UnitPrice.Times(quantity))
+			free -= quantity
+		}
+
+		return discount
+	}
+
+	return NewMoney(0)
+}
+
+func (promotion Promotion) appliesTo(item PromotionItem) bool {
+	if promotion.Category != "" && promotion.Category != item.Category {
+		return false
+	}
+
+	return promotion.ProductID == nil || *promotion.ProductID == item.ProductID
+}
+
+var promotionCategories = []string{
+	CategoryCombo,
+	CategorySnack,
+	CategoryBeverage,
+	CategoryToppings,
+	CategoryDesert,
+}
+
+func invalidPromotion(message string) error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+	}
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+var promotionItems = []PromotionItem{
+	{ProductID: 1, Category: CategorySnack, UnitPrice: NewMoney(2990), Quantity: 2},
+	{ProductID: 2, Category: CategoryBeverage, UnitPrice: NewMoney(790), Quantity: 3},
+	{ProductID: 3, Category: CategoryDesert, UnitPrice: NewMoney(1250), Quantity: 1},
+}
+
+func TestPromotion(t *testing.T) {
+	t.Run("got percentage of the order items in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		sut := Promotion{Type: PromotionTypePercentage, Percentage: 10}
+
+		assert.Equal(t, NewMoney(960), sut.Discount(promotionItems))
+	})
+
+	t.Run("got percentage only of the category items in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		sut := Promotion{Type: PromotionTypePercentage, Percentage: 15, Category: CategoryBeverage}
+
+		// 15% of 23.70 is 3.555, rounded down to the cent
+		assert.Equal(t, NewMoney(355), sut.Discount(promotionItems))
+	})
+
+	t.Run("got fixed amount limited to the product price in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		productID := uint(3)
+		sut := Promotion{Type: PromotionTypeFixedAmount, Amount: NewMoney(2000), ProductID: &productID}
+
+		assert.Equal(t, NewMoney(1250), sut.Discount(promotionItems))
+
+		sut.ProductID = nil
+
+		assert.Equal(t, NewMoney(2000), sut.Discount(promotionItems))
+	})
+
+	t.Run("got cheapest items free in buy x get y promotion", func(t *testing.T) {
+		t.Parallel()
+
+		sut := Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 2, FreeQuantity: 1}
+
+		// 6 items give 2 free ones, the cheapest beverages
+		assert.Equal(t, NewMoney(1580), sut.Discount(promotionItems))
+
+		sut.Category = CategorySnack
+
+		// 2 snacks are not enough to get a free one
+		assert.Equal(t, NewMoney(0), sut.Discount(promotionItems))
+	})
+
+	t.Run("got promotion valid only inside its dates, weekdays and hours in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		startsAt := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+		endsAt := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+		sut := Promotion{
+			Type:       PromotionTypePercentage,
+			Percentage: 10,
+			StartsAt:   &startsAt,
+			EndsAt:     &endsAt,
+			Weekdays:   []time.Weekday{time.Tuesday},
+			StartTime:  "14:00",
+			EndTime:    "17:00",
+		}
+
+		assert.True(t, sut.IsValidAt(time.Date(2024, time.June, 11, 14, 0, 0, 0, time.UTC)))
+		assert.False(t, sut.IsValidAt(time.Date(2024, time.June, 11, 17, 0, 0, 0, time.UTC)))
+		assert.False(t, sut.IsValidAt(time.Date(2024, time.June, 12, 15, 0, 0, 0, time.UTC)))
+		assert.False(t, sut.IsValidAt(time.Date(2024, time.July, 2, 15, 0, 0, 0, time.UTC)))
+		assert.False(t, sut.IsValidAt(time.Date(2024, time.May, 28, 15, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("got promotion valid in hours crossing midnight in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		sut := Promotion{Type: PromotionTypePercentage, Percentage: 10, StartTime: "22:00", EndTime: "02:00"}
+
+		assert.True(t, sut.IsValidAt(time.Date(2024, time.June, 11, 23, 30, 0, 0, time.UTC)))
+		assert.True(t, sut.IsValidAt(time.Date(2024, time.June, 12, 1, 59, 0, 0, time.UTC)))
+		assert.False(t, sut.IsValidAt(time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("got error when validating invalid rules in promotion", func(t *testing.T) {
+		t.Parallel()
+
+		for _, promotion := range []Promotion{
+			{Type: "FREE"},
+			{Type: PromotionTypePercentage, Percentage: 150},
+			{Type: PromotionTypeFixedAmount},
+			{Type: PromotionTypeBuyXGetY, BuyQuantity: 2},
+			{Type: PromotionTypePercentage, Percentage: 10, Category: "Pizza"},
+			{Type: PromotionTypePercentage, Percentage: 10, Weekdays: []time.Weekday{7}},
+			{Type: PromotionTypePercentage, Percentage: 10, StartTime: "14:00"},
+			{Type: PromotionTypePercentage, Percentage: 10, StartTime: "2pm", EndTime: "5pm"},
+		} {
+			err := promotion.Validate()
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError), promotion)
+			assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+		}
+
+		assert.NoError(t, Promotion{Type: PromotionTypeBuyXGetY, BuyQuantity: 2, FreeQuantity: 1}.Validate())
+	})
+}
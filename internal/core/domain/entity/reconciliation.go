@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+const (
+	// QRCodeExpiration is the expiration date sent to Mercado Livre when the QR Code is generated
+	QRCodeExpiration = 12 * time.Hour
+
+	QRCodeReconciliationInterval = 5 * time.Minute
+	// QRCodeReconciliationMinAge gives the webhook the chance to finish the recent orders first
+	QRCodeReconciliationMinAge = 2 * time.Minute
+
+	QRCodeReconciliationPending = "pending"
+	QRCodeReconciliationSkipped = "skipped"
+	QRCodeReconciliationFailed  = "failed"
+)
@@ -0,0 +1,6 @@
+package entity
+
+const (
+	ReportDefaultDays = 30
+	ReportMaxDays     = 366
+)
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+// AuthRepository is the passwordless login of the identity provider. The StartLogin keeps the
+// code in the login session and returns it, so only the RespondToLogin with the same code gets the tokens
+type AuthRepository interface {
+	StartLogin(ctx context.Context, cpf string, code string) (string, error)
+	RespondToLogin(ctx context.Context, form dto.LoginCodeForm) (dto.Token, error)
+	RefreshToken(ctx context.Context, refreshToken string) (dto.Token, error)
+	RevokeToken(ctx context.Context, refreshToken string) error
+}
+
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginCodeNotifier sends the one-time login code to the user and returns the masked destination,
+// like the email or the phone which received it
+type LoginCodeNotifier interface {
+	SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error)
+}
@@ -11,6 +11,5 @@ type CustomerRepository interface {
 	UpdateCustomer(ctx context.Context, customer dto.Customer) error
 	GetCustomerById(ctx context.Context, id uint) (dto.Customer, error)
 	GetCustomerByCPF(ctx context.Context, cpf string) (dto.Customer, error)
-	Login(ctx context.Context, cpf string) (string, error)
 	LoginUnknown() (string, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+type IdempotencyRepository interface {
+	// Reserve returns false when the key is already used and it is not expired
+	Reserve(ctx context.Context, request dto.IdempotentRequest, expiresAt time.Time, lockTimeout time.Duration) (bool, error)
+	GetIdempotencyKey(ctx context.Context, scope string, key string) (dto.IdempotencyKey, error)
+	SaveResponse(ctx context.Context, request dto.IdempotentRequest, response dto.IdempotentResponse) error
+	Release(ctx context.Context, request dto.IdempotentRequest) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
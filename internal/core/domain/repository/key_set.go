@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"crypto/rsa"
+)
+
+// KeySetRepository has the public keys which sign the access tokens
+type KeySetRepository interface {
+	GetPublicKey(ctx context.Context, keyID string) (*rsa.PublicKey, error)
+}
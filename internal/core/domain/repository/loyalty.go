@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+// LoyaltyRepository reads the points ledger. The points are written by the order repository,
+// in the same transaction which creates the order or changes its status
+type LoyaltyRepository interface {
+	GetBalance(ctx context.Context, customerID uint) (int, error)
+	GetTransactions(ctx context.Context, customerID uint) ([]dto.LoyaltyTransactionResponse, error)
+}
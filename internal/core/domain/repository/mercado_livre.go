@@ -9,4 +9,6 @@ import (
 type QRCodePaymentRepository interface {
 	Generate(ctx context.Context, token string, form dto.Order, orderID int) (dto.QRCodeDataResponse, error)
 	GetQRCodePaymentData(ctx context.Context, token string, endpoint string) (dto.ExternalPaymentInformation, error)
+	SearchQRCodePaymentData(ctx context.Context, token string, externalReference string) (dto.ExternalPaymentInformation, error)
+	Refund(ctx context.Context, token string, gatewayPaymentID string) error
 }
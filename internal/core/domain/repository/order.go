@@ -9,15 +9,19 @@ import (
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error)
 	CreatePayingOrder(ctx context.Context, order dto.Order) (dto.OrderResponse, error)
-	FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID uint) error
+	FinishOrderPayment(ctx context.Context, result dto.OrderPaymentResult) (bool, error)
 	DeleteOrder(ctx context.Context, orderID uint) error
 	GetOrderById(ctx context.Context, orderID uint) (dto.OrderResponse, error)
+	GetOrderByPaymentId(ctx context.Context, paymentID uint) (dto.OrderResponse, error)
+	GetOrderOwner(ctx context.Context, orderID uint) (dto.ResourceOwner, error)
 	GetOrdersToPrepare(ctx context.Context) ([]dto.OrderResponse, error)
 	GetOrdersToFollow(ctx context.Context) ([]dto.OrderResponse, error)
 	GetOrdersWaitingPayment(ctx context.Context) ([]dto.OrderResponse, error)
-	UpdateToPreparing(ctx context.Context, orderID uint) error
-	UpdateToDone(ctx context.Context, orderID uint) error
-	UpdateToDelivered(ctx context.Context, orderID uint) error
-	UpdateToNotDelivered(ctx context.Context, orderID uint) error
-	GetNextTicketNumber(ctx context.Context, date int64) int
+	GetOrdersWaitingPaymentByType(ctx context.Context, paymentType string) ([]dto.OrderResponse, error)
+	GetOrders(ctx context.Context, filter dto.OrderFilter) (dto.OrderPage, error)
+	GetCustomerOrders(ctx context.Context, filter dto.CustomerOrdersFilter) (dto.OrderPage, error)
+	MergeGuestOrders(ctx context.Context, guestID string, customerID uint) (int, error)
+	UpdateOrderStatus(ctx context.Context, transition dto.OrderStatusTransition) error
+	GetOrderStatusHistory(ctx context.Context, orderID uint) ([]dto.OrderStatusHistoryResponse, error)
+	GetNextTicketNumber(ctx context.Context, date int64) (int, error)
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+type OrderEventRepository interface {
+	Publish(ctx context.Context, event dto.OrderEvent) error
+	GetEventsAfter(ctx context.Context, orderID *uint, lastEventID int64) ([]dto.OrderEvent, error)
+	Subscribe(orderID *uint) (<-chan dto.OrderEvent, func())
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+type OutboxRepository interface {
+	// ClaimPendingEvents reserves the events for the lease duration, so other replicas will not
+	// publish them at the same time. The events not marked until then are claimed again
+	ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]dto.OutboxEvent, error)
+	MarkAsPublished(ctx context.Context, eventID uint) error
+	MarkAsFailed(ctx context.Context, eventID uint, nextAttemptAt time.Time, reason string) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event dto.OutboxEvent) error
+}
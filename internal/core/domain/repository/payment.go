@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
 )
 
 type PaymentRepository interface {
 	GetPaymentTypes() []string
 	CreatePaymentOrder(ctx context.Context, payment dto.Payment) (dto.PaymentResponse, error)
-	FinishPaymentWithSuccess(ctx context.Context, paymentId uint) error
+	GetPaymentById(ctx context.Context, paymentId uint) (dto.PaymentDetails, error)
+	GetPaymentOwner(ctx context.Context, paymentId uint) (dto.ResourceOwner, error)
+	FinishPaymentWithSuccess(ctx context.Context, paymentId uint, gatewayPaymentId string) error
 	FinishPaymentWithError(ctx context.Context, paymentId uint) error
+	RefundPayment(ctx context.Context, paymentId uint) error
+	VoidPayment(ctx context.Context, paymentId uint) error
 }
@@ -1,7 +1,24 @@
 package repository
 
-import "github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+import (
+	"context"
 
-type PaymentGateway interface {
-	Pay(paymentResonse dto.PaymentResponse, payment dto.Payment) (dto.PaymentGatewayResponse, error)
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+// PaymentProvider is implemented by every payment vendor. The card providers authorize and capture
+// the payment in the request, while the QR Code ones only finish it when the webhook arrives
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error)
+	Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error)
+	Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error)
+	GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error)
+	ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error)
+}
+
+// PaymentProviderRegistry finds the provider of each payment type returned by GetPaymentTypes
+type PaymentProviderRegistry interface {
+	GetProvider(paymentType string) (PaymentProvider, error)
+	GetProviderByName(name string) (PaymentProvider, error)
 }
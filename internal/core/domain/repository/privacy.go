@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+// PrivacyRepository handles the LGPD requests of the customers. The erasure also removes the
+// customer from Cognito
+type PrivacyRepository interface {
+	GetCustomerPayments(ctx context.Context, customerID uint) ([]dto.PaymentDetails, error)
+	CreateDataSubjectRequest(ctx context.Context, request dto.DataSubjectRequest) error
+	EraseCustomer(ctx context.Context, customer dto.Customer, request dto.DataSubjectRequest) error
+}
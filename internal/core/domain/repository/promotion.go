@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+type PromotionRepository interface {
+	CreatePromotion(ctx context.Context, promotion dto.PromotionForm) (uint, error)
+	GetPromotions(ctx context.Context) ([]dto.PromotionResponse, error)
+	GetPromotionById(ctx context.Context, id uint) (dto.PromotionResponse, error)
+	UpdatePromotion(ctx context.Context, promotion dto.PromotionForm) error
+	DeletePromotion(ctx context.Context, id uint) error
+	GetActivePromotions(ctx context.Context, couponCode *string) ([]dto.PromotionResponse, error)
+	CountCustomerUsages(ctx context.Context, promotionID uint, customerID uint) (int64, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+)
+
+type ReportRepository interface {
+	GetRevenueByDay(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByDay, error)
+	GetRevenueByCategory(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByCategory, error)
+	GetRevenueByProduct(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByProduct, error)
+	GetOrderTimes(ctx context.Context, filter dto.ReportFilter) (dto.OrderTimesReport, error)
+	GetNotDelivered(ctx context.Context, filter dto.ReportFilter) (dto.NotDeliveredReport, error)
+	GetHourlyVolume(ctx context.Context, filter dto.ReportFilter) ([]dto.HourlyVolume, error)
+}
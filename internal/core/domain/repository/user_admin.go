@@ -11,5 +11,4 @@ type UserAdminRepository interface {
 	UpdateUser(ctx context.Context, customer dto.UserAdmin) error
 	GetUserById(ctx context.Context, id uint) (dto.UserAdmin, error)
 	GetUserByCPF(ctx context.Context, cpf string) (dto.UserAdmin, error)
-	Login(ctx context.Context, cpf string) (string, error)
 }
@@ -0,0 +1,396 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type AuthenticateUseCase struct {
+	keySet           repository.KeySetRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	issuer           string
+	clientID         string
+}
+
+type AuthorizeCustomerUseCase struct {
+	repository repository.CustomerRepository
+	adminGroup string
+}
+
+type AuthorizeOrderUseCase struct {
+	orderRepo  repository.OrderRepository
+	adminGroup string
+}
+
+type AuthorizePaymentUseCase struct {
+	paymentRepo repository.PaymentRepository
+	adminGroup  string
+}
+
+type VerifyCustomerTokenUseCase struct {
+	repository repository.CustomerRepository
+}
+
+type VerifyLoginCodeUseCase struct {
+	authRepo repository.AuthRepository
+}
+
+type RefreshTokenUseCase struct {
+	authRepo repository.AuthRepository
+}
+
+type LogoutUseCase struct {
+	authRepo         repository.AuthRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+}
+
+type DeleteExpiredRevokedTokensUseCase struct {
+	revokedTokenRepo repository.RevokedTokenRepository
+}
+
+func NewAuthenticateUseCase(
+	keySet repository.KeySetRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	issuer string,
+	clientID string,
+) *AuthenticateUseCase {
+	return &AuthenticateUseCase{
+		keySet:           keySet,
+		revokedTokenRepo: revokedTokenRepo,
+		issuer:           issuer,
+		clientID:         clientID,
+	}
+}
+
+func NewAuthorizeCustomerUseCase(repository repository.CustomerRepository, adminGroup string) *AuthorizeCustomerUseCase {
+	return &AuthorizeCustomerUseCase{
+		repository: repository,
+		adminGroup: adminGroup,
+	}
+}
+
+func NewAuthorizeOrderUseCase(orderRepo repository.OrderRepository, adminGroup string) *AuthorizeOrderUseCase {
+	return &AuthorizeOrderUseCase{
+		orderRepo:  orderRepo,
+		adminGroup: adminGroup,
+	}
+}
+
+func NewAuthorizePaymentUseCase(paymentRepo repository.PaymentRepository, adminGroup string) *AuthorizePaymentUseCase {
+	return &AuthorizePaymentUseCase{
+		paymentRepo: paymentRepo,
+		adminGroup:  adminGroup,
+	}
+}
+
+func NewVerifyCustomerTokenUseCase(repository repository.CustomerRepository) *VerifyCustomerTokenUseCase {
+	return &VerifyCustomerTokenUseCase{
+		repository: repository,
+	}
+}
+
+// Execute validates the signature and the claims of the access token and returns its claims
+func (service *AuthenticateUseCase) Execute(ctx context.Context, token string) (dto.TokenClaims, error) {
+	accessToken, err := entity.ParseAccessToken(token)
+
+	if err != nil {
+		return dto.TokenClaims{}, err
+	}
+
+	key, err := service.keySet.GetPublicKey(ctx, accessToken.Header.KeyID)
+
+	if err != nil {
+		return dto.TokenClaims{}, responses.GetResponseError(err, "AuthService -> GetPublicKey")
+	}
+
+	err = accessToken.VerifySignature(key)
+
+	if err != nil {
+		return dto.TokenClaims{}, err
+	}
+
+	err = accessToken.Claims.Validate(service.issuer, service.clientID, time.Now())
+
+	if err != nil {
+		return dto.TokenClaims{}, err
+	}
+
+	tokenID := accessToken.Claims.TokenID()
+
+	if tokenID != "" {
+		revoked, err := service.revokedTokenRepo.IsRevoked(ctx, tokenID)
+
+		if err != nil {
+			return dto.TokenClaims{}, responses.GetResponseError(err, "AuthService -> IsRevoked")
+		}
+
+		if revoked {
+			return dto.TokenClaims{}, &responses.BusinessResponse{
+				StatusCode: http.StatusUnauthorized,
+				Message:    "The Bearer token was revoked by the logout",
+			}
+		}
+	}
+
+	return dto.TokenClaims{
+		Subject:  accessToken.Claims.Subject,
+		Username: accessToken.Claims.Username,
+		Groups:   accessToken.Claims.Groups,
+		TokenID:  tokenID,
+		GuestID:  accessToken.Claims.GuestID(),
+	}, nil
+}
+
+// Execute allows the customer data only to the customer itself, whose CPF is the token username,
+// and to the admins
+func (service *AuthorizeCustomerUseCase) Execute(ctx context.Context, customerID uint, claims dto.TokenClaims) error {
+	if slices.Contains(claims.Groups, service.adminGroup) {
+		return nil
+	}
+
+	customer, err := service.repository.GetCustomerById(ctx, customerID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> GetCustomerById")
+	}
+
+	if customer.CPF != claims.Username {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusForbidden,
+			Message:    "The customer can only access their own data",
+		}
+	}
+
+	return nil
+}
+
+// Execute allows the order only to its customer or guest and to the admins. The claims are nil
+// when the request has no token
+func (service *AuthorizeOrderUseCase) Execute(ctx context.Context, orderID uint, claims *dto.TokenClaims) error {
+	owner, err := service.orderRepo.GetOrderOwner(ctx, orderID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> GetOrderOwner")
+	}
+
+	return authorizeResourceOwner(owner, claims, service.adminGroup)
+}
+
+// Execute allows the payment only to the customer or guest of its order and to the admins. The claims
+// are nil when the request has no token
+func (service *AuthorizePaymentUseCase) Execute(ctx context.Context, paymentID uint, claims *dto.TokenClaims) error {
+	owner, err := service.paymentRepo.GetPaymentOwner(ctx, paymentID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> GetPaymentOwner")
+	}
+
+	return authorizeResourceOwner(owner, claims, service.adminGroup)
+}
+
+// authorizeResourceOwner keeps the kiosk orders without login open, since they have no customer data and
+// the kiosk follows them without a token
+func authorizeResourceOwner(owner dto.ResourceOwner, claims *dto.TokenClaims, adminGroup string) error {
+	if owner.CustomerCPF == nil && owner.GuestID == nil && !owner.Erased {
+		return nil
+	}
+
+	if claims == nil {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "The Bearer token is required",
+		}
+	}
+
+	if slices.Contains(claims.Groups, adminGroup) {
+		return nil
+	}
+
+	if owner.Erased {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusForbidden,
+			Message:    "The orders and payments of an erased customer are only available to the admins",
+		}
+	}
+
+	if owner.CustomerCPF != nil && claims.GuestID == "" && *owner.CustomerCPF == claims.Username {
+		return nil
+	}
+
+	if owner.GuestID != nil && *owner.GuestID == claims.GuestID {
+		return nil
+	}
+
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusForbidden,
+		Message:    "The customer can only access their own orders and payments",
+	}
+}
+
+// Execute allows the loyalty points and the promotions of the customer only to the customer itself. The
+// customerId of the order is sent by the kiosk, so the authenticatedCPF must be the username of its token
+func (service *VerifyCustomerTokenUseCase) Execute(ctx context.Context, customerID uint, authenticatedCPF string) error {
+	if authenticatedCPF == "" {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "The Bearer token of the customer is required",
+		}
+	}
+
+	customer, err := service.repository.GetCustomerById(ctx, customerID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> GetCustomerById")
+	}
+
+	if customer.CPF != authenticatedCPF {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusForbidden,
+			Message:    "The customer can only use their own loyalty points and promotions",
+		}
+	}
+
+	return nil
+}
+
+func NewVerifyLoginCodeUseCase(authRepo repository.AuthRepository) *VerifyLoginCodeUseCase {
+	return &VerifyLoginCodeUseCase{
+		authRepo: authRepo,
+	}
+}
+
+func NewRefreshTokenUseCase(authRepo repository.AuthRepository) *RefreshTokenUseCase {
+	return &RefreshTokenUseCase{
+		authRepo: authRepo,
+	}
+}
+
+func NewLogoutUseCase(authRepo repository.AuthRepository, revokedTokenRepo repository.RevokedTokenRepository) *LogoutUseCase {
+	return &LogoutUseCase{
+		authRepo:         authRepo,
+		revokedTokenRepo: revokedTokenRepo,
+	}
+}
+
+func NewDeleteExpiredRevokedTokensUseCase(revokedTokenRepo repository.RevokedTokenRepository) *DeleteExpiredRevokedTokensUseCase {
+	return &DeleteExpiredRevokedTokensUseCase{
+		revokedTokenRepo: revokedTokenRepo,
+	}
+}
+
+// Execute returns the tokens when the code is the one sent by the login
+func (service *VerifyLoginCodeUseCase) Execute(ctx context.Context, form dto.LoginCodeForm) (dto.Token, error) {
+	token, err := service.authRepo.RespondToLogin(ctx, form)
+
+	if err != nil {
+		return dto.Token{}, responses.GetResponseError(err, "AuthService -> RespondToLogin")
+	}
+
+	return token, nil
+}
+
+func (service *RefreshTokenUseCase) Execute(ctx context.Context, refreshToken string) (dto.Token, error) {
+	token, err := service.authRepo.RefreshToken(ctx, refreshToken)
+
+	if err != nil {
+		return dto.Token{}, responses.GetResponseError(err, "AuthService -> RefreshToken")
+	}
+
+	return token, nil
+}
+
+// Execute revokes the refresh token, so it does not get new access tokens, and the login of the
+// access token, since Cognito does not tell the API which access tokens were revoked
+func (service *LogoutUseCase) Execute(ctx context.Context, claims dto.TokenClaims, refreshToken string) error {
+	err := service.authRepo.RevokeToken(ctx, refreshToken)
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> RevokeToken")
+	}
+
+	if claims.TokenID == "" {
+		return nil
+	}
+
+	err = service.revokedTokenRepo.Revoke(ctx, claims.TokenID, time.Now().Add(entity.AccessTokenMaxDuration))
+
+	if err != nil {
+		return responses.GetResponseError(err, "AuthService -> Revoke")
+	}
+
+	return nil
+}
+
+func (service *DeleteExpiredRevokedTokensUseCase) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := service.Execute(ctx)
+
+		if err != nil {
+			log.Print("delete expired revoked tokens", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (service *DeleteExpiredRevokedTokensUseCase) Execute(ctx context.Context) (int64, error) {
+	deleted, err := service.revokedTokenRepo.DeleteExpired(ctx, time.Now())
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "AuthService -> DeleteExpired")
+	}
+
+	return deleted, nil
+}
+
+// startLogin creates the one-time code, starts the login with it and sends it to the user.
+// The code is only sent after the login is started, so the user never gets a code which does not work
+func startLogin(
+	ctx context.Context,
+	authRepo repository.AuthRepository,
+	notifier repository.LoginCodeNotifier,
+	message dto.LoginCodeMessage,
+	cpf string,
+) (dto.LoginChallenge, error) {
+	code, err := entity.NewLoginCode()
+
+	if err != nil {
+		return dto.LoginChallenge{}, responses.GetResponseError(err, "AuthService -> NewLoginCode")
+	}
+
+	session, err := authRepo.StartLogin(ctx, cpf, code)
+
+	if err != nil {
+		return dto.LoginChallenge{}, responses.GetResponseError(err, "AuthService -> StartLogin")
+	}
+
+	message.Code = code
+
+	destination, err := notifier.SendLoginCode(ctx, message)
+
+	if err != nil {
+		return dto.LoginChallenge{}, responses.GetResponseError(err, "AuthService -> SendLoginCode")
+	}
+
+	return dto.LoginChallenge{
+		Session:     session,
+		Destination: destination,
+	}, nil
+}
@@ -0,0 +1,368 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	external "github.com/thiagoluis88git/tech1/internal/integrations"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestAuthServices(t *testing.T) {
+	keySet := external.NewLocalKeySet(map[string]*rsa.PublicKey{
+		accessTokenKeyID: &accessTokenKey.PublicKey,
+	})
+
+	noRevokedTokens := new(MockRevokedTokenRepository)
+	noRevokedTokens.On("IsRevoked", mock.Anything, mock.Anything).Return(false, nil)
+
+	t.Run("got claims when authenticating valid token in services", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewAuthenticateUseCase(keySet, noRevokedTokens, accessTokenIssuer, accessTokenClientID)
+
+		token := signAccessToken(accessTokenKey, accessTokenKeyID, validAccessTokenClaims("17107972073", "groupAdmin"))
+
+		claims, err := sut.Execute(context.TODO(), token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "17107972073", claims.Username)
+		assert.Equal(t, "8c1d5f3e-sub", claims.Subject)
+		assert.Equal(t, []string{"groupAdmin"}, claims.Groups)
+		assert.Equal(t, "4f2a9c1b-origin", claims.TokenID)
+	})
+
+	t.Run("got error when authenticating token revoked by the logout in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		sut := NewAuthenticateUseCase(keySet, mockRevokedRepo, accessTokenIssuer, accessTokenClientID)
+
+		ctx := context.TODO()
+
+		mockRevokedRepo.On("IsRevoked", ctx, "4f2a9c1b-origin").Return(true, nil)
+
+		token := signAccessToken(accessTokenKey, accessTokenKeyID, validAccessTokenClaims("17107972073"))
+
+		claims, err := sut.Execute(ctx, token)
+
+		assert.Error(t, err)
+		assert.Empty(t, claims)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+	})
+
+	t.Run("got error when authenticating invalid tokens in services", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewAuthenticateUseCase(keySet, noRevokedTokens, accessTokenIssuer, accessTokenClientID)
+
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		expired := validAccessTokenClaims("17107972073")
+		expired.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+
+		idToken := validAccessTokenClaims("17107972073")
+		idToken.TokenUse = "id"
+
+		otherClient := validAccessTokenClaims("17107972073")
+		otherClient.ClientID = "other-client"
+
+		otherIssuer := validAccessTokenClaims("17107972073")
+		otherIssuer.Issuer = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_other"
+
+		valid := signAccessToken(accessTokenKey, accessTokenKeyID, validAccessTokenClaims("17107972073"))
+		parts := strings.Split(valid, ".")
+		tampered := parts[0] + "." + strings.Split(signAccessToken(accessTokenKey, accessTokenKeyID, validAccessTokenClaims("07073286083", "groupAdmin")), ".")[1] + "." + parts[2]
+
+		for name, token := range map[string]string{
+			"malformed":    "not-a-token",
+			"expired":      signAccessToken(accessTokenKey, accessTokenKeyID, expired),
+			"id token":     signAccessToken(accessTokenKey, accessTokenKeyID, idToken),
+			"other client": signAccessToken(accessTokenKey, accessTokenKeyID, otherClient),
+			"other issuer": signAccessToken(accessTokenKey, accessTokenKeyID, otherIssuer),
+			"unknown key":  signAccessToken(otherKey, "other-key", validAccessTokenClaims("17107972073")),
+			"wrong key":    signAccessToken(otherKey, accessTokenKeyID, validAccessTokenClaims("17107972073")),
+			"tampered":     tampered,
+			"not signed":   parts[0] + "." + parts[1] + ".",
+		} {
+			claims, err := sut.Execute(context.TODO(), token)
+
+			assert.Error(t, err, name)
+			assert.Empty(t, claims, name)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError), name)
+			assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode, name)
+		}
+	})
+
+	t.Run("got success when authorizing the own customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		sut := NewAuthorizeCustomerUseCase(mockRepo, "groupAdmin")
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCustomerById", ctx, uint(1)).Return(mockedSaveCustomer, nil)
+
+		err := sut.Execute(ctx, 1, dto.TokenClaims{Username: "17107972073", Groups: []string{"groupUser"}})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when authorizing admin without customer lookup in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		sut := NewAuthorizeCustomerUseCase(mockRepo, "groupAdmin")
+
+		err := sut.Execute(context.TODO(), 1, dto.TokenClaims{Username: "07073286083", Groups: []string{"groupAdmin"}})
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetCustomerById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got error when authorizing another customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		sut := NewAuthorizeCustomerUseCase(mockRepo, "groupAdmin")
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCustomerById", ctx, uint(1)).Return(mockedSaveCustomer, nil)
+
+		err := sut.Execute(ctx, 1, dto.TokenClaims{Username: "07073286083", Groups: []string{"groupUser"}})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got success when authorizing the order of the customer, the guest or an admin in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewAuthorizeOrderUseCase(mockRepo, "groupAdmin")
+
+		ctx := context.TODO()
+		customerCPF := "17107972073"
+		guestID := "guest-1"
+
+		mockRepo.On("GetOrderOwner", ctx, uint(1)).Return(dto.ResourceOwner{CustomerCPF: &customerCPF}, nil)
+		mockRepo.On("GetOrderOwner", ctx, uint(2)).Return(dto.ResourceOwner{GuestID: &guestID}, nil)
+		mockRepo.On("GetOrderOwner", ctx, uint(3)).Return(dto.ResourceOwner{}, nil)
+		mockRepo.On("GetOrderOwner", ctx, uint(4)).Return(dto.ResourceOwner{Erased: true}, nil)
+
+		assert.NoError(t, sut.Execute(ctx, 1, &dto.TokenClaims{Username: customerCPF, Groups: []string{"groupUser"}}))
+		assert.NoError(t, sut.Execute(ctx, 1, &dto.TokenClaims{Username: "07073286083", Groups: []string{"groupAdmin"}}))
+		assert.NoError(t, sut.Execute(ctx, 2, &dto.TokenClaims{Username: "unknown", GuestID: guestID}))
+		assert.NoError(t, sut.Execute(ctx, 3, nil))
+		assert.NoError(t, sut.Execute(ctx, 4, &dto.TokenClaims{Username: "07073286083", Groups: []string{"groupAdmin"}}))
+	})
+
+	t.Run("got error when authorizing the order of another customer or without token in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sut := NewAuthorizeOrderUseCase(mockRepo, "groupAdmin")
+
+		ctx := context.TODO()
+		customerCPF := "17107972073"
+		guestID := "guest-1"
+
+		mockRepo.On("GetOrderOwner", ctx, uint(1)).Return(dto.ResourceOwner{CustomerCPF: &customerCPF}, nil)
+		mockRepo.On("GetOrderOwner", ctx, uint(2)).Return(dto.ResourceOwner{GuestID: &guestID}, nil)
+		mockRepo.On("GetOrderOwner", ctx, uint(3)).Return(dto.ResourceOwner{Erased: true}, nil)
+
+		tests := []struct {
+			name       string
+			orderID    uint
+			claims     *dto.TokenClaims
+			statusCode int
+		}{
+			{name: "without token", orderID: 1, claims: nil, statusCode: http.StatusUnauthorized},
+			{name: "another customer", orderID: 1, claims: &dto.TokenClaims{Username: "07073286083"}, statusCode: http.StatusForbidden},
+			{name: "another guest", orderID: 2, claims: &dto.TokenClaims{Username: "unknown", GuestID: "guest-2"}, statusCode: http.StatusForbidden},
+			{name: "customer without guest", orderID: 2, claims: &dto.TokenClaims{Username: customerCPF}, statusCode: http.StatusForbidden},
+			{name: "erased without token", orderID: 3, claims: nil, statusCode: http.StatusUnauthorized},
+			{name: "erased customer order", orderID: 3, claims: &dto.TokenClaims{Username: customerCPF}, statusCode: http.StatusForbidden},
+		}
+
+		for _, test := range tests {
+			err := sut.Execute(ctx, test.orderID, test.claims)
+
+			assert.Error(t, err, test.name)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError), test.name)
+			assert.Equal(t, test.statusCode, businessError.StatusCode, test.name)
+		}
+	})
+
+	t.Run("got error when authorizing the payment of another customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockPaymentRepository)
+		sut := NewAuthorizePaymentUseCase(mockRepo, "groupAdmin")
+
+		ctx := context.TODO()
+		customerCPF := "17107972073"
+
+		mockRepo.On("GetPaymentOwner", ctx, uint(1)).Return(dto.ResourceOwner{CustomerCPF: &customerCPF}, nil)
+
+		assert.NoError(t, sut.Execute(ctx, 1, &dto.TokenClaims{Username: customerCPF}))
+
+		err := sut.Execute(ctx, 1, &dto.TokenClaims{Username: "07073286083"})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got challenge and code sent to the customer email when logging in in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		mockAuthRepo := new(MockAuthRepository)
+		mockNotifier := new(MockLoginCodeNotifier)
+		sut := NewLoginCustomerUseCase(mockRepo, mockAuthRepo, mockNotifier)
+
+		ctx := context.TODO()
+
+		var sentCode string
+
+		mockRepo.On("GetCustomerByCPF", ctx, "17107972073").Return(mockedSaveCustomer, nil)
+		mockAuthRepo.On("StartLogin", ctx, "17107972073", mock.MatchedBy(func(code string) bool {
+			sentCode = code
+			return len(code) == entity.LoginCodeLength
+		})).Return("session-1", nil)
+		mockNotifier.On("SendLoginCode", ctx, mock.MatchedBy(func(message dto.LoginCodeMessage) bool {
+			return message.Email == mockedSaveCustomer.Email && message.Code == sentCode
+		})).Return(entity.MaskEmail(mockedSaveCustomer.Email), nil)
+
+		response, err := sut.Execute(ctx, "17107972073")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "session-1", response.Session)
+		assert.Equal(t, entity.MaskEmail(mockedSaveCustomer.Email), response.Destination)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("got code sent to the customer phone when logging in in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		mockAuthRepo := new(MockAuthRepository)
+		mockNotifier := new(MockLoginCodeNotifier)
+		sut := NewLoginCustomerUseCase(mockRepo, mockAuthRepo, mockNotifier)
+
+		ctx := context.TODO()
+		phone := "+5511987654321"
+		customer := mockedSaveCustomer
+		customer.Phone = &phone
+
+		mockRepo.On("GetCustomerByCPF", ctx, "17107972073").Return(customer, nil)
+		mockAuthRepo.On("StartLogin", ctx, "17107972073", mock.Anything).Return("session-1", nil)
+		mockNotifier.On("SendLoginCode", ctx, mock.MatchedBy(func(message dto.LoginCodeMessage) bool {
+			return message.Phone == phone
+		})).Return(entity.MaskPhone(phone), nil)
+
+		response, err := sut.Execute(ctx, "17107972073")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "**********4321", response.Destination)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("got error without sending code when login can not start in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		mockAuthRepo := new(MockAuthRepository)
+		mockNotifier := new(MockLoginCodeNotifier)
+		sut := NewLoginCustomerUseCase(mockRepo, mockAuthRepo, mockNotifier)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetCustomerByCPF", ctx, "17107972073").Return(mockedSaveCustomer, nil)
+		mockAuthRepo.On("StartLogin", ctx, "17107972073", mock.Anything).Return("", &responses.NetworkError{
+			Code:    http.StatusTooManyRequests,
+			Message: "TooManyRequestsException",
+		})
+
+		response, err := sut.Execute(ctx, "17107972073")
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockNotifier.AssertNotCalled(t, "SendLoginCode", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusTooManyRequests, businessError.StatusCode)
+	})
+
+	t.Run("got error when verifying wrong login code in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockAuthRepo := new(MockAuthRepository)
+		sut := NewVerifyLoginCodeUseCase(mockAuthRepo)
+
+		ctx := context.TODO()
+
+		form := dto.LoginCodeForm{CPF: "17107972073", Session: "session-1", Code: "000000"}
+
+		mockAuthRepo.On("RespondToLogin", ctx, form).Return(dto.Token{}, &responses.NetworkError{
+			Code:    http.StatusUnauthorized,
+			Message: "The login code is not valid",
+		})
+
+		response, err := sut.Execute(ctx, form)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+	})
+
+	t.Run("got refresh token and login revoked when logging out in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockAuthRepo := new(MockAuthRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		sut := NewLogoutUseCase(mockAuthRepo, mockRevokedRepo)
+
+		ctx := context.TODO()
+
+		mockAuthRepo.On("RevokeToken", ctx, "refresh-1").Return(nil)
+		mockRevokedRepo.On("Revoke", ctx, "4f2a9c1b-origin", mock.MatchedBy(func(expiresAt time.Time) bool {
+			return expiresAt.After(time.Now().Add(entity.AccessTokenMaxDuration - time.Minute))
+		})).Return(nil)
+
+		err := sut.Execute(ctx, dto.TokenClaims{Username: "17107972073", TokenID: "4f2a9c1b-origin"}, "refresh-1")
+
+		assert.NoError(t, err)
+		mockRevokedRepo.AssertExpectations(t)
+	})
+}
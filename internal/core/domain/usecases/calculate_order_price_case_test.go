@@ -0,0 +1,329 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestCalculateOrderPriceUseCase(t *testing.T) {
+	t.Run("got success when calculating order price with catalog prices use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(3)).Return(orderedCombo, nil)
+
+		products, totalPrice, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 1,
+			},
+			{
+				ProductID:    3,
+				ProductPrice: entity.NewMoney(1),
+			},
+		}, entity.NewMoney(1999000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(1999000), totalPrice)
+		assert.Equal(t, 2, len(products))
+		assert.Equal(t, entity.NewMoney(1000000), products[0].ProductPrice)
+		assert.Equal(t, entity.NewMoney(999000), products[1].ProductPrice)
+	})
+
+	t.Run("got success when calculating order price with same product twice use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+
+		products, totalPrice, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 2,
+			},
+			{
+				ProductID: 2,
+			},
+		}, entity.NewMoney(469000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(469000), totalPrice)
+		assert.Equal(t, 2, len(products))
+	})
+
+	t.Run("got error when client total price disagrees use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(3)).Return(orderedCombo, nil)
+
+		products, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID:    3,
+				ProductPrice: entity.NewMoney(1),
+			},
+		}, entity.NewMoney(1))
+
+		assert.Error(t, err)
+		assert.Empty(t, products)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The order total price 0.01 does not match the products total price 9990.00", businessError.Message)
+	})
+
+	t.Run("got error when product does not exist use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(99)).Return(dto.ProductResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		_, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 99,
+			},
+		}, entity.NewMoney(1000))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The product 99 is not available", businessError.Message)
+	})
+
+	t.Run("got error when combo has no products anymore use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		emptyCombo := orderedCombo
+		emptyCombo.ComboProducts = &[]dto.ProductResponse{}
+
+		mockProductRepo.On("GetProductById", ctx, uint(3)).Return(emptyCombo, nil)
+
+		_, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 3,
+			},
+		}, entity.NewMoney(999000))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when order has no products use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		_, _, err := sut.Execute(context.TODO(), []dto.OrderProduct{}, entity.NewMoney(1000))
+
+		assert.Error(t, err)
+		mockProductRepo.AssertNotCalled(t, "GetProductById")
+	})
+
+	t.Run("got error when database is unavailable use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(dto.ProductResponse{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		_, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 1,
+			},
+		}, entity.NewMoney(1000000))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
+	t.Run("got success when calculating order price with quantity and modifiers use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		toppingId := uint(4)
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(4)).Return(orderedTopping, nil)
+
+		products, totalPrice, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Quantity:  3,
+				Modifiers: []dto.OrderProductModifier{
+					{
+						Type:        "remove",
+						Description: "Onion",
+					},
+					{
+						Type:      "extra",
+						ProductID: &toppingId,
+						Price:     entity.NewMoney(1),
+					},
+				},
+			},
+		}, entity.NewMoney(3150000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(3150000), totalPrice)
+		assert.Equal(t, 3, products[0].Quantity)
+		assert.Equal(t, entity.NewMoney(1000000), products[0].ProductPrice)
+		assert.Equal(t, entity.NewMoney(0), products[0].Modifiers[0].Price)
+		assert.Equal(t, entity.NewMoney(50000), products[0].Modifiers[1].Price)
+		assert.Equal(t, "Bacon", products[0].Modifiers[1].Description)
+	})
+
+	t.Run("got error when extra modifier is not a topping use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		beverageId := uint(2)
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+
+		_, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Modifiers: []dto.OrderProductModifier{
+					{
+						Type:      "extra",
+						ProductID: &beverageId,
+					},
+				},
+			},
+		}, entity.NewMoney(1234500))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The product 2 can not be used as an extra", businessError.Message)
+	})
+
+	t.Run("got error when modifier type is unknown use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+
+		_, _, err := sut.Execute(ctx, []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Modifiers: []dto.OrderProductModifier{
+					{
+						Type:        "discount",
+						Description: "Free",
+					},
+				},
+			},
+		}, entity.NewMoney(1000000))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, "The modifier type discount is not valid", businessError.Message)
+	})
+
+	t.Run("got error when quantity is negative use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		_, _, err := sut.Execute(context.TODO(), []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Quantity:  -2,
+			},
+		}, entity.NewMoney(-2000000))
+
+		assert.Error(t, err)
+		mockProductRepo.AssertNotCalled(t, "GetProductById")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when quantity is above the maximum use case", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		sut := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		_, _, err := sut.Execute(context.TODO(), []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Quantity:  entity.OrderProductMaxQuantity + 1,
+			},
+		}, entity.NewMoney(2000000))
+
+		assert.Error(t, err)
+		mockProductRepo.AssertNotCalled(t, "GetProductById")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The quantity of product 1 must be at most 99", businessError.Message)
+	})
+}
@@ -2,9 +2,12 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
@@ -19,23 +22,31 @@ type UpdateCustomerUseCase struct {
 	repository         repository.CustomerRepository
 }
 
-type GetCustomerByCPFUseCase struct {
-	validateCPFUseCase *ValidateCPFUseCase
-	repository         repository.CustomerRepository
+type GetCustomerByIdUseCase struct {
+	repository repository.CustomerRepository
 }
 
-type GetCustomerByIdUseCase struct {
+type GetCustomerOrdersUseCase struct {
 	repository repository.CustomerRepository
+	orderRepo  repository.OrderRepository
 }
 
 type LoginCustomerUseCase struct {
 	repository repository.CustomerRepository
+	authRepo   repository.AuthRepository
+	notifier   repository.LoginCodeNotifier
 }
 
 type LoginUnknownCustomerUseCase struct {
 	repository repository.CustomerRepository
 }
 
+type MergeGuestOrdersUseCase struct {
+	validateCPFUseCase *ValidateCPFUseCase
+	repository         repository.CustomerRepository
+	orderRepo          repository.OrderRepository
+}
+
 func NewUpdateCustomerUseCase(validateCPFUseCase *ValidateCPFUseCase, repository repository.CustomerRepository) *UpdateCustomerUseCase {
 	return &UpdateCustomerUseCase{
 		validateCPFUseCase: validateCPFUseCase,
@@ -50,22 +61,31 @@ func NewCreateCustomerUseCase(validateCPFUseCase *ValidateCPFUseCase, repository
 	}
 }
 
-func NewGetCustomerByCPFUseCase(validateCPFUseCase *ValidateCPFUseCase, repository repository.CustomerRepository) *GetCustomerByCPFUseCase {
-	return &GetCustomerByCPFUseCase{
-		validateCPFUseCase: validateCPFUseCase,
-		repository:         repository,
+func NewGetCustomerByIdUseCase(repository repository.CustomerRepository) *GetCustomerByIdUseCase {
+	return &GetCustomerByIdUseCase{
+		repository: repository,
 	}
 }
 
-func NewGetCustomerByIdUseCase(repository repository.CustomerRepository) *GetCustomerByIdUseCase {
-	return &GetCustomerByIdUseCase{
+func NewGetCustomerOrdersUseCase(
+	repository repository.CustomerRepository,
+	orderRepo repository.OrderRepository,
+) *GetCustomerOrdersUseCase {
+	return &GetCustomerOrdersUseCase{
 		repository: repository,
+		orderRepo:  orderRepo,
 	}
 }
 
-func NewLoginCustomerUseCase(repository repository.CustomerRepository) *LoginCustomerUseCase {
+func NewLoginCustomerUseCase(
+	repository repository.CustomerRepository,
+	authRepo repository.AuthRepository,
+	notifier repository.LoginCodeNotifier,
+) *LoginCustomerUseCase {
 	return &LoginCustomerUseCase{
 		repository: repository,
+		authRepo:   authRepo,
+		notifier:   notifier,
 	}
 }
 
@@ -75,6 +95,18 @@ func NewLoginUnknownCustomerUseCase(repository repository.CustomerRepository) *L
 	}
 }
 
+func NewMergeGuestOrdersUseCase(
+	validateCPFUseCase *ValidateCPFUseCase,
+	repository repository.CustomerRepository,
+	orderRepo repository.OrderRepository,
+) *MergeGuestOrdersUseCase {
+	return &MergeGuestOrdersUseCase{
+		validateCPFUseCase: validateCPFUseCase,
+		repository:         repository,
+		orderRepo:          orderRepo,
+	}
+}
+
 func (service *CreateCustomerUseCase) Execute(ctx context.Context, customer dto.Customer) (dto.CustomerResponse, error) {
 	cleanedCPF, validate := service.validateCPFUseCase.Execute(customer.CPF)
 
@@ -127,37 +159,58 @@ func (service *GetCustomerByIdUseCase) Execute(ctx context.Context, id uint) (dt
 	return customer, nil
 }
 
-func (service *GetCustomerByCPFUseCase) Execute(ctx context.Context, cpf string) (dto.Customer, error) {
-	cleanedCPF, validate := service.validateCPFUseCase.Execute(cpf)
+// Execute pages the orders of the customer. The routes of the customer data only allow the
+// customer itself and the admins, so it is not checked here
+func (service *GetCustomerOrdersUseCase) Execute(ctx context.Context, filter dto.CustomerOrdersFilter) (dto.OrderPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = entity.OrderPageDefaultLimit
+	}
 
-	if !validate {
-		return dto.Customer{}, &responses.BusinessResponse{
+	if filter.Limit < 0 || filter.Limit > entity.OrderPageMaxLimit {
+		return dto.OrderPage{}, &responses.BusinessResponse{
 			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid CPF",
+			Message:    fmt.Sprintf("The limit must be between 1 and %v", entity.OrderPageMaxLimit),
 		}
 	}
 
-	customer, err := service.repository.GetCustomerByCPF(ctx, cleanedCPF)
+	_, err := service.repository.GetCustomerById(ctx, filter.CustomerID)
 
 	if err != nil {
-		return dto.Customer{}, responses.GetResponseError(err, "CustomerService")
+		return dto.OrderPage{}, responses.GetResponseError(err, "CustomerService -> GetCustomerById")
 	}
 
-	return customer, nil
+	response, err := service.orderRepo.GetCustomerOrders(ctx, filter)
+
+	if err != nil {
+		return dto.OrderPage{}, responses.GetResponseError(err, "CustomerService -> GetCustomerOrders")
+	}
+
+	return response, nil
 }
 
-func (uc *LoginCustomerUseCase) Execute(ctx context.Context, cpf string) (dto.Token, error) {
-	token, err := uc.repository.Login(ctx, cpf)
+// Execute sends the login code to the customer phone, or to the email when the customer has no phone.
+// The tokens are returned by the VerifyLoginCodeUseCase
+func (uc *LoginCustomerUseCase) Execute(ctx context.Context, cpf string) (dto.LoginChallenge, error) {
+	customer, err := uc.repository.GetCustomerByCPF(ctx, cpf)
 
 	if err != nil {
-		return dto.Token{}, responses.GetResponseError(err, "CustomerService")
+		return dto.LoginChallenge{}, responses.GetResponseError(err, "CustomerService -> GetCustomerByCPF")
 	}
 
-	return dto.Token{
-		AccessToken: token,
-	}, nil
+	message := dto.LoginCodeMessage{
+		Name:  customer.Name,
+		Email: customer.Email,
+	}
+
+	if customer.Phone != nil {
+		message.Phone = *customer.Phone
+	}
+
+	return startLogin(ctx, uc.authRepo, uc.notifier, message, customer.CPF)
 }
 
+// Execute logs in a new guest. The orders created with its token are kept by the GuestID until the
+// guest signs up or identifies itself
 func (uc *LoginUnknownCustomerUseCase) Execute(ctx context.Context) (dto.Token, error) {
 	token, err := uc.repository.LoginUnknown()
 
@@ -165,7 +218,51 @@ func (uc *LoginUnknownCustomerUseCase) Execute(ctx context.Context) (dto.Token,
 		return dto.Token{}, responses.GetResponseError(err, "CustomerService")
 	}
 
+	accessToken, err := entity.ParseAccessToken(token)
+
+	if err != nil {
+		return dto.Token{}, responses.GetResponseError(err, "CustomerService -> ParseAccessToken")
+	}
+
 	return dto.Token{
 		AccessToken: token,
+		GuestID:     accessToken.Claims.GuestID(),
 	}, nil
 }
+
+// Execute gives the orders of the guest to the customer with the CPF, when the guest signs up or
+// identifies itself. The requests without guest and the CPFs of the admins have nothing to merge
+func (service *MergeGuestOrdersUseCase) Execute(ctx context.Context, guestID *string, cpf string) (int, error) {
+	if guestID == nil {
+		return 0, nil
+	}
+
+	cleanedCPF, validate := service.validateCPFUseCase.Execute(cpf)
+
+	if !validate {
+		return 0, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Invalid CPF",
+		}
+	}
+
+	customer, err := service.repository.GetCustomerByCPF(ctx, cleanedCPF)
+
+	var localError *responses.LocalError
+
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "CustomerService -> GetCustomerByCPF")
+	}
+
+	merged, err := service.orderRepo.MergeGuestOrders(ctx, *guestID, customer.ID)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "CustomerService -> MergeGuestOrders")
+	}
+
+	return merged, nil
+}
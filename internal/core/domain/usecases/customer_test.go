@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
@@ -163,47 +165,107 @@ func TestCustomerServices(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
 
-	t.Run("got success when getting customer by CPF in services", func(t *testing.T) {
+	t.Run("got the customer own orders when getting customer orders in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockCustomerRepository)
-		sut := NewGetCustomerByCPFUseCase(validateCPFUseCase, mockRepo)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewGetCustomerOrdersUseCase(mockRepo, mockOrderRepo)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetCustomerByCPF", ctx, "07073286083").Return(customerByCPF, nil)
+		mockRepo.On("GetCustomerById", ctx, uint(1)).Return(mockedSaveCustomer, nil)
+		mockOrderRepo.On("GetCustomerOrders", ctx, dto.CustomerOrdersFilter{
+			CustomerID: 1,
+			Limit:      entity.OrderPageDefaultLimit,
+		}).Return(dto.OrderPage{Orders: ordersList}, nil)
 
-		response, err := sut.Execute(ctx, "070.732.860-83")
+		response, err := sut.Execute(ctx, dto.CustomerOrdersFilter{CustomerID: 1})
 
 		assert.NoError(t, err)
-		assert.NotEmpty(t, response)
-
-		assert.Equal(t, uint(1), response.ID)
-		assert.Equal(t, "Name", response.Name)
-		assert.Equal(t, "070.732.860-83", response.CPF)
-		assert.Equal(t, "teste@teste.com", response.Email)
+		assert.Equal(t, len(ordersList), len(response.Orders))
 	})
 
-	t.Run("got error when getting customer by CPF in services", func(t *testing.T) {
+	t.Run("got error when getting orders of unknown customer in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockCustomerRepository)
-		sut := NewGetCustomerByCPFUseCase(validateCPFUseCase, mockRepo)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewGetCustomerOrdersUseCase(mockRepo, mockOrderRepo)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetCustomerByCPF", ctx, "07073286083").Return(dto.Customer{}, &responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
-		})
+		mockRepo.On("GetCustomerById", ctx, uint(99)).Return(dto.Customer{}, customerNotFound)
 
-		response, err := sut.Execute(ctx, "070.732.860-83")
+		response, err := sut.Execute(ctx, dto.CustomerOrdersFilter{CustomerID: 99})
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
+		mockOrderRepo.AssertNotCalled(t, "GetCustomerOrders", mock.Anything, mock.Anything)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
+
+	t.Run("got error when getting customer orders with invalid limit in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		sut := NewGetCustomerOrdersUseCase(mockRepo, new(MockOrderRepository))
+
+		response, err := sut.Execute(context.TODO(), dto.CustomerOrdersFilter{CustomerID: 1, Limit: 500})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "GetCustomerById", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got merged guest orders when the guest identifies itself in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewMergeGuestOrdersUseCase(validateCPFUseCase, mockRepo, mockOrderRepo)
+
+		ctx := context.TODO()
+		guestID := "4f2a9c1b-guest"
+
+		mockRepo.On("GetCustomerByCPF", ctx, "07073286083").Return(customerByCPF, nil)
+		mockOrderRepo.On("MergeGuestOrders", ctx, guestID, uint(1)).Return(2, nil)
+
+		merged, err := sut.Execute(ctx, &guestID, "070.732.860-83")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, merged)
+	})
+
+	t.Run("got nothing merged without guest or customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockCustomerRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewMergeGuestOrdersUseCase(validateCPFUseCase, mockRepo, mockOrderRepo)
+
+		ctx := context.TODO()
+		guestID := "4f2a9c1b-guest"
+
+		mockRepo.On("GetCustomerByCPF", ctx, "17107972073").Return(dto.Customer{}, customerNotFound)
+
+		merged, err := sut.Execute(ctx, nil, "070.732.860-83")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, merged)
+
+		// An admin has no customer, so its guest orders are kept
+		merged, err = sut.Execute(ctx, &guestID, "171.079.720-73")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, merged)
+		mockOrderRepo.AssertNotCalled(t, "MergeGuestOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type IdempotentRequestUseCase struct {
+	repository repository.IdempotencyRepository
+}
+
+type DeleteExpiredIdempotencyKeysUseCase struct {
+	repository repository.IdempotencyRepository
+}
+
+func NewIdempotentRequestUseCase(repository repository.IdempotencyRepository) *IdempotentRequestUseCase {
+	return &IdempotentRequestUseCase{
+		repository: repository,
+	}
+}
+
+func NewDeleteExpiredIdempotencyKeysUseCase(repository repository.IdempotencyRepository) *DeleteExpiredIdempotencyKeysUseCase {
+	return &DeleteExpiredIdempotencyKeysUseCase{
+		repository: repository,
+	}
+}
+
+// Begin reserves the key for the request. It returns the stored response when the request
+// was already processed, so it must be replayed instead of processing the request again
+func (usecase *IdempotentRequestUseCase) Begin(ctx context.Context, request dto.IdempotentRequest) (*dto.IdempotentResponse, error) {
+	if len(request.Key) > entity.IdempotencyKeyMaxLength {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The %v can not be longer than %v characters", entity.IdempotencyKeyHeader, entity.IdempotencyKeyMaxLength),
+		}
+	}
+
+	reserved, err := usecase.repository.Reserve(
+		ctx,
+		request,
+		time.Now().Add(entity.IdempotencyKeyTTL),
+		entity.IdempotencyKeyLockTimeout,
+	)
+
+	if err != nil {
+		return nil, responses.GetResponseError(err, "IdempotencyService -> Reserve")
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := usecase.repository.GetIdempotencyKey(ctx, request.Scope, request.Key)
+
+	if err != nil {
+		return nil, responses.GetResponseError(err, "IdempotencyService -> GetIdempotencyKey")
+	}
+
+	if stored.RequestHash != request.RequestHash {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The %v was already used with a different request", entity.IdempotencyKeyHeader),
+		}
+	}
+
+	if stored.Response == nil {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusConflict,
+			Message:    fmt.Sprintf("A request with this %v is still being processed", entity.IdempotencyKeyHeader),
+		}
+	}
+
+	return stored.Response, nil
+}
+
+// Finish stores the response to be replayed. The server errors are not stored, since the
+// request may succeed if the client retries it
+func (usecase *IdempotentRequestUseCase) Finish(ctx context.Context, request dto.IdempotentRequest, response dto.IdempotentResponse) {
+	var err error
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		err = usecase.repository.Release(ctx, request)
+	} else {
+		err = usecase.repository.SaveResponse(ctx, request, response)
+	}
+
+	// The key is taken over after the lock timeout
+	if err != nil {
+		log.Print("finish idempotent request", map[string]interface{}{
+			"scope": request.Scope,
+			"error": err.Error(),
+		})
+	}
+}
+
+// Start deletes the expired keys until the context is done
+func (usecase *DeleteExpiredIdempotencyKeysUseCase) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := usecase.Execute(ctx)
+
+		if err != nil {
+			log.Print("delete expired idempotency keys", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (usecase *DeleteExpiredIdempotencyKeysUseCase) Execute(ctx context.Context) (int64, error) {
+	deleted, err := usecase.repository.DeleteExpired(ctx, time.Now())
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "IdempotencyService -> DeleteExpired")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestIdempotencyServices(t *testing.T) {
+	t.Parallel()
+
+	request := dto.IdempotentRequest{
+		Scope:       "POST /api/orders",
+		Key:         "kiosk-1-order-1",
+		RequestHash: "hash",
+	}
+
+	storedResponse := dto.IdempotentResponse{
+		StatusCode:  http.StatusOK,
+		ContentType: "application/json",
+		Body:        []byte(`{"orderId":1}`),
+	}
+
+	t.Run("got success when reserving new key in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("Reserve", ctx, request, mock.Anything, entity.IdempotencyKeyLockTimeout).Return(true, nil)
+
+		response, err := sut.Begin(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Nil(t, response)
+		mockRepo.AssertNotCalled(t, "GetIdempotencyKey")
+	})
+
+	t.Run("got stored response when replaying key in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("Reserve", ctx, request, mock.Anything, entity.IdempotencyKeyLockTimeout).Return(false, nil)
+		mockRepo.On("GetIdempotencyKey", ctx, request.Scope, request.Key).Return(dto.IdempotencyKey{
+			Scope:       request.Scope,
+			Key:         request.Key,
+			RequestHash: request.RequestHash,
+			Response:    &storedResponse,
+		}, nil)
+
+		response, err := sut.Begin(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &storedResponse, response)
+	})
+
+	t.Run("got error when key is reused with another body in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("Reserve", ctx, request, mock.Anything, entity.IdempotencyKeyLockTimeout).Return(false, nil)
+		mockRepo.On("GetIdempotencyKey", ctx, request.Scope, request.Key).Return(dto.IdempotencyKey{
+			RequestHash: "another hash",
+			Response:    &storedResponse,
+		}, nil)
+
+		response, err := sut.Begin(ctx, request)
+
+		assert.Error(t, err)
+		assert.Nil(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when key is still being processed in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("Reserve", ctx, request, mock.Anything, entity.IdempotencyKeyLockTimeout).Return(false, nil)
+		mockRepo.On("GetIdempotencyKey", ctx, request.Scope, request.Key).Return(dto.IdempotencyKey{
+			RequestHash: request.RequestHash,
+		}, nil)
+
+		response, err := sut.Begin(ctx, request)
+
+		assert.Error(t, err)
+		assert.Nil(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got error when key is too long in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		response, err := sut.Begin(context.TODO(), dto.IdempotentRequest{
+			Scope: request.Scope,
+			Key:   strings.Repeat("k", entity.IdempotencyKeyMaxLength+1),
+		})
+
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		mockRepo.AssertNotCalled(t, "Reserve")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got response stored when finishing request in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("SaveResponse", ctx, request, storedResponse).Return(nil)
+
+		sut.Finish(ctx, request, storedResponse)
+
+		mockRepo.AssertCalled(t, "SaveResponse", ctx, request, storedResponse)
+		mockRepo.AssertNotCalled(t, "Release")
+	})
+
+	t.Run("got key released when request fails with server error in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewIdempotentRequestUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("Release", ctx, request).Return(nil)
+
+		sut.Finish(ctx, request, dto.IdempotentResponse{StatusCode: http.StatusServiceUnavailable})
+
+		mockRepo.AssertCalled(t, "Release", ctx, request)
+		mockRepo.AssertNotCalled(t, "SaveResponse")
+	})
+
+	t.Run("got success when deleting expired keys in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockIdempotencyRepository)
+		sut := NewDeleteExpiredIdempotencyKeysUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("DeleteExpired", ctx, mock.Anything).Return(int64(3), nil)
+
+		deleted, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+	})
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type ApplyLoyaltyPointsUseCase struct {
+	repository          repository.LoyaltyRepository
+	verifyCustomerToken *VerifyCustomerTokenUseCase
+}
+
+type GetCustomerLoyaltyUseCase struct {
+	customerRepo repository.CustomerRepository
+	repository   repository.LoyaltyRepository
+}
+
+func NewApplyLoyaltyPointsUseCase(
+	repository repository.LoyaltyRepository,
+	verifyCustomerToken *VerifyCustomerTokenUseCase,
+) *ApplyLoyaltyPointsUseCase {
+	return &ApplyLoyaltyPointsUseCase{
+		repository:          repository,
+		verifyCustomerToken: verifyCustomerToken,
+	}
+}
+
+func NewGetCustomerLoyaltyUseCase(
+	customerRepo repository.CustomerRepository,
+	repository repository.LoyaltyRepository,
+) *GetCustomerLoyaltyUseCase {
+	return &GetCustomerLoyaltyUseCase{
+		customerRepo: customerRepo,
+		repository:   repository,
+	}
+}
+
+// Execute returns the discount of the points redeemed by the customer. The points can not be worth
+// more than the total with the promotions, and the balance is checked again when the order is created.
+// Only the customer with the authenticatedCPF can redeem them
+func (service *ApplyLoyaltyPointsUseCase) Execute(
+	ctx context.Context,
+	customerID *uint,
+	authenticatedCPF string,
+	points int,
+	totalPrice entity.Money,
+) (entity.Money, error) {
+	if points == 0 {
+		return entity.NewMoney(0), nil
+	}
+
+	if points < 0 {
+		return entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "The loyalty points must be positive",
+		}
+	}
+
+	if customerID == nil {
+		return entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "The loyalty points are only for identified customers",
+		}
+	}
+
+	err := service.verifyCustomerToken.Execute(ctx, *customerID, authenticatedCPF)
+
+	if err != nil {
+		return entity.Money{}, err
+	}
+
+	balance, err := service.repository.GetBalance(ctx, *customerID)
+
+	if err != nil {
+		return entity.Money{}, responses.GetResponseError(err, "LoyaltyService -> GetBalance")
+	}
+
+	if balance < points {
+		return entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The customer has %v points, not %v", balance, points),
+		}
+	}
+
+	discount := entity.LoyaltyPointsDiscount(points)
+
+	if discount.Cents > totalPrice.Cents {
+		return entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The %v points are worth %v, more than the order total %v", points, discount, totalPrice),
+		}
+	}
+
+	return discount, nil
+}
+
+func (service *GetCustomerLoyaltyUseCase) Execute(ctx context.Context, customerID uint) (dto.LoyaltyResponse, error) {
+	_, err := service.customerRepo.GetCustomerById(ctx, customerID)
+
+	if err != nil {
+		return dto.LoyaltyResponse{}, responses.GetResponseError(err, "LoyaltyService -> GetCustomerById")
+	}
+
+	balance, err := service.repository.GetBalance(ctx, customerID)
+
+	if err != nil {
+		return dto.LoyaltyResponse{}, responses.GetResponseError(err, "LoyaltyService -> GetBalance")
+	}
+
+	transactions, err := service.repository.GetTransactions(ctx, customerID)
+
+	if err != nil {
+		return dto.LoyaltyResponse{}, responses.GetResponseError(err, "LoyaltyService -> GetTransactions")
+	}
+
+	return dto.LoyaltyResponse{
+		CustomerID:   customerID,
+		Balance:      balance,
+		BalanceValue: entity.LoyaltyPointsDiscount(balance),
+		Transactions: transactions,
+	}, nil
+}
@@ -0,0 +1,190 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestLoyaltyServices(t *testing.T) {
+	t.Run("got discount when applying loyalty points in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("GetBalance", ctx, customerId).Return(250, nil)
+
+		discount, err := sut.Execute(ctx, &customerId, customerResponse.CPF, 200, entity.NewMoney(1250))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(1000), discount)
+	})
+
+	t.Run("got no discount without loyalty points in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		discount, err := sut.Execute(context.TODO(), nil, "", 0, entity.NewMoney(1250))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(0), discount)
+		mockRepo.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got error when applying loyalty points without customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		_, err := sut.Execute(context.TODO(), nil, "", 100, entity.NewMoney(1250))
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when applying more loyalty points than the balance in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("GetBalance", ctx, customerId).Return(50, nil)
+
+		_, err := sut.Execute(ctx, &customerId, customerResponse.CPF, 100, entity.NewMoney(1250))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The customer has 50 points, not 100", businessError.Message)
+	})
+
+	t.Run("got error when applying loyalty points worth more than the order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("GetBalance", ctx, customerId).Return(1000, nil)
+
+		_, err := sut.Execute(ctx, &customerId, customerResponse.CPF, 300, entity.NewMoney(1250))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when applying loyalty points without the customer token in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		_, err := sut.Execute(context.TODO(), &customerId, "", 100, entity.NewMoney(1250))
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+	})
+
+	t.Run("got error when applying loyalty points of another customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewApplyLoyaltyPointsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+
+		_, err := sut.Execute(ctx, &customerId, "07073286083", 100, entity.NewMoney(1250))
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got balance and history when getting customer loyalty in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewGetCustomerLoyaltyUseCase(mockCustomerRepo, mockRepo)
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("GetBalance", ctx, customerId).Return(250, nil)
+		mockRepo.On("GetTransactions", ctx, customerId).Return(loyaltyTransactions, nil)
+
+		response, err := sut.Execute(ctx, customerId)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 250, response.Balance)
+		assert.Equal(t, entity.NewMoney(1250), response.BalanceValue)
+		assert.Equal(t, 2, len(response.Transactions))
+	})
+
+	t.Run("got error when getting loyalty of unknown customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockLoyaltyRepository)
+		sut := NewGetCustomerLoyaltyUseCase(mockCustomerRepo, mockRepo)
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, uint(99)).Return(dto.Customer{}, customerNotFound)
+
+		response, err := sut.Execute(ctx, uint(99))
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+}
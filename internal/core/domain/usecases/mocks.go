@@ -2,39 +2,65 @@ package usecases
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
 var (
 	orderCreation = dto.Order{
-		TotalPrice:   12345,
-		PaymentID:    uint(1),
-		TicketNumber: 1,
+		TotalPrice:      entity.NewMoney(1234500),
+		PaymentID:       uint(1),
+		TicketNumber:    1,
+		LoyaltyDiscount: entity.NewMoney(0),
 		OrderProduct: []dto.OrderProduct{
 			{
-				ProductID: 1,
+				ProductID:       1,
+				ProductPrice:    entity.NewMoney(1000000),
+				Quantity:        1,
+				Modifiers:       []dto.OrderProductModifier{},
+				ProductCategory: entity.CategorySnack,
 			},
 			{
-				ProductID: 2,
+				ProductID:       2,
+				ProductPrice:    entity.NewMoney(234500),
+				Quantity:        1,
+				Modifiers:       []dto.OrderProductModifier{},
+				ProductCategory: entity.CategoryBeverage,
 			},
 		},
 	}
 	customerId = uint(1)
 
 	orderCreationWithCustomer = dto.Order{
-		TotalPrice:   12345,
-		PaymentID:    uint(1),
-		TicketNumber: 1,
-		CustomerID:   &customerId,
+		TotalPrice:      entity.NewMoney(1234500),
+		PaymentID:       uint(1),
+		TicketNumber:    1,
+		LoyaltyDiscount: entity.NewMoney(0),
+		CustomerID:      &customerId,
 		OrderProduct: []dto.OrderProduct{
 			{
-				ProductID: 1,
+				ProductID:       1,
+				ProductPrice:    entity.NewMoney(1000000),
+				Quantity:        1,
+				Modifiers:       []dto.OrderProductModifier{},
+				ProductCategory: entity.CategorySnack,
 			},
 			{
-				ProductID: 2,
+				ProductID:       2,
+				ProductPrice:    entity.NewMoney(234500),
+				Quantity:        1,
+				Modifiers:       []dto.OrderProductModifier{},
+				ProductCategory: entity.CategoryBeverage,
 			},
 		},
 	}
@@ -76,7 +102,9 @@ var (
 	}
 
 	customerResponse = dto.Customer{
+		ID:   customerId,
 		Name: "Customer",
+		CPF:  "17107972073",
 	}
 
 	ordersList = []dto.OrderResponse{
@@ -98,8 +126,24 @@ var (
 		},
 	}
 
+	orderStatusHistory = []dto.OrderStatusHistoryResponse{
+		{
+			ToStatus:  "Criado",
+			Actor:     "customer",
+			Reason:    "Order created",
+			ChangedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			FromStatus: "Criado",
+			ToStatus:   "Preparando",
+			Actor:      "kitchen",
+			Reason:     "The kitchen started preparing the order",
+			ChangedAt:  time.Date(2024, 1, 1, 0, 10, 0, 0, time.Local),
+		},
+	}
+
 	paymentCreation = dto.Payment{
-		TotalPrice:  1234,
+		TotalPrice:  entity.NewMoney(123400),
 		PaymentType: "Crédito",
 	}
 
@@ -109,16 +153,126 @@ var (
 		PaymentDate:      time.Date(2024, 10, 10, 0, 0, 0, 0, time.Local),
 	}
 
+	paymentAuthorization = dto.PaymentAuthorization{
+		PaymentID:   1,
+		TotalPrice:  entity.NewMoney(123400),
+		PaymentType: "Crédito",
+	}
+
+	authorizedGatewayResponse = dto.PaymentGatewayResponse{
+		PaymentGatewayId: "1234",
+		PaymentStatus:    entity.PaymentPayingStatus,
+		PaymentDate:      time.Date(2024, 10, 10, 0, 0, 0, 0, time.Local),
+	}
+
 	paymentGatewayResponse = dto.PaymentGatewayResponse{
 		PaymentGatewayId: "1234",
+		PaymentStatus:    entity.PaymentPayedStatus,
 		PaymentDate:      time.Date(2024, 10, 10, 0, 0, 0, 0, time.Local),
 	}
 
+	orderPaymentDetails = dto.PaymentDetails{
+		PaymentId:        1,
+		TotalPrice:       entity.NewMoney(1234500),
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentType:      entity.PaymentCreditType,
+		PaymentGatewayId: "1234",
+	}
+
+	splitOrderCreation = dto.Order{
+		TotalPrice:      entity.NewMoney(1234500),
+		PaymentID:       uint(1),
+		PaymentIDs:      []uint{2},
+		TicketNumber:    1,
+		LoyaltyDiscount: entity.NewMoney(0),
+		OrderProduct:    orderCreation.OrderProduct,
+	}
+
+	splitCreditPaymentDetails = dto.PaymentDetails{
+		PaymentId:        1,
+		TotalPrice:       entity.NewMoney(1000000),
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentType:      entity.PaymentCreditType,
+		PaymentGatewayId: "1234",
+	}
+
+	splitQRCodePaymentDetails = dto.PaymentDetails{
+		PaymentId:        2,
+		TotalPrice:       entity.NewMoney(234500),
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentType:      entity.PaymentQRCodeType,
+		PaymentGatewayId: "9876",
+	}
+
+	welcomeCouponCode = "BEMVINDO"
+
+	beveragePromotion = dto.PromotionResponse{
+		Id:         1,
+		Name:       "Bebidas com 10% de desconto",
+		Type:       entity.PromotionTypePercentage,
+		Percentage: 10,
+		Category:   entity.CategoryBeverage,
+		Active:     true,
+	}
+
+	welcomeCouponPromotion = dto.PromotionResponse{
+		Id:                    2,
+		Name:                  "Cupom de boas-vindas",
+		Code:                  &welcomeCouponCode,
+		Type:                  entity.PromotionTypeFixedAmount,
+		Amount:                entity.NewMoney(500000),
+		UsageLimitPerCustomer: 1,
+		Active:                true,
+	}
+
+	promotionNotFound = &responses.LocalError{
+		Code:    responses.NOT_FOUND_ERROR,
+		Message: "Promotion not found",
+	}
+
+	orderByPaymentNotFound = &responses.LocalError{
+		Code:    responses.NOT_FOUND_ERROR,
+		Message: "Order not found",
+	}
+
+	customerNotFound = &responses.LocalError{
+		Code:    responses.NOT_FOUND_ERROR,
+		Message: "record not found",
+	}
+
+	loyaltyTransactions = []dto.LoyaltyTransactionResponse{
+		{Id: 2, Type: entity.LoyaltyTransactionRedeem, Points: -100, Description: "Resgate no pedido 2"},
+		{Id: 1, Type: entity.LoyaltyTransactionEarn, Points: 350, Description: "Pontos do pedido 1"},
+	}
+
+	creditPaymentDetails = dto.PaymentDetails{
+		PaymentId:        1,
+		TotalPrice:       entity.NewMoney(1250),
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentType:      entity.PaymentCreditType,
+		PaymentGatewayId: "1234",
+	}
+
+	qrCodePaymentDetails = dto.PaymentDetails{
+		PaymentId:        1,
+		TotalPrice:       entity.NewMoney(1250),
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentType:      entity.PaymentQRCodeType,
+		PaymentGatewayId: "9876",
+	}
+
+	payingPaymentDetails = dto.PaymentDetails{
+		PaymentId:     1,
+		TotalPrice:    entity.NewMoney(1250),
+		PaymentStatus: entity.PaymentPayingStatus,
+		PaymentType:   entity.PaymentQRCodeType,
+	}
+
 	productCreation = dto.ProductForm{
 		Name:        "Name",
 		Description: "Description",
 		Category:    "Category",
-		Price:       23456,
+		Price:       entity.NewMoney(2345600),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "imageUrl",
@@ -131,7 +285,7 @@ var (
 		Name:        "Name",
 		Description: "Description",
 		Category:    "Category",
-		Price:       23456,
+		Price:       entity.NewMoney(2345600),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "imageUrl",
@@ -145,7 +299,7 @@ var (
 			Name:        "Name",
 			Description: "Description",
 			Category:    "Category",
-			Price:       23456,
+			Price:       entity.NewMoney(2345600),
 			Images: []dto.ProducImage{
 				{
 					ImageUrl: "imageUrl",
@@ -157,7 +311,7 @@ var (
 			Name:        "Name 2",
 			Description: "Description 2",
 			Category:    "Category 2",
-			Price:       23456,
+			Price:       entity.NewMoney(2345600),
 			Images: []dto.ProducImage{
 				{
 					ImageUrl: "imageUrl",
@@ -169,7 +323,7 @@ var (
 			Name:        "Name 3",
 			Description: "Description 3",
 			Category:    "Category 3",
-			Price:       34567,
+			Price:       entity.NewMoney(3456700),
 			Images: []dto.ProducImage{
 				{
 					ImageUrl: "imageUrl",
@@ -178,12 +332,41 @@ var (
 		},
 	}
 
+	orderedSnack = dto.ProductResponse{
+		Id:       uint(1),
+		Name:     "Snack",
+		Category: "Lanche",
+		Price:    entity.NewMoney(1000000),
+	}
+
+	orderedBeverage = dto.ProductResponse{
+		Id:       uint(2),
+		Name:     "Beverage",
+		Category: "Bebida",
+		Price:    entity.NewMoney(234500),
+	}
+
+	orderedTopping = dto.ProductResponse{
+		Id:       uint(4),
+		Name:     "Bacon",
+		Category: "Acompanhamento",
+		Price:    entity.NewMoney(50000),
+	}
+
+	orderedCombo = dto.ProductResponse{
+		Id:            uint(3),
+		Name:          "Combo",
+		Category:      "Combo",
+		Price:         entity.NewMoney(999000),
+		ComboProducts: &[]dto.ProductResponse{orderedSnack, orderedBeverage},
+	}
+
 	productById = dto.ProductResponse{
 		Id:          uint(12),
 		Name:        "Name",
 		Description: "Description",
 		Category:    "Category",
-		Price:       23456,
+		Price:       entity.NewMoney(2345600),
 		Images: []dto.ProducImage{
 			{
 				ImageUrl: "imageUrl",
@@ -196,6 +379,10 @@ type MockOrderRepository struct {
 	mock.Mock
 }
 
+type MockOrderEventRepository struct {
+	mock.Mock
+}
+
 type MockCustomerRepository struct {
 	mock.Mock
 }
@@ -204,7 +391,15 @@ type MockPaymentRepository struct {
 	mock.Mock
 }
 
-type MockPaymentGatewayRepository struct {
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+type MockPaymentProviderRegistry struct {
+	mock.Mock
+}
+
+type MockQRCodePaymentRepository struct {
 	mock.Mock
 }
 
@@ -212,26 +407,120 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (mock *MockCustomerRepository) CreateCustomer(ctx context.Context, customer dto.Customer) (uint, error) {
-	args := mock.Called(ctx, customer)
+type MockPromotionRepository struct {
+	mock.Mock
+}
+
+type MockLoyaltyRepository struct {
+	mock.Mock
+}
+
+type MockReportRepository struct {
+	mock.Mock
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+type MockAuthRepository struct {
+	mock.Mock
+}
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+type MockLoginCodeNotifier struct {
+	mock.Mock
+}
+
+type MockPrivacyRepository struct {
+	mock.Mock
+}
+
+func (mock *MockOrderRepository) GetOrders(ctx context.Context, filter dto.OrderFilter) (dto.OrderPage, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderPage{}, err
+	}
+
+	return args.Get(0).(dto.OrderPage), nil
+}
+
+func (mock *MockOrderRepository) GetCustomerOrders(ctx context.Context, filter dto.CustomerOrdersFilter) (dto.OrderPage, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderPage{}, err
+	}
+
+	return args.Get(0).(dto.OrderPage), nil
+}
+
+func (mock *MockOrderRepository) MergeGuestOrders(ctx context.Context, guestID string, customerID uint) (int, error) {
+	args := mock.Called(ctx, guestID, customerID)
 	err := args.Error(1)
 
 	if err != nil {
 		return 0, err
 	}
 
-	return args.Get(0).(uint), nil
+	return args.Get(0).(int), nil
 }
 
-func (mock *MockCustomerRepository) Login(ctx context.Context, cpf string) (string, error) {
-	args := mock.Called(ctx, cpf)
+func (mock *MockOrderEventRepository) Publish(ctx context.Context, event dto.OrderEvent) error {
+	args := mock.Called(ctx, event)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockOrderEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := mock.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockOrderEventRepository) GetEventsAfter(ctx context.Context, orderID *uint, lastEventID int64) ([]dto.OrderEvent, error) {
+	args := mock.Called(ctx, orderID, lastEventID)
 	err := args.Error(1)
 
 	if err != nil {
-		return "", err
+		return []dto.OrderEvent{}, err
 	}
 
-	return args.Get(0).(string), nil
+	return args.Get(0).([]dto.OrderEvent), nil
+}
+
+func (mock *MockOrderEventRepository) Subscribe(orderID *uint) (<-chan dto.OrderEvent, func()) {
+	args := mock.Called(orderID)
+	return args.Get(0).(chan dto.OrderEvent), args.Get(1).(func())
+}
+
+func (mock *MockCustomerRepository) CreateCustomer(ctx context.Context, customer dto.Customer) (uint, error) {
+	args := mock.Called(ctx, customer)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
 }
 
 func (mock *MockCustomerRepository) LoginUnknown() (string, error) {
@@ -311,19 +600,35 @@ func (mock *MockOrderRepository) DeleteOrder(ctx context.Context, orderID uint)
 	return nil
 }
 
-func (mock *MockOrderRepository) FinishOrderWithPayment(ctx context.Context, orderID uint, paymentID uint) error {
-	args := mock.Called(ctx, orderID, paymentID)
-	err := args.Error(0)
+func (mock *MockOrderRepository) FinishOrderPayment(ctx context.Context, result dto.OrderPaymentResult) (bool, error) {
+	args := mock.Called(ctx, result)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mock *MockOrderRepository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
+	args := mock.Called(ctx, orderId)
+	err := args.Error(1)
 
 	if err != nil {
-		return err
+		return dto.OrderResponse{}, err
 	}
 
-	return nil
+	return args.Get(0).(dto.OrderResponse), nil
 }
 
-func (mock *MockOrderRepository) GetOrderById(ctx context.Context, orderId uint) (dto.OrderResponse, error) {
-	args := mock.Called(ctx, orderId)
+func (mock *MockOrderRepository) GetOrderOwner(ctx context.Context, orderID uint) (dto.ResourceOwner, error) {
+	args := mock.Called(ctx, orderID)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.ResourceOwner{}, err
+	}
+
+	return args.Get(0).(dto.ResourceOwner), nil
+}
+
+func (mock *MockOrderRepository) GetOrderByPaymentId(ctx context.Context, paymentId uint) (dto.OrderResponse, error) {
+	args := mock.Called(ctx, paymentId)
 	err := args.Error(1)
 
 	if err != nil {
@@ -366,19 +671,19 @@ func (mock *MockOrderRepository) GetOrdersWaitingPayment(ctx context.Context) ([
 	return args.Get(0).([]dto.OrderResponse), nil
 }
 
-func (mock *MockOrderRepository) UpdateToPreparing(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
-	err := args.Error(0)
+func (mock *MockOrderRepository) GetOrdersWaitingPaymentByType(ctx context.Context, paymentType string) ([]dto.OrderResponse, error) {
+	args := mock.Called(ctx, paymentType)
+	err := args.Error(1)
 
 	if err != nil {
-		return err
+		return []dto.OrderResponse{}, err
 	}
 
-	return nil
+	return args.Get(0).([]dto.OrderResponse), nil
 }
 
-func (mock *MockOrderRepository) UpdateToDone(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
+func (mock *MockOrderRepository) UpdateOrderStatus(ctx context.Context, transition dto.OrderStatusTransition) error {
+	args := mock.Called(ctx, transition)
 	err := args.Error(0)
 
 	if err != nil {
@@ -388,31 +693,26 @@ func (mock *MockOrderRepository) UpdateToDone(ctx context.Context, orderId uint)
 	return nil
 }
 
-func (mock *MockOrderRepository) UpdateToDelivered(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
-	err := args.Error(0)
+func (mock *MockOrderRepository) GetOrderStatusHistory(ctx context.Context, orderID uint) ([]dto.OrderStatusHistoryResponse, error) {
+	args := mock.Called(ctx, orderID)
+	err := args.Error(1)
 
 	if err != nil {
-		return err
+		return []dto.OrderStatusHistoryResponse{}, err
 	}
 
-	return nil
+	return args.Get(0).([]dto.OrderStatusHistoryResponse), nil
 }
 
-func (mock *MockOrderRepository) UpdateToNotDelivered(ctx context.Context, orderId uint) error {
-	args := mock.Called(ctx, orderId)
-	err := args.Error(0)
+func (mock *MockOrderRepository) GetNextTicketNumber(ctx context.Context, date int64) (int, error) {
+	args := mock.Called(ctx, date)
+	err := args.Error(1)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
-}
-
-func (mock *MockOrderRepository) GetNextTicketNumber(ctx context.Context, date int64) int {
-	args := mock.Called(ctx, date)
-	return args.Get(0).(int)
+	return args.Get(0).(int), nil
 }
 
 func (mock *MockPaymentRepository) GetPaymentTypes() []string {
@@ -431,8 +731,30 @@ func (mock *MockPaymentRepository) CreatePaymentOrder(ctx context.Context, payme
 	return args.Get(0).(dto.PaymentResponse), nil
 }
 
-func (mock *MockPaymentRepository) FinishPaymentWithSuccess(ctx context.Context, paymentId uint) error {
+func (mock *MockPaymentRepository) GetPaymentOwner(ctx context.Context, paymentId uint) (dto.ResourceOwner, error) {
 	args := mock.Called(ctx, paymentId)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.ResourceOwner{}, err
+	}
+
+	return args.Get(0).(dto.ResourceOwner), nil
+}
+
+func (mock *MockPaymentRepository) GetPaymentById(ctx context.Context, paymentId uint) (dto.PaymentDetails, error) {
+	args := mock.Called(ctx, paymentId)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PaymentDetails{}, err
+	}
+
+	return args.Get(0).(dto.PaymentDetails), nil
+}
+
+func (mock *MockPaymentRepository) FinishPaymentWithSuccess(ctx context.Context, paymentId uint, gatewayPaymentId string) error {
+	args := mock.Called(ctx, paymentId, gatewayPaymentId)
 	err := args.Error(0)
 
 	if err != nil {
@@ -453,8 +775,57 @@ func (mock *MockPaymentRepository) FinishPaymentWithError(ctx context.Context, p
 	return nil
 }
 
-func (mock *MockPaymentGatewayRepository) Pay(paymentResonse dto.PaymentResponse, payment dto.Payment) (dto.PaymentGatewayResponse, error) {
-	args := mock.Called(paymentResonse, payment)
+func (mock *MockPaymentRepository) RefundPayment(ctx context.Context, paymentId uint) error {
+	args := mock.Called(ctx, paymentId)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockPaymentRepository) VoidPayment(ctx context.Context, paymentId uint) error {
+	args := mock.Called(ctx, paymentId)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mock *MockPaymentProvider) Name() string {
+	args := mock.Called()
+	return args.String(0)
+}
+
+func (mock *MockPaymentProvider) Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error) {
+	args := mock.Called(ctx, authorization)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	return args.Get(0).(dto.PaymentGatewayResponse), nil
+}
+
+func (mock *MockPaymentProvider) Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error) {
+	args := mock.Called(ctx, authorized)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	return args.Get(0).(dto.PaymentGatewayResponse), nil
+}
+
+func (mock *MockPaymentProvider) Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	args := mock.Called(ctx, payment)
 	err := args.Error(1)
 
 	if err != nil {
@@ -464,6 +835,94 @@ func (mock *MockPaymentGatewayRepository) Pay(paymentResonse dto.PaymentResponse
 	return args.Get(0).(dto.PaymentGatewayResponse), nil
 }
 
+func (mock *MockPaymentProvider) GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	args := mock.Called(ctx, orderID, payment)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	return args.Get(0).(dto.PaymentGatewayResponse), nil
+}
+
+func (mock *MockPaymentProvider) ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error) {
+	args := mock.Called(ctx, webhook)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PaymentWebhookEvent{}, err
+	}
+
+	return args.Get(0).(dto.PaymentWebhookEvent), nil
+}
+
+func (mock *MockPaymentProviderRegistry) GetProvider(paymentType string) (repository.PaymentProvider, error) {
+	args := mock.Called(paymentType)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(repository.PaymentProvider), nil
+}
+
+func (mock *MockPaymentProviderRegistry) GetProviderByName(name string) (repository.PaymentProvider, error) {
+	args := mock.Called(name)
+	err := args.Error(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(repository.PaymentProvider), nil
+}
+
+func (mock *MockQRCodePaymentRepository) Generate(ctx context.Context, token string, form dto.Order, orderID int) (dto.QRCodeDataResponse, error) {
+	args := mock.Called(ctx, token, form, orderID)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, err
+	}
+
+	return args.Get(0).(dto.QRCodeDataResponse), nil
+}
+
+func (mock *MockQRCodePaymentRepository) GetQRCodePaymentData(ctx context.Context, token string, endpoint string) (dto.ExternalPaymentInformation, error) {
+	args := mock.Called(ctx, token, endpoint)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.ExternalPaymentInformation{}, err
+	}
+
+	return args.Get(0).(dto.ExternalPaymentInformation), nil
+}
+
+func (mock *MockQRCodePaymentRepository) SearchQRCodePaymentData(ctx context.Context, token string, externalReference string) (dto.ExternalPaymentInformation, error) {
+	args := mock.Called(ctx, token, externalReference)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.ExternalPaymentInformation{}, err
+	}
+
+	return args.Get(0).(dto.ExternalPaymentInformation), nil
+}
+
+func (mock *MockQRCodePaymentRepository) Refund(ctx context.Context, token string, gatewayPaymentID string) error {
+	args := mock.Called(ctx, token, gatewayPaymentID)
+	err := args.Error(0)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (mock *MockProductRepository) CreateProduct(ctx context.Context, product dto.ProductForm) (uint, error) {
 	args := mock.Called(ctx, product)
 	err := args.Error(1)
@@ -523,3 +982,332 @@ func (mock *MockProductRepository) GetCategories() []string {
 	args := mock.Called()
 	return args.Get(0).([]string)
 }
+
+func (mock *MockPromotionRepository) CreatePromotion(ctx context.Context, promotion dto.PromotionForm) (uint, error) {
+	args := mock.Called(ctx, promotion)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(uint), nil
+}
+
+func (mock *MockPromotionRepository) GetPromotions(ctx context.Context) ([]dto.PromotionResponse, error) {
+	args := mock.Called(ctx)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.PromotionResponse{}, err
+	}
+
+	return args.Get(0).([]dto.PromotionResponse), nil
+}
+
+func (mock *MockPromotionRepository) GetPromotionById(ctx context.Context, id uint) (dto.PromotionResponse, error) {
+	args := mock.Called(ctx, id)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.PromotionResponse{}, err
+	}
+
+	return args.Get(0).(dto.PromotionResponse), nil
+}
+
+func (mock *MockPromotionRepository) UpdatePromotion(ctx context.Context, promotion dto.PromotionForm) error {
+	args := mock.Called(ctx, promotion)
+	return args.Error(0)
+}
+
+func (mock *MockPromotionRepository) DeletePromotion(ctx context.Context, id uint) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockPromotionRepository) GetActivePromotions(ctx context.Context, couponCode *string) ([]dto.PromotionResponse, error) {
+	args := mock.Called(ctx, couponCode)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.PromotionResponse{}, err
+	}
+
+	return args.Get(0).([]dto.PromotionResponse), nil
+}
+
+func (mock *MockPromotionRepository) CountCustomerUsages(ctx context.Context, promotionID uint, customerID uint) (int64, error) {
+	args := mock.Called(ctx, promotionID, customerID)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(int64), nil
+}
+
+func (mock *MockLoyaltyRepository) GetBalance(ctx context.Context, customerID uint) (int, error) {
+	args := mock.Called(ctx, customerID)
+	err := args.Error(1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return args.Get(0).(int), nil
+}
+
+func (mock *MockLoyaltyRepository) GetTransactions(ctx context.Context, customerID uint) ([]dto.LoyaltyTransactionResponse, error) {
+	args := mock.Called(ctx, customerID)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.LoyaltyTransactionResponse{}, err
+	}
+
+	return args.Get(0).([]dto.LoyaltyTransactionResponse), nil
+}
+
+func (mock *MockReportRepository) GetRevenueByDay(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByDay, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.RevenueByDay{}, err
+	}
+
+	return args.Get(0).([]dto.RevenueByDay), nil
+}
+
+func (mock *MockReportRepository) GetRevenueByCategory(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByCategory, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.RevenueByCategory{}, err
+	}
+
+	return args.Get(0).([]dto.RevenueByCategory), nil
+}
+
+func (mock *MockReportRepository) GetRevenueByProduct(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByProduct, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.RevenueByProduct{}, err
+	}
+
+	return args.Get(0).([]dto.RevenueByProduct), nil
+}
+
+func (mock *MockReportRepository) GetOrderTimes(ctx context.Context, filter dto.ReportFilter) (dto.OrderTimesReport, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.OrderTimesReport{}, err
+	}
+
+	return args.Get(0).(dto.OrderTimesReport), nil
+}
+
+func (mock *MockReportRepository) GetNotDelivered(ctx context.Context, filter dto.ReportFilter) (dto.NotDeliveredReport, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.NotDeliveredReport{}, err
+	}
+
+	return args.Get(0).(dto.NotDeliveredReport), nil
+}
+
+func (mock *MockReportRepository) GetHourlyVolume(ctx context.Context, filter dto.ReportFilter) ([]dto.HourlyVolume, error) {
+	args := mock.Called(ctx, filter)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.HourlyVolume{}, err
+	}
+
+	return args.Get(0).([]dto.HourlyVolume), nil
+}
+
+func (mock *MockOutboxRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]dto.OutboxEvent, error) {
+	args := mock.Called(ctx, limit, lease)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.OutboxEvent{}, err
+	}
+
+	return args.Get(0).([]dto.OutboxEvent), nil
+}
+
+func (mock *MockOutboxRepository) MarkAsPublished(ctx context.Context, eventID uint) error {
+	args := mock.Called(ctx, eventID)
+	return args.Error(0)
+}
+
+func (mock *MockOutboxRepository) MarkAsFailed(ctx context.Context, eventID uint, nextAttemptAt time.Time, reason string) error {
+	args := mock.Called(ctx, eventID, nextAttemptAt, reason)
+	return args.Error(0)
+}
+
+func (mock *MockEventPublisher) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	args := mock.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (mock *MockIdempotencyRepository) Reserve(
+	ctx context.Context,
+	request dto.IdempotentRequest,
+	expiresAt time.Time,
+	lockTimeout time.Duration,
+) (bool, error) {
+	args := mock.Called(ctx, request, expiresAt, lockTimeout)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mock *MockIdempotencyRepository) GetIdempotencyKey(ctx context.Context, scope string, key string) (dto.IdempotencyKey, error) {
+	args := mock.Called(ctx, scope, key)
+	err := args.Error(1)
+
+	if err != nil {
+		return dto.IdempotencyKey{}, err
+	}
+
+	return args.Get(0).(dto.IdempotencyKey), nil
+}
+
+func (mock *MockIdempotencyRepository) SaveResponse(ctx context.Context, request dto.IdempotentRequest, response dto.IdempotentResponse) error {
+	args := mock.Called(ctx, request, response)
+	return args.Error(0)
+}
+
+func (mock *MockIdempotencyRepository) Release(ctx context.Context, request dto.IdempotentRequest) error {
+	args := mock.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (mock *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := mock.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockAuthRepository) StartLogin(ctx context.Context, cpf string, code string) (string, error) {
+	args := mock.Called(ctx, cpf, code)
+	return args.String(0), args.Error(1)
+}
+
+func (mock *MockAuthRepository) RespondToLogin(ctx context.Context, form dto.LoginCodeForm) (dto.Token, error) {
+	args := mock.Called(ctx, form)
+	return args.Get(0).(dto.Token), args.Error(1)
+}
+
+func (mock *MockAuthRepository) RefreshToken(ctx context.Context, refreshToken string) (dto.Token, error) {
+	args := mock.Called(ctx, refreshToken)
+	return args.Get(0).(dto.Token), args.Error(1)
+}
+
+func (mock *MockAuthRepository) RevokeToken(ctx context.Context, refreshToken string) error {
+	args := mock.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
+func (mock *MockRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := mock.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (mock *MockRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := mock.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mock *MockRevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := mock.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockPrivacyRepository) GetCustomerPayments(ctx context.Context, customerID uint) ([]dto.PaymentDetails, error) {
+	args := mock.Called(ctx, customerID)
+	err := args.Error(1)
+
+	if err != nil {
+		return []dto.PaymentDetails{}, err
+	}
+
+	return args.Get(0).([]dto.PaymentDetails), nil
+}
+
+func (mock *MockPrivacyRepository) CreateDataSubjectRequest(ctx context.Context, request dto.DataSubjectRequest) error {
+	args := mock.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (mock *MockPrivacyRepository) EraseCustomer(ctx context.Context, customer dto.Customer, request dto.DataSubjectRequest) error {
+	args := mock.Called(ctx, customer, request)
+	return args.Error(0)
+}
+
+func (mock *MockLoginCodeNotifier) SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error) {
+	args := mock.Called(ctx, message)
+	err := args.Error(1)
+
+	if err != nil {
+		return "", err
+	}
+
+	return args.String(0), nil
+}
+
+// applyNoPromotions is used by the orders priced without any promotion
+func applyNoPromotions() *ApplyPromotionsUseCase {
+	mockPromotionRepo := new(MockPromotionRepository)
+	mockPromotionRepo.On("GetActivePromotions", mock.Anything, mock.Anything).Return([]dto.PromotionResponse{}, nil)
+
+	return NewApplyPromotionsUseCase(mockPromotionRepo, NewVerifyCustomerTokenUseCase(new(MockCustomerRepository)))
+}
+
+// applyNoLoyaltyPoints is used by the orders which do not redeem points, so the balance is never read
+func applyNoLoyaltyPoints() *ApplyLoyaltyPointsUseCase {
+	return NewApplyLoyaltyPointsUseCase(new(MockLoyaltyRepository), NewVerifyCustomerTokenUseCase(new(MockCustomerRepository)))
+}
+
+func signWebhook(secret string, manifest string, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(manifest))
+
+	return "ts=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const (
+	accessTokenKeyID    = "test-key"
+	accessTokenIssuer   = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test"
+	accessTokenClientID = "test-client"
+)
+
+var accessTokenKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+func validAccessTokenClaims(username string, groups ...string) entity.AccessTokenClaims {
+	return entity.AccessTokenClaims{
+		Subject:   "8c1d5f3e-sub",
+		Username:  username,
+		OriginJTI: "4f2a9c1b-origin",
+		Groups:    groups,
+		TokenUse:  entity.AccessTokenUse,
+		ClientID:  accessTokenClientID,
+		Issuer:    accessTokenIssuer,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		IssuedAt:  time.Now().Unix(),
+	}
+}
+
+func signAccessToken(key *rsa.PrivateKey, keyID string, claims entity.AccessTokenClaims) string {
+	token, _ := claims.Sign(key, keyID)
+	return token
+}
@@ -2,40 +2,67 @@ package usecases
 
 import (
 	"context"
-	"sync"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
 type CreateOrderUseCase struct {
-	orderRepo                repository.OrderRepository
-	customerRepo             repository.CustomerRepository
-	validateToPrepare        *ValidateOrderToPrepareUseCase
-	validateToDone           *ValidateOrderToDoneUseCase
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
-	sortOrderUseCase         *SortOrdersUseCase
+	orderRepo           repository.OrderRepository
+	orderEvents         repository.OrderEventRepository
+	customerRepo        repository.CustomerRepository
+	paymentRepo         repository.PaymentRepository
+	calculateOrderPrice *CalculateOrderPriceUseCase
+	applyPromotions     *ApplyPromotionsUseCase
+	applyLoyaltyPoints  *ApplyLoyaltyPointsUseCase
+	sortOrderUseCase    *SortOrdersUseCase
 }
 
 type UpdateToPreparingUseCase struct {
-	orderRepo         repository.OrderRepository
-	validateToPrepare *ValidateOrderToPrepareUseCase
+	orderRepo          repository.OrderRepository
+	orderEvents        repository.OrderEventRepository
+	validateTransition *ValidateOrderTransitionUseCase
 }
 
 type UpdateToDoneUseCase struct {
-	orderRepo      repository.OrderRepository
-	validateToDone *ValidateOrderToDoneUseCase
+	orderRepo          repository.OrderRepository
+	orderEvents        repository.OrderEventRepository
+	validateTransition *ValidateOrderTransitionUseCase
 }
 
 type UpdateToDeliveredUseCase struct {
-	orderRepo                repository.OrderRepository
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
+	orderRepo          repository.OrderRepository
+	orderEvents        repository.OrderEventRepository
+	validateTransition *ValidateOrderTransitionUseCase
 }
 
 type UpdateToNotDeliveredUseCase struct {
-	orderRepo                repository.OrderRepository
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase
+	orderRepo          repository.OrderRepository
+	orderEvents        repository.OrderEventRepository
+	validateTransition *ValidateOrderTransitionUseCase
+}
+
+type CancelOrderUseCase struct {
+	orderRepo      repository.OrderRepository
+	orderEvents    repository.OrderEventRepository
+	refundPayments *RefundOrderPaymentsUseCase
+	stateMachine   *entity.OrderStateMachine
+}
+
+type GetOrdersUseCase struct {
+	orderRepo    repository.OrderRepository
+	stateMachine *entity.OrderStateMachine
+}
+
+type GetOrderStatusHistoryUseCase struct {
+	orderRepo repository.OrderRepository
 }
 
 type GetOrderByIdUseCase struct {
@@ -59,19 +86,23 @@ type GetOrdersWaitingPaymentUseCase struct {
 
 func NewCreateOrderUseCase(
 	orderRepo repository.OrderRepository,
+	orderEvents repository.OrderEventRepository,
 	customerRepo repository.CustomerRepository,
-	validateToPrepate *ValidateOrderToPrepareUseCase,
-	validateToDone *ValidateOrderToDoneUseCase,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	paymentRepo repository.PaymentRepository,
+	calculateOrderPrice *CalculateOrderPriceUseCase,
+	applyPromotions *ApplyPromotionsUseCase,
+	applyLoyaltyPoints *ApplyLoyaltyPointsUseCase,
 	sortOrderUseCase *SortOrdersUseCase,
 ) *CreateOrderUseCase {
 	return &CreateOrderUseCase{
-		orderRepo:                orderRepo,
-		customerRepo:             customerRepo,
-		validateToPrepare:        validateToPrepate,
-		validateToDone:           validateToDone,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
-		sortOrderUseCase:         sortOrderUseCase,
+		orderRepo:           orderRepo,
+		orderEvents:         orderEvents,
+		customerRepo:        customerRepo,
+		paymentRepo:         paymentRepo,
+		calculateOrderPrice: calculateOrderPrice,
+		applyPromotions:     applyPromotions,
+		applyLoyaltyPoints:  applyLoyaltyPoints,
+		sortOrderUseCase:    sortOrderUseCase,
 	}
 }
 
@@ -115,49 +146,119 @@ func NewGetOrdersWaitingPaymentUseCase(
 
 func NewUpdateToPreparingUseCase(
 	orderRepo repository.OrderRepository,
-	validateToPrepare *ValidateOrderToPrepareUseCase,
+	orderEvents repository.OrderEventRepository,
+	validateTransition *ValidateOrderTransitionUseCase,
 ) *UpdateToPreparingUseCase {
 	return &UpdateToPreparingUseCase{
-		orderRepo:         orderRepo,
-		validateToPrepare: validateToPrepare,
+		orderRepo:          orderRepo,
+		orderEvents:        orderEvents,
+		validateTransition: validateTransition,
 	}
 }
 
 func NewUpdateToDoneUseCase(
 	orderRepo repository.OrderRepository,
-	validateToDone *ValidateOrderToDoneUseCase,
+	orderEvents repository.OrderEventRepository,
+	validateTransition *ValidateOrderTransitionUseCase,
 ) *UpdateToDoneUseCase {
 	return &UpdateToDoneUseCase{
-		orderRepo:      orderRepo,
-		validateToDone: validateToDone,
+		orderRepo:          orderRepo,
+		orderEvents:        orderEvents,
+		validateTransition: validateTransition,
 	}
 }
 
 func NewUpdateToDeliveredUseCase(
 	orderRepo repository.OrderRepository,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	orderEvents repository.OrderEventRepository,
+	validateTransition *ValidateOrderTransitionUseCase,
 ) *UpdateToDeliveredUseCase {
 	return &UpdateToDeliveredUseCase{
-		orderRepo:                orderRepo,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
+		orderRepo:          orderRepo,
+		orderEvents:        orderEvents,
+		validateTransition: validateTransition,
 	}
 }
 
 func NewUpdateToNotDeliveredUseCase(
 	orderRepo repository.OrderRepository,
-	validateToDeliveredOrNot *ValidateOrderToDeliveredOrNotUseCase,
+	orderEvents repository.OrderEventRepository,
+	validateTransition *ValidateOrderTransitionUseCase,
 ) *UpdateToNotDeliveredUseCase {
 	return &UpdateToNotDeliveredUseCase{
-		orderRepo:                orderRepo,
-		validateToDeliveredOrNot: validateToDeliveredOrNot,
+		orderRepo:          orderRepo,
+		orderEvents:        orderEvents,
+		validateTransition: validateTransition,
 	}
 }
 
-func (usecase *CreateOrderUseCase) Execute(ctx context.Context, order dto.Order, date int64, wg *sync.WaitGroup, ch chan bool) (dto.OrderResponse, error) {
-	//Block this code below until this Channel be empty (by reading with <-ch)
-	ch <- true
+func NewCancelOrderUseCase(
+	orderRepo repository.OrderRepository,
+	orderEvents repository.OrderEventRepository,
+	refundPayments *RefundOrderPaymentsUseCase,
+	stateMachine *entity.OrderStateMachine,
+) *CancelOrderUseCase {
+	return &CancelOrderUseCase{
+		orderRepo:      orderRepo,
+		orderEvents:    orderEvents,
+		refundPayments: refundPayments,
+		stateMachine:   stateMachine,
+	}
+}
+
+func NewGetOrdersUseCase(
+	orderRepo repository.OrderRepository,
+	stateMachine *entity.OrderStateMachine,
+) *GetOrdersUseCase {
+	return &GetOrdersUseCase{
+		orderRepo:    orderRepo,
+		stateMachine: stateMachine,
+	}
+}
+
+func NewGetOrderStatusHistoryUseCase(orderRepo repository.OrderRepository) *GetOrderStatusHistoryUseCase {
+	return &GetOrderStatusHistoryUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+func (usecase *CreateOrderUseCase) Execute(ctx context.Context, order dto.Order, date int64) (dto.OrderResponse, error) {
+	orderProducts, totalPrice, err := usecase.calculateOrderPrice.Execute(ctx, order.OrderProduct, order.TotalPrice)
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CalculateOrderPrice")
+	}
+
+	discounts, totalPrice, err := usecase.applyPromotions.Execute(ctx, orderProducts, totalPrice, order.CustomerID, order.AuthenticatedCPF, order.CouponCode)
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> ApplyPromotions")
+	}
+
+	loyaltyDiscount, err := usecase.applyLoyaltyPoints.Execute(ctx, order.CustomerID, order.AuthenticatedCPF, order.LoyaltyPoints, totalPrice)
+
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> ApplyLoyaltyPoints")
+	}
+
+	order.OrderProduct = orderProducts
+	order.TotalPrice = totalPrice.Sub(loyaltyDiscount)
+	order.Discounts = discounts
+	order.LoyaltyDiscount = loyaltyDiscount
+
+	err = usecase.validatePayments(ctx, order)
+
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+
+	ticketNumber, err := usecase.GenerateTicket(ctx, date)
 
-	order.TicketNumber = usecase.GenerateTicket(ctx, date)
+	if err != nil {
+		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> GenerateTicket")
+	}
+
+	order.TicketNumber = ticketNumber
 
 	response, err := usecase.orderRepo.CreateOrder(ctx, order)
 
@@ -165,6 +266,12 @@ func (usecase *CreateOrderUseCase) Execute(ctx context.Context, order dto.Order,
 		return dto.OrderResponse{}, responses.GetResponseError(err, "OrderService -> CreateOrder")
 	}
 
+	publishOrderEvent(ctx, usecase.orderEvents, dto.OrderStatusTransition{
+		OrderID:  response.OrderId,
+		ToStatus: entity.OrderStatusCreated,
+		Actor:    entity.OrderActorCustomer,
+	})
+
 	if order.CustomerID != nil {
 		customer, err := usecase.customerRepo.GetCustomerById(ctx, *order.CustomerID)
 		if err == nil {
@@ -172,14 +279,93 @@ func (usecase *CreateOrderUseCase) Execute(ctx context.Context, order dto.Order,
 		}
 	}
 
-	// Release the channel to others process be able to start a new order creation
-	<-ch
-	wg.Done()
-
 	return response, nil
 }
 
-func (usecase *CreateOrderUseCase) GenerateTicket(ctx context.Context, date int64) int {
+// validatePayments accepts only paid payments, not used by another order, whose sum is the order total.
+// Two orders created at the same time with the same payment are also rejected by the database
+func (usecase *CreateOrderUseCase) validatePayments(ctx context.Context, order dto.Order) error {
+	paymentIDs := append([]uint{order.PaymentID}, order.PaymentIDs...)
+
+	paidPrice, err := validatePaidPayments(ctx, usecase.paymentRepo, paymentIDs, "OrderService")
+
+	if err != nil {
+		return err
+	}
+
+	if !paidPrice.Equal(order.TotalPrice) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The payments total %v is not the order total %v", paidPrice, order.TotalPrice),
+		}
+	}
+
+	return validateUnusedPayments(ctx, usecase.orderRepo, paymentIDs, "OrderService")
+}
+
+// validatePaidPayments checks if each part of a split order is paid and returns the paid sum
+func validatePaidPayments(
+	ctx context.Context,
+	paymentRepo repository.PaymentRepository,
+	paymentIDs []uint,
+	service string,
+) (entity.Money, error) {
+	paidPrice := entity.NewMoney(0)
+
+	for index, paymentID := range paymentIDs {
+		if slices.Contains(paymentIDs[:index], paymentID) {
+			return entity.Money{}, &responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("The payment %v was sent more than once", paymentID),
+			}
+		}
+
+		payment, err := paymentRepo.GetPaymentById(ctx, paymentID)
+
+		if err != nil {
+			return entity.Money{}, responses.GetResponseError(err, fmt.Sprintf("%v -> GetPaymentById", service))
+		}
+
+		if payment.PaymentStatus != entity.PaymentPayedStatus {
+			return entity.Money{}, &responses.BusinessResponse{
+				StatusCode: http.StatusPaymentRequired,
+				Message:    fmt.Sprintf("The payment %v is not paid. Its status is %v", payment.PaymentId, payment.PaymentStatus),
+			}
+		}
+
+		paidPrice = paidPrice.Add(payment.TotalPrice)
+	}
+
+	return paidPrice, nil
+}
+
+func validateUnusedPayments(
+	ctx context.Context,
+	orderRepo repository.OrderRepository,
+	paymentIDs []uint,
+	service string,
+) error {
+	for _, paymentID := range paymentIDs {
+		paidOrder, err := orderRepo.GetOrderByPaymentId(ctx, paymentID)
+
+		if err == nil {
+			return &responses.BusinessResponse{
+				StatusCode: http.StatusConflict,
+				Message:    fmt.Sprintf("The payment %v was already used by the order %v", paymentID, paidOrder.OrderId),
+			}
+		}
+
+		var localError *responses.LocalError
+
+		if !errors.As(err, &localError) || localError.Code != responses.NOT_FOUND_ERROR {
+			return responses.GetResponseError(err, fmt.Sprintf("%v -> GetOrderByPaymentId", service))
+		}
+	}
+
+	return nil
+}
+
+func (usecase *CreateOrderUseCase) GenerateTicket(ctx context.Context, date int64) (int, error) {
 	return usecase.orderRepo.GetNextTicketNumber(ctx, date)
 }
 
@@ -230,65 +416,221 @@ func (usecase *GetOrdersWaitingPaymentUseCase) Execute(ctx context.Context) ([]d
 }
 
 func (usecase *UpdateToPreparingUseCase) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToPrepare.Execute(ctx, orderId)
+	transition, err := usecase.validateTransition.Execute(ctx, orderId, entity.OrderStatusPreparing)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToPreparing")
 	}
 
-	err = usecase.orderRepo.UpdateToPreparing(ctx, orderId)
+	transition.Actor = entity.OrderActorKitchen
+	transition.Reason = "The kitchen started preparing the order"
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, transition)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToPreparing")
 	}
 
+	publishOrderEvent(ctx, usecase.orderEvents, transition)
+
 	return nil
 }
 
 func (usecase *UpdateToDoneUseCase) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDone.Execute(ctx, orderId)
+	transition, err := usecase.validateTransition.Execute(ctx, orderId, entity.OrderStatusDone)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToDone")
 	}
 
-	err = usecase.orderRepo.UpdateToDone(ctx, orderId)
+	transition.Actor = entity.OrderActorKitchen
+	transition.Reason = "The kitchen finished the order"
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, transition)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToDone")
 	}
 
+	publishOrderEvent(ctx, usecase.orderEvents, transition)
+
 	return nil
 }
 
 func (usecase *UpdateToDeliveredUseCase) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDeliveredOrNot.Execute(ctx, orderId)
+	transition, err := usecase.validateTransition.Execute(ctx, orderId, entity.OrderStatusDelivered)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToDelivered")
 	}
 
-	err = usecase.orderRepo.UpdateToDelivered(ctx, orderId)
+	transition.Actor = entity.OrderActorWaiter
+	transition.Reason = "The customer got the order"
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, transition)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToDelivered")
 	}
 
+	publishOrderEvent(ctx, usecase.orderEvents, transition)
+
 	return nil
 }
 
 func (usecase *UpdateToNotDeliveredUseCase) Execute(ctx context.Context, orderId uint) error {
-	err := usecase.validateToDeliveredOrNot.Execute(ctx, orderId)
+	transition, err := usecase.validateTransition.Execute(ctx, orderId, entity.OrderStatusNotDelivered)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToNotDelivered")
 	}
 
-	err = usecase.orderRepo.UpdateToNotDelivered(ctx, orderId)
+	transition.Actor = entity.OrderActorWaiter
+	transition.Reason = "The customer did not get the order"
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, transition)
 
 	if err != nil {
 		return responses.GetResponseError(err, "OrderService -> UpdateToNotDelivered")
 	}
 
+	publishOrderEvent(ctx, usecase.orderEvents, transition)
+
+	return nil
+}
+
+// Execute reverses every order payment before moving the order to Cancelado. If a payment
+// was already reversed by a previous attempt, only the other ones will be reversed
+func (usecase *CancelOrderUseCase) Execute(ctx context.Context, orderId uint, reason string) error {
+	order, err := usecase.orderRepo.GetOrderById(ctx, orderId)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	// The paid parts of a split order canceled by its QR Code may not have been refunded yet
+	if order.OrderStatus == entity.OrderStatusCanceled {
+		err = usecase.refundPayments.Execute(ctx, orderPaymentIDs(order))
+
+		if err != nil {
+			return responses.GetResponseError(err, "OrderService -> CancelOrder")
+		}
+
+		return nil
+	}
+
+	err = usecase.stateMachine.Validate(order.OrderStatus, entity.OrderStatusCanceled)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	err = usecase.refundPayments.Execute(ctx, orderPaymentIDs(order))
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	transition := dto.OrderStatusTransition{
+		OrderID:    orderId,
+		FromStatus: order.OrderStatus,
+		ToStatus:   entity.OrderStatusCanceled,
+		Actor:      entity.OrderActorSystem,
+		Reason:     reason,
+	}
+
+	err = usecase.orderRepo.UpdateOrderStatus(ctx, transition)
+
+	if err != nil {
+		return responses.GetResponseError(err, "OrderService -> CancelOrder")
+	}
+
+	publishOrderEvent(ctx, usecase.orderEvents, transition)
+
+	return nil
+}
+
+// orderPaymentIDs returns the main payment first and then the other parts of a split order
+func orderPaymentIDs(order dto.OrderResponse) []uint {
+	paymentIDs := []uint{order.PaymentID}
+
+	for _, payment := range order.Payments {
+		if payment.PaymentID != order.PaymentID {
+			paymentIDs = append(paymentIDs, payment.PaymentID)
+		}
+	}
+
+	return paymentIDs
+}
+
+func (usecase *GetOrdersUseCase) Execute(ctx context.Context, filter dto.OrderFilter) (dto.OrderPage, error) {
+	err := usecase.validateFilter(&filter)
+
+	if err != nil {
+		return dto.OrderPage{}, err
+	}
+
+	response, err := usecase.orderRepo.GetOrders(ctx, filter)
+
+	if err != nil {
+		return dto.OrderPage{}, responses.GetResponseError(err, "OrderService -> GetOrders")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetOrdersUseCase) validateFilter(filter *dto.OrderFilter) error {
+	for _, status := range filter.Statuses {
+		if !usecase.stateMachine.IsKnownStatus(status) {
+			return &responses.BusinessResponse{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("The order status %v is not valid", status),
+			}
+		}
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = entity.OrderSortCreatedAt
+	}
+
+	if !slices.Contains(entity.OrderSortOptions, filter.SortBy) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message: fmt.Sprintf(
+				"The sort %v is not valid. Use %v",
+				filter.SortBy,
+				strings.Join(entity.OrderSortOptions, ", "),
+			),
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = entity.OrderPageDefaultLimit
+	}
+
+	if filter.Limit < 0 || filter.Limit > entity.OrderPageMaxLimit {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The limit must be between 1 and %v", entity.OrderPageMaxLimit),
+		}
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "The createdFrom date must be before the createdTo date",
+		}
+	}
+
 	return nil
 }
+
+func (usecase *GetOrderStatusHistoryUseCase) Execute(ctx context.Context, orderId uint) ([]dto.OrderStatusHistoryResponse, error) {
+	response, err := usecase.orderRepo.GetOrderStatusHistory(ctx, orderId)
+
+	if err != nil {
+		return []dto.OrderStatusHistoryResponse{}, responses.GetResponseError(err, "OrderService -> GetOrderStatusHistory")
+	}
+
+	return response, nil
+}
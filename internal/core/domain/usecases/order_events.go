@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type StreamOrderEventsUseCase struct {
+	orderRepo   repository.OrderRepository
+	orderEvents repository.OrderEventRepository
+}
+
+type DeleteExpiredOrderEventsUseCase struct {
+	orderEvents repository.OrderEventRepository
+}
+
+func NewStreamOrderEventsUseCase(
+	orderRepo repository.OrderRepository,
+	orderEvents repository.OrderEventRepository,
+) *StreamOrderEventsUseCase {
+	return &StreamOrderEventsUseCase{
+		orderRepo:   orderRepo,
+		orderEvents: orderEvents,
+	}
+}
+
+func NewDeleteExpiredOrderEventsUseCase(orderEvents repository.OrderEventRepository) *DeleteExpiredOrderEventsUseCase {
+	return &DeleteExpiredOrderEventsUseCase{
+		orderEvents: orderEvents,
+	}
+}
+
+// Execute streams the order events until the context is done. When the orderID is nil, the events
+// of all the orders are streamed. If the client informs the lastEventID, the events it missed
+// are sent before the live ones
+func (usecase *StreamOrderEventsUseCase) Execute(ctx context.Context, orderID *uint, lastEventID int64) (<-chan dto.OrderEvent, error) {
+	if orderID != nil {
+		_, err := usecase.orderRepo.GetOrderById(ctx, *orderID)
+
+		if err != nil {
+			return nil, responses.GetResponseError(err, "OrderEventsService -> GetOrderById")
+		}
+	}
+
+	// Subscribe before the replay, so no event is lost between them
+	live, unsubscribe := usecase.orderEvents.Subscribe(orderID)
+
+	replay := []dto.OrderEvent{}
+
+	if lastEventID > 0 {
+		events, err := usecase.orderEvents.GetEventsAfter(ctx, orderID, lastEventID)
+
+		if err != nil {
+			unsubscribe()
+			return nil, responses.GetResponseError(err, "OrderEventsService -> GetEventsAfter")
+		}
+
+		replay = events
+	}
+
+	stream := make(chan dto.OrderEvent)
+
+	go func() {
+		defer close(stream)
+		defer unsubscribe()
+
+		replayed := map[int64]bool{}
+
+		for _, event := range replay {
+			replayed[event.EventID] = true
+
+			select {
+			case stream <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+
+				if replayed[event.EventID] {
+					continue
+				}
+
+				select {
+				case stream <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stream, nil
+}
+
+func (usecase *DeleteExpiredOrderEventsUseCase) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := usecase.Execute(ctx)
+
+		if err != nil {
+			log.Print("delete expired order events", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// Execute removes the events older than the OrderEventRetention, so a stream can only replay them
+// for this time after it disconnects
+func (usecase *DeleteExpiredOrderEventsUseCase) Execute(ctx context.Context) (int64, error) {
+	deleted, err := usecase.orderEvents.DeleteBefore(ctx, time.Now().Add(-entity.OrderEventRetention))
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "OrderEventsService -> DeleteBefore")
+	}
+
+	return deleted, nil
+}
+
+// publishOrderEvent is called after the status change was committed, so a failure here
+// can not fail the request. The screens will get the status in their next list refresh
+func publishOrderEvent(ctx context.Context, orderEvents repository.OrderEventRepository, transition dto.OrderStatusTransition) {
+	err := orderEvents.Publish(ctx, dto.OrderEvent{
+		OrderID:    transition.OrderID,
+		FromStatus: transition.FromStatus,
+		ToStatus:   transition.ToStatus,
+		Actor:      transition.Actor,
+	})
+
+	if err != nil {
+		log.Print("publish order event", map[string]interface{}{
+			"orderId": transition.OrderID,
+			"error":   err.Error(),
+		})
+	}
+}
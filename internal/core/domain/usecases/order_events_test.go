@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestOrderEventsServices(t *testing.T) {
+	t.Run("got success when streaming missed and live events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewStreamOrderEventsUseCase(mockRepo, mockEventRepo)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		live := make(chan dto.OrderEvent, 2)
+		unsubscribed := make(chan bool, 1)
+
+		mockEventRepo.On("Subscribe", (*uint)(nil)).Return(live, func() {
+			unsubscribed <- true
+		})
+		mockEventRepo.On("GetEventsAfter", ctx, (*uint)(nil), int64(10)).Return([]dto.OrderEvent{
+			{
+				EventID:  11,
+				OrderID:  1,
+				ToStatus: "Preparando",
+			},
+		}, nil)
+
+		// The event 11 was published between the subscription and the replay
+		live <- dto.OrderEvent{EventID: 11, OrderID: 1, ToStatus: "Preparando"}
+		live <- dto.OrderEvent{EventID: 12, OrderID: 2, ToStatus: "Criado"}
+
+		stream, err := sut.Execute(ctx, nil, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), (<-stream).EventID)
+		assert.Equal(t, int64(12), (<-stream).EventID)
+
+		cancel()
+
+		_, ok := <-stream
+		assert.False(t, ok)
+		assert.True(t, <-unsubscribed)
+	})
+
+	t.Run("got success when streaming events without last event id in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewStreamOrderEventsUseCase(mockRepo, mockEventRepo)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		orderId := uint(1)
+		live := make(chan dto.OrderEvent, 1)
+
+		mockRepo.On("GetOrderById", ctx, orderId).Return(orderCreationResponse, nil)
+		mockEventRepo.On("Subscribe", &orderId).Return(live, func() {})
+
+		live <- dto.OrderEvent{EventID: 3, OrderID: 1, ToStatus: "Finalizado"}
+
+		stream, err := sut.Execute(ctx, &orderId, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Finalizado", (<-stream).ToStatus)
+		mockEventRepo.AssertNotCalled(t, "GetEventsAfter")
+	})
+
+	t.Run("got error when streaming events of unknown order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewStreamOrderEventsUseCase(mockRepo, mockEventRepo)
+
+		ctx := context.TODO()
+
+		orderId := uint(99)
+
+		mockRepo.On("GetOrderById", ctx, orderId).Return(dto.OrderResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		stream, err := sut.Execute(ctx, &orderId, 0)
+
+		assert.Error(t, err)
+		assert.Nil(t, stream)
+		mockEventRepo.AssertNotCalled(t, "Subscribe")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got error when replaying missed events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewStreamOrderEventsUseCase(mockRepo, mockEventRepo)
+
+		ctx := context.TODO()
+
+		unsubscribed := false
+
+		mockEventRepo.On("Subscribe", (*uint)(nil)).Return(make(chan dto.OrderEvent), func() {
+			unsubscribed = true
+		})
+		mockEventRepo.On("GetEventsAfter", ctx, (*uint)(nil), int64(10)).Return([]dto.OrderEvent{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		stream, err := sut.Execute(ctx, nil, 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, stream)
+		assert.True(t, unsubscribed)
+	})
+
+	t.Run("got event published when updating order to done in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		sut := NewUpdateToDoneUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+		mockEventRepo.On("Publish", ctx, dto.OrderEvent{
+			OrderID:    uint(1),
+			FromStatus: "Preparando",
+			ToStatus:   "Finalizado",
+			Actor:      entity.OrderActorKitchen,
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+		mockEventRepo.AssertCalled(t, "Publish", ctx, mock.Anything)
+	})
+
+	t.Run("got success when publishing order event fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		sut := NewUpdateToPreparingUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(&responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got old events deleted after the retention in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockEventRepo := new(MockOrderEventRepository)
+		sut := NewDeleteExpiredOrderEventsUseCase(mockEventRepo)
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("DeleteBefore", ctx, mock.MatchedBy(func(before time.Time) bool {
+			return before.Before(time.Now().Add(-entity.OrderEventRetention).Add(time.Second))
+		})).Return(int64(4), nil)
+
+		deleted, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), deleted)
+	})
+}
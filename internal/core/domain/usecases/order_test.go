@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
@@ -19,60 +20,130 @@ func TestOrderServices(t *testing.T) {
 
 		mockRepo := new(MockOrderRepository)
 		mockCustomerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 		sortOrdersUseCase := NewSortOrdersUseCase()
 
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
 		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
 			mockCustomerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
 			sortOrdersUseCase,
 		)
 
+		mockProductRepo.On("GetProductById", mock.Anything, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", mock.Anything, uint(2)).Return(orderedBeverage, nil)
+
 		ctx := context.TODO()
 
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
 		date := time.Now().UnixMilli()
 
 		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
 
-		response := sut.GenerateTicket(ctx, date)
+		response, err := sut.GenerateTicket(ctx, date)
 
+		assert.NoError(t, err)
 		assert.Equal(t, 1, response)
 	})
 
+	t.Run("got error when generating ticket number in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		sortOrdersUseCase := NewSortOrdersUseCase()
+
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
+		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
+			mockCustomerRepo,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			sortOrdersUseCase,
+		)
+
+		mockProductRepo.On("GetProductById", mock.Anything, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", mock.Anything, uint(2)).Return(orderedBeverage, nil)
+
+		ctx := context.TODO()
+
+		date := time.Now().UnixMilli()
+
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockRepo.On("GetNextTicketNumber", ctx, date).Return(0, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		response, err := sut.Execute(ctx, orderCreation, date)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+
 	t.Run("got success when creating order in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
 		mockCustomerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 		sortOrdersUseCase := NewSortOrdersUseCase()
 
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
 		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
 			mockCustomerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
 			sortOrdersUseCase,
 		)
 
+		mockProductRepo.On("GetProductById", mock.Anything, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", mock.Anything, uint(2)).Return(orderedBeverage, nil)
+
 		ctx := context.TODO()
 
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
 		date := time.Now().UnixMilli()
 
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
 		mockRepo.On("CreateOrder", ctx, orderCreation).Return(orderCreationResponse, nil)
 		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
 
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
-
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreation, date, wg, ch)
+		response, err := sut.Execute(ctx, orderCreation, date)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response)
@@ -83,32 +154,41 @@ func TestOrderServices(t *testing.T) {
 
 		mockRepo := new(MockOrderRepository)
 		mockCustomerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 		sortOrdersUseCase := NewSortOrdersUseCase()
 
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
 		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
 			mockCustomerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
 			sortOrdersUseCase,
 		)
 
+		mockProductRepo.On("GetProductById", mock.Anything, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", mock.Anything, uint(2)).Return(orderedBeverage, nil)
+
 		ctx := context.TODO()
 
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
 		date := time.Now().UnixMilli()
 
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
 		mockRepo.On("CreateOrder", ctx, orderCreationWithCustomer).Return(orderWithCustomerCreationResponse, nil)
 		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
 		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
 
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
-
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreationWithCustomer, date, wg, ch)
+		response, err := sut.Execute(ctx, orderCreationWithCustomer, date)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response)
@@ -119,34 +199,41 @@ func TestOrderServices(t *testing.T) {
 
 		mockRepo := new(MockOrderRepository)
 		mockCustomerRepo := new(MockCustomerRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 		sortOrdersUseCase := NewSortOrdersUseCase()
 
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
 		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
 			mockCustomerRepo,
-			validateToPrepare,
-			validateToDone,
-			validateToDeliveredOrNot,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
 			sortOrdersUseCase,
 		)
 
+		mockProductRepo.On("GetProductById", mock.Anything, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", mock.Anything, uint(2)).Return(orderedBeverage, nil)
+
 		ctx := context.TODO()
 
 		date := time.Now().UnixMilli()
 
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
 		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
 		mockRepo.On("CreateOrder", ctx, orderCreationWithCustomer).Return(dto.OrderResponse{}, &responses.NetworkError{
 			Code:    409,
 			Message: "Conflict",
 		})
 
-		wg := &sync.WaitGroup{}
-		ch := make(chan bool, 1)
-
-		wg.Add(1)
-		response, err := sut.Execute(ctx, orderCreationWithCustomer, date, wg, ch)
+		response, err := sut.Execute(ctx, orderCreationWithCustomer, date)
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
@@ -156,89 +243,193 @@ func TestOrderServices(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
 
-	t.Run("got success when getting order by id in services", func(t *testing.T) {
+	t.Run("got error when creating order with tampered total price in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		sortOrdersUseCase := NewSortOrdersUseCase()
+		mockProductRepo := new(MockProductRepository)
+		calculateOrderPrice := NewCalculateOrderPriceUseCase(mockProductRepo)
 
-		sut := NewGetOrderByIdUseCase(mockRepo)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		mockPaymentRepo := new(MockPaymentRepository)
+
+		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
+			mockCustomerRepo,
+			mockPaymentRepo,
+			calculateOrderPrice,
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			sortOrdersUseCase,
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(orderCreationResponse, nil)
+		date := time.Now().UnixMilli()
 
-		response, err := sut.Execute(ctx, uint(1))
+		mockProductRepo.On("GetProductById", ctx, uint(3)).Return(orderedCombo, nil)
 
-		assert.NoError(t, err)
-		assert.NotEmpty(t, response)
+		response, err := sut.Execute(ctx, dto.Order{
+			TotalPrice: entity.NewMoney(1),
+			PaymentID:  uint(1),
+			OrderProduct: []dto.OrderProduct{
+				{
+					ProductID:    3,
+					ProductPrice: entity.NewMoney(1),
+				},
+			},
+		}, date)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "GetNextTicketNumber")
+		mockRepo.AssertNotCalled(t, "CreateOrder")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
 	})
 
-	t.Run("got error when getting order by id in services", func(t *testing.T) {
+	t.Run("got error when creating order with unpaid payment in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
 
-		sut := NewGetOrderByIdUseCase(mockRepo)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderProductResponse{}, &responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
-		})
+		unpaidPayment := orderPaymentDetails
+		unpaidPayment.PaymentStatus = entity.PaymentPayingStatus
 
-		response, err := sut.Execute(ctx, uint(1))
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(unpaidPayment, nil)
+
+		response, err := sut.Execute(ctx, orderCreation, time.Now().UnixMilli())
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
-		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+		assert.Equal(t, http.StatusPaymentRequired, businessError.StatusCode)
 	})
 
-	t.Run("got success when getting orders to prepare in services", func(t *testing.T) {
+	t.Run("got error when payment total is not the order total in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
 
-		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Criado",
-		}, nil)
-		mockRepo.On("GetOrdersToPrepare", ctx).Return(ordersList, nil)
+		cheaperPayment := orderPaymentDetails
+		cheaperPayment.TotalPrice = entity.NewMoney(1000000)
 
-		response, err := sut.Execute(ctx)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(cheaperPayment, nil)
 
-		assert.NoError(t, err)
-		assert.NotEmpty(t, response)
+		response, err := sut.Execute(ctx, orderCreation, time.Now().UnixMilli())
 
-		assert.Equal(t, 1, len(response))
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
 	})
 
-	t.Run("got error when getting orders to prepare in services", func(t *testing.T) {
+	t.Run("got error when payment was used by another order in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
 
-		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Criado",
-		}, nil)
-		mockRepo.On("GetOrdersToPrepare", ctx).Return(dto.OrderResponse{}, &responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{OrderId: 7, PaymentID: 1}, nil)
+
+		response, err := sut.Execute(ctx, orderCreation, time.Now().UnixMilli())
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "GetNextTicketNumber", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got error when payment does not exist in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(dto.PaymentDetails{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
 		})
 
-		response, err := sut.Execute(ctx)
+		response, err := sut.Execute(ctx, orderCreation, time.Now().UnixMilli())
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
@@ -248,236 +439,1151 @@ func TestOrderServices(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
 
-	t.Run("got success when getting orders status in services", func(t *testing.T) {
+	t.Run("got success when creating order with split payments in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
 
-		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
+		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrdersToFollow", ctx).Return(ordersList, nil)
+		date := time.Now().UnixMilli()
 
-		response, err := sut.Execute(ctx)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(2)).Return(splitQRCodePaymentDetails, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(2)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
+		mockRepo.On("CreateOrder", ctx, splitOrderCreation).Return(orderCreationResponse, nil)
+
+		response, err := sut.Execute(ctx, splitOrderCreation, date)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response)
-
-		assert.Equal(t, 1, len(response))
 	})
 
-	t.Run("got error when getting orders status in services", func(t *testing.T) {
+	t.Run("got error when split payments do not sum the order total in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		sortOrdersUseCase := NewSortOrdersUseCase()
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
 
-		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrdersToFollow", ctx).Return(dto.OrderResponse{}, &responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
-		})
+		cheaperPayment := splitQRCodePaymentDetails
+		cheaperPayment.TotalPrice = entity.NewMoney(200000)
 
-		response, err := sut.Execute(ctx)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(2)).Return(cheaperPayment, nil)
+
+		response, err := sut.Execute(ctx, splitOrderCreation, time.Now().UnixMilli())
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
-		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
 	})
 
-	t.Run("got success when updating order to delivered in services", func(t *testing.T) {
+	t.Run("got error when the same payment is sent twice in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
 
-		sut := NewUpdateToDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Finalizado",
-		}, nil)
-		mockRepo.On("UpdateToDelivered", ctx, uint(1)).Return(nil)
+		duplicatedPayments := splitOrderCreation
+		duplicatedPayments.PaymentIDs = []uint{1}
 
-		err := sut.Execute(ctx, uint(1))
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
 
-		assert.NoError(t, err)
+		response, err := sut.Execute(ctx, duplicatedPayments, time.Now().UnixMilli())
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
 	})
 
-	t.Run("got error when updating order to delivered in services", func(t *testing.T) {
+	t.Run("got success when creating order with promotion discounts in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockPromotionRepo := new(MockPromotionRepository)
+		mockEventRepo := new(MockOrderEventRepository)
 
-		sut := NewUpdateToDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			NewApplyPromotionsUseCase(mockPromotionRepo, NewVerifyCustomerTokenUseCase(new(MockCustomerRepository))),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
+		date := time.Now().UnixMilli()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Finalizado",
-		}, nil)
-		mockRepo.On("UpdateToDelivered", ctx, uint(1)).Return(&responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
-		})
+		discountedPayment := orderPaymentDetails
+		discountedPayment.TotalPrice = entity.NewMoney(1211050)
 
-		err := sut.Execute(ctx, uint(1))
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPromotionRepo.On("GetActivePromotions", ctx, (*string)(nil)).Return([]dto.PromotionResponse{beveragePromotion}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(discountedPayment, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
+		mockRepo.On("CreateOrder", ctx, mock.MatchedBy(func(order dto.Order) bool {
+			return order.TotalPrice == entity.NewMoney(1211050) &&
+				len(order.Discounts) == 1 &&
+				order.Discounts[0].Amount == entity.NewMoney(23450)
+		})).Return(orderCreationResponse, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
 
-		assert.Error(t, err)
+		response, err := sut.Execute(ctx, orderCreation, date)
 
-		var businessError *responses.BusinessResponse
-		assert.Equal(t, true, errors.As(err, &businessError))
-		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
 	})
 
-	t.Run("got success when updating order to done in services", func(t *testing.T) {
+	t.Run("got success when creating order redeeming loyalty points in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockLoyaltyRepo := new(MockLoyaltyRepository)
+		mockEventRepo := new(MockOrderEventRepository)
 
-		sut := NewUpdateToDoneUseCase(mockRepo, validateToDone)
+		sut := NewCreateOrderUseCase(mockRepo,
+			mockEventRepo,
+			mockCustomerRepo,
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			applyNoPromotions(),
+			NewApplyLoyaltyPointsUseCase(mockLoyaltyRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo)),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
+		date := time.Now().UnixMilli()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Preparando",
-		}, nil)
-		mockRepo.On("UpdateToDone", ctx, uint(1)).Return(nil)
+		redeemingOrder := orderCreationWithCustomer
+		redeemingOrder.LoyaltyPoints = 200
+		redeemingOrder.AuthenticatedCPF = customerResponse.CPF
 
-		err := sut.Execute(ctx, uint(1))
+		discountedPayment := orderPaymentDetails
+		discountedPayment.TotalPrice = entity.NewMoney(1233500)
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockLoyaltyRepo.On("GetBalance", ctx, customerId).Return(350, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(discountedPayment, nil)
+		mockRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockRepo.On("GetNextTicketNumber", ctx, date).Return(1, nil)
+		mockRepo.On("CreateOrder", ctx, mock.MatchedBy(func(order dto.Order) bool {
+			return order.TotalPrice == entity.NewMoney(1233500) &&
+				order.LoyaltyPoints == 200 &&
+				order.LoyaltyDiscount == entity.NewMoney(1000)
+		})).Return(orderWithCustomerCreationResponse, nil)
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		response, err := sut.Execute(ctx, redeemingOrder, date)
 
 		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
 	})
 
-	t.Run("got error when updating order to done in services", func(t *testing.T) {
+	t.Run("got error when the payment does not have the promotion discount in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDone := NewValidateOrderToDoneUseCase(mockRepo)
+		mockProductRepo := new(MockProductRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockPromotionRepo := new(MockPromotionRepository)
 
-		sut := NewUpdateToDoneUseCase(mockRepo, validateToDone)
+		sut := NewCreateOrderUseCase(mockRepo,
+			new(MockOrderEventRepository),
+			new(MockCustomerRepository),
+			mockPaymentRepo,
+			NewCalculateOrderPriceUseCase(mockProductRepo),
+			NewApplyPromotionsUseCase(mockPromotionRepo, NewVerifyCustomerTokenUseCase(new(MockCustomerRepository))),
+			applyNoLoyaltyPoints(),
+			NewSortOrdersUseCase(),
+		)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Preparando",
-		}, nil)
-		mockRepo.On("UpdateToDone", ctx, uint(1)).Return(&responses.NetworkError{
-			Code:    404,
-			Message: "Not Found",
-		})
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockPromotionRepo.On("GetActivePromotions", ctx, (*string)(nil)).Return([]dto.PromotionResponse{beveragePromotion}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
 
-		err := sut.Execute(ctx, uint(1))
+		response, err := sut.Execute(ctx, orderCreation, time.Now().UnixMilli())
 
 		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
-		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
 	})
 
-	t.Run("got success when updating order to not delivered in services", func(t *testing.T) {
+	t.Run("got success when getting order by id in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 
-		sut := NewUpdateToNotDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewGetOrderByIdUseCase(mockRepo)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Finalizado",
-		}, nil)
-		mockRepo.On("UpdateToNotDelivered", ctx, uint(1)).Return(nil)
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(orderCreationResponse, nil)
 
-		err := sut.Execute(ctx, uint(1))
+		response, err := sut.Execute(ctx, uint(1))
 
 		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
 	})
 
-	t.Run("got error when updating order to not delivered in services", func(t *testing.T) {
+	t.Run("got error when getting order by id in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToDeliveredOrNot := NewValidateOrderToDeliveredOrNotUseCase(mockRepo)
 
-		sut := NewUpdateToNotDeliveredUseCase(mockRepo, validateToDeliveredOrNot)
+		sut := NewGetOrderByIdUseCase(mockRepo)
 
 		ctx := context.TODO()
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Finalizado",
-		}, nil)
-		mockRepo.On("UpdateToNotDelivered", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderProductResponse{}, &responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
 
-		err := sut.Execute(ctx, uint(1))
+		response, err := sut.Execute(ctx, uint(1))
 
 		assert.Error(t, err)
+		assert.Empty(t, response)
 
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
 
-	t.Run("got success when updating order to preparing in services", func(t *testing.T) {
+	t.Run("got success when getting orders to prepare in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
+		sortOrdersUseCase := NewSortOrdersUseCase()
 
-		sut := NewUpdateToPreparingUseCase(mockRepo, validateToPrepare)
+		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
 
 		ctx := context.TODO()
 
 		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
 			OrderStatus: "Criado",
 		}, nil)
-		mockRepo.On("UpdateToPreparing", ctx, uint(1)).Return(nil)
+		mockRepo.On("GetOrdersToPrepare", ctx).Return(ordersList, nil)
 
-		err := sut.Execute(ctx, uint(1))
+		response, err := sut.Execute(ctx)
 
 		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
+
+		assert.Equal(t, 1, len(response))
 	})
 
-	t.Run("got error when updating order to preparing in services", func(t *testing.T) {
+	t.Run("got error when getting orders to prepare in services", func(t *testing.T) {
 		t.Parallel()
 
 		mockRepo := new(MockOrderRepository)
-		validateToPrepare := NewValidateOrderToPrepareUseCase(mockRepo)
+		sortOrdersUseCase := NewSortOrdersUseCase()
 
-		sut := NewUpdateToPreparingUseCase(mockRepo, validateToPrepare)
+		sut := NewGetOrdersToPrepareUseCase(mockRepo, sortOrdersUseCase)
 
 		ctx := context.TODO()
 
-		mockRepo.On("UpdateToPreparing", ctx, uint(1)).Return(&responses.NetworkError{
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("GetOrdersToPrepare", ctx).Return(dto.OrderResponse{}, &responses.NetworkError{
 			Code:    404,
 			Message: "Not Found",
 		})
 
-		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
-			OrderStatus: "Criado",
+		response, err := sut.Execute(ctx)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when getting orders status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sortOrdersUseCase := NewSortOrdersUseCase()
+
+		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrdersToFollow", ctx).Return(ordersList, nil)
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response)
+
+		assert.Equal(t, 1, len(response))
+	})
+
+	t.Run("got error when getting orders status in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		sortOrdersUseCase := NewSortOrdersUseCase()
+
+		sut := NewGetOrdersToFollowUseCase(mockRepo, sortOrdersUseCase)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrdersToFollow", ctx).Return(dto.OrderResponse{}, &responses.NetworkError{
+			Code:    404,
+			Message: "Not Found",
+		})
+
+		response, err := sut.Execute(ctx)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating order to delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToDeliveredUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Finalizado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Finalizado",
+			ToStatus:   "Entregue",
+			Actor:      entity.OrderActorWaiter,
+			Reason:     "The customer got the order",
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when updating order to delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToDeliveredUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Finalizado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Finalizado",
+			ToStatus:   "Entregue",
+			Actor:      entity.OrderActorWaiter,
+			Reason:     "The customer got the order",
+		}).Return(&responses.NetworkError{
+			Code:    404,
+			Message: "Not Found",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating order to done in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToDoneUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Preparando",
+			ToStatus:   "Finalizado",
+			Actor:      entity.OrderActorKitchen,
+			Reason:     "The kitchen finished the order",
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when updating order to done in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToDoneUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Preparando",
+			ToStatus:   "Finalizado",
+			Actor:      entity.OrderActorKitchen,
+			Reason:     "The kitchen finished the order",
+		}).Return(&responses.NetworkError{
+			Code:    404,
+			Message: "Not Found",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating order to not delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToNotDeliveredUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Finalizado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Finalizado",
+			ToStatus:   "Não entregue",
+			Actor:      entity.OrderActorWaiter,
+			Reason:     "The customer did not get the order",
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when updating order to not delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToNotDeliveredUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Finalizado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Finalizado",
+			ToStatus:   "Não entregue",
+			Actor:      entity.OrderActorWaiter,
+			Reason:     "The customer did not get the order",
+		}).Return(&responses.NetworkError{
+			Code:    404,
+			Message: "Not Found",
+		})
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got success when updating order to preparing in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToPreparingUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Criado",
+			ToStatus:   "Preparando",
+			Actor:      entity.OrderActorKitchen,
+			Reason:     "The kitchen started preparing the order",
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when updating order to preparing in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToPreparingUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Criado",
+			ToStatus:   "Preparando",
+			Actor:      entity.OrderActorKitchen,
+			Reason:     "The kitchen started preparing the order",
+		}).Return(&responses.NetworkError{
+			Code:    404,
+			Message: "Not Found",
+		})
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got precondition error when updating order to done without preparing in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToDoneUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+	})
+
+	t.Run("got precondition error when updating delivered order to not delivered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToNotDeliveredUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Entregue",
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+	})
+
+	t.Run("got conflict error when order status changed concurrently in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		validateTransition := NewValidateOrderTransitionUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewUpdateToPreparingUseCase(mockRepo, mockEventRepo, validateTransition)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
 		}, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(&responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Order is not in Criado status anymore",
+		})
+
 		err := sut.Execute(ctx, uint(1))
 
 		assert.Error(t, err)
 
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
+	})
+
+	t.Run("got success when getting order status history in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrderStatusHistoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderStatusHistory", ctx, uint(1)).Return(orderStatusHistory, nil)
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(response))
+		assert.Equal(t, "Preparando", response[1].ToStatus)
+	})
+
+	t.Run("got error when getting order status history in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrderStatusHistoryUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderStatusHistory", ctx, uint(1)).Return([]dto.OrderStatusHistoryResponse{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "Order not found",
+		})
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
 		var businessError *responses.BusinessResponse
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
 	})
+
+	t.Run("got success when canceling paid order with credit payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+			PaymentID:   uint(1),
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(creditPaymentDetails, nil)
+		mockCreditProvider.On("Refund", ctx, creditPaymentDetails).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, dto.OrderStatusTransition{
+			OrderID:    uint(1),
+			FromStatus: "Criado",
+			ToStatus:   "Cancelado",
+			Actor:      entity.OrderActorSystem,
+			Reason:     "Customer gave up",
+		}).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.NoError(t, err)
+		mockQRCodeProvider.AssertNotCalled(t, "Refund")
+		mockPaymentRepo.AssertNotCalled(t, "VoidPayment")
+	})
+
+	t.Run("got success when canceling paid order with qr code payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Preparando",
+			PaymentID:   uint(1),
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(qrCodePaymentDetails, nil)
+		mockQRCodeProvider.On("Refund", ctx, qrCodePaymentDetails).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Out of stock")
+
+		assert.NoError(t, err)
+		mockCreditProvider.AssertNotCalled(t, "Refund")
+	})
+
+	t.Run("got success when canceling order not paid yet in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Em pagamento",
+			PaymentID:   uint(1),
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(payingPaymentDetails, nil)
+		mockPaymentRepo.On("VoidPayment", ctx, uint(1)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.NoError(t, err)
+		mockQRCodeProvider.AssertNotCalled(t, "Refund")
+		mockCreditProvider.AssertNotCalled(t, "Refund")
+		mockPaymentRepo.AssertNotCalled(t, "RefundPayment")
+	})
+
+	t.Run("got precondition error when canceling finished order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Finalizado",
+			PaymentID:   uint(1),
+		}, nil)
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.Error(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "GetPaymentById")
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPreconditionRequired, businessError.StatusCode)
+	})
+
+	t.Run("got error when refunding payment while canceling order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+			PaymentID:   uint(1),
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(qrCodePaymentDetails, nil)
+		mockQRCodeProvider.On("Refund", ctx, qrCodePaymentDetails).Return(dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    502,
+			Message: "Bad Gateway",
+		})
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.Error(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "RefundPayment")
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus")
+	})
+
+	t.Run("got success when canceling order with payment already refunded in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		refundedPayment := creditPaymentDetails
+		refundedPayment.PaymentStatus = entity.PaymentRefundedStatus
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+			PaymentID:   uint(1),
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(refundedPayment, nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.NoError(t, err)
+		mockCreditProvider.AssertNotCalled(t, "Refund")
+		mockPaymentRepo.AssertNotCalled(t, "RefundPayment")
+	})
+
+	t.Run("got success when canceling order with split payments in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockQRCodeProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockPaymentProviders.On("GetProvider", entity.PaymentQRCodeType).Return(mockQRCodeProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Criado",
+			PaymentID:   uint(2),
+			Payments: []dto.OrderPaymentResponse{
+				{PaymentID: 1, PaymentType: entity.PaymentCreditType, TotalPrice: entity.NewMoney(1000000)},
+				{PaymentID: 2, PaymentType: entity.PaymentQRCodeType, TotalPrice: entity.NewMoney(234500)},
+			},
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(2)).Return(splitQRCodePaymentDetails, nil)
+		mockCreditProvider.On("Refund", ctx, splitCreditPaymentDetails).Return(paymentGatewayResponse, nil)
+		mockQRCodeProvider.On("Refund", ctx, splitQRCodePaymentDetails).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(1)).Return(nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(2)).Return(nil)
+		mockRepo.On("UpdateOrderStatus", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Customer gave up")
+
+		assert.NoError(t, err)
+		mockCreditProvider.AssertCalled(t, "Refund", ctx, splitCreditPaymentDetails)
+		mockQRCodeProvider.AssertCalled(t, "Refund", ctx, splitQRCodePaymentDetails)
+		mockPaymentRepo.AssertNotCalled(t, "VoidPayment")
+	})
+
+	t.Run("got paid parts refunded when canceling canceled order again in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		mockPaymentProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewCancelOrderUseCase(mockRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockPaymentProviders), entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		expiredQRCodePayment := splitQRCodePaymentDetails
+		expiredQRCodePayment.PaymentStatus = entity.PaymentErrorStatus
+
+		mockRepo.On("GetOrderById", ctx, uint(1)).Return(dto.OrderResponse{
+			OrderStatus: "Cancelado",
+			PaymentID:   uint(2),
+			Payments: []dto.OrderPaymentResponse{
+				{PaymentID: 1, PaymentType: entity.PaymentCreditType, TotalPrice: entity.NewMoney(1000000)},
+				{PaymentID: 2, PaymentType: entity.PaymentQRCodeType, TotalPrice: entity.NewMoney(234500)},
+			},
+		}, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(2)).Return(expiredQRCodePayment, nil)
+		mockCreditProvider.On("Refund", ctx, splitCreditPaymentDetails).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(1)).Return(nil)
+
+		err := sut.Execute(ctx, uint(1), "Refund the paid parts")
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertCalled(t, "RefundPayment", ctx, uint(1))
+		mockPaymentRepo.AssertNotCalled(t, "VoidPayment")
+		mockRepo.AssertNotCalled(t, "UpdateOrderStatus")
+		mockEventRepo.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("got success when getting orders page in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrdersUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		nextCursor := "cursor"
+
+		mockRepo.On("GetOrders", ctx, dto.OrderFilter{
+			Statuses:   []string{"Criado"},
+			SortBy:     "createdAt",
+			Descending: true,
+			Limit:      20,
+		}).Return(dto.OrderPage{
+			Orders:     ordersList,
+			NextCursor: &nextCursor,
+		}, nil)
+
+		response, err := sut.Execute(ctx, dto.OrderFilter{
+			Statuses:   []string{"Criado"},
+			Descending: true,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, len(ordersList), len(response.Orders))
+		assert.Equal(t, "cursor", *response.NextCursor)
+	})
+
+	t.Run("got error when getting orders with invalid filter in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrdersUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		from := time.Date(2024, 10, 10, 0, 0, 0, 0, time.Local)
+		to := time.Date(2024, 10, 9, 0, 0, 0, 0, time.Local)
+
+		filters := []dto.OrderFilter{
+			{Statuses: []string{"Unknown"}},
+			{SortBy: "customerName"},
+			{Limit: 101},
+			{Limit: -1},
+			{CreatedFrom: &from, CreatedTo: &to},
+		}
+
+		for _, filter := range filters {
+			_, err := sut.Execute(ctx, filter)
+
+			assert.Error(t, err)
+
+			var businessError *responses.BusinessResponse
+			assert.Equal(t, true, errors.As(err, &businessError))
+			assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+		}
+
+		mockRepo.AssertNotCalled(t, "GetOrders")
+	})
+
+	t.Run("got error when getting orders with invalid cursor in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOrderRepository)
+
+		sut := NewGetOrdersUseCase(mockRepo, entity.NewOrderStateMachine())
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrders", ctx, mock.Anything).Return(dto.OrderPage{}, &responses.LocalError{
+			Code:    responses.LOGIC_ERROR,
+			Message: "The cursor is not valid",
+		})
+
+		_, err := sut.Execute(ctx, dto.OrderFilter{
+			Cursor: "invalid",
+		})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
 }
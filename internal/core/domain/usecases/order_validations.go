@@ -2,36 +2,35 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"slices"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
-type ValidateOrderToDeliveredOrNotUseCase struct {
-	repository repository.OrderRepository
+type ValidateOrderTransitionUseCase struct {
+	repository   repository.OrderRepository
+	stateMachine *entity.OrderStateMachine
 }
 
 type SortOrdersUseCase struct{}
 
-type ValidateOrderToDoneUseCase struct {
-	repository repository.OrderRepository
+type CalculateOrderPriceUseCase struct {
+	productRepo repository.ProductRepository
 }
 
-type ValidateOrderToPrepareUseCase struct {
-	repository repository.OrderRepository
-}
-
-func NewValidateOrderToPrepareUseCase(repository repository.OrderRepository) *ValidateOrderToPrepareUseCase {
-	return &ValidateOrderToPrepareUseCase{
-		repository: repository,
-	}
-}
-
-func NewValidateOrderToDoneUseCase(repository repository.OrderRepository) *ValidateOrderToDoneUseCase {
-	return &ValidateOrderToDoneUseCase{
-		repository: repository,
+func NewValidateOrderTransitionUseCase(
+	repository repository.OrderRepository,
+	stateMachine *entity.OrderStateMachine,
+) *ValidateOrderTransitionUseCase {
+	return &ValidateOrderTransitionUseCase{
+		repository:   repository,
+		stateMachine: stateMachine,
 	}
 }
 
@@ -39,36 +38,42 @@ func NewSortOrdersUseCase() *SortOrdersUseCase {
 	return &SortOrdersUseCase{}
 }
 
-func NewValidateOrderToDeliveredOrNotUseCase(repository repository.OrderRepository) *ValidateOrderToDeliveredOrNotUseCase {
-	return &ValidateOrderToDeliveredOrNotUseCase{
-		repository: repository,
+func NewCalculateOrderPriceUseCase(productRepo repository.ProductRepository) *CalculateOrderPriceUseCase {
+	return &CalculateOrderPriceUseCase{
+		productRepo: productRepo,
 	}
 }
 
-func (usecase *ValidateOrderToDeliveredOrNotUseCase) Execute(ctx context.Context, orderId uint) error {
+// Execute checks if the order can be moved to the 'to' status and returns
+// the transition with the current order status to be persisted
+func (usecase *ValidateOrderTransitionUseCase) Execute(ctx context.Context, orderId uint, to string) (dto.OrderStatusTransition, error) {
 	response, err := usecase.repository.GetOrderById(ctx, orderId)
 
 	if err != nil {
-		return responses.GetResponseError(err, "ValidateOrderToDoneUseCase -> GetOrderById")
+		return dto.OrderStatusTransition{}, responses.GetResponseError(err, "ValidateOrderTransitionUseCase -> GetOrderById")
 	}
 
-	if response.OrderStatus != "Finalizado" {
-		return &responses.BusinessResponse{
-			StatusCode: 428,
-			Message:    "The order must be in Finalizado status",
-		}
+	err = usecase.stateMachine.Validate(response.OrderStatus, to)
+
+	if err != nil {
+		return dto.OrderStatusTransition{}, err
 	}
 
-	return nil
+	return dto.OrderStatusTransition{
+		OrderID:    orderId,
+		FromStatus: response.OrderStatus,
+		ToStatus:   to,
+	}, nil
 }
 
 func (usecase *SortOrdersUseCase) Execute(orders []dto.OrderResponse) {
 	slices.SortFunc(orders, func(previous, next dto.OrderResponse) int {
-		if next.OrderStatus == "Finalizado" && (previous.OrderStatus == "Preparando" || previous.OrderStatus == "Criado") {
+		if next.OrderStatus == entity.OrderStatusDone &&
+			(previous.OrderStatus == entity.OrderStatusPreparing || previous.OrderStatus == entity.OrderStatusCreated) {
 			return 1
 		}
 
-		if next.OrderStatus == "Preparando" && previous.OrderStatus == "Criado" {
+		if next.OrderStatus == entity.OrderStatusPreparing && previous.OrderStatus == entity.OrderStatusCreated {
 			return 0
 		}
 
@@ -76,36 +81,166 @@ func (usecase *SortOrdersUseCase) Execute(orders []dto.OrderResponse) {
 	})
 }
 
-func (usecase *ValidateOrderToDoneUseCase) Execute(ctx context.Context, orderId uint) error {
-	response, err := usecase.repository.GetOrderById(ctx, orderId)
+// Execute loads every ordered product to replace the client prices by the catalog ones.
+// It returns the priced products and fails if the client total price disagrees with them
+func (usecase *CalculateOrderPriceUseCase) Execute(
+	ctx context.Context,
+	orderProducts []dto.OrderProduct,
+	clientTotalPrice entity.Money,
+) ([]dto.OrderProduct, entity.Money, error) {
+	if len(orderProducts) == 0 {
+		return []dto.OrderProduct{}, entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "The order must have at least one product",
+		}
+	}
 
-	if err != nil {
-		return responses.GetResponseError(err, "ValidateOrderToDoneUseCase -> GetOrderById")
+	pricedProducts := make([]dto.OrderProduct, 0, len(orderProducts))
+
+	// The total has the currency of the products
+	var totalPrice entity.Money
+
+	for _, orderProduct := range orderProducts {
+		if orderProduct.Quantity == 0 {
+			orderProduct.Quantity = 1
+		}
+
+		if orderProduct.Quantity < 0 {
+			return []dto.OrderProduct{}, entity.Money{}, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("The quantity of product %v must be greater than zero", orderProduct.ProductID),
+			}
+		}
+
+		if orderProduct.Quantity > entity.OrderProductMaxQuantity {
+			return []dto.OrderProduct{}, entity.Money{}, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message: fmt.Sprintf(
+					"The quantity of product %v must be at most %v",
+					orderProduct.ProductID,
+					entity.OrderProductMaxQuantity,
+				),
+			}
+		}
+
+		product, err := usecase.getAvailableProduct(ctx, orderProduct.ProductID)
+
+		if err != nil {
+			return []dto.OrderProduct{}, entity.Money{}, err
+		}
+
+		modifiers, surcharge, err := usecase.priceModifiers(ctx, orderProduct.Modifiers)
+
+		if err != nil {
+			return []dto.OrderProduct{}, entity.Money{}, err
+		}
+
+		orderProduct.ProductPrice = product.Price
+		orderProduct.ProductCategory = product.Category
+		orderProduct.Modifiers = modifiers
+		totalPrice = totalPrice.Add(product.Price.Add(surcharge).Times(orderProduct.Quantity))
+
+		pricedProducts = append(pricedProducts, orderProduct)
 	}
 
-	if response.OrderStatus != "Preparando" {
-		return &responses.BusinessResponse{
-			StatusCode: 428,
-			Message:    "The order must be in Preparando status",
+	if !totalPrice.Equal(clientTotalPrice) {
+		return []dto.OrderProduct{}, entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf(
+				"The order total price %v does not match the products total price %v",
+				clientTotalPrice,
+				totalPrice,
+			),
 		}
 	}
 
-	return nil
+	return pricedProducts, totalPrice, nil
 }
 
-func (usecase *ValidateOrderToPrepareUseCase) Execute(ctx context.Context, orderId uint) error {
-	response, err := usecase.repository.GetOrderById(ctx, orderId)
+func (usecase *CalculateOrderPriceUseCase) getAvailableProduct(ctx context.Context, productId uint) (dto.ProductResponse, error) {
+	product, err := usecase.productRepo.GetProductById(ctx, productId)
+
+	var localError *responses.LocalError
+
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		return dto.ProductResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The product %v is not available", productId),
+		}
+	}
 
 	if err != nil {
-		return responses.GetResponseError(err, "ValidateOrderToPrepareUseCase -> GetOrderById")
+		return dto.ProductResponse{}, responses.GetResponseError(err, "CalculateOrderPriceUseCase -> GetProductById")
 	}
 
-	if response.OrderStatus != "Criado" {
-		return &responses.BusinessResponse{
-			StatusCode: 428,
-			Message:    "The order must be in Criado status",
+	// A combo is sold by its own price, but only while it still has products in the catalog
+	if product.Category == entity.CategoryCombo && (product.ComboProducts == nil || len(*product.ComboProducts) == 0) {
+		return dto.ProductResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The combo %v is not available", productId),
 		}
 	}
 
-	return nil
+	return product, nil
+}
+
+// priceModifiers returns the modifiers with the catalog surcharge of each extra topping,
+// and the sum of these surcharges to be added to the item unit price
+func (usecase *CalculateOrderPriceUseCase) priceModifiers(
+	ctx context.Context,
+	modifiers []dto.OrderProductModifier,
+) ([]dto.OrderProductModifier, entity.Money, error) {
+	pricedModifiers := []dto.OrderProductModifier{}
+	surcharge := entity.NewMoney(0)
+
+	for _, modifier := range modifiers {
+		switch modifier.Type {
+		case entity.OrderModifierRemove, entity.OrderModifierNote:
+			if modifier.Description == "" {
+				return []dto.OrderProductModifier{}, entity.Money{}, &responses.BusinessResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    fmt.Sprintf("The %v modifier must have a description", modifier.Type),
+				}
+			}
+
+			modifier.ProductID = nil
+			modifier.Price = entity.NewMoney(0)
+		case entity.OrderModifierExtra:
+			if modifier.ProductID == nil {
+				return []dto.OrderProductModifier{}, entity.Money{}, &responses.BusinessResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    "The extra modifier must have a product",
+				}
+			}
+
+			topping, err := usecase.getAvailableProduct(ctx, *modifier.ProductID)
+
+			if err != nil {
+				return []dto.OrderProductModifier{}, entity.Money{}, err
+			}
+
+			if topping.Category != entity.CategoryToppings {
+				return []dto.OrderProductModifier{}, entity.Money{}, &responses.BusinessResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    fmt.Sprintf("The product %v can not be used as an extra", topping.Id),
+				}
+			}
+
+			if modifier.Description == "" {
+				modifier.Description = topping.Name
+			}
+
+			modifier.Price = topping.Price
+			surcharge = surcharge.Add(topping.Price)
+		default:
+			return []dto.OrderProductModifier{}, entity.Money{}, &responses.BusinessResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("The modifier type %v is not valid", modifier.Type),
+			}
+		}
+
+		pricedModifiers = append(pricedModifiers, modifier)
+	}
+
+	return pricedModifiers, surcharge, nil
 }
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type RelayOutboxEventsUseCase struct {
+	outboxRepo repository.OutboxRepository
+	publisher  repository.EventPublisher
+}
+
+func NewRelayOutboxEventsUseCase(
+	outboxRepo repository.OutboxRepository,
+	publisher repository.EventPublisher,
+) *RelayOutboxEventsUseCase {
+	return &RelayOutboxEventsUseCase{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+	}
+}
+
+// Start relays the outbox events until the context is done. A full batch means there are
+// more pending events, so the next one is claimed without waiting the interval
+func (usecase *RelayOutboxEventsUseCase) Start(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := usecase.Execute(ctx)
+
+		if err != nil {
+			log.Print("relay outbox events", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		if published == entity.OutboxBatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Execute publishes a batch of pending events and returns how many were claimed. The delivery
+// is at least once: an event is published again if the relay stops before marking it
+func (usecase *RelayOutboxEventsUseCase) Execute(ctx context.Context) (int, error) {
+	events, err := usecase.outboxRepo.ClaimPendingEvents(ctx, entity.OutboxBatchSize, entity.OutboxLease)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "OutboxService -> ClaimPendingEvents")
+	}
+
+	for _, event := range events {
+		err = usecase.publisher.Publish(ctx, event)
+
+		if err != nil {
+			log.Print("publish outbox event", map[string]interface{}{
+				"id":       event.ID,
+				"type":     event.EventType,
+				"attempts": event.Attempts,
+				"error":    err.Error(),
+			})
+
+			nextAttemptAt := time.Now().Add(entity.OutboxRetryDelay(event.Attempts))
+			err = usecase.outboxRepo.MarkAsFailed(ctx, event.ID, nextAttemptAt, err.Error())
+		} else {
+			err = usecase.outboxRepo.MarkAsPublished(ctx, event.ID)
+		}
+
+		// The lease expires and the event is claimed again
+		if err != nil {
+			log.Print("mark outbox event", map[string]interface{}{
+				"id":    event.ID,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,141 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestOutboxServices(t *testing.T) {
+	t.Parallel()
+
+	orderPaid := dto.OutboxEvent{
+		ID:            1,
+		EventType:     entity.OrderPaidEvent,
+		AggregateType: entity.OutboxAggregateOrder,
+		AggregateID:   10,
+		Payload:       []byte(`{"orderId":10}`),
+		Attempts:      1,
+	}
+
+	orderReady := dto.OutboxEvent{
+		ID:            2,
+		EventType:     entity.OrderReadyEvent,
+		AggregateType: entity.OutboxAggregateOrder,
+		AggregateID:   10,
+		Payload:       []byte(`{"orderId":10}`),
+		Attempts:      3,
+	}
+
+	t.Run("got success when relaying outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		mockPublisher := new(MockEventPublisher)
+
+		sut := NewRelayOutboxEventsUseCase(mockRepo, mockPublisher)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimPendingEvents", ctx, entity.OutboxBatchSize, entity.OutboxLease).
+			Return([]dto.OutboxEvent{orderPaid, orderReady}, nil)
+		mockPublisher.On("Publish", ctx, mock.Anything).Return(nil)
+		mockRepo.On("MarkAsPublished", ctx, mock.Anything).Return(nil)
+
+		published, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, published)
+		mockRepo.AssertCalled(t, "MarkAsPublished", ctx, uint(1))
+		mockRepo.AssertCalled(t, "MarkAsPublished", ctx, uint(2))
+		mockRepo.AssertNotCalled(t, "MarkAsFailed")
+	})
+
+	t.Run("got retry with backoff when publisher fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		mockPublisher := new(MockEventPublisher)
+
+		sut := NewRelayOutboxEventsUseCase(mockRepo, mockPublisher)
+
+		ctx := context.TODO()
+		before := time.Now()
+
+		mockRepo.On("ClaimPendingEvents", ctx, entity.OutboxBatchSize, entity.OutboxLease).
+			Return([]dto.OutboxEvent{orderPaid, orderReady}, nil)
+		mockPublisher.On("Publish", ctx, orderPaid).Return(nil)
+		mockPublisher.On("Publish", ctx, orderReady).Return(errors.New("webhook returned 503"))
+		mockRepo.On("MarkAsPublished", ctx, uint(1)).Return(nil)
+		mockRepo.On("MarkAsFailed", ctx, uint(2), mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+			// The third attempt waits 8 seconds
+			return !nextAttemptAt.Before(before.Add(8 * time.Second))
+		}), "webhook returned 503").Return(nil)
+
+		published, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, published)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("got error when claiming outbox events in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		mockPublisher := new(MockEventPublisher)
+
+		sut := NewRelayOutboxEventsUseCase(mockRepo, mockPublisher)
+
+		ctx := context.TODO()
+
+		mockRepo.On("ClaimPendingEvents", ctx, entity.OutboxBatchSize, entity.OutboxLease).
+			Return([]dto.OutboxEvent{}, &responses.LocalError{
+				Code:    responses.DATABASE_ERROR,
+				Message: "service unavailable",
+			})
+
+		published, err := sut.Execute(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, published)
+		mockPublisher.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("got stop when context is done in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockOutboxRepository)
+		mockPublisher := new(MockEventPublisher)
+
+		sut := NewRelayOutboxEventsUseCase(mockRepo, mockPublisher)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+
+		mockRepo.On("ClaimPendingEvents", ctx, entity.OutboxBatchSize, entity.OutboxLease).
+			Run(func(args mock.Arguments) {
+				cancel()
+			}).
+			Return([]dto.OutboxEvent{}, nil)
+
+		done := make(chan bool)
+
+		go func() {
+			sut.Start(ctx, time.Hour)
+			done <- true
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "relay did not stop")
+		}
+	})
+}
@@ -2,25 +2,39 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
 type PayOrderUseCase struct {
-	paymentRepo    repository.PaymentRepository
-	paymentGateway repository.PaymentGateway
+	paymentRepo      repository.PaymentRepository
+	paymentProviders repository.PaymentProviderRegistry
 }
 
 type GetPaymentTypesUseCase struct {
 	paymentRepo repository.PaymentRepository
 }
 
-func NewPayOrderUseCase(paymentRepo repository.PaymentRepository, paymentGateway repository.PaymentGateway) *PayOrderUseCase {
+type RefundOrderPaymentsUseCase struct {
+	paymentRepo      repository.PaymentRepository
+	paymentProviders repository.PaymentProviderRegistry
+}
+
+type GetPaymentByIdUseCase struct {
+	paymentRepo repository.PaymentRepository
+	orderRepo   repository.OrderRepository
+}
+
+func NewPayOrderUseCase(paymentRepo repository.PaymentRepository, paymentProviders repository.PaymentProviderRegistry) *PayOrderUseCase {
 	return &PayOrderUseCase{
-		paymentRepo:    paymentRepo,
-		paymentGateway: paymentGateway,
+		paymentRepo:      paymentRepo,
+		paymentProviders: paymentProviders,
 	}
 }
 
@@ -30,14 +44,37 @@ func NewGetPaymentTypesUseCasee(paymentRepo repository.PaymentRepository) *GetPa
 	}
 }
 
+func NewRefundOrderPaymentsUseCase(
+	paymentRepo repository.PaymentRepository,
+	paymentProviders repository.PaymentProviderRegistry,
+) *RefundOrderPaymentsUseCase {
+	return &RefundOrderPaymentsUseCase{
+		paymentRepo:      paymentRepo,
+		paymentProviders: paymentProviders,
+	}
+}
+
+func NewGetPaymentByIdUseCase(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository) *GetPaymentByIdUseCase {
+	return &GetPaymentByIdUseCase{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+	}
+}
+
 func (usecase *PayOrderUseCase) Execute(ctx context.Context, payment dto.Payment) (dto.PaymentResponse, error) {
+	provider, err := usecase.paymentProviders.GetProvider(payment.PaymentType)
+
+	if err != nil {
+		return dto.PaymentResponse{}, responses.GetResponseError(err, "PaymentService")
+	}
+
 	paymentResponse, err := usecase.paymentRepo.CreatePaymentOrder(ctx, payment)
 
 	if err != nil {
 		return dto.PaymentResponse{}, responses.GetResponseError(err, "PaymentService")
 	}
 
-	gatewayResponse, err := usecase.paymentGateway.Pay(paymentResponse, payment)
+	gatewayResponse, err := usecase.authorizeAndCapture(ctx, provider, paymentResponse, payment)
 
 	if err != nil {
 		paymentWithError := usecase.paymentRepo.FinishPaymentWithError(ctx, paymentResponse.PaymentId)
@@ -49,7 +86,7 @@ func (usecase *PayOrderUseCase) Execute(ctx context.Context, payment dto.Payment
 		return dto.PaymentResponse{}, responses.GetResponseError(err, "PaymentService")
 	}
 
-	err = usecase.paymentRepo.FinishPaymentWithSuccess(ctx, paymentResponse.PaymentId)
+	err = usecase.paymentRepo.FinishPaymentWithSuccess(ctx, paymentResponse.PaymentId, gatewayResponse.PaymentGatewayId)
 
 	if err != nil {
 		return dto.PaymentResponse{}, responses.GetResponseError(err, "PaymentService")
@@ -62,6 +99,120 @@ func (usecase *PayOrderUseCase) Execute(ctx context.Context, payment dto.Payment
 	}, nil
 }
 
+func (usecase *PayOrderUseCase) authorizeAndCapture(
+	ctx context.Context,
+	provider repository.PaymentProvider,
+	paymentResponse dto.PaymentResponse,
+	payment dto.Payment,
+) (dto.PaymentGatewayResponse, error) {
+	gatewayResponse, err := provider.Authorize(ctx, dto.PaymentAuthorization{
+		PaymentID:   paymentResponse.PaymentId,
+		CustomerID:  payment.CustomerID,
+		TotalPrice:  payment.TotalPrice,
+		PaymentType: payment.PaymentType,
+	})
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	if gatewayResponse.PaymentStatus == entity.PaymentPayingStatus {
+		gatewayResponse, err = provider.Capture(ctx, gatewayResponse)
+
+		if err != nil {
+			return dto.PaymentGatewayResponse{}, err
+		}
+	}
+
+	if gatewayResponse.PaymentStatus != entity.PaymentPayedStatus {
+		return dto.PaymentGatewayResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusPaymentRequired,
+			Message:    fmt.Sprintf("The payment was not approved by %v", provider.Name()),
+		}
+	}
+
+	return gatewayResponse, nil
+}
+
 func (usecase *GetPaymentTypesUseCase) Execute() []string {
 	return usecase.paymentRepo.GetPaymentTypes()
 }
+
+// Execute reverses each payment of an order by itself, so a split order refunds every part with its
+// own provider. The payments already reversed by a previous attempt and the failed ones are skipped
+func (usecase *RefundOrderPaymentsUseCase) Execute(ctx context.Context, paymentIDs []uint) error {
+	for _, paymentID := range paymentIDs {
+		payment, err := usecase.paymentRepo.GetPaymentById(ctx, paymentID)
+
+		if err != nil {
+			return responses.GetResponseError(err, "PaymentService -> GetPaymentById")
+		}
+
+		err = usecase.reversePayment(ctx, payment)
+
+		if err != nil {
+			return responses.GetResponseError(err, "PaymentService -> RefundPayment")
+		}
+	}
+
+	return nil
+}
+
+func (usecase *RefundOrderPaymentsUseCase) reversePayment(ctx context.Context, payment dto.PaymentDetails) error {
+	switch payment.PaymentStatus {
+	case entity.PaymentRefundedStatus, entity.PaymentVoidedStatus, entity.PaymentErrorStatus:
+		return nil
+	case entity.PaymentPayedStatus:
+		err := usecase.refund(ctx, payment)
+
+		if err != nil {
+			return err
+		}
+
+		return usecase.paymentRepo.RefundPayment(ctx, payment.PaymentId)
+	}
+
+	// The customer didn't pay yet, so there is nothing to give back
+	return usecase.paymentRepo.VoidPayment(ctx, payment.PaymentId)
+}
+
+func (usecase *RefundOrderPaymentsUseCase) refund(ctx context.Context, payment dto.PaymentDetails) error {
+	provider, err := usecase.paymentProviders.GetProvider(payment.PaymentType)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.Refund(ctx, payment)
+
+	return err
+}
+
+func (usecase *GetPaymentByIdUseCase) Execute(ctx context.Context, paymentId uint) (dto.PaymentOrderResponse, error) {
+	payment, err := usecase.paymentRepo.GetPaymentById(ctx, paymentId)
+
+	if err != nil {
+		return dto.PaymentOrderResponse{}, responses.GetResponseError(err, "PaymentService -> GetPaymentById")
+	}
+
+	response := dto.PaymentOrderResponse{
+		PaymentDetails: payment,
+	}
+
+	order, err := usecase.orderRepo.GetOrderByPaymentId(ctx, paymentId)
+
+	var localError *responses.LocalError
+
+	// The payment was not used by any order yet
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		return response, nil
+	}
+
+	if err != nil {
+		return dto.PaymentOrderResponse{}, responses.GetResponseError(err, "PaymentService -> GetOrderByPaymentId")
+	}
+
+	response.Order = &order
+
+	return response, nil
+}
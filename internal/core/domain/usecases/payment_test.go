@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
@@ -31,19 +33,22 @@ func TestPaymentServices(t *testing.T) {
 		t.Parallel()
 
 		mockPaymentRepo := new(MockPaymentRepository)
-		mockPaymentGatewayRepo := new(MockPaymentGatewayRepository)
-		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentGatewayRepo)
+		mockPaymentProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
 
 		ctx := context.TODO()
 
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(mockPaymentProvider, nil)
 		mockPaymentRepo.On("CreatePaymentOrder", ctx, paymentCreation).Return(paymentResponse, nil)
-		mockPaymentGatewayRepo.On("Pay", paymentResponse, paymentCreation).Return(paymentGatewayResponse, nil)
-		mockPaymentRepo.On("FinishPaymentWithSuccess", ctx, uint(1)).Return(nil)
+		mockPaymentProvider.On("Authorize", ctx, paymentAuthorization).Return(authorizedGatewayResponse, nil)
+		mockPaymentProvider.On("Capture", ctx, authorizedGatewayResponse).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("FinishPaymentWithSuccess", ctx, uint(1), "1234").Return(nil)
 
 		response, err := sut.Execute(ctx, paymentCreation)
 
 		mockPaymentRepo.AssertExpectations(t)
-		mockPaymentGatewayRepo.AssertExpectations(t)
+		mockPaymentProvider.AssertExpectations(t)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response)
@@ -53,11 +58,13 @@ func TestPaymentServices(t *testing.T) {
 		t.Parallel()
 
 		mockPaymentRepo := new(MockPaymentRepository)
-		mockPaymentGatewayRepo := new(MockPaymentGatewayRepository)
-		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentGatewayRepo)
+		mockPaymentProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
 
 		ctx := context.TODO()
 
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(mockPaymentProvider, nil)
 		mockPaymentRepo.On("CreatePaymentOrder", ctx, paymentCreation).Return(dto.PaymentResponse{}, &responses.LocalError{
 			Code:    3,
 			Message: "DATABASE_CONFLICT_ERROR",
@@ -79,13 +86,15 @@ func TestPaymentServices(t *testing.T) {
 		t.Parallel()
 
 		mockPaymentRepo := new(MockPaymentRepository)
-		mockPaymentGatewayRepo := new(MockPaymentGatewayRepository)
-		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentGatewayRepo)
+		mockPaymentProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
 
 		ctx := context.TODO()
 
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(mockPaymentProvider, nil)
 		mockPaymentRepo.On("CreatePaymentOrder", ctx, paymentCreation).Return(paymentResponse, nil)
-		mockPaymentGatewayRepo.On("Pay", paymentResponse, paymentCreation).Return(dto.PaymentGatewayResponse{}, &responses.NetworkError{
+		mockPaymentProvider.On("Authorize", ctx, paymentAuthorization).Return(dto.PaymentGatewayResponse{}, &responses.NetworkError{
 			Code:    503,
 			Message: "Service Unavailable",
 		})
@@ -94,7 +103,7 @@ func TestPaymentServices(t *testing.T) {
 		response, err := sut.Execute(ctx, paymentCreation)
 
 		mockPaymentRepo.AssertExpectations(t)
-		mockPaymentGatewayRepo.AssertExpectations(t)
+		mockPaymentProvider.AssertExpectations(t)
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
@@ -108,14 +117,17 @@ func TestPaymentServices(t *testing.T) {
 		t.Parallel()
 
 		mockPaymentRepo := new(MockPaymentRepository)
-		mockPaymentGatewayRepo := new(MockPaymentGatewayRepository)
-		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentGatewayRepo)
+		mockPaymentProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
 
 		ctx := context.TODO()
 
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(mockPaymentProvider, nil)
 		mockPaymentRepo.On("CreatePaymentOrder", ctx, paymentCreation).Return(paymentResponse, nil)
-		mockPaymentGatewayRepo.On("Pay", paymentResponse, paymentCreation).Return(paymentGatewayResponse, nil)
-		mockPaymentRepo.On("FinishPaymentWithSuccess", ctx, uint(1)).Return(&responses.LocalError{
+		mockPaymentProvider.On("Authorize", ctx, paymentAuthorization).Return(authorizedGatewayResponse, nil)
+		mockPaymentProvider.On("Capture", ctx, authorizedGatewayResponse).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("FinishPaymentWithSuccess", ctx, uint(1), "1234").Return(&responses.LocalError{
 			Code:    3,
 			Message: "DATABASE_CONFLICT_ERROR",
 		})
@@ -123,7 +135,7 @@ func TestPaymentServices(t *testing.T) {
 		response, err := sut.Execute(ctx, paymentCreation)
 
 		mockPaymentRepo.AssertExpectations(t)
-		mockPaymentGatewayRepo.AssertExpectations(t)
+		mockPaymentProvider.AssertExpectations(t)
 
 		assert.Error(t, err)
 		assert.Empty(t, response)
@@ -132,4 +144,126 @@ func TestPaymentServices(t *testing.T) {
 		assert.Equal(t, true, errors.As(err, &businessError))
 		assert.Equal(t, http.StatusConflict, businessError.StatusCode)
 	})
+	t.Run("got payment required error when payment is declined in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockPaymentProvider := new(MockPaymentProvider)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
+
+		ctx := context.TODO()
+
+		declinedGatewayResponse := authorizedGatewayResponse
+		declinedGatewayResponse.PaymentStatus = entity.PaymentErrorStatus
+
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(mockPaymentProvider, nil)
+		mockPaymentRepo.On("CreatePaymentOrder", ctx, paymentCreation).Return(paymentResponse, nil)
+		mockPaymentProvider.On("Authorize", ctx, paymentAuthorization).Return(declinedGatewayResponse, nil)
+		mockPaymentProvider.On("Name").Return(entity.PaymentProviderSandbox)
+		mockPaymentRepo.On("FinishPaymentWithError", ctx, uint(1)).Return(nil)
+
+		response, err := sut.Execute(ctx, paymentCreation)
+
+		mockPaymentRepo.AssertExpectations(t)
+		mockPaymentProvider.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+		mockPaymentRepo.AssertNotCalled(t, "FinishPaymentWithSuccess", mock.Anything, mock.Anything, mock.Anything)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusPaymentRequired, businessError.StatusCode)
+	})
+
+	t.Run("got bad request when payment type is not supported in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockPaymentProviders := new(MockPaymentProviderRegistry)
+		sut := NewPayOrderUseCase(mockPaymentRepo, mockPaymentProviders)
+
+		ctx := context.TODO()
+
+		mockPaymentProviders.On("GetProvider", "Crédito").Return(nil, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "The payment type is not supported",
+		})
+
+		response, err := sut.Execute(ctx, paymentCreation)
+
+		mockPaymentRepo.AssertNotCalled(t, "CreatePaymentOrder", mock.Anything, mock.Anything)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got payment with its order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewGetPaymentByIdUseCase(mockPaymentRepo, mockOrderRepo)
+
+		ctx := context.TODO()
+
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockOrderRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{OrderId: 7, PaymentID: 1}, nil)
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+		assert.Equal(t, orderPaymentDetails, response.PaymentDetails)
+		assert.NotNil(t, response.Order)
+		assert.Equal(t, uint(7), response.Order.OrderId)
+	})
+
+	t.Run("got payment without order when it was not used in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewGetPaymentByIdUseCase(mockPaymentRepo, mockOrderRepo)
+
+		ctx := context.TODO()
+
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(orderPaymentDetails, nil)
+		mockOrderRepo.On("GetOrderByPaymentId", ctx, uint(1)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.NoError(t, err)
+		assert.Equal(t, orderPaymentDetails, response.PaymentDetails)
+		assert.Nil(t, response.Order)
+	})
+
+	t.Run("got error when payment does not exist in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		sut := NewGetPaymentByIdUseCase(mockPaymentRepo, mockOrderRepo)
+
+		ctx := context.TODO()
+
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(dto.PaymentDetails{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: "record not found",
+		})
+
+		response, err := sut.Execute(ctx, uint(1))
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockOrderRepo.AssertNotCalled(t, "GetOrderByPaymentId", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
 }
@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type VerifyWebhookSignatureUseCase struct {
+	secret string
+}
+
+func NewVerifyWebhookSignatureUseCase(secret string) *VerifyWebhookSignatureUseCase {
+	return &VerifyWebhookSignatureUseCase{
+		secret: secret,
+	}
+}
+
+// Execute checks the 'x-signature' header sent by Mercado Livre, formatted as 'ts=<timestamp>,v1=<hash>'.
+// The hash is the HMAC SHA256 of the manifest 'id:<data.id>;request-id:<x-request-id>;ts:<timestamp>;',
+// where the values not sent in the notification are left out
+func (usecase *VerifyWebhookSignatureUseCase) Execute(signature dto.WebhookSignature) error {
+	var timestamp, hash string
+
+	for _, part := range strings.Split(signature.Signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "ts":
+			timestamp = value
+		case "v1":
+			hash = value
+		}
+	}
+
+	if timestamp == "" || hash == "" || usecase.secret == "" {
+		return usecase.invalidSignature()
+	}
+
+	expected, err := hex.DecodeString(hash)
+
+	if err != nil {
+		return usecase.invalidSignature()
+	}
+
+	manifest := ""
+
+	if signature.DataID != "" {
+		manifest += fmt.Sprintf("id:%v;", strings.ToLower(signature.DataID))
+	}
+
+	if signature.RequestID != "" {
+		manifest += fmt.Sprintf("request-id:%v;", signature.RequestID)
+	}
+
+	manifest += fmt.Sprintf("ts:%v;", timestamp)
+
+	mac := hmac.New(sha256.New, []byte(usecase.secret))
+	mac.Write([]byte(manifest))
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return usecase.invalidSignature()
+	}
+
+	return nil
+}
+
+func (usecase *VerifyWebhookSignatureUseCase) invalidSignature() error {
+	return &responses.BusinessResponse{
+		StatusCode: http.StatusUnauthorized,
+		Message:    "The webhook signature is not valid",
+	}
+}
@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type HandlePaymentWebhookUseCase struct {
+	paymentProviders  repository.PaymentProviderRegistry
+	orderRepository   repository.OrderRepository
+	paymentRepository repository.PaymentRepository
+	orderEvents       repository.OrderEventRepository
+	refundPayments    *RefundOrderPaymentsUseCase
+}
+
+func NewHandlePaymentWebhookUseCase(
+	paymentProviders repository.PaymentProviderRegistry,
+	orderRepository repository.OrderRepository,
+	paymentRepository repository.PaymentRepository,
+	orderEvents repository.OrderEventRepository,
+	refundPayments *RefundOrderPaymentsUseCase,
+) *HandlePaymentWebhookUseCase {
+	return &HandlePaymentWebhookUseCase{
+		paymentProviders:  paymentProviders,
+		orderRepository:   orderRepository,
+		paymentRepository: paymentRepository,
+		orderEvents:       orderEvents,
+		refundPayments:    refundPayments,
+	}
+}
+
+// Execute lets the provider read its own notification. The payments of a paying order finish the order
+// in the same transaction, while the payments made before the order only change the payment status
+func (usecase *HandlePaymentWebhookUseCase) Execute(ctx context.Context, providerName string, webhook dto.PaymentWebhook) error {
+	provider, err := usecase.paymentProviders.GetProviderByName(providerName)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentWebhookService")
+	}
+
+	event, err := provider.ParseWebhook(ctx, webhook)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentWebhookService -> ParseWebhook")
+	}
+
+	if !event.Finished {
+		return nil
+	}
+
+	if event.OrderID == 0 {
+		return usecase.finishPayment(ctx, event)
+	}
+
+	order, err := usecase.orderRepository.GetOrderById(ctx, event.OrderID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentWebhookService -> GetOrderById")
+	}
+
+	if order.PaymentID != event.PaymentID {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The payment %v does not belong to the order %v", event.PaymentID, event.OrderID),
+		}
+	}
+
+	err = validatePaidAmount(event, orderPaymentPrice(order, event.PaymentID))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = finishPayingOrder(ctx, usecase.orderRepository, usecase.orderEvents, usecase.refundPayments, order, dto.OrderPaymentResult{
+		EventKey:         event.EventKey,
+		OrderID:          event.OrderID,
+		PaymentID:        event.PaymentID,
+		PaymentStatus:    event.PaymentStatus,
+		GatewayPaymentID: event.GatewayPaymentID,
+		OrderStatus:      event.OrderStatus,
+		Reason:           event.Reason,
+	})
+
+	return err
+}
+
+// finishPayment ignores the payments already finished, so the duplicated notifications change nothing
+func (usecase *HandlePaymentWebhookUseCase) finishPayment(ctx context.Context, event dto.PaymentWebhookEvent) error {
+	payment, err := usecase.paymentRepository.GetPaymentById(ctx, event.PaymentID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentWebhookService -> GetPaymentById")
+	}
+
+	if payment.PaymentStatus != entity.PaymentPayingStatus {
+		return nil
+	}
+
+	err = validatePaidAmount(event, payment.TotalPrice)
+
+	if err != nil {
+		return err
+	}
+
+	if event.PaymentStatus == entity.PaymentPayedStatus {
+		err = usecase.paymentRepository.FinishPaymentWithSuccess(ctx, event.PaymentID, event.GatewayPaymentID)
+	} else {
+		err = usecase.paymentRepository.FinishPaymentWithError(ctx, event.PaymentID)
+	}
+
+	if err != nil {
+		return responses.GetResponseError(err, "PaymentWebhookService -> FinishPayment")
+	}
+
+	return nil
+}
+
+// orderPaymentPrice is the amount charged by the payment. The QR Code of a split order only charges
+// the rest of the order total
+func orderPaymentPrice(order dto.OrderResponse, paymentID uint) entity.Money {
+	for _, payment := range order.Payments {
+		if payment.PaymentID == paymentID {
+			return payment.TotalPrice
+		}
+	}
+
+	return order.TotalPrice
+}
+
+// validatePaidAmount rejects the approved payments with another amount, because the customer can
+// change the amount in some payment apps
+func validatePaidAmount(event dto.PaymentWebhookEvent, totalPrice entity.Money) error {
+	if event.PaidAmount.IsZero() || event.PaymentStatus != entity.PaymentPayedStatus {
+		return nil
+	}
+
+	if !event.PaidAmount.Equal(totalPrice) {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The paid amount %v is not the payment total %v", event.PaidAmount, totalPrice),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestHandlePaymentWebhookServices(t *testing.T) {
+	t.Parallel()
+
+	webhook := dto.PaymentWebhook{
+		Body: []byte(`{"orderId": 10, "paymentId": 20, "status": "approved"}`),
+	}
+
+	paidEvent := dto.PaymentWebhookEvent{
+		EventKey:         "sandbox:20:approved",
+		OrderID:          10,
+		PaymentID:        20,
+		GatewayPaymentID: "sandbox-20",
+		PaymentStatus:    entity.PaymentPayedStatus,
+		OrderStatus:      entity.OrderStatusCreated,
+		Reason:           "Payment confirmed",
+		Finished:         true,
+	}
+
+	t.Run("got paying order finished by provider webhook in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(paidEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:         "sandbox:20:approved",
+			OrderID:          10,
+			PaymentID:        20,
+			PaymentStatus:    entity.PaymentPayedStatus,
+			GatewayPaymentID: "sandbox-20",
+			OrderStatus:      entity.OrderStatusCreated,
+			Reason:           "Payment confirmed",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertExpectations(t)
+		mockEventRepo.AssertCalled(t, "Publish", ctx, dto.OrderEvent{
+			OrderID:    10,
+			FromStatus: entity.OrderStatusPaying,
+			ToStatus:   entity.OrderStatusCreated,
+			Actor:      entity.OrderActorPaymentGateway,
+		})
+	})
+
+	t.Run("got nothing changed when payment is not finished in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(dto.PaymentWebhookEvent{}, nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment", mock.Anything, mock.Anything)
+		mockPaymentRepo.AssertNotCalled(t, "GetPaymentById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got payment finished when it has no order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		paymentEvent := paidEvent
+		paymentEvent.OrderID = 0
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(paymentEvent, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(20)).Return(payingPaymentDetails, nil)
+		mockPaymentRepo.On("FinishPaymentWithSuccess", ctx, uint(20), "sandbox-20").Return(nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertExpectations(t)
+		mockOrderRepo.AssertNotCalled(t, "GetOrderById", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got duplicated notification ignored when payment is finished in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		paymentEvent := paidEvent
+		paymentEvent.OrderID = 0
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(paymentEvent, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(20)).Return(creditPaymentDetails, nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "FinishPaymentWithSuccess", mock.Anything, mock.Anything, mock.Anything)
+		mockPaymentRepo.AssertNotCalled(t, "FinishPaymentWithError", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got unprocessable entity when payment does not belong to order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(paidEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   99,
+		}, nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.Error(t, err)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got not found when provider is not registered in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProviderByName", "unknown").Return(nil, &responses.BusinessResponse{
+			StatusCode: http.StatusNotFound,
+			Message:    "The payment provider is not registered",
+		})
+
+		err := sut.Execute(ctx, "unknown", webhook)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got error when provider can not read the webhook in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderMercadoPago).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(dto.PaymentWebhookEvent{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "The webhook signature is not valid",
+		})
+
+		err := sut.Execute(ctx, entity.PaymentProviderMercadoPago, webhook)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+	})
+
+	t.Run("got error when pix paid amount is not the order total in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		pixEvent := paidEvent
+		pixEvent.EventKey = "pix:E00000000202410181200abcdef12345"
+		pixEvent.GatewayPaymentID = "E00000000202410181200abcdef12345"
+		pixEvent.PaidAmount = entity.NewMoney(1000)
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderPIX).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(pixEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+			TotalPrice:  entity.NewMoney(2590),
+		}, nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderPIX, webhook)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got pix order finished when paid amount is the order total in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		pixEvent := paidEvent
+		pixEvent.EventKey = "pix:E00000000202410181200abcdef12345"
+		pixEvent.GatewayPaymentID = "E00000000202410181200abcdef12345"
+		pixEvent.PaidAmount = entity.NewMoney(2590)
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderPIX).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(pixEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+			TotalPrice:  entity.NewMoney(2590),
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:         "pix:E00000000202410181200abcdef12345",
+			OrderID:          10,
+			PaymentID:        20,
+			PaymentStatus:    entity.PaymentPayedStatus,
+			GatewayPaymentID: "E00000000202410181200abcdef12345",
+			OrderStatus:      entity.OrderStatusCreated,
+			Reason:           "Payment confirmed",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderPIX, webhook)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("got split order finished when pix paid amount is the qr code part in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		pixEvent := paidEvent
+		pixEvent.PaidAmount = entity.NewMoney(1090)
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderPIX).Return(mockProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(pixEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+			TotalPrice:  entity.NewMoney(2590),
+			Payments: []dto.OrderPaymentResponse{
+				{PaymentID: 19, PaymentType: entity.PaymentCreditType, TotalPrice: entity.NewMoney(1500)},
+				{PaymentID: 20, PaymentType: entity.PaymentPIXType, TotalPrice: entity.NewMoney(1090)},
+			},
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, mock.Anything).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderPIX, webhook)
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got paid parts refunded when split order is canceled by provider webhook in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockCreditProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewHandlePaymentWebhookUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(mockPaymentRepo, mockProviders))
+
+		ctx := context.TODO()
+
+		rejectedEvent := paidEvent
+		rejectedEvent.EventKey = "sandbox:20:rejected"
+		rejectedEvent.PaymentStatus = entity.PaymentErrorStatus
+		rejectedEvent.OrderStatus = entity.OrderStatusCanceled
+
+		creditPart := splitCreditPaymentDetails
+		creditPart.PaymentId = 19
+
+		mockProviders.On("GetProviderByName", entity.PaymentProviderSandbox).Return(mockProvider, nil)
+		mockProviders.On("GetProvider", entity.PaymentCreditType).Return(mockCreditProvider, nil)
+		mockProvider.On("ParseWebhook", ctx, webhook).Return(rejectedEvent, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(dto.OrderResponse{
+			OrderId:     10,
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+			Payments: []dto.OrderPaymentResponse{
+				{PaymentID: 19, PaymentType: entity.PaymentCreditType},
+				{PaymentID: 20, PaymentType: entity.PaymentQRCodeType},
+			},
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, mock.Anything).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(19)).Return(creditPart, nil)
+		mockCreditProvider.On("Refund", ctx, creditPart).Return(paymentGatewayResponse, nil)
+		mockPaymentRepo.On("RefundPayment", ctx, uint(19)).Return(nil)
+
+		err := sut.Execute(ctx, entity.PaymentProviderSandbox, webhook)
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertCalled(t, "RefundPayment", ctx, uint(19))
+		mockPaymentRepo.AssertNotCalled(t, "GetPaymentById", ctx, uint(20))
+	})
+}
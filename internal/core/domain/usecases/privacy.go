@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type ExportCustomerDataUseCase struct {
+	customerRepo repository.CustomerRepository
+	orderRepo    repository.OrderRepository
+	loyaltyRepo  repository.LoyaltyRepository
+	repository   repository.PrivacyRepository
+	adminGroup   string
+}
+
+type EraseCustomerDataUseCase struct {
+	customerRepo repository.CustomerRepository
+	repository   repository.PrivacyRepository
+	adminGroup   string
+}
+
+func NewExportCustomerDataUseCase(
+	customerRepo repository.CustomerRepository,
+	orderRepo repository.OrderRepository,
+	loyaltyRepo repository.LoyaltyRepository,
+	repository repository.PrivacyRepository,
+	adminGroup string,
+) *ExportCustomerDataUseCase {
+	return &ExportCustomerDataUseCase{
+		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
+		loyaltyRepo:  loyaltyRepo,
+		repository:   repository,
+		adminGroup:   adminGroup,
+	}
+}
+
+func NewEraseCustomerDataUseCase(
+	customerRepo repository.CustomerRepository,
+	repository repository.PrivacyRepository,
+	adminGroup string,
+) *EraseCustomerDataUseCase {
+	return &EraseCustomerDataUseCase{
+		customerRepo: customerRepo,
+		repository:   repository,
+		adminGroup:   adminGroup,
+	}
+}
+
+// Execute returns all the data of the customer. The export is only returned after its audit is
+// written. The routes of the customer data only allow the customer itself and the admins
+func (service *ExportCustomerDataUseCase) Execute(ctx context.Context, customerID uint, claims dto.TokenClaims) (dto.CustomerDataExport, error) {
+	customer, err := service.customerRepo.GetCustomerById(ctx, customerID)
+
+	if err != nil {
+		return dto.CustomerDataExport{}, responses.GetResponseError(err, "PrivacyService -> GetCustomerById")
+	}
+
+	orders, err := service.getAllOrders(ctx, customerID)
+
+	if err != nil {
+		return dto.CustomerDataExport{}, err
+	}
+
+	payments, err := service.repository.GetCustomerPayments(ctx, customerID)
+
+	if err != nil {
+		return dto.CustomerDataExport{}, responses.GetResponseError(err, "PrivacyService -> GetCustomerPayments")
+	}
+
+	transactions, err := service.loyaltyRepo.GetTransactions(ctx, customerID)
+
+	if err != nil {
+		return dto.CustomerDataExport{}, responses.GetResponseError(err, "PrivacyService -> GetTransactions")
+	}
+
+	request := newDataSubjectRequest(customerID, entity.DataSubjectRequestExport, claims, service.adminGroup)
+
+	err = service.repository.CreateDataSubjectRequest(ctx, request)
+
+	if err != nil {
+		return dto.CustomerDataExport{}, responses.GetResponseError(err, "PrivacyService -> CreateDataSubjectRequest")
+	}
+
+	return dto.CustomerDataExport{
+		Customer:            customer,
+		Orders:              orders,
+		Payments:            payments,
+		LoyaltyTransactions: transactions,
+		ExportedAt:          time.Now(),
+	}, nil
+}
+
+// getAllOrders reads all the pages of the customer orders
+func (service *ExportCustomerDataUseCase) getAllOrders(ctx context.Context, customerID uint) ([]dto.OrderResponse, error) {
+	orders := []dto.OrderResponse{}
+	filter := dto.CustomerOrdersFilter{
+		CustomerID: customerID,
+		Limit:      entity.OrderPageMaxLimit,
+	}
+
+	for {
+		page, err := service.orderRepo.GetCustomerOrders(ctx, filter)
+
+		if err != nil {
+			return []dto.OrderResponse{}, responses.GetResponseError(err, "PrivacyService -> GetCustomerOrders")
+		}
+
+		orders = append(orders, page.Orders...)
+
+		if page.NextCursor == nil {
+			return orders, nil
+		}
+
+		filter.Cursor = *page.NextCursor
+	}
+}
+
+// Execute anonymizes the customer and removes it from Cognito. The orders are kept without the
+// customer, so the sales reports do not change
+func (service *EraseCustomerDataUseCase) Execute(ctx context.Context, customerID uint, claims dto.TokenClaims) error {
+	customer, err := service.customerRepo.GetCustomerById(ctx, customerID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PrivacyService -> GetCustomerById")
+	}
+
+	request := newDataSubjectRequest(customerID, entity.DataSubjectRequestErasure, claims, service.adminGroup)
+
+	err = service.repository.EraseCustomer(ctx, customer, request)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PrivacyService -> EraseCustomer")
+	}
+
+	return nil
+}
+
+func newDataSubjectRequest(customerID uint, requestType string, claims dto.TokenClaims, adminGroup string) dto.DataSubjectRequest {
+	actor := entity.DataSubjectActorCustomer
+
+	if slices.Contains(claims.Groups, adminGroup) {
+		actor = entity.DataSubjectActorAdmin
+	}
+
+	return dto.DataSubjectRequest{
+		CustomerID:  customerID,
+		Type:        requestType,
+		Actor:       actor,
+		RequestedBy: claims.Subject,
+	}
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+var (
+	customerClaims = dto.TokenClaims{
+		Subject:  "5c1e2a4d-customer",
+		Username: "17107972073",
+		Groups:   []string{"user"},
+	}
+
+	adminClaims = dto.TokenClaims{
+		Subject:  "9b7f3e1c-admin",
+		Username: "07073286083",
+		Groups:   []string{"admin"},
+	}
+)
+
+func TestPrivacyServices(t *testing.T) {
+	t.Run("got all the customer data when exporting in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockLoyaltyRepo := new(MockLoyaltyRepository)
+		mockRepo := new(MockPrivacyRepository)
+		sut := NewExportCustomerDataUseCase(mockCustomerRepo, mockOrderRepo, mockLoyaltyRepo, mockRepo, "admin")
+
+		ctx := context.TODO()
+		nextCursor := "cursor"
+		payments := []dto.PaymentDetails{orderPaymentDetails}
+
+		mockCustomerRepo.On("GetCustomerById", ctx, uint(1)).Return(customerById, nil)
+		mockOrderRepo.On("GetCustomerOrders", ctx, dto.CustomerOrdersFilter{CustomerID: 1, Limit: entity.OrderPageMaxLimit}).
+			Return(dto.OrderPage{Orders: ordersList, NextCursor: &nextCursor}, nil)
+		mockOrderRepo.On("GetCustomerOrders", ctx, dto.CustomerOrdersFilter{CustomerID: 1, Cursor: nextCursor, Limit: entity.OrderPageMaxLimit}).
+			Return(dto.OrderPage{Orders: ordersList}, nil)
+		mockRepo.On("GetCustomerPayments", ctx, uint(1)).Return(payments, nil)
+		mockLoyaltyRepo.On("GetTransactions", ctx, uint(1)).Return(loyaltyTransactions, nil)
+		mockRepo.On("CreateDataSubjectRequest", ctx, dto.DataSubjectRequest{
+			CustomerID:  1,
+			Type:        entity.DataSubjectRequestExport,
+			Actor:       entity.DataSubjectActorCustomer,
+			RequestedBy: customerClaims.Subject,
+		}).Return(nil)
+
+		response, err := sut.Execute(ctx, 1, customerClaims)
+
+		assert.NoError(t, err)
+		assert.Equal(t, customerById, response.Customer)
+		assert.Len(t, response.Orders, 2)
+		assert.Equal(t, payments, response.Payments)
+		assert.Equal(t, loyaltyTransactions, response.LoyaltyTransactions)
+		assert.False(t, response.ExportedAt.IsZero())
+	})
+
+	t.Run("got error when the export audit fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockLoyaltyRepo := new(MockLoyaltyRepository)
+		mockRepo := new(MockPrivacyRepository)
+		sut := NewExportCustomerDataUseCase(mockCustomerRepo, mockOrderRepo, mockLoyaltyRepo, mockRepo, "admin")
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, uint(1)).Return(customerById, nil)
+		mockOrderRepo.On("GetCustomerOrders", ctx, mock.Anything).Return(dto.OrderPage{Orders: ordersList}, nil)
+		mockRepo.On("GetCustomerPayments", ctx, uint(1)).Return([]dto.PaymentDetails{}, nil)
+		mockLoyaltyRepo.On("GetTransactions", ctx, uint(1)).Return(loyaltyTransactions, nil)
+		mockRepo.On("CreateDataSubjectRequest", ctx, mock.Anything).Return(&responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "database error",
+		})
+
+		response, err := sut.Execute(ctx, 1, customerClaims)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+	})
+
+	t.Run("got success when an admin erases the customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPrivacyRepository)
+		sut := NewEraseCustomerDataUseCase(mockCustomerRepo, mockRepo, "admin")
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, uint(1)).Return(customerById, nil)
+		mockRepo.On("EraseCustomer", ctx, customerById, dto.DataSubjectRequest{
+			CustomerID:  1,
+			Type:        entity.DataSubjectRequestErasure,
+			Actor:       entity.DataSubjectActorAdmin,
+			RequestedBy: adminClaims.Subject,
+		}).Return(nil)
+
+		err := sut.Execute(ctx, 1, adminClaims)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when erasing unknown customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPrivacyRepository)
+		sut := NewEraseCustomerDataUseCase(mockCustomerRepo, mockRepo, "admin")
+
+		ctx := context.TODO()
+
+		mockCustomerRepo.On("GetCustomerById", ctx, uint(99)).Return(dto.Customer{}, customerNotFound)
+
+		err := sut.Execute(ctx, 99, customerClaims)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "EraseCustomer", mock.Anything, mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+}
@@ -1,6 +1,9 @@
 package usecases
 
-import "github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+import (
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+)
 
 type ValidateProductCategoryUseCase struct{}
 
@@ -9,7 +12,7 @@ func NewValidateProductCategoryUseCase() *ValidateProductCategoryUseCase {
 }
 
 func (usecase *ValidateProductCategoryUseCase) Execute(product dto.ProductForm) bool {
-	if product.Category == "Combo" {
+	if product.Category == entity.CategoryCombo {
 		return product.ComboProductsIds != nil && len(*product.ComboProductsIds) > 0
 	}
 
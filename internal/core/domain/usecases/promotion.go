@@ -0,0 +1,373 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type CreatePromotionUseCase struct {
+	repository repository.PromotionRepository
+}
+
+type GetPromotionsUseCase struct {
+	repository repository.PromotionRepository
+}
+
+type GetPromotionByIdUseCase struct {
+	repository repository.PromotionRepository
+}
+
+type UpdatePromotionUseCase struct {
+	repository repository.PromotionRepository
+}
+
+type DeletePromotionUseCase struct {
+	repository repository.PromotionRepository
+}
+
+type ApplyPromotionsUseCase struct {
+	repository          repository.PromotionRepository
+	verifyCustomerToken *VerifyCustomerTokenUseCase
+}
+
+type GetOrderPriceUseCase struct {
+	calculateOrderPrice *CalculateOrderPriceUseCase
+	applyPromotions     *ApplyPromotionsUseCase
+	applyLoyaltyPoints  *ApplyLoyaltyPointsUseCase
+}
+
+func NewCreatePromotionUseCase(repository repository.PromotionRepository) *CreatePromotionUseCase {
+	return &CreatePromotionUseCase{
+		repository: repository,
+	}
+}
+
+func NewGetPromotionsUseCase(repository repository.PromotionRepository) *GetPromotionsUseCase {
+	return &GetPromotionsUseCase{
+		repository: repository,
+	}
+}
+
+func NewGetPromotionByIdUseCase(repository repository.PromotionRepository) *GetPromotionByIdUseCase {
+	return &GetPromotionByIdUseCase{
+		repository: repository,
+	}
+}
+
+func NewUpdatePromotionUseCase(repository repository.PromotionRepository) *UpdatePromotionUseCase {
+	return &UpdatePromotionUseCase{
+		repository: repository,
+	}
+}
+
+func NewDeletePromotionUseCase(repository repository.PromotionRepository) *DeletePromotionUseCase {
+	return &DeletePromotionUseCase{
+		repository: repository,
+	}
+}
+
+func NewApplyPromotionsUseCase(
+	repository repository.PromotionRepository,
+	verifyCustomerToken *VerifyCustomerTokenUseCase,
+) *ApplyPromotionsUseCase {
+	return &ApplyPromotionsUseCase{
+		repository:          repository,
+		verifyCustomerToken: verifyCustomerToken,
+	}
+}
+
+func NewGetOrderPriceUseCase(
+	calculateOrderPrice *CalculateOrderPriceUseCase,
+	applyPromotions *ApplyPromotionsUseCase,
+	applyLoyaltyPoints *ApplyLoyaltyPointsUseCase,
+) *GetOrderPriceUseCase {
+	return &GetOrderPriceUseCase{
+		calculateOrderPrice: calculateOrderPrice,
+		applyPromotions:     applyPromotions,
+		applyLoyaltyPoints:  applyLoyaltyPoints,
+	}
+}
+
+func (service *CreatePromotionUseCase) Execute(ctx context.Context, promotion dto.PromotionForm) (uint, error) {
+	promotion.Code = normalizeCouponCode(promotion.Code)
+
+	err := promotionRule(promotion).Validate()
+
+	if err != nil {
+		return 0, err
+	}
+
+	promotionId, err := service.repository.CreatePromotion(ctx, promotion)
+
+	if err != nil {
+		return 0, responses.GetResponseError(err, "PromotionService")
+	}
+
+	return promotionId, nil
+}
+
+func (service *GetPromotionsUseCase) Execute(ctx context.Context) ([]dto.PromotionResponse, error) {
+	promotions, err := service.repository.GetPromotions(ctx)
+
+	if err != nil {
+		return []dto.PromotionResponse{}, responses.GetResponseError(err, "PromotionService")
+	}
+
+	return promotions, nil
+}
+
+func (service *GetPromotionByIdUseCase) Execute(ctx context.Context, id uint) (dto.PromotionResponse, error) {
+	promotion, err := service.repository.GetPromotionById(ctx, id)
+
+	if err != nil {
+		return dto.PromotionResponse{}, responses.GetResponseError(err, "PromotionService")
+	}
+
+	return promotion, nil
+}
+
+func (service *UpdatePromotionUseCase) Execute(ctx context.Context, promotion dto.PromotionForm) error {
+	promotion.Code = normalizeCouponCode(promotion.Code)
+
+	err := promotionRule(promotion).Validate()
+
+	if err != nil {
+		return err
+	}
+
+	err = service.repository.UpdatePromotion(ctx, promotion)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PromotionService")
+	}
+
+	return nil
+}
+
+func (service *DeletePromotionUseCase) Execute(ctx context.Context, id uint) error {
+	err := service.repository.DeletePromotion(ctx, id)
+
+	if err != nil {
+		return responses.GetResponseError(err, "PromotionService")
+	}
+
+	return nil
+}
+
+// Execute applies the automatic promotions and the coupon to the priced products. Each discount is
+// calculated over the products price and the discounts together never exceed it. The automatic
+// promotions which do not apply are ignored, but a coupon which does not apply fails with the reason.
+// The promotions limited by customer are only for the customer with the authenticatedCPF
+func (service *ApplyPromotionsUseCase) Execute(
+	ctx context.Context,
+	orderProducts []dto.OrderProduct,
+	productsPrice entity.Money,
+	customerID *uint,
+	authenticatedCPF string,
+	couponCode *string,
+) ([]dto.OrderDiscount, entity.Money, error) {
+	couponCode = normalizeCouponCode(couponCode)
+
+	promotions, err := service.repository.GetActivePromotions(ctx, couponCode)
+
+	if err != nil {
+		return nil, entity.Money{}, responses.GetResponseError(err, "PromotionService -> GetActivePromotions")
+	}
+
+	items := promotionItems(orderProducts)
+	now := time.Now()
+	totalPrice := productsPrice
+	couponFound := false
+
+	var discounts []dto.OrderDiscount
+
+	for _, promotion := range promotions {
+		isCoupon := promotion.Code != nil
+		couponFound = couponFound || isCoupon
+
+		discount, reason, err := service.discount(ctx, promotion, items, customerID, authenticatedCPF, now)
+
+		if err != nil {
+			return nil, entity.Money{}, err
+		}
+
+		discount = entity.NewMoney(min(discount.Cents, totalPrice.Cents))
+
+		if reason == "" && discount.IsZero() {
+			reason = "does not apply to the order products"
+		}
+
+		if reason != "" {
+			if isCoupon {
+				return nil, entity.Money{}, &responses.BusinessResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    fmt.Sprintf("The coupon %v %v", *promotion.Code, reason),
+				}
+			}
+
+			continue
+		}
+
+		discounts = append(discounts, dto.OrderDiscount{
+			PromotionID: promotion.Id,
+			Description: promotion.Name,
+			Code:        promotion.Code,
+			Amount:      discount,
+		})
+
+		totalPrice = totalPrice.Sub(discount)
+	}
+
+	if couponCode != nil && !couponFound {
+		return nil, entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The coupon %v is not valid", *couponCode),
+		}
+	}
+
+	return discounts, totalPrice, nil
+}
+
+// discount returns the reason when the promotion can not be used now or by the customer
+func (service *ApplyPromotionsUseCase) discount(
+	ctx context.Context,
+	promotion dto.PromotionResponse,
+	items []entity.PromotionItem,
+	customerID *uint,
+	authenticatedCPF string,
+	now time.Time,
+) (entity.Money, string, error) {
+	rule := promotionRule(dto.PromotionForm(promotion))
+
+	if !rule.IsValidAt(now) {
+		return entity.Money{}, "is not valid at this time", nil
+	}
+
+	if promotion.UsageLimitPerCustomer > 0 {
+		if customerID == nil {
+			return entity.Money{}, "is only for identified customers", nil
+		}
+
+		err := service.verifyCustomerToken.Execute(ctx, *customerID, authenticatedCPF)
+
+		if err != nil {
+			return entity.Money{}, "", err
+		}
+
+		usages, err := service.repository.CountCustomerUsages(ctx, promotion.Id, *customerID)
+
+		if err != nil {
+			return entity.Money{}, "", responses.GetResponseError(err, "PromotionService -> CountCustomerUsages")
+		}
+
+		if usages >= int64(promotion.UsageLimitPerCustomer) {
+			return entity.Money{}, "was already used by the customer", nil
+		}
+	}
+
+	return rule.Discount(items), "", nil
+}
+
+// Execute prices the order like its creation, so the kiosk can charge the total with the discounts
+// and the redeemed points
+func (service *GetOrderPriceUseCase) Execute(ctx context.Context, form dto.OrderPriceForm) (dto.OrderPriceResponse, error) {
+	orderProducts, productsPrice, err := service.calculateOrderPrice.Execute(ctx, form.OrderProduct, form.TotalPrice)
+
+	if err != nil {
+		return dto.OrderPriceResponse{}, responses.GetResponseError(err, "OrderPriceService -> CalculateOrderPrice")
+	}
+
+	discounts, totalPrice, err := service.applyPromotions.Execute(ctx, orderProducts, productsPrice, form.CustomerID, form.AuthenticatedCPF, form.CouponCode)
+
+	if err != nil {
+		return dto.OrderPriceResponse{}, responses.GetResponseError(err, "OrderPriceService -> ApplyPromotions")
+	}
+
+	loyaltyDiscount, err := service.applyLoyaltyPoints.Execute(ctx, form.CustomerID, form.AuthenticatedCPF, form.LoyaltyPoints, totalPrice)
+
+	if err != nil {
+		return dto.OrderPriceResponse{}, responses.GetResponseError(err, "OrderPriceService -> ApplyLoyaltyPoints")
+	}
+
+	totalPrice = totalPrice.Sub(loyaltyDiscount)
+
+	if discounts == nil {
+		discounts = []dto.OrderDiscount{}
+	}
+
+	return dto.OrderPriceResponse{
+		ProductsPrice:   productsPrice,
+		DiscountPrice:   productsPrice.Sub(totalPrice),
+		TotalPrice:      totalPrice,
+		Discounts:       discounts,
+		LoyaltyDiscount: loyaltyDiscount,
+	}, nil
+}
+
+// normalizeCouponCode makes the coupons case insensitive, since the customers type them in the kiosk
+func normalizeCouponCode(code *string) *string {
+	if code == nil {
+		return nil
+	}
+
+	normalized := strings.ToUpper(strings.TrimSpace(*code))
+
+	if normalized == "" {
+		return nil
+	}
+
+	return &normalized
+}
+
+func promotionRule(promotion dto.PromotionForm) entity.Promotion {
+	weekdays := []time.Weekday{}
+
+	for _, weekday := range promotion.Weekdays {
+		weekdays = append(weekdays, time.Weekday(weekday))
+	}
+
+	return entity.Promotion{
+		Type:         promotion.Type,
+		Percentage:   promotion.Percentage,
+		Amount:       promotion.Amount,
+		BuyQuantity:  promotion.BuyQuantity,
+		FreeQuantity: promotion.FreeQuantity,
+		Category:     promotion.Category,
+		ProductID:    promotion.ProductID,
+		StartsAt:     promotion.StartsAt,
+		EndsAt:       promotion.EndsAt,
+		Weekdays:     weekdays,
+		StartTime:    promotion.StartTime,
+		EndTime:      promotion.EndTime,
+	}
+}
+
+// promotionItems prices each item with its extras, which are discounted together with the product
+func promotionItems(orderProducts []dto.OrderProduct) []entity.PromotionItem {
+	items := []entity.PromotionItem{}
+
+	for _, orderProduct := range orderProducts {
+		unitPrice := orderProduct.ProductPrice
+
+		for _, modifier := range orderProduct.Modifiers {
+			unitPrice = unitPrice.Add(modifier.Price)
+		}
+
+		items = append(items, entity.PromotionItem{
+			ProductID: orderProduct.ProductID,
+			Category:  orderProduct.ProductCategory,
+			UnitPrice: unitPrice,
+			Quantity:  orderProduct.Quantity,
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,281 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestPromotionServices(t *testing.T) {
+	t.Run("got success when creating promotion with normalized coupon code in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockPromotionRepository)
+		sut := NewCreatePromotionUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		code := " bemvindo "
+		form := dto.PromotionForm{
+			Name:   "Cupom de boas-vindas",
+			Code:   &code,
+			Type:   entity.PromotionTypeFixedAmount,
+			Amount: entity.NewMoney(500),
+			Active: true,
+		}
+
+		mockRepo.On("CreatePromotion", ctx, mock.MatchedBy(func(promotion dto.PromotionForm) bool {
+			return *promotion.Code == welcomeCouponCode
+		})).Return(uint(2), nil)
+
+		response, err := sut.Execute(ctx, form)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), response)
+	})
+
+	t.Run("got error when creating promotion with invalid rules in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockPromotionRepository)
+		sut := NewCreatePromotionUseCase(mockRepo)
+
+		response, err := sut.Execute(context.TODO(), dto.PromotionForm{
+			Name:       "Desconto impossivel",
+			Type:       entity.PromotionTypePercentage,
+			Percentage: 150,
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "CreatePromotion", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when updating unknown promotion in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockPromotionRepository)
+		sut := NewUpdatePromotionUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		form := dto.PromotionForm{
+			Id:         99,
+			Name:       "Bebidas com 10% de desconto",
+			Type:       entity.PromotionTypePercentage,
+			Percentage: 10,
+		}
+
+		mockRepo.On("UpdatePromotion", ctx, form).Return(promotionNotFound)
+
+		err := sut.Execute(ctx, form)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusNotFound, businessError.StatusCode)
+	})
+
+	t.Run("got automatic promotion discount when applying promotions in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetActivePromotions", ctx, (*string)(nil)).Return([]dto.PromotionResponse{beveragePromotion}, nil)
+
+		discounts, totalPrice, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, nil, "", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(discounts))
+		assert.Equal(t, uint(1), discounts[0].PromotionID)
+		assert.Equal(t, entity.NewMoney(23450), discounts[0].Amount)
+		assert.Equal(t, entity.NewMoney(1211050), totalPrice)
+	})
+
+	t.Run("got coupon and automatic discounts when applying promotions in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		code := "bemvindo"
+
+		mockRepo.On("GetActivePromotions", ctx, &welcomeCouponCode).
+			Return([]dto.PromotionResponse{beveragePromotion, welcomeCouponPromotion}, nil)
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("CountCustomerUsages", ctx, uint(2), customerId).Return(int64(0), nil)
+
+		discounts, totalPrice, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, &customerId, customerResponse.CPF, &code)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(discounts))
+		assert.Equal(t, &welcomeCouponCode, discounts[1].Code)
+		assert.Equal(t, entity.NewMoney(500000), discounts[1].Amount)
+		assert.Equal(t, entity.NewMoney(711050), totalPrice)
+	})
+
+	t.Run("got discounts limited to the products price when applying promotions in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		hugePromotion := dto.PromotionResponse{
+			Id:     3,
+			Name:   "Tudo por conta da casa",
+			Type:   entity.PromotionTypeFixedAmount,
+			Amount: entity.NewMoney(9999900),
+			Active: true,
+		}
+
+		mockRepo.On("GetActivePromotions", ctx, (*string)(nil)).
+			Return([]dto.PromotionResponse{beveragePromotion, hugePromotion}, nil)
+
+		discounts, totalPrice, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, nil, "", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(discounts))
+		assert.Equal(t, entity.NewMoney(1211050), discounts[1].Amount)
+		assert.Equal(t, entity.NewMoney(0), totalPrice)
+	})
+
+	t.Run("got error when applying unknown coupon in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		code := "NAOEXISTE"
+
+		mockRepo.On("GetActivePromotions", ctx, &code).Return([]dto.PromotionResponse{beveragePromotion}, nil)
+
+		discounts, _, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, nil, "", &code)
+
+		assert.Error(t, err)
+		assert.Empty(t, discounts)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when applying coupon already used by the customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetActivePromotions", ctx, &welcomeCouponCode).
+			Return([]dto.PromotionResponse{welcomeCouponPromotion}, nil)
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+		mockRepo.On("CountCustomerUsages", ctx, uint(2), customerId).Return(int64(1), nil)
+
+		discounts, _, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, &customerId, customerResponse.CPF, &welcomeCouponCode)
+
+		assert.Error(t, err)
+		assert.Empty(t, discounts)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+		assert.Equal(t, "The coupon BEMVINDO was already used by the customer", businessError.Message)
+	})
+
+	t.Run("got error when applying limited coupon with the token of another customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetActivePromotions", ctx, &welcomeCouponCode).
+			Return([]dto.PromotionResponse{welcomeCouponPromotion}, nil)
+		mockCustomerRepo.On("GetCustomerById", ctx, customerId).Return(customerResponse, nil)
+
+		_, _, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, &customerId, "07073286083", &welcomeCouponCode)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "CountCustomerUsages", mock.Anything, mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusForbidden, businessError.StatusCode)
+	})
+
+	t.Run("got error when applying limited coupon without customer in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(mockCustomerRepo))
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetActivePromotions", ctx, &welcomeCouponCode).
+			Return([]dto.PromotionResponse{welcomeCouponPromotion}, nil)
+
+		_, _, err := sut.Execute(ctx, orderCreation.OrderProduct, orderCreation.TotalPrice, nil, "", &welcomeCouponCode)
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "CountCustomerUsages", mock.Anything, mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got order price with discounts in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProductRepo := new(MockProductRepository)
+		mockRepo := new(MockPromotionRepository)
+		sut := NewGetOrderPriceUseCase(NewCalculateOrderPriceUseCase(mockProductRepo), NewApplyPromotionsUseCase(mockRepo, NewVerifyCustomerTokenUseCase(new(MockCustomerRepository))), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(2)).Return(orderedBeverage, nil)
+		mockRepo.On("GetActivePromotions", ctx, (*string)(nil)).Return([]dto.PromotionResponse{beveragePromotion}, nil)
+
+		response, err := sut.Execute(ctx, dto.OrderPriceForm{
+			TotalPrice:   orderCreation.TotalPrice,
+			OrderProduct: orderCreation.OrderProduct,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NewMoney(1234500), response.ProductsPrice)
+		assert.Equal(t, entity.NewMoney(23450), response.DiscountPrice)
+		assert.Equal(t, entity.NewMoney(1211050), response.TotalPrice)
+		assert.Equal(t, 1, len(response.Discounts))
+	})
+}
@@ -2,68 +2,126 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	"strconv"
-	"strings"
-	"sync"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
 type GenerateQRCodePaymentUseCase struct {
-	repository        repository.QRCodePaymentRepository
-	orderRepository   repository.OrderRepository
-	paymentRepository repository.PaymentRepository
+	paymentProviders    repository.PaymentProviderRegistry
+	orderRepository     repository.OrderRepository
+	paymentRepository   repository.PaymentRepository
+	calculateOrderPrice *CalculateOrderPriceUseCase
+	applyPromotions     *ApplyPromotionsUseCase
+	applyLoyaltyPoints  *ApplyLoyaltyPointsUseCase
 }
 
 type FinishOrderForQRCodeUseCase struct {
-	repository        repository.QRCodePaymentRepository
-	orderRepository   repository.OrderRepository
-	paymentRepository repository.PaymentRepository
+	repository      repository.QRCodePaymentRepository
+	orderRepository repository.OrderRepository
+	orderEvents     repository.OrderEventRepository
+	refundPayments  *RefundOrderPaymentsUseCase
 }
 
 func NewGenerateQRCodePaymentUseCase(
-	repository repository.QRCodePaymentRepository,
+	paymentProviders repository.PaymentProviderRegistry,
 	orderRepository repository.OrderRepository,
 	paymentRepository repository.PaymentRepository,
+	calculateOrderPrice *CalculateOrderPriceUseCase,
+	applyPromotions *ApplyPromotionsUseCase,
+	applyLoyaltyPoints *ApplyLoyaltyPointsUseCase,
 ) *GenerateQRCodePaymentUseCase {
 	return &GenerateQRCodePaymentUseCase{
-		repository:        repository,
-		orderRepository:   orderRepository,
-		paymentRepository: paymentRepository,
+		paymentProviders:    paymentProviders,
+		orderRepository:     orderRepository,
+		paymentRepository:   paymentRepository,
+		calculateOrderPrice: calculateOrderPrice,
+		applyPromotions:     applyPromotions,
+		applyLoyaltyPoints:  applyLoyaltyPoints,
 	}
 }
 
 func NewFinishOrderForQRCodeUseCase(
 	repository repository.QRCodePaymentRepository,
 	orderRepository repository.OrderRepository,
-	paymentRepository repository.PaymentRepository,
+	orderEvents repository.OrderEventRepository,
+	refundPayments *RefundOrderPaymentsUseCase,
 ) *FinishOrderForQRCodeUseCase {
 	return &FinishOrderForQRCodeUseCase{
-		repository:        repository,
-		orderRepository:   orderRepository,
-		paymentRepository: paymentRepository,
+		repository:      repository,
+		orderRepository: orderRepository,
+		orderEvents:     orderEvents,
+		refundPayments:  refundPayments,
 	}
 }
 
 func (service *GenerateQRCodePaymentUseCase) Execute(
 	ctx context.Context,
-	token string,
 	qrOrder dto.QRCodeOrder,
 	date int64,
-	wg *sync.WaitGroup,
-	ch chan bool,
 ) (dto.QRCodeDataResponse, error) {
-	//Block this code below until this Channel be empty (by reading with <-ch)
-	ch <- true
+	// The Mercado Pago QR Code was the only one before PIX, so it is still the default
+	if qrOrder.PaymentType == "" {
+		qrOrder.PaymentType = entity.PaymentQRCodeType
+	}
+
+	if !entity.IsQRCodePaymentType(qrOrder.PaymentType) {
+		return dto.QRCodeDataResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The payment type %v is not paid with a QR Code", qrOrder.PaymentType),
+		}
+	}
+
+	provider, err := service.paymentProviders.GetProvider(qrOrder.PaymentType)
 
-	qrOrder.TicketNumber = service.orderRepository.GetNextTicketNumber(ctx, date)
+	if err != nil {
+		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
+	}
+
+	orderProducts, totalPrice, err := service.calculateOrderPrice.Execute(ctx, qrOrder.OrderProduct, qrOrder.TotalPrice)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
+	}
+
+	discounts, totalPrice, err := service.applyPromotions.Execute(ctx, orderProducts, totalPrice, qrOrder.CustomerID, qrOrder.AuthenticatedCPF, qrOrder.CouponCode)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
+	}
+
+	loyaltyDiscount, err := service.applyLoyaltyPoints.Execute(ctx, qrOrder.CustomerID, qrOrder.AuthenticatedCPF, qrOrder.LoyaltyPoints, totalPrice)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
+	}
+
+	qrOrder.OrderProduct = orderProducts
+	qrOrder.TotalPrice = totalPrice.Sub(loyaltyDiscount)
+
+	remainingPrice, err := service.remainingPrice(ctx, qrOrder)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, err
+	}
+
+	ticketNumber, err := service.orderRepository.GetNextTicketNumber(ctx, date)
+
+	if err != nil {
+		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
+	}
+
+	qrOrder.TicketNumber = ticketNumber
 
 	payment := dto.Payment{
-		TotalPrice:  qrOrder.TotalPrice,
-		PaymentType: "QR Code",
+		TotalPrice:  remainingPrice,
+		PaymentType: qrOrder.PaymentType,
 	}
 
 	paymentResponse, err := service.paymentRepository.CreatePaymentOrder(ctx, payment)
@@ -75,11 +133,17 @@ func (service *GenerateQRCodePaymentUseCase) Execute(
 	qrOrder.PaymentID = paymentResponse.PaymentId
 
 	order := dto.Order{
-		TotalPrice:   qrOrder.TotalPrice,
-		CustomerID:   qrOrder.CustomerID,
-		OrderProduct: []dto.OrderProduct(qrOrder.OrderProduct),
-		TicketNumber: qrOrder.TicketNumber,
-		PaymentID:    qrOrder.PaymentID,
+		TotalPrice:      qrOrder.TotalPrice,
+		CustomerID:      qrOrder.CustomerID,
+		GuestID:         qrOrder.GuestID,
+		OrderProduct:    []dto.OrderProduct(qrOrder.OrderProduct),
+		TicketNumber:    qrOrder.TicketNumber,
+		PaymentID:       qrOrder.PaymentID,
+		PaymentIDs:      qrOrder.PaymentIDs,
+		CouponCode:      qrOrder.CouponCode,
+		Discounts:       discounts,
+		LoyaltyPoints:   qrOrder.LoyaltyPoints,
+		LoyaltyDiscount: loyaltyDiscount,
 	}
 
 	orderResponse, err := service.orderRepository.CreatePayingOrder(ctx, order)
@@ -88,7 +152,14 @@ func (service *GenerateQRCodePaymentUseCase) Execute(
 		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
 	}
 
-	qrCode, err := service.repository.Generate(ctx, token, order, int(orderResponse.OrderId))
+	qrCode, err := provider.Authorize(ctx, dto.PaymentAuthorization{
+		PaymentID:   qrOrder.PaymentID,
+		OrderID:     orderResponse.OrderId,
+		CustomerID:  qrOrder.CustomerID,
+		TotalPrice:  remainingPrice,
+		PaymentType: qrOrder.PaymentType,
+		Order:       &order,
+	})
 
 	if err != nil {
 		errDelete := service.orderRepository.DeleteOrder(ctx, orderResponse.OrderId)
@@ -97,14 +168,49 @@ func (service *GenerateQRCodePaymentUseCase) Execute(
 			return dto.QRCodeDataResponse{}, responses.GetResponseError(errDelete, "QRCodeGeneratorService")
 		}
 
+		// The QR Code was never shown, so its payment can not be paid anymore
+		errVoid := service.paymentRepository.VoidPayment(ctx, qrOrder.PaymentID)
+
+		if errVoid != nil {
+			return dto.QRCodeDataResponse{}, responses.GetResponseError(errVoid, "QRCodeGeneratorService")
+		}
+
 		return dto.QRCodeDataResponse{}, responses.GetResponseError(err, "QRCodeGeneratorService")
 	}
 
-	// Release the channel to others process be able to start a new order creation
-	<-ch
-	wg.Done()
+	return dto.QRCodeDataResponse{
+		Data: qrCode.QRCodeData,
+		PNG:  qrCode.QRCodePNG,
+	}, nil
+}
+
+// remainingPrice is the part of the order total not paid yet by the other parts of a split order,
+// which must leave something to be paid by the QR Code
+func (service *GenerateQRCodePaymentUseCase) remainingPrice(ctx context.Context, qrOrder dto.QRCodeOrder) (entity.Money, error) {
+	if len(qrOrder.PaymentIDs) == 0 {
+		return qrOrder.TotalPrice, nil
+	}
+
+	paidPrice, err := validatePaidPayments(ctx, service.paymentRepository, qrOrder.PaymentIDs, "QRCodeGeneratorService")
+
+	if err != nil {
+		return entity.Money{}, err
+	}
+
+	if paidPrice.Cents >= qrOrder.TotalPrice.Cents {
+		return entity.Money{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The payments total %v leaves nothing of the order total %v to the QR Code", paidPrice, qrOrder.TotalPrice),
+		}
+	}
+
+	err = validateUnusedPayments(ctx, service.orderRepository, qrOrder.PaymentIDs, "QRCodeGeneratorService")
+
+	if err != nil {
+		return entity.Money{}, err
+	}
 
-	return qrCode, nil
+	return qrOrder.TotalPrice.Sub(paidPrice), nil
 }
 
 func (service *FinishOrderForQRCodeUseCase) Execute(ctx context.Context, token string, form dto.ExternalPaymentEvent) error {
@@ -120,15 +226,125 @@ func (service *FinishOrderForQRCodeUseCase) Execute(ctx context.Context, token s
 		return err
 	}
 
-	if mercadoLivrePayment.OrderStatus == "paid" {
-		ids := strings.Split(mercadoLivrePayment.ExternalReference, "|")
+	outcome, finished := entity.ResolveQRCodePayment(
+		mercadoLivrePayment.Status,
+		mercadoLivrePayment.OrderStatus,
+		mercadoLivrePayment.LastPaymentStatus,
+	)
 
-		orderID, _ := strconv.Atoi(ids[0])
-		paymentID, _ := strconv.Atoi(ids[1])
+	if !finished {
+		return nil
+	}
+
+	orderID, paymentID, err := service.parseExternalReference(mercadoLivrePayment.ExternalReference)
 
-		service.paymentRepository.FinishPaymentWithSuccess(ctx, uint(paymentID))
-		service.orderRepository.FinishOrderWithPayment(ctx, uint(orderID), uint(paymentID))
+	if err != nil {
+		return err
+	}
+
+	order, err := service.orderRepository.GetOrderById(ctx, orderID)
+
+	if err != nil {
+		return responses.GetResponseError(err, "FinishOrderForQRCodeService -> GetOrderById")
+	}
+
+	if order.PaymentID != paymentID {
+		return &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The payment %v does not belong to the order %v", paymentID, orderID),
+		}
+	}
+
+	result := dto.OrderPaymentResult{
+		EventKey:      fmt.Sprintf("merchant_order:%v:%v", mercadoLivrePayment.ID, outcome.Result),
+		OrderID:       orderID,
+		PaymentID:     paymentID,
+		PaymentStatus: outcome.PaymentStatus,
+		OrderStatus:   outcome.OrderStatus,
+		Reason:        outcome.Reason,
+	}
+
+	if outcome.PaymentStatus == entity.PaymentPayedStatus {
+		result.GatewayPaymentID = mercadoLivrePayment.ApprovedPaymentID
+	}
+
+	_, err = finishPayingOrder(ctx, service.orderRepository, service.orderEvents, service.refundPayments, order, result)
+
+	return err
+}
+
+// finishPayingOrder applies the payment result of a paying order and returns false when it was already applied.
+// The order canceled or finished by another notification is a conflict, which is not retried
+func finishPayingOrder(
+	ctx context.Context,
+	orderRepository repository.OrderRepository,
+	orderEvents repository.OrderEventRepository,
+	refundPayments *RefundOrderPaymentsUseCase,
+	order dto.OrderResponse,
+	result dto.OrderPaymentResult,
+) (bool, error) {
+	applied, err := orderRepository.FinishOrderPayment(ctx, result)
+
+	var localError *responses.LocalError
+
+	if errors.As(err, &localError) && localError.Code == responses.DATABASE_CONFLICT_ERROR {
+		log.Print("finish paying order", map[string]interface{}{
+			"orderId":  result.OrderID,
+			"eventKey": result.EventKey,
+			"error":    err.Error(),
+		})
+		return false, nil
+	}
+
+	if err != nil {
+		return false, responses.GetResponseError(err, "PaymentService -> FinishOrderPayment")
+	}
+
+	if applied {
+		publishOrderEvent(ctx, orderEvents, dto.OrderStatusTransition{
+			OrderID:    result.OrderID,
+			FromStatus: entity.OrderStatusPaying,
+			ToStatus:   result.OrderStatus,
+			Actor:      entity.OrderActorPaymentGateway,
+		})
+	}
+
+	if applied && result.OrderStatus == entity.OrderStatusCanceled {
+		refundPaidParts(ctx, refundPayments, order)
+	}
+
+	return applied, nil
+}
+
+// refundPaidParts gives back the other parts of a split order whose QR Code was not paid. The order is
+// already canceled, so a failed refund is logged and retried by calling the cancel endpoint again, which
+// refunds the parts still paid of a canceled order
+func refundPaidParts(ctx context.Context, refundPayments *RefundOrderPaymentsUseCase, order dto.OrderResponse) {
+	paidParts := orderPaymentIDs(order)[1:]
+
+	if len(paidParts) == 0 {
+		return
+	}
+
+	err := refundPayments.Execute(ctx, paidParts)
+
+	if err != nil {
+		log.Print("refund paid parts", map[string]interface{}{
+			"orderId": order.OrderId,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func (service *FinishOrderForQRCodeUseCase) parseExternalReference(reference string) (uint, uint, error) {
+	orderID, paymentID, ok := entity.ParseQRCodeExternalReference(reference)
+
+	if !ok {
+		return 0, 0, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("The external reference %q is not valid", reference),
+		}
 	}
 
-	return nil
+	return orderID, paymentID, nil
 }
@@ -0,0 +1,448 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestFinishOrderForQRCodeServices(t *testing.T) {
+	t.Parallel()
+
+	notification := dto.ExternalPaymentEvent{
+		Resource: "https://api.mercadolibre.com/merchant_orders/20203112410",
+		Topic:    "merchant_order",
+	}
+
+	payingOrder := dto.OrderResponse{
+		OrderId:     10,
+		OrderStatus: entity.OrderStatusPaying,
+		PaymentID:   20,
+	}
+
+	t.Run("got success when finishing paid order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			Status:            "closed",
+			OrderStatus:       "paid",
+			ExternalReference: "10|20",
+			ApprovedPaymentID: "81030312426",
+			LastPaymentStatus: "approved",
+		}, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(payingOrder, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:         "merchant_order:20203112410:paid",
+			OrderID:          10,
+			PaymentID:        20,
+			PaymentStatus:    entity.PaymentPayedStatus,
+			GatewayPaymentID: "81030312426",
+			OrderStatus:      entity.OrderStatusCreated,
+			Reason:           "Payment confirmed",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertExpectations(t)
+		mockEventRepo.AssertCalled(t, "Publish", ctx, dto.OrderEvent{
+			OrderID:    10,
+			FromStatus: entity.OrderStatusPaying,
+			ToStatus:   entity.OrderStatusCreated,
+			Actor:      entity.OrderActorPaymentGateway,
+		})
+	})
+
+	t.Run("got order canceled when qr code expires in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			Status:            "expired",
+			OrderStatus:       "expired",
+			ExternalReference: "10|20",
+		}, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(payingOrder, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:      "merchant_order:20203112410:expired",
+			OrderID:       10,
+			PaymentID:     20,
+			PaymentStatus: entity.PaymentVoidedStatus,
+			OrderStatus:   entity.OrderStatusCanceled,
+			Reason:        "QR Code payment expired",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("got nothing changed when notification is duplicated in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			OrderStatus:       "paid",
+			ExternalReference: "10|20",
+		}, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(payingOrder, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, mock.Anything).Return(false, nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.NoError(t, err)
+		mockEventRepo.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("got nothing changed when payment is pending in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			Status:            "opened",
+			OrderStatus:       "payment_required",
+			ExternalReference: "10|20",
+		}, nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment")
+	})
+
+	t.Run("got error when external reference is not valid in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			OrderStatus:       "paid",
+			ExternalReference: "10-abc",
+		}, nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.Error(t, err)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got error when payment belongs to another order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			OrderStatus:       "paid",
+			ExternalReference: "10|99",
+		}, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(payingOrder, nil)
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.Error(t, err)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+
+	t.Run("got success when order is not paying anymore in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewFinishOrderForQRCodeUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)))
+
+		ctx := context.TODO()
+
+		mockQRCodeRepo.On("GetQRCodePaymentData", ctx, "token", notification.Resource).Return(dto.ExternalPaymentInformation{
+			OrderStatus:       "paid",
+			ExternalReference: "10|20",
+		}, nil)
+		mockOrderRepo.On("GetOrderById", ctx, uint(10)).Return(payingOrder, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, mock.Anything).Return(false, &responses.LocalError{
+			Code:    responses.DATABASE_CONFLICT_ERROR,
+			Message: "Order is not in Pagando status anymore",
+		})
+
+		err := sut.Execute(ctx, "token", notification)
+
+		assert.NoError(t, err)
+		mockEventRepo.AssertNotCalled(t, "Publish")
+	})
+}
+
+func TestGenerateQRCodePaymentServices(t *testing.T) {
+	t.Parallel()
+
+	pixOrder := dto.QRCodeOrder{
+		TotalPrice:  entity.NewMoney(1000000),
+		PaymentType: entity.PaymentPIXType,
+		OrderProduct: []dto.OrderProduct{
+			{
+				ProductID: 1,
+				Quantity:  1,
+			},
+		},
+	}
+
+	t.Run("got pix qr code with png in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProvider", entity.PaymentPIXType).Return(mockProvider, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockOrderRepo.On("GetNextTicketNumber", ctx, int64(1)).Return(7, nil)
+		mockPaymentRepo.On("CreatePaymentOrder", ctx, dto.Payment{
+			TotalPrice:  entity.NewMoney(1000000),
+			PaymentType: entity.PaymentPIXType,
+		}).Return(dto.PaymentResponse{PaymentId: 20}, nil)
+		mockOrderRepo.On("CreatePayingOrder", ctx, mock.Anything).Return(dto.OrderResponse{OrderId: 10}, nil)
+		mockProvider.On("Authorize", ctx, mock.MatchedBy(func(authorization dto.PaymentAuthorization) bool {
+			return authorization.OrderID == 10 &&
+				authorization.PaymentID == 20 &&
+				authorization.PaymentType == entity.PaymentPIXType &&
+				authorization.Order != nil
+		})).Return(dto.PaymentGatewayResponse{
+			PaymentStatus: entity.PaymentPayingStatus,
+			QRCodeData:    "00020126",
+			QRCodePNG:     []byte("png"),
+		}, nil)
+
+		response, err := sut.Execute(ctx, pixOrder, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.QRCodeDataResponse{
+			Data: "00020126",
+			PNG:  []byte("png"),
+		}, response)
+	})
+
+	t.Run("got order deleted and payment voided when qr code authorization fails in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		mockProviders.On("GetProvider", entity.PaymentPIXType).Return(mockProvider, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockOrderRepo.On("GetNextTicketNumber", ctx, int64(1)).Return(7, nil)
+		mockPaymentRepo.On("CreatePaymentOrder", ctx, mock.Anything).Return(dto.PaymentResponse{PaymentId: 20}, nil)
+		mockOrderRepo.On("CreatePayingOrder", ctx, mock.Anything).Return(dto.OrderResponse{OrderId: 10}, nil)
+		mockProvider.On("Authorize", ctx, mock.Anything).Return(dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusBadGateway,
+			Message: "PSP unavailable",
+		})
+		mockOrderRepo.On("DeleteOrder", ctx, uint(10)).Return(nil)
+		mockPaymentRepo.On("VoidPayment", ctx, uint(20)).Return(nil)
+
+		_, err := sut.Execute(ctx, pixOrder, 1)
+
+		assert.Error(t, err)
+		mockOrderRepo.AssertCalled(t, "DeleteOrder", ctx, uint(10))
+		mockPaymentRepo.AssertCalled(t, "VoidPayment", ctx, uint(20))
+	})
+
+	t.Run("got mercado pago qr code when payment type is empty in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		mercadoPagoOrder := pixOrder
+		mercadoPagoOrder.PaymentType = ""
+
+		mockProviders.On("GetProvider", entity.PaymentQRCodeType).Return(nil, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "There is no payment provider",
+		})
+
+		_, err := sut.Execute(ctx, mercadoPagoOrder, 1)
+
+		assert.Error(t, err)
+		mockProviders.AssertCalled(t, "GetProvider", entity.PaymentQRCodeType)
+	})
+
+	t.Run("got error when payment type is not paid with qr code in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		creditOrder := pixOrder
+		creditOrder.PaymentType = entity.PaymentCreditType
+
+		_, err := sut.Execute(context.TODO(), creditOrder, 1)
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+		mockProviders.AssertNotCalled(t, "GetProvider", mock.Anything)
+	})
+
+	t.Run("got pix qr code with the rest of a split order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		splitOrder := pixOrder
+		splitOrder.PaymentIDs = []uint{5}
+
+		creditPart := splitCreditPaymentDetails
+		creditPart.PaymentId = 5
+		creditPart.TotalPrice = entity.NewMoney(600000)
+
+		mockProviders.On("GetProvider", entity.PaymentPIXType).Return(mockProvider, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(5)).Return(creditPart, nil)
+		mockOrderRepo.On("GetOrderByPaymentId", ctx, uint(5)).Return(dto.OrderResponse{}, orderByPaymentNotFound)
+		mockOrderRepo.On("GetNextTicketNumber", ctx, int64(1)).Return(7, nil)
+		mockPaymentRepo.On("CreatePaymentOrder", ctx, dto.Payment{
+			TotalPrice:  entity.NewMoney(400000),
+			PaymentType: entity.PaymentPIXType,
+		}).Return(dto.PaymentResponse{PaymentId: 20}, nil)
+		mockOrderRepo.On("CreatePayingOrder", ctx, mock.MatchedBy(func(order dto.Order) bool {
+			return order.TotalPrice == entity.NewMoney(1000000) &&
+				order.PaymentID == 20 &&
+				len(order.PaymentIDs) == 1 && order.PaymentIDs[0] == 5
+		})).Return(dto.OrderResponse{OrderId: 10}, nil)
+		mockProvider.On("Authorize", ctx, mock.MatchedBy(func(authorization dto.PaymentAuthorization) bool {
+			return authorization.PaymentID == 20 && authorization.TotalPrice == entity.NewMoney(400000)
+		})).Return(dto.PaymentGatewayResponse{
+			PaymentStatus: entity.PaymentPayingStatus,
+			QRCodeData:    "00020126",
+		}, nil)
+
+		response, err := sut.Execute(ctx, splitOrder, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "00020126", response.Data)
+	})
+
+	t.Run("got error when the split parts already pay the order in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockProviders := new(MockPaymentProviderRegistry)
+		mockProvider := new(MockPaymentProvider)
+		mockOrderRepo := new(MockOrderRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		mockProductRepo := new(MockProductRepository)
+
+		sut := NewGenerateQRCodePaymentUseCase(mockProviders, mockOrderRepo, mockPaymentRepo, NewCalculateOrderPriceUseCase(mockProductRepo), applyNoPromotions(), applyNoLoyaltyPoints())
+
+		ctx := context.TODO()
+
+		splitOrder := pixOrder
+		splitOrder.PaymentIDs = []uint{1}
+
+		mockProviders.On("GetProvider", entity.PaymentPIXType).Return(mockProvider, nil)
+		mockProductRepo.On("GetProductById", ctx, uint(1)).Return(orderedSnack, nil)
+		mockPaymentRepo.On("GetPaymentById", ctx, uint(1)).Return(splitCreditPaymentDetails, nil)
+
+		_, err := sut.Execute(ctx, splitOrder, 1)
+
+		assert.Error(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "CreatePaymentOrder", mock.Anything, mock.Anything)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnprocessableEntity, businessError.StatusCode)
+	})
+}
@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type ReconcileQRCodePaymentsUseCase struct {
+	repository      repository.QRCodePaymentRepository
+	orderRepository repository.OrderRepository
+	orderEvents     repository.OrderEventRepository
+	refundPayments  *RefundOrderPaymentsUseCase
+	token           string
+	mutex           sync.RWMutex
+	lastRun         *dto.QRCodeReconciliation
+}
+
+func NewReconcileQRCodePaymentsUseCase(
+	repository repository.QRCodePaymentRepository,
+	orderRepository repository.OrderRepository,
+	orderEvents repository.OrderEventRepository,
+	refundPayments *RefundOrderPaymentsUseCase,
+	token string,
+) *ReconcileQRCodePaymentsUseCase {
+	return &ReconcileQRCodePaymentsUseCase{
+		repository:      repository,
+		orderRepository: orderRepository,
+		orderEvents:     orderEvents,
+		refundPayments:  refundPayments,
+		token:           token,
+	}
+}
+
+// Start reconciles the orders waiting payment until the context is done. It finishes the orders
+// whose Mercado Livre webhook never arrived
+func (usecase *ReconcileQRCodePaymentsUseCase) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := usecase.Execute(ctx)
+
+		if err != nil {
+			log.Print("reconcile qr code payments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+func (usecase *ReconcileQRCodePaymentsUseCase) Execute(ctx context.Context) (dto.QRCodeReconciliation, error) {
+	now := time.Now()
+
+	run := dto.QRCodeReconciliation{
+		StartedAt: now,
+		Orders:    []dto.QRCodeReconciliationOrder{},
+	}
+
+	// Only the Mercado Pago QR Codes expire. The PIX BR Code can still be paid after any cancel
+	// and its payments can not be refunded, so the PIX orders are only finished by the webhook
+	orders, err := usecase.orderRepository.GetOrdersWaitingPaymentByType(ctx, entity.PaymentQRCodeType)
+
+	if err != nil {
+		err = responses.GetResponseError(err, "ReconcileQRCodePaymentsService -> GetOrdersWaitingPaymentByType")
+		run.Error = err.Error()
+		usecase.saveLastRun(run)
+		return run, err
+	}
+
+	for _, order := range orders {
+		if order.OrderDate.After(now.Add(-entity.QRCodeReconciliationMinAge)) {
+			continue
+		}
+
+		result := usecase.reconcileOrder(ctx, order, now)
+
+		run.Checked++
+		run.Orders = append(run.Orders, result)
+
+		switch result.Result {
+		case entity.QRCodeOrderStatusPaid:
+			run.Paid++
+		case entity.QRCodeOrderStatusExpired, entity.QRCodePaymentStatusCanceled, entity.QRCodePaymentStatusRejected:
+			run.Canceled++
+		case entity.QRCodeReconciliationPending:
+			run.Pending++
+		case entity.QRCodeReconciliationFailed:
+			run.Failed++
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	usecase.saveLastRun(run)
+
+	return run, nil
+}
+
+// LastRun returns the result of the last reconciliation and false if it did not run yet
+func (usecase *ReconcileQRCodePaymentsUseCase) LastRun() (dto.QRCodeReconciliation, bool) {
+	usecase.mutex.RLock()
+	defer usecase.mutex.RUnlock()
+
+	if usecase.lastRun == nil {
+		return dto.QRCodeReconciliation{}, false
+	}
+
+	return *usecase.lastRun, true
+}
+
+func (usecase *ReconcileQRCodePaymentsUseCase) saveLastRun(run dto.QRCodeReconciliation) {
+	usecase.mutex.Lock()
+	defer usecase.mutex.Unlock()
+
+	usecase.lastRun = &run
+}
+
+// reconcileOrder uses the same event key of the webhook for the Mercado Livre results, so a late
+// notification does not finish the order again. The expiration has its own key because it is decided here
+func (usecase *ReconcileQRCodePaymentsUseCase) reconcileOrder(
+	ctx context.Context,
+	order dto.OrderResponse,
+	now time.Time,
+) dto.QRCodeReconciliationOrder {
+	reconciliation := dto.QRCodeReconciliationOrder{
+		OrderID:   order.OrderId,
+		PaymentID: order.PaymentID,
+	}
+
+	reference := fmt.Sprintf("%v|%v", order.OrderId, order.PaymentID)
+
+	mercadoLivrePayment, err := usecase.repository.SearchQRCodePaymentData(ctx, usecase.token, reference)
+
+	var localError *responses.LocalError
+
+	// The customer did not scan the QR Code yet
+	if errors.As(err, &localError) && localError.Code == responses.NOT_FOUND_ERROR {
+		err = nil
+	}
+
+	if err != nil {
+		reconciliation.Result = entity.QRCodeReconciliationFailed
+		reconciliation.Error = err.Error()
+		return reconciliation
+	}
+
+	outcome, finished := entity.ResolveQRCodePayment(
+		mercadoLivrePayment.Status,
+		mercadoLivrePayment.OrderStatus,
+		mercadoLivrePayment.LastPaymentStatus,
+	)
+
+	eventKey := fmt.Sprintf("merchant_order:%v:%v", mercadoLivrePayment.ID, outcome.Result)
+
+	if !finished {
+		if now.Before(order.OrderDate.Add(entity.QRCodeExpiration)) {
+			reconciliation.Result = entity.QRCodeReconciliationPending
+			return reconciliation
+		}
+
+		outcome = entity.ExpiredQRCodePayment()
+		eventKey = fmt.Sprintf("qr_code_expiration:%v", order.OrderId)
+	}
+
+	result := dto.OrderPaymentResult{
+		EventKey:      eventKey,
+		OrderID:       order.OrderId,
+		PaymentID:     order.PaymentID,
+		PaymentStatus: outcome.PaymentStatus,
+		OrderStatus:   outcome.OrderStatus,
+		Reason:        outcome.Reason,
+	}
+
+	if outcome.PaymentStatus == entity.PaymentPayedStatus {
+		result.GatewayPaymentID = mercadoLivrePayment.ApprovedPaymentID
+	}
+
+	applied, err := finishPayingOrder(ctx, usecase.orderRepository, usecase.orderEvents, usecase.refundPayments, order, result)
+
+	if err != nil {
+		reconciliation.Result = entity.QRCodeReconciliationFailed
+		reconciliation.Error = err.Error()
+		return reconciliation
+	}
+
+	if !applied {
+		reconciliation.Result = entity.QRCodeReconciliationSkipped
+		return reconciliation
+	}
+
+	reconciliation.Result = outcome.Result
+
+	return reconciliation
+}
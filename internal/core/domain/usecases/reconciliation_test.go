@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestReconcileQRCodePaymentsServices(t *testing.T) {
+	t.Parallel()
+
+	merchantOrderNotFound := &responses.LocalError{
+		Code:    responses.NOT_FOUND_ERROR,
+		Message: "Merchant order not found",
+	}
+
+	waitingOrder := func(age time.Duration) dto.OrderResponse {
+		return dto.OrderResponse{
+			OrderId:     10,
+			OrderDate:   time.Now().Add(-age),
+			OrderStatus: entity.OrderStatusPaying,
+			PaymentID:   20,
+		}
+	}
+
+	t.Run("got paid order when the webhook did not arrive in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(time.Hour)}, nil)
+		mockQRCodeRepo.On("SearchQRCodePaymentData", ctx, "token", "10|20").Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			Status:            "closed",
+			OrderStatus:       "paid",
+			ExternalReference: "10|20",
+			ApprovedPaymentID: "81030312426",
+			LastPaymentStatus: "approved",
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:         "merchant_order:20203112410:paid",
+			OrderID:          10,
+			PaymentID:        20,
+			PaymentStatus:    entity.PaymentPayedStatus,
+			GatewayPaymentID: "81030312426",
+			OrderStatus:      entity.OrderStatusCreated,
+			Reason:           "Payment confirmed",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Checked)
+		assert.Equal(t, 1, response.Paid)
+		assert.Equal(t, []dto.QRCodeReconciliationOrder{
+			{OrderID: 10, PaymentID: 20, Result: entity.QRCodeOrderStatusPaid},
+		}, response.Orders)
+		mockOrderRepo.AssertExpectations(t)
+		mockEventRepo.AssertCalled(t, "Publish", ctx, dto.OrderEvent{
+			OrderID:    10,
+			FromStatus: entity.OrderStatusPaying,
+			ToStatus:   entity.OrderStatusCreated,
+			Actor:      entity.OrderActorPaymentGateway,
+		})
+
+		lastRun, ok := sut.LastRun()
+
+		assert.True(t, ok)
+		assert.Equal(t, response, lastRun)
+	})
+
+	t.Run("got pending order when qr code is not paid yet in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(time.Hour)}, nil)
+		mockQRCodeRepo.On("SearchQRCodePaymentData", ctx, "token", "10|20").Return(dto.ExternalPaymentInformation{}, merchantOrderNotFound)
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Checked)
+		assert.Equal(t, 1, response.Pending)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got order canceled when qr code expired without payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(entity.QRCodeExpiration + time.Minute)}, nil)
+		mockQRCodeRepo.On("SearchQRCodePaymentData", ctx, "token", "10|20").Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			Status:            "opened",
+			OrderStatus:       "payment_required",
+			ExternalReference: "10|20",
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, dto.OrderPaymentResult{
+			EventKey:      "qr_code_expiration:10",
+			OrderID:       10,
+			PaymentID:     20,
+			PaymentStatus: entity.PaymentVoidedStatus,
+			OrderStatus:   entity.OrderStatusCanceled,
+			Reason:        "QR Code payment expired",
+		}).Return(true, nil)
+		mockEventRepo.On("Publish", ctx, mock.Anything).Return(nil)
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Canceled)
+		assert.Equal(t, entity.QRCodeOrderStatusExpired, response.Orders[0].Result)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("got skipped order when it was already finished in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(time.Hour)}, nil)
+		mockQRCodeRepo.On("SearchQRCodePaymentData", ctx, "token", "10|20").Return(dto.ExternalPaymentInformation{
+			ID:                20203112410,
+			OrderStatus:       "paid",
+			ApprovedPaymentID: "81030312426",
+		}, nil)
+		mockOrderRepo.On("FinishOrderPayment", ctx, mock.Anything).Return(false, &responses.LocalError{
+			Code: responses.DATABASE_CONFLICT_ERROR,
+		})
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Checked)
+		assert.Equal(t, 0, response.Paid)
+		assert.Equal(t, entity.QRCodeReconciliationSkipped, response.Orders[0].Result)
+		mockEventRepo.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got recent order ignored in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(0)}, nil)
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, response.Checked)
+		assert.Empty(t, response.Orders)
+		mockQRCodeRepo.AssertNotCalled(t, "SearchQRCodePaymentData", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("got failed order when mercado livre is unavailable in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{waitingOrder(entity.QRCodeExpiration + time.Minute)}, nil)
+		mockQRCodeRepo.On("SearchQRCodePaymentData", ctx, "token", "10|20").Return(dto.ExternalPaymentInformation{}, &responses.NetworkError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "service unavailable",
+		})
+
+		response, err := sut.Execute(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Failed)
+		assert.NotEmpty(t, response.Orders[0].Error)
+		mockOrderRepo.AssertNotCalled(t, "FinishOrderPayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("got error when getting orders waiting payment in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockQRCodeRepo := new(MockQRCodePaymentRepository)
+		mockOrderRepo := new(MockOrderRepository)
+		mockEventRepo := new(MockOrderEventRepository)
+
+		sut := NewReconcileQRCodePaymentsUseCase(mockQRCodeRepo, mockOrderRepo, mockEventRepo, NewRefundOrderPaymentsUseCase(new(MockPaymentRepository), new(MockPaymentProviderRegistry)), "token")
+
+		ctx := context.TODO()
+
+		_, ok := sut.LastRun()
+
+		assert.False(t, ok)
+
+		mockOrderRepo.On("GetOrdersWaitingPaymentByType", ctx, entity.PaymentQRCodeType).Return([]dto.OrderResponse{}, errors.New("error"))
+
+		response, err := sut.Execute(ctx)
+
+		assert.Error(t, err)
+		assert.NotEmpty(t, response.Error)
+
+		lastRun, ok := sut.LastRun()
+
+		assert.True(t, ok)
+		assert.Equal(t, response.Error, lastRun.Error)
+	})
+}
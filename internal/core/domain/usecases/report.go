@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type GetRevenueByDayUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+type GetRevenueByCategoryUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+type GetRevenueByProductUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+type GetOrderTimesUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+type GetNotDeliveredUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+type GetHourlyVolumeUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewGetRevenueByDayUseCase(reportRepo repository.ReportRepository) *GetRevenueByDayUseCase {
+	return &GetRevenueByDayUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func NewGetRevenueByCategoryUseCase(reportRepo repository.ReportRepository) *GetRevenueByCategoryUseCase {
+	return &GetRevenueByCategoryUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func NewGetRevenueByProductUseCase(reportRepo repository.ReportRepository) *GetRevenueByProductUseCase {
+	return &GetRevenueByProductUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func NewGetOrderTimesUseCase(reportRepo repository.ReportRepository) *GetOrderTimesUseCase {
+	return &GetOrderTimesUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func NewGetNotDeliveredUseCase(reportRepo repository.ReportRepository) *GetNotDeliveredUseCase {
+	return &GetNotDeliveredUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func NewGetHourlyVolumeUseCase(reportRepo repository.ReportRepository) *GetHourlyVolumeUseCase {
+	return &GetHourlyVolumeUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+func (usecase *GetRevenueByDayUseCase) Execute(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByDay, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return []dto.RevenueByDay{}, err
+	}
+
+	response, err := usecase.reportRepo.GetRevenueByDay(ctx, filter)
+
+	if err != nil {
+		return []dto.RevenueByDay{}, responses.GetResponseError(err, "ReportService -> GetRevenueByDay")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetRevenueByCategoryUseCase) Execute(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByCategory, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return []dto.RevenueByCategory{}, err
+	}
+
+	response, err := usecase.reportRepo.GetRevenueByCategory(ctx, filter)
+
+	if err != nil {
+		return []dto.RevenueByCategory{}, responses.GetResponseError(err, "ReportService -> GetRevenueByCategory")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetRevenueByProductUseCase) Execute(ctx context.Context, filter dto.ReportFilter) ([]dto.RevenueByProduct, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return []dto.RevenueByProduct{}, err
+	}
+
+	response, err := usecase.reportRepo.GetRevenueByProduct(ctx, filter)
+
+	if err != nil {
+		return []dto.RevenueByProduct{}, responses.GetResponseError(err, "ReportService -> GetRevenueByProduct")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetOrderTimesUseCase) Execute(ctx context.Context, filter dto.ReportFilter) (dto.OrderTimesReport, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return dto.OrderTimesReport{}, err
+	}
+
+	response, err := usecase.reportRepo.GetOrderTimes(ctx, filter)
+
+	if err != nil {
+		return dto.OrderTimesReport{}, responses.GetResponseError(err, "ReportService -> GetOrderTimes")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetNotDeliveredUseCase) Execute(ctx context.Context, filter dto.ReportFilter) (dto.NotDeliveredReport, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return dto.NotDeliveredReport{}, err
+	}
+
+	response, err := usecase.reportRepo.GetNotDelivered(ctx, filter)
+
+	if err != nil {
+		return dto.NotDeliveredReport{}, responses.GetResponseError(err, "ReportService -> GetNotDelivered")
+	}
+
+	return response, nil
+}
+
+func (usecase *GetHourlyVolumeUseCase) Execute(ctx context.Context, filter dto.ReportFilter) ([]dto.HourlyVolume, error) {
+	filter, err := validateReportFilter(filter)
+
+	if err != nil {
+		return []dto.HourlyVolume{}, err
+	}
+
+	response, err := usecase.reportRepo.GetHourlyVolume(ctx, filter)
+
+	if err != nil {
+		return []dto.HourlyVolume{}, responses.GetResponseError(err, "ReportService -> GetHourlyVolume")
+	}
+
+	return response, nil
+}
+
+// validateReportFilter fills the missing dates with the last days until now and limits the range,
+// since the reports aggregate all the orders of the period
+func validateReportFilter(filter dto.ReportFilter) (dto.ReportFilter, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -entity.ReportDefaultDays)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return dto.ReportFilter{}, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "The from date must be before the to date",
+		}
+	}
+
+	if filter.To.Sub(filter.From) > entity.ReportMaxDays*24*time.Hour {
+		return dto.ReportFilter{}, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The report period can not be longer than %v days", entity.ReportMaxDays),
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestReportServices(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+
+	t.Run("got success when getting revenue by day in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockReportRepository)
+		sut := NewGetRevenueByDayUseCase(mockRepo)
+
+		ctx := context.TODO()
+		filter := dto.ReportFilter{From: from, To: to}
+
+		mockRepo.On("GetRevenueByDay", ctx, filter).Return([]dto.RevenueByDay{
+			{Day: "2024-07-01", Orders: 3, Revenue: entity.NewMoney(12345)},
+		}, nil)
+
+		response, err := sut.Execute(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Len(t, response, 1)
+		assert.Equal(t, entity.NewMoney(12345), response[0].Revenue)
+	})
+
+	t.Run("got success when getting order times without dates in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockReportRepository)
+		sut := NewGetOrderTimesUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		mockRepo.On("GetOrderTimes", ctx, mock.MatchedBy(func(filter dto.ReportFilter) bool {
+			return filter.To.Sub(filter.From) == 30*24*time.Hour
+		})).Return(dto.OrderTimesReport{Orders: 10, P95WaitSeconds: 300}, nil)
+
+		response, err := sut.Execute(ctx, dto.ReportFilter{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, response.Orders)
+		assert.Equal(t, float64(300), response.P95WaitSeconds)
+	})
+
+	t.Run("got error when from is after to in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockReportRepository)
+		sut := NewGetRevenueByProductUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		response, err := sut.Execute(ctx, dto.ReportFilter{From: to, To: from})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+		mockRepo.AssertNotCalled(t, "GetRevenueByProduct")
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when period is too long in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockReportRepository)
+		sut := NewGetHourlyVolumeUseCase(mockRepo)
+
+		ctx := context.TODO()
+
+		response, err := sut.Execute(ctx, dto.ReportFilter{From: from, To: from.AddDate(2, 0, 0)})
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusBadRequest, businessError.StatusCode)
+	})
+
+	t.Run("got error when getting not delivered rate in services", func(t *testing.T) {
+		t.Parallel()
+
+		mockRepo := new(MockReportRepository)
+		sut := NewGetNotDeliveredUseCase(mockRepo)
+
+		ctx := context.TODO()
+		filter := dto.ReportFilter{From: from, To: to}
+
+		mockRepo.On("GetNotDelivered", ctx, filter).Return(dto.NotDeliveredReport{}, &responses.LocalError{
+			Code:    responses.DATABASE_ERROR,
+			Message: "service unavailable",
+		})
+
+		response, err := sut.Execute(ctx, filter)
+
+		assert.Error(t, err)
+		assert.Empty(t, response)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusServiceUnavailable, businessError.StatusCode)
+	})
+}
@@ -19,17 +19,14 @@ type UpdateUserUseCase struct {
 	repository         repository.UserAdminRepository
 }
 
-type GetUserByCPFUseCase struct {
-	validateCPFUseCase *ValidateCPFUseCase
-	repository         repository.UserAdminRepository
-}
-
 type GetUserByIdUseCase struct {
 	repository repository.UserAdminRepository
 }
 
 type LoginUserUseCase struct {
 	repository repository.UserAdminRepository
+	authRepo   repository.AuthRepository
+	notifier   repository.LoginCodeNotifier
 }
 
 func NewUpdateUserUseCase(validateCPFUseCase *ValidateCPFUseCase, repository repository.UserAdminRepository) *UpdateUserUseCase {
@@ -46,22 +43,21 @@ func NewCreateUserUseCase(validateCPFUseCase *ValidateCPFUseCase, repository rep
 	}
 }
 
-func NewGetUserByCPFUseCase(validateCPFUseCase *ValidateCPFUseCase, repository repository.UserAdminRepository) *GetUserByCPFUseCase {
-	return &GetUserByCPFUseCase{
-		validateCPFUseCase: validateCPFUseCase,
-		repository:         repository,
-	}
-}
-
 func NewGetUserByIdUseCase(repository repository.UserAdminRepository) *GetUserByIdUseCase {
 	return &GetUserByIdUseCase{
 		repository: repository,
 	}
 }
 
-func NewLoginUserUseCase(repository repository.UserAdminRepository) *LoginUserUseCase {
+func NewLoginUserUseCase(
+	repository repository.UserAdminRepository,
+	authRepo repository.AuthRepository,
+	notifier repository.LoginCodeNotifier,
+) *LoginUserUseCase {
 	return &LoginUserUseCase{
 		repository: repository,
+		authRepo:   authRepo,
+		notifier:   notifier,
 	}
 }
 
@@ -117,33 +113,16 @@ func (service *GetUserByIdUseCase) Execute(ctx context.Context, id uint) (dto.Us
 	return user, nil
 }
 
-func (service *GetUserByCPFUseCase) Execute(ctx context.Context, cpf string) (dto.UserAdmin, error) {
-	cleanedCPF, validate := service.validateCPFUseCase.Execute(cpf)
-
-	if !validate {
-		return dto.UserAdmin{}, &responses.BusinessResponse{
-			StatusCode: http.StatusBadRequest,
-			Message:    "Invalid CPF",
-		}
-	}
-
-	user, err := service.repository.GetUserByCPF(ctx, cleanedCPF)
-
-	if err != nil {
-		return dto.UserAdmin{}, responses.GetResponseError(err, "UserService")
-	}
-
-	return user, nil
-}
-
-func (uc *LoginUserUseCase) Execute(ctx context.Context, cpf string) (dto.Token, error) {
-	token, err := uc.repository.Login(ctx, cpf)
+// Execute sends the login code to the user email. The tokens are returned by the VerifyLoginCodeUseCase
+func (uc *LoginUserUseCase) Execute(ctx context.Context, cpf string) (dto.LoginChallenge, error) {
+	user, err := uc.repository.GetUserByCPF(ctx, cpf)
 
 	if err != nil {
-		return dto.Token{}, responses.GetResponseError(err, "UserService")
+		return dto.LoginChallenge{}, responses.GetResponseError(err, "UserService -> GetUserByCPF")
 	}
 
-	return dto.Token{
-		AccessToken: token,
-	}, nil
+	return startLogin(ctx, uc.authRepo, uc.notifier, dto.LoginCodeMessage{
+		Name:  user.Name,
+		Email: user.Email,
+	}, user.CPF)
 }
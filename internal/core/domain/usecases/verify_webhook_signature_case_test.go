@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+func TestVerifyWebhookSignatureUseCase(t *testing.T) {
+	forgedSignature := "ts=1704908010,v1=4e3c4b3f0f4d5b9f1c8c8e0b6f4f9b4b8b8a1f4b1a5c6f0d5e2f1b1c1d6b7c8a"
+
+	t.Run("got success when verifying valid signature", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewVerifyWebhookSignatureUseCase("secret")
+
+		err := sut.Execute(dto.WebhookSignature{
+			Signature: signWebhook("secret", "id:20203112410;request-id:bb56a2f1-6aae-46ac-982e-9dcd3581d08e;ts:1704908010;", "1704908010"),
+			RequestID: "bb56a2f1-6aae-46ac-982e-9dcd3581d08e",
+			DataID:    "20203112410",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got success when verifying signature without request id", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewVerifyWebhookSignatureUseCase("secret")
+
+		err := sut.Execute(dto.WebhookSignature{
+			Signature: signWebhook("secret", "id:abc123;ts:1704908010;", "1704908010"),
+			DataID:    "ABC123",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("got error when verifying forged signature", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewVerifyWebhookSignatureUseCase("secret")
+
+		err := sut.Execute(dto.WebhookSignature{
+			Signature: forgedSignature,
+			RequestID: "bb56a2f1-6aae-46ac-982e-9dcd3581d08e",
+			DataID:    "20203112410",
+		})
+
+		assert.Error(t, err)
+
+		var businessError *responses.BusinessResponse
+		assert.Equal(t, true, errors.As(err, &businessError))
+		assert.Equal(t, http.StatusUnauthorized, businessError.StatusCode)
+	})
+
+	t.Run("got error when verifying signature of another data id", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewVerifyWebhookSignatureUseCase("secret")
+
+		err := sut.Execute(dto.WebhookSignature{
+			Signature: signWebhook("secret", "id:20203112410;ts:1704908010;", "1704908010"),
+			DataID:    "99999999999",
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("got error when verifying missing signature", func(t *testing.T) {
+		t.Parallel()
+
+		sut := NewVerifyWebhookSignatureUseCase("secret")
+
+		assert.Error(t, sut.Execute(dto.WebhookSignature{}))
+		assert.Error(t, sut.Execute(dto.WebhookSignature{Signature: "ts=1704908010"}))
+		assert.Error(t, sut.Execute(dto.WebhookSignature{Signature: "ts=1704908010,v1=not-hex"}))
+	})
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// Authenticate validates the 'Authorization: Bearer' token and keeps its claims in the request context.
+// The token is optional here, since the kiosk orders without login. The routes which need a logged
+// user or a group are protected by RequireGroup and RequireCustomerOwner
+func Authenticate(authenticate *usecases.AuthenticateUseCase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := httpserver.GetBearerTokenFromRequest(r)
+
+			if !found {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := authenticate.Execute(r.Context(), token)
+
+			if err != nil {
+				log.Print("authenticate", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withTokenClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// RequireGroup allows only the tokens of the Cognito group, like the admin group
+func RequireGroup(group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := getTokenClaimsFromRequest(r)
+
+			if err != nil {
+				log.Print("require group", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			if !slices.Contains(claims.Groups, group) {
+				err = &responses.BusinessResponse{
+					StatusCode: http.StatusForbidden,
+					Message:    "The user is not allowed to access this resource",
+				}
+				log.Print("require group", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireCustomerOwner allows the routes of the '{id}' customer only to the customer itself and to the admins
+func RequireCustomerOwner(authorizeCustomer *usecases.AuthorizeCustomerUseCase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := getTokenClaimsFromRequest(r)
+
+			if err != nil {
+				log.Print("require customer owner", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			customerIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+			if err != nil {
+				log.Print("require customer owner", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendBadRequestError(w, err)
+				return
+			}
+
+			customerId, err := strconv.Atoi(customerIdStr)
+
+			if err != nil {
+				log.Print("require customer owner", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendBadRequestError(w, err)
+				return
+			}
+
+			err = authorizeCustomer.Execute(r.Context(), uint(customerId), claims)
+
+			if err != nil {
+				log.Print("require customer owner", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOrderOwner allows the '{id}' order only to its customer or guest and to the admins
+func RequireOrderOwner(authorizeOrder *usecases.AuthorizeOrderUseCase) func(http.Handler) http.Handler {
+	return requireResourceOwner("require order owner", authorizeOrder.Execute)
+}
+
+// RequirePaymentOwner allows the '{id}' payment only to the customer or guest of its order and to the admins
+func RequirePaymentOwner(authorizePayment *usecases.AuthorizePaymentUseCase) func(http.Handler) http.Handler {
+	return requireResourceOwner("require payment owner", authorizePayment.Execute)
+}
+
+// requireResourceOwner does not require the token, since the kiosk orders without login are open
+func requireResourceOwner(
+	logMessage string,
+	authorize func(ctx context.Context, id uint, claims *dto.TokenClaims) error,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+			if err != nil {
+				log.Print(logMessage, map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendBadRequestError(w, err)
+				return
+			}
+
+			id, err := strconv.Atoi(idStr)
+
+			if err != nil {
+				log.Print(logMessage, map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendBadRequestError(w, err)
+				return
+			}
+
+			var claims *dto.TokenClaims
+
+			if tokenClaims, err := getTokenClaimsFromRequest(r); err == nil {
+				claims = &tokenClaims
+			}
+
+			err = authorize(r.Context(), uint(id), claims)
+
+			if err != nil {
+				log.Print(logMessage, map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendResponseError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// @Summary Verify login code
+// @Description Finish the login of a customer or an admin with the code sent by the '/auth/login' or '/auth/admin/login'.
+// @Description The 'refreshToken' gets new access tokens in the '/auth/token/refresh' until the logout.
+// @Description With the token of a guest, the orders created by the guest are given to the customer
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param form body dto.LoginCodeForm true "login code form"
+// @Success 200 {object} dto.Token
+// @Failure 401 "The login code is not valid or the session is expired"
+// @Router /auth/login/verify [post]
+func VerifyLoginCodeHandler(
+	verifyLoginCode *usecases.VerifyLoginCodeUseCase,
+	mergeGuestOrders *usecases.MergeGuestOrdersUseCase,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var form dto.LoginCodeForm
+
+		err := httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding login code body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		token, err := verifyLoginCode.Execute(r.Context(), form)
+
+		if err != nil {
+			log.Print("verify login code", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		// The code was verified for this CPF, so the guest orders are given to the customer who received it
+		mergeGuestOrdersFromRequest(r, mergeGuestOrders, form.CPF)
+
+		httpserver.SendResponseSuccess(w, token)
+	}
+}
+
+// @Summary Refresh token
+// @Description Get a new access token with the refresh token of the login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param form body dto.RefreshTokenForm true "refresh token form"
+// @Success 200 {object} dto.Token
+// @Failure 401 "The refresh token is not valid or was revoked"
+// @Router /auth/token/refresh [post]
+func RefreshTokenHandler(refreshToken *usecases.RefreshTokenUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var form dto.RefreshTokenForm
+
+		err := httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding refresh token body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		token, err := refreshToken.Execute(r.Context(), form.RefreshToken)
+
+		if err != nil {
+			log.Print("refresh token", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, token)
+	}
+}
+
+// @Summary Logout
+// @Description Revoke the refresh token and the access tokens of the login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token of the login"
+// @Param form body dto.RefreshTokenForm true "refresh token form"
+// @Success 204
+// @Failure 401 "The Bearer token is required"
+// @Router /auth/logout [post]
+func LogoutHandler(logout *usecases.LogoutUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := getTokenClaimsFromRequest(r)
+
+		if err != nil {
+			log.Print("logout token", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		var form dto.RefreshTokenForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding logout body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = logout.Execute(r.Context(), claims, form.RefreshToken)
+
+		if err != nil {
+			log.Print("logout", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
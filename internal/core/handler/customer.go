@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,7 +12,8 @@ import (
 )
 
 // @Summary Create new customer
-// @Description Create new customer. This process is not required to make an order
+// @Description Create new customer. This process is not required to make an order.
+// @Description With the token of a guest, the orders created by the guest are given to the new customer
 // @Tags Customer
 // @Accept json
 // @Produce json
@@ -20,7 +22,10 @@ import (
 // @Failure 400 "Customer has required fields"
 // @Failure 409 "This Customer is already added"
 // @Router /auth/signup [post]
-func CreateCustomerHandler(createCustomer *usecases.CreateCustomerUseCase) http.HandlerFunc {
+func CreateCustomerHandler(
+	createCustomer *usecases.CreateCustomerUseCase,
+	mergeGuestOrders *usecases.MergeGuestOrdersUseCase,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var customer dto.Customer
 
@@ -46,6 +51,8 @@ func CreateCustomerHandler(createCustomer *usecases.CreateCustomerUseCase) http.
 			return
 		}
 
+		mergeGuestOrdersFromRequest(r, mergeGuestOrders, customer.CPF)
+
 		httpserver.SendResponseSuccess(w, response)
 	}
 }
@@ -161,23 +168,38 @@ func GetCustomerByIdHandler(getCustomerById *usecases.GetCustomerByIdUseCase) ht
 	}
 }
 
-// @Summary Get customer by CPF
-// @Description Get customer by CPF. This Endpoint can be used as a Login
+// @Summary Get customer orders
+// @Description Get the orders of the logged customer with their products, status dates and payments, the newest first.
+// @Description Use the 'nextCursor' of the response as the 'cursor' param to get the next page. When it is null, there are no more orders
 // @Tags Customer
+// @Param id path int true "12"
+// @Param Authorization header string true "Bearer token of the customer login"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size, up to 100" default(20)
 // @Accept json
 // @Produce json
-// @Param customer body dto.CustomerForm true "customerForm"
-// @Success 200 {object} dto.Customer
+// @Success 200 {object} dto.OrderPage
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own data"
 // @Failure 404 "Customer not found"
-// @Router /api/customers/login [post]
-func GetCustomerByCPFHandler(getCustomerByCPF *usecases.GetCustomerByCPFUseCase) http.HandlerFunc {
+// @Router /api/customers/{id}/orders [get]
+func GetCustomerOrdersHandler(getCustomerOrders *usecases.GetCustomerOrdersUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var customerForm dto.CustomerForm
+		customerIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
 
-		err := httpserver.DecodeJSONBody(w, r, &customerForm)
+		if err != nil {
+			log.Print("get customer orders", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		customerId, err := strconv.Atoi(customerIdStr)
 
 		if err != nil {
-			log.Print("decoding customer form body", map[string]interface{}{
+			log.Print("get customer orders", map[string]interface{}{
 				"error":  err.Error(),
 				"status": httpserver.GetStatusCodeFromError(err),
 			})
@@ -185,10 +207,28 @@ func GetCustomerByCPFHandler(getCustomerByCPF *usecases.GetCustomerByCPFUseCase)
 			return
 		}
 
-		customer, err := getCustomerByCPF.Execute(r.Context(), customerForm.CPF)
+		filter := dto.CustomerOrdersFilter{
+			CustomerID: uint(customerId),
+			Cursor:     r.URL.Query().Get("cursor"),
+		}
+
+		if value := r.URL.Query().Get("limit"); value != "" {
+			filter.Limit, err = strconv.Atoi(value)
+
+			if err != nil {
+				log.Print("get customer orders limit", map[string]interface{}{
+					"error":  err.Error(),
+					"status": httpserver.GetStatusCodeFromError(err),
+				})
+				httpserver.SendBadRequestError(w, fmt.Errorf("limit is not valid"))
+				return
+			}
+		}
+
+		response, err := getCustomerOrders.Execute(r.Context(), filter)
 
 		if err != nil {
-			log.Print("get customer by id", map[string]interface{}{
+			log.Print("get customer orders", map[string]interface{}{
 				"error":  err.Error(),
 				"status": httpserver.GetStatusCodeFromError(err),
 			})
@@ -196,17 +236,18 @@ func GetCustomerByCPFHandler(getCustomerByCPF *usecases.GetCustomerByCPFUseCase)
 			return
 		}
 
-		httpserver.SendResponseSuccess(w, customer)
+		httpserver.SendResponseSuccess(w, response)
 	}
 }
 
 // @Summary Login
-// @Description Login the customer by its CPF
+// @Description Start the login of the customer by its CPF. A one-time code is sent by SMS to the customer phone, or to its email,
+// @Description and it must be sent with the 'session' to the '/auth/login/verify' to get the tokens
 // @Tags Customer
 // @Accept json
 // @Produce json
 // @Param customer body dto.CustomerForm true "customer form"
-// @Success 200 {object} dto.Token
+// @Success 200 {object} dto.LoginChallenge
 // @Failure 404 "Customer not found"
 // @Router /auth/login [post]
 func LoginCustomerHandler(loginCustomerUseCase *usecases.LoginCustomerUseCase) http.HandlerFunc {
@@ -224,7 +265,7 @@ func LoginCustomerHandler(loginCustomerUseCase *usecases.LoginCustomerUseCase) h
 			return
 		}
 
-		token, err := loginCustomerUseCase.Execute(r.Context(), customerForm.CPF)
+		challenge, err := loginCustomerUseCase.Execute(r.Context(), customerForm.CPF)
 
 		if err != nil {
 			log.Print("login user", map[string]interface{}{
@@ -235,7 +276,7 @@ func LoginCustomerHandler(loginCustomerUseCase *usecases.LoginCustomerUseCase) h
 			return
 		}
 
-		httpserver.SendResponseSuccess(w, token)
+		httpserver.SendResponseSuccess(w, challenge)
 	}
 }
 
@@ -263,3 +304,26 @@ func LoginUnknownCustomerHandler(loginCustomerUseCase *usecases.LoginUnknownCust
 		httpserver.SendResponseSuccess(w, token)
 	}
 }
+
+// mergeGuestOrdersFromRequest gives the orders of the request guest to the customer verified by the login code
+// or created by the signup. The customer is already identified, so a failure is only logged and the guest orders are kept
+func mergeGuestOrdersFromRequest(r *http.Request, mergeGuestOrders *usecases.MergeGuestOrdersUseCase, cpf string) {
+	guestID := getGuestIDFromRequest(r)
+
+	merged, err := mergeGuestOrders.Execute(r.Context(), guestID, cpf)
+
+	if err != nil {
+		log.Print("merge guest orders", map[string]interface{}{
+			"error":  err.Error(),
+			"status": httpserver.GetStatusCodeFromError(err),
+		})
+		return
+	}
+
+	if merged > 0 {
+		log.Print("merge guest orders", map[string]interface{}{
+			"guestId": *guestID,
+			"orders":  merged,
+		})
+	}
+}
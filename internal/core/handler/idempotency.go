@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+const idempotentMaxBodySize = 1048576
+
+// Idempotent honors the Idempotency-Key header. A request repeated with the same key and body
+// gets the original response without running the handler again. The header is optional, so the
+// requests without it are handled as before
+func Idempotent(idempotentRequest *usecases.IdempotentRequestUseCase, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(entity.IdempotencyKeyHeader)
+
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, idempotentMaxBodySize+1))
+
+		if err != nil || len(body) > idempotentMaxBodySize {
+			log.Print("reading idempotent request body", map[string]interface{}{
+				"error": fmt.Sprintf("%v", err),
+			})
+			httpserver.SendBadRequestError(w, fmt.Errorf("request body must not be larger than 1MB"))
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		request := dto.IdempotentRequest{
+			Scope:       idempotentScope(r),
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+			Owner:       idempotentOwner(r),
+		}
+
+		stored, err := idempotentRequest.Begin(r.Context(), request)
+
+		if err != nil {
+			log.Print("begin idempotent request", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		if stored != nil {
+			w.Header().Set("Content-Type", stored.ContentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		recorder := &idempotentResponseRecorder{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next(recorder, r)
+
+		// The response is stored even when the client is gone, otherwise its retry would run the handler again
+		idempotentRequest.Finish(context.WithoutCancel(r.Context()), request, dto.IdempotentResponse{
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+	}
+}
+
+// idempotentScope keeps the keys of each caller apart, so two callers sending the same key do not get the
+// response of each other. The guests share the unknown customer subject, so they are told apart by the guest
+func idempotentScope(r *http.Request) string {
+	scope := r.Method + " " + r.URL.Path
+	claims, err := getTokenClaimsFromRequest(r)
+
+	if err != nil {
+		return scope
+	}
+
+	if claims.GuestID != "" {
+		return scope + " guest:" + claims.GuestID
+	}
+
+	return scope + " sub:" + claims.Subject
+}
+
+// idempotentOwner is the username of the caller, which is the CPF of the customers. The guests have no
+// personal data, so their keys have no owner
+func idempotentOwner(r *http.Request) string {
+	claims, err := getTokenClaimsFromRequest(r)
+
+	if err != nil || claims.GuestID != "" {
+		return ""
+	}
+
+	return claims.Username
+}
+
+// idempotentResponseRecorder writes the response to the client and keeps a copy to be stored
+type idempotentResponseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *idempotentResponseRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *idempotentResponseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+// @Summary Get customer loyalty points
+// @Description Get the points balance of the customer and the history of the points earned with the delivered orders,
+// @Description redeemed as discount and reversed by the canceled or not delivered orders
+// @Tags Customer
+// @Param id path int true "12"
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.LoyaltyResponse
+// @Failure 404 "Customer not found"
+// @Router /api/customers/{id}/loyalty [get]
+func GetCustomerLoyaltyHandler(getCustomerLoyalty *usecases.GetCustomerLoyaltyUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customerIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("get customer loyalty", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		customerId, err := strconv.Atoi(customerIdStr)
+
+		if err != nil {
+			log.Print("get customer loyalty", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		loyalty, err := getCustomerLoyalty.Execute(r.Context(), uint(customerId))
+
+		if err != nil {
+			log.Print("get customer loyalty", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, loyalty)
+	}
+}
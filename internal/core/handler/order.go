@@ -1,13 +1,15 @@
 package handler
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
 	"github.com/thiagoluis88git/tech1/pkg/httpserver"
 )
@@ -16,6 +18,7 @@ import (
 // @Description Create new order. To make an order the payment needs to be completed
 // @Description A new Ticket will be generated by the Order Date starting from 1
 // @Description In the next day the Ticket number will starts from 1 and so on
+// @Description The totalPrice is the products total. The promotions and the couponCode discounts are taken from it
 // @Tags Order
 // @Accept json
 // @Produce json
@@ -38,15 +41,13 @@ func CreateOrderHandler(createOrder *usecases.CreateOrderUseCase) http.HandlerFu
 			return
 		}
 
+		order.GuestID = getGuestIDFromRequest(r)
+		order.AuthenticatedCPF = getUsernameFromRequest(r)
+
 		now := time.Now()
 		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 
-		// Create this to prevent 2 process/goroutines create order with the same TicketNumber
-		var waitGroup sync.WaitGroup
-		ch := make(chan bool, 1)
-		waitGroup.Add(1)
-
-		response, err := createOrder.Execute(r.Context(), order, orderDate.UnixMilli(), &waitGroup, ch)
+		response, err := createOrder.Execute(r.Context(), order, orderDate.UnixMilli())
 
 		if err != nil {
 			log.Print("create order", map[string]interface{}{
@@ -61,14 +62,59 @@ func CreateOrderHandler(createOrder *usecases.CreateOrderUseCase) http.HandlerFu
 	}
 }
 
+// @Summary Get order price
+// @Description Get the order price with the promotions and the couponCode discounts, before paying the order.
+// @Description The totalPrice is the products total, and the payments must sum the returned totalPrice
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param order body dto.OrderPriceForm true "order"
+// @Success 200 {object} dto.OrderPriceResponse
+// @Failure 422 "The products or the coupon are not valid"
+// @Router /api/orders/price [post]
+func GetOrderPriceHandler(getOrderPrice *usecases.GetOrderPriceUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var form dto.OrderPriceForm
+
+		err := httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding order price body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		form.AuthenticatedCPF = getUsernameFromRequest(r)
+
+		response, err := getOrderPrice.Execute(r.Context(), form)
+
+		if err != nil {
+			log.Print("get order price", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
 // @Summary Get order by Id
 // @Description Get an order by Id
 // @Tags Order
 // @Accept json
 // @Produce json
 // @Param id path int true "12"
+// @Param Authorization header string false "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest"
 // @Success 200 {object} dto.OrderResponse
 // @Failure 400 "Order has required fields"
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own orders and payments"
 // @Router /api/orders/{id} [get]
 func GetOrderByIdHandler(getOrderById *usecases.GetOrderByIdUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -109,6 +155,58 @@ func GetOrderByIdHandler(getOrderById *usecases.GetOrderByIdUseCase) http.Handle
 	}
 }
 
+// @Summary Get order status history
+// @Description Get all status transitions of an order with its date, actor and reason.
+// @Description This can be used to audit who moved the order and when
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param Authorization header string false "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest"
+// @Success 200 {object} []dto.OrderStatusHistoryResponse
+// @Failure 404 "Order not found"
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own orders and payments"
+// @Router /api/orders/{id}/history [get]
+func GetOrderStatusHistoryHandler(getOrderStatusHistory *usecases.GetOrderStatusHistoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("get order status history path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := GetOrderId(idStr)
+
+		if err != nil {
+			log.Print("get order status history path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getOrderStatusHistory.Execute(r.Context(), id)
+
+		if err != nil {
+			log.Print("get order status history", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
 // @Summary Get all orders to prepare
 // @Description Get all orders already payed that needs to be prepared. This endpoint will be used by the kitchen
 // @Tags Order
@@ -157,6 +255,148 @@ func GetOrdersToFollowHandler(getOrdersToFollow *usecases.GetOrdersToFollowUseCa
 	}
 }
 
+// @Summary List orders
+// @Description List the orders with filters and cursor pagination. This endpoint will be used by the admin to search orders.
+// @Description Use the 'nextCursor' of the response as the 'cursor' param to get the next page. When it is null, there are no more orders
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param status query []string false "Order status. Can be repeated or comma separated" collectionFormat(multi)
+// @Param customerId query int false "Customer ID"
+// @Param ticketNumber query int false "Ticket number"
+// @Param paymentType query string false "Payment type"
+// @Param createdFrom query string false "Orders created from this date (RFC3339 or 2006-01-02)"
+// @Param createdTo query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates)"
+// @Param sort query string false "createdAt, ticketNumber or totalPrice. Use the '-' prefix to sort descending" default(-createdAt)
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size, up to 100" default(20)
+// @Success 200 {object} dto.OrderPage
+// @Failure 400 "Invalid filter"
+// @Router /api/admin/orders [get]
+func GetOrdersHandler(getOrders *usecases.GetOrdersUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getOrderFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get orders filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getOrders.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get orders", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
+func getOrderFilterFromRequest(r *http.Request) (dto.OrderFilter, error) {
+	query := r.URL.Query()
+	filter := dto.OrderFilter{
+		SortBy:     entity.OrderSortCreatedAt,
+		Descending: true,
+		Cursor:     query.Get("cursor"),
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+
+	if value := query.Get("customerId"); value != "" {
+		customerId, err := strconv.ParseUint(value, 10, 64)
+
+		if err != nil {
+			return dto.OrderFilter{}, fmt.Errorf("customerId is not valid")
+		}
+
+		id := uint(customerId)
+		filter.CustomerID = &id
+	}
+
+	if value := query.Get("ticketNumber"); value != "" {
+		ticketNumber, err := strconv.Atoi(value)
+
+		if err != nil {
+			return dto.OrderFilter{}, fmt.Errorf("ticketNumber is not valid")
+		}
+
+		filter.TicketNumber = &ticketNumber
+	}
+
+	if value := query.Get("paymentType"); value != "" {
+		filter.PaymentType = &value
+	}
+
+	if value := query.Get("createdFrom"); value != "" {
+		createdFrom, _, err := parseFilterDate(value)
+
+		if err != nil {
+			return dto.OrderFilter{}, fmt.Errorf("createdFrom is not valid")
+		}
+
+		filter.CreatedFrom = &createdFrom
+	}
+
+	if value := query.Get("createdTo"); value != "" {
+		createdTo, isDate, err := parseFilterDate(value)
+
+		if err != nil {
+			return dto.OrderFilter{}, fmt.Errorf("createdTo is not valid")
+		}
+
+		// A date includes the whole day
+		if isDate {
+			createdTo = createdTo.AddDate(0, 0, 1)
+		}
+
+		filter.CreatedTo = &createdTo
+	}
+
+	if value := query.Get("sort"); value != "" {
+		filter.Descending = strings.HasPrefix(value, "-")
+		filter.SortBy = strings.TrimPrefix(value, "-")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+
+		if err != nil {
+			return dto.OrderFilter{}, fmt.Errorf("limit is not valid")
+		}
+
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func parseFilterDate(value string) (time.Time, bool, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+
+	if err == nil {
+		return date, true, nil
+	}
+
+	date, err = time.Parse(time.RFC3339, value)
+
+	return date, false, err
+}
+
 // @Summary Get all orders with waiting payment status
 // @Description Get all orders with waiting payment by the owner.
 // @Description This endpoint will be used by the owner to know it the Mercado Livre QR Code was paid
@@ -378,6 +618,70 @@ func UpdateOrderNotDeliveredandler(updateToNotDelivered *usecases.UpdateToNotDel
 	}
 }
 
+// @Summary Cancel an order
+// @Description Cancel an order. The order can only be canceled while it is in Em pagamento, Criado or Preparando status.
+// @Description If the order was already paid, the payment will be refunded. Otherwise, the payment will be voided
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param orderCancelForm body dto.OrderCancelForm true "orderCancelForm"
+// @Success 204
+// @Failure 404 "Order not found"
+// @Failure 428 "Precondition failed: Need to be with status Em pagamento, Criado or Preparando"
+// @Router /api/orders/{id}/cancel [put]
+func CancelOrderHandler(cancelOrder *usecases.CancelOrderUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := GetOrderId(idStr)
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var form dto.OrderCancelForm
+
+		err = httpserver.DecodeJSONBody(w, r, &form)
+
+		if err != nil {
+			log.Print("decoding cancel order body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		err = cancelOrder.Execute(r.Context(), id, form.Reason)
+
+		if err != nil {
+			log.Print("cancel order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
 func GetOrderId(orderdStr string) (uint, error) {
 	orderId, err := strconv.Atoi(orderdStr)
 
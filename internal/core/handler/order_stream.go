@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+const orderStatusEvent = "order-status"
+
+// @Summary Stream the status changes of all orders
+// @Description Stream the status changes of all orders with Server-Sent Events. This endpoint will be used by the kitchen
+// @Description and the customer pickup monitor instead of polling the order lists. The browser resends the last received
+// @Description event id in the Last-Event-ID header when it reconnects, so the missed events are sent first
+// @Tags Order
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Last received event id"
+// @Success 200 {object} dto.OrderEvent
+// @Router /api/orders/stream [get]
+func OrdersStreamHandler(streamOrderEvents *usecases.StreamOrderEventsUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		streamOrderEventsToClient(w, r, streamOrderEvents, nil)
+	}
+}
+
+// @Summary Stream the status changes of an order
+// @Description Stream the status changes of a single order with Server-Sent Events. This endpoint will be used by the customer
+// @Description to know when the order is ready without polling it
+// @Tags Order
+// @Produce text/event-stream
+// @Param id path int true "12"
+// @Param Authorization header string false "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest"
+// @Param Last-Event-ID header int false "Last received event id"
+// @Success 200 {object} dto.OrderEvent
+// @Failure 404 "Order not found"
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own orders and payments"
+// @Router /api/orders/{id}/stream [get]
+func OrderStreamHandler(streamOrderEvents *usecases.StreamOrderEventsUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("stream order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		id, err := GetOrderId(idStr)
+
+		if err != nil {
+			log.Print("stream order", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		streamOrderEventsToClient(w, r, streamOrderEvents, &id)
+	}
+}
+
+func streamOrderEventsToClient(
+	w http.ResponseWriter,
+	r *http.Request,
+	streamOrderEvents *usecases.StreamOrderEventsUseCase,
+	orderID *uint,
+) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events, err := streamOrderEvents.Execute(ctx, orderID, httpserver.GetLastEventID(r))
+
+	if err != nil {
+		log.Print("stream order events", map[string]interface{}{
+			"error":  err.Error(),
+			"status": httpserver.GetStatusCodeFromError(err),
+		})
+		httpserver.SendResponseError(w, err)
+		return
+	}
+
+	stream, err := httpserver.NewEventStream(w)
+
+	if err != nil {
+		log.Print("open order events stream", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	heartbeat := time.NewTicker(httpserver.EventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			err = stream.Send(event.EventID, orderStatusEvent, event)
+		case <-heartbeat.C:
+			err = stream.Heartbeat()
+		case <-ctx.Done():
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
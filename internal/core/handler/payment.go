@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
@@ -49,6 +50,57 @@ func CreatePaymentHandler(payOrder *usecases.PayOrderUseCase) http.HandlerFunc {
 	}
 }
 
+// @Summary Get payment by Id
+// @Description Get a payment by Id with the order paid by it. The order is null while the payment was not used
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param id path int true "12"
+// @Param Authorization header string false "Bearer token of the customer, guest or admin login. Required for the orders of a customer or a guest"
+// @Success 200 {object} dto.PaymentOrderResponse
+// @Failure 404 "Payment not found"
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own orders and payments"
+// @Router /api/payments/{id} [get]
+func GetPaymentByIdHandler(getPaymentById *usecases.GetPaymentByIdUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		paymentIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("get payment by id path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		paymentId, err := strconv.Atoi(paymentIdStr)
+
+		if err != nil {
+			log.Print("get payment by id path", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getPaymentById.Execute(r.Context(), uint(paymentId))
+
+		if err != nil {
+			log.Print("get payment by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
+
 // @Summary Get payment types
 // @Description Get payment type, like [DEBIT, CREDIT, QR Code (Mercado Pago)]
 // @Tags Payment
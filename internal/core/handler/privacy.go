@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+// @Summary Export customer data
+// @Description Export all the data kept about the customer, as required by the LGPD: the profile, the orders,
+// @Description the payments and the loyalty points. Each export is kept in an audit record
+// @Tags Customer
+// @Param id path int true "12"
+// @Param Authorization header string true "Bearer token of the customer or admin login"
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.CustomerDataExport
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own data"
+// @Failure 404 "Customer not found"
+// @Router /api/customers/{id}/data-export [get]
+func ExportCustomerDataHandler(exportCustomerData *usecases.ExportCustomerDataUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := getTokenClaimsFromRequest(r)
+
+		if err != nil {
+			log.Print("export customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		customerIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("export customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		customerId, err := strconv.Atoi(customerIdStr)
+
+		if err != nil {
+			log.Print("export customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		export, err := exportCustomerData.Execute(r.Context(), uint(customerId), claims)
+
+		if err != nil {
+			log.Print("export customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, export)
+	}
+}
+
+// @Summary Erase customer data
+// @Description Erase the customer, as required by the LGPD. The customer is anonymized and removed from Cognito,
+// @Description and its orders are kept without the customer, so the sales totals do not change. Each erasure is kept in an audit record
+// @Tags Customer
+// @Param id path int true "12"
+// @Param Authorization header string true "Bearer token of the customer or admin login"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The customer can only access their own data"
+// @Failure 404 "Customer not found"
+// @Router /api/customers/{id} [delete]
+func EraseCustomerDataHandler(eraseCustomerData *usecases.EraseCustomerDataUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := getTokenClaimsFromRequest(r)
+
+		if err != nil {
+			log.Print("erase customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		customerIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("erase customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		customerId, err := strconv.Atoi(customerIdStr)
+
+		if err != nil {
+			log.Print("erase customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = eraseCustomerData.Execute(r.Context(), uint(customerId), claims)
+
+		if err != nil {
+			log.Print("erase customer data", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
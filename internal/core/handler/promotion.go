@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+// @Summary Create new promotion
+// @Description Create a promotion. Without code it is applied automatically to the orders, otherwise
+// @Description only when the customer types the coupon code. The type can be PERCENTAGE, FIXED_AMOUNT or BUY_X_GET_Y
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Param promotion body dto.PromotionForm true "promotion"
+// @Success 200 {object} dto.PromotionCreationResponse
+// @Failure 400 "Promotion has invalid rules"
+// @Failure 409 "This coupon code is already used"
+// @Router /api/admin/promotions [post]
+func CreatePromotionHandler(createPromotion *usecases.CreatePromotionUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var promotion dto.PromotionForm
+
+		err := httpserver.DecodeJSONBody(w, r, &promotion)
+
+		if err != nil {
+			log.Print("decoding promotion body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		promotionId, err := createPromotion.Execute(r.Context(), promotion)
+
+		if err != nil {
+			log.Print("create promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, dto.PromotionCreationResponse{
+			Id: promotionId,
+		})
+	}
+}
+
+// @Summary List all promotions
+// @Description List all promotions, active or not
+// @Tags Promotion
+// @Accept json
+// @Produce json
+// @Success 200 {object} []dto.PromotionResponse
+// @Router /api/admin/promotions [get]
+func GetPromotionsHandler(getPromotions *usecases.GetPromotionsUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotions, err := getPromotions.Execute(r.Context())
+
+		if err != nil {
+			log.Print("get promotions", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, promotions)
+	}
+}
+
+// @Summary Get promotion by ID
+// @Description Get promotion by ID
+// @Tags Promotion
+// @Param id path int true "12"
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.PromotionResponse
+// @Failure 404 "Promotion not found"
+// @Router /api/admin/promotions/{id} [get]
+func GetPromotionByIdHandler(getPromotionById *usecases.GetPromotionByIdUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotionIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("get promotion by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		promotionId, err := strconv.Atoi(promotionIdStr)
+
+		if err != nil {
+			log.Print("get promotion by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		promotion, err := getPromotionById.Execute(r.Context(), uint(promotionId))
+
+		if err != nil {
+			log.Print("get promotion by id", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, promotion)
+	}
+}
+
+// @Summary Update a promotion
+// @Description Update all the rules of a promotion by ID
+// @Tags Promotion
+// @Param id path int true "12"
+// @Param promotion body dto.PromotionForm true "promotion"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 400 "Promotion has invalid rules"
+// @Failure 404 "Promotion not found"
+// @Router /api/admin/promotions/{id} [put]
+func UpdatePromotionHandler(updatePromotion *usecases.UpdatePromotionUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotionIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("update promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		promotionId, err := strconv.Atoi(promotionIdStr)
+
+		if err != nil {
+			log.Print("update promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		var promotion dto.PromotionForm
+
+		err = httpserver.DecodeJSONBody(w, r, &promotion)
+
+		if err != nil {
+			log.Print("decoding promotion body for update promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		promotion.Id = uint(promotionId)
+		err = updatePromotion.Execute(r.Context(), promotion)
+
+		if err != nil {
+			log.Print("update promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
+
+// @Summary Delete a promotion
+// @Description Delete a promotion by ID. The orders keep the discounts already given
+// @Tags Promotion
+// @Param id path int true "12"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 404 "Promotion not found"
+// @Router /api/admin/promotions/{id} [delete]
+func DeletePromotionHandler(deletePromotion *usecases.DeletePromotionUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotionIdStr, err := httpserver.GetPathParamFromRequest(r, "id")
+
+		if err != nil {
+			log.Print("delete promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		promotionId, err := strconv.Atoi(promotionIdStr)
+
+		if err != nil {
+			log.Print("delete promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = deletePromotion.Execute(r.Context(), uint(promotionId))
+
+		if err != nil {
+			log.Print("delete promotion", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
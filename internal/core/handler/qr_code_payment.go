@@ -3,18 +3,17 @@ package handler
 import (
 	"log"
 	"net/http"
-	"sync"
 	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
-	"github.com/thiagoluis88git/tech1/pkg/environment"
 	"github.com/thiagoluis88git/tech1/pkg/httpserver"
 )
 
 // @Summary Generate a QR Code
 // @Description Generate a QR Code. This can be used to get the QR Code data, transform in a image and
 // @Description pay with a Mercado Livre test account to activate a Webhook to proccess the order.
+// @Description Send the paymentType 'PIX' to get a PIX BR Code with its PNG image, encoded in base64.
 // @Tags QRCode
 // @Accept json
 // @Produce json
@@ -36,16 +35,13 @@ func GenerateQRCodeHandler(generateQRCodePayment *usecases.GenerateQRCodePayment
 			return
 		}
 
+		form.GuestID = getGuestIDFromRequest(r)
+		form.AuthenticatedCPF = getUsernameFromRequest(r)
+
 		now := time.Now()
 		orderDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 
-		// Create this to prevent 2 process/goroutines create order with the same TicketNumber
-		var waitGroup sync.WaitGroup
-		ch := make(chan bool, 1)
-		waitGroup.Add(1)
-
-		token := environment.GetQRCodeGatewayToken()
-		response, err := generateQRCodePayment.Execute(r.Context(), token, form, orderDate.UnixMilli(), &waitGroup, ch)
+		response, err := generateQRCodePayment.Execute(r.Context(), form, orderDate.UnixMilli())
 
 		if err != nil {
 			log.Print("generate qrcode", map[string]interface{}{
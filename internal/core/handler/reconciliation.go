@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// @Summary Last QR Code reconciliation
+// @Description Get the result of the last run of the QR Code reconciliation. It checks in Mercado Livre the orders
+// @Description waiting payment whose webhook did not arrive, finishing the paid ones and canceling the expired ones.
+// @Description This endpoint will be used by the admin
+// @Tags Payment
+// @Produce json
+// @Success 200 {object} dto.QRCodeReconciliation
+// @Failure 404 "The reconciliation did not run yet"
+// @Router /api/admin/reconciliation/qrcode [get]
+func GetQRCodeReconciliationHandler(reconcileQRCodePayments *usecases.ReconcileQRCodePaymentsUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ok := reconcileQRCodePayments.LastRun()
+
+		if !ok {
+			err := &responses.BusinessResponse{
+				StatusCode: http.StatusNotFound,
+				Message:    "The QR Code reconciliation did not run yet",
+			}
+			log.Print("get qr code reconciliation", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseSuccess(w, response)
+	}
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+// @Summary Revenue per day
+// @Description Get the number of paid orders and the revenue of each day. Canceled orders are not considered.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} []dto.RevenueByDay
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/revenue/daily [get]
+func GetRevenueByDayHandler(getRevenueByDay *usecases.GetRevenueByDayUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get revenue by day filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getRevenueByDay.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get revenue by day", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "revenue-daily", response)
+	}
+}
+
+// @Summary Revenue per category
+// @Description Get the quantity sold and the revenue of each product category, including the modifiers. Canceled orders are not considered.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} []dto.RevenueByCategory
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/revenue/categories [get]
+func GetRevenueByCategoryHandler(getRevenueByCategory *usecases.GetRevenueByCategoryUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get revenue by category filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getRevenueByCategory.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get revenue by category", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "revenue-categories", response)
+	}
+}
+
+// @Summary Revenue per product
+// @Description Get the quantity sold and the revenue of each product, including the modifiers. Canceled orders are not considered.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} []dto.RevenueByProduct
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/revenue/products [get]
+func GetRevenueByProductHandler(getRevenueByProduct *usecases.GetRevenueByProductUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get revenue by product filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getRevenueByProduct.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get revenue by product", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "revenue-products", response)
+	}
+}
+
+// @Summary Kitchen times
+// @Description Get the average and the p95 of the wait, preparation and pickup times in seconds.
+// @Description Wait is until the kitchen starts the order, preparation is until it is done and pickup is until the waiter finishes it.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} dto.OrderTimesReport
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/order-times [get]
+func GetOrderTimesHandler(getOrderTimes *usecases.GetOrderTimesUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get order times filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getOrderTimes.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get order times", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "order-times", response)
+	}
+}
+
+// @Summary Not delivered rate
+// @Description Get the number of delivered and not delivered orders and the rate of the not delivered ones.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} dto.NotDeliveredReport
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/not-delivered [get]
+func GetNotDeliveredHandler(getNotDelivered *usecases.GetNotDeliveredUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get not delivered filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getNotDelivered.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get not delivered", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "not-delivered", response)
+	}
+}
+
+// @Summary Hourly order volume
+// @Description Get the number of orders created in each hour of the day. All the 24 hours are returned.
+// @Description This endpoint will be used by the admin. Use 'format=csv' or the 'Accept: text/csv' header to export it
+// @Tags Report
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Orders created from this date (RFC3339 or 2006-01-02). Default is 30 days before 'to'"
+// @Param to query string false "Orders created before this date (RFC3339 or 2006-01-02, inclusive for dates). Default is now"
+// @Param format query string false "json or csv"
+// @Success 200 {object} []dto.HourlyVolume
+// @Failure 400 "Invalid period"
+// @Router /api/admin/reports/hourly-volume [get]
+func GetHourlyVolumeHandler(getHourlyVolume *usecases.GetHourlyVolumeUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := getReportFilterFromRequest(r)
+
+		if err != nil {
+			log.Print("get hourly volume filter", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		response, err := getHourlyVolume.Execute(r.Context(), filter)
+
+		if err != nil {
+			log.Print("get hourly volume", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		sendReport(w, r, "hourly-volume", response)
+	}
+}
+
+func getReportFilterFromRequest(r *http.Request) (dto.ReportFilter, error) {
+	query := r.URL.Query()
+	filter := dto.ReportFilter{}
+
+	if value := query.Get("from"); value != "" {
+		from, _, err := parseFilterDate(value)
+
+		if err != nil {
+			return dto.ReportFilter{}, fmt.Errorf("from is not valid")
+		}
+
+		filter.From = from
+	}
+
+	if value := query.Get("to"); value != "" {
+		to, isDate, err := parseFilterDate(value)
+
+		if err != nil {
+			return dto.ReportFilter{}, fmt.Errorf("to is not valid")
+		}
+
+		// A date includes the whole day
+		if isDate {
+			to = to.AddDate(0, 0, 1)
+		}
+
+		filter.To = to
+	}
+
+	return filter, nil
+}
+
+func sendReport(w http.ResponseWriter, r *http.Request, name string, report any) {
+	if !httpserver.WantsCSV(r) {
+		httpserver.SendResponseSuccess(w, report)
+		return
+	}
+
+	err := httpserver.SendCSVResponse(w, name+".csv", report)
+
+	if err != nil {
+		log.Print("send report csv", map[string]interface{}{
+			"report": name,
+			"error":  err.Error(),
+		})
+	}
+}
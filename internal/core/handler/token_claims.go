@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+type tokenClaimsKey struct{}
+
+// withTokenClaims keeps the claims of the validated token for the next handlers
+func withTokenClaims(ctx context.Context, claims dto.TokenClaims) context.Context {
+	return context.WithValue(ctx, tokenClaimsKey{}, claims)
+}
+
+// getTokenClaimsFromRequest returns the claims of the token validated by the authentication
+// middleware. The requests without a token are unauthorized
+func getTokenClaimsFromRequest(r *http.Request) (dto.TokenClaims, error) {
+	claims, ok := r.Context().Value(tokenClaimsKey{}).(dto.TokenClaims)
+
+	if !ok {
+		return dto.TokenClaims{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "The Bearer token is required",
+		}
+	}
+
+	return claims, nil
+}
+
+// getUsernameFromRequest returns the username of the identified user token, which is the CPF of the
+// customer, or an empty string when the request has no token or the token of the unknown customer
+func getUsernameFromRequest(r *http.Request) string {
+	claims, ok := r.Context().Value(tokenClaimsKey{}).(dto.TokenClaims)
+
+	if !ok || claims.GuestID != "" {
+		return ""
+	}
+
+	return claims.Username
+}
+
+// getGuestIDFromRequest returns the guest of the unknown customer token, or nil when the request
+// has no token or the token of an identified user
+func getGuestIDFromRequest(r *http.Request) *string {
+	claims, ok := r.Context().Value(tokenClaimsKey{}).(dto.TokenClaims)
+
+	if !ok || claims.GuestID == "" {
+		return nil
+	}
+
+	return &claims.GuestID
+}
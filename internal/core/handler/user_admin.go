@@ -11,13 +11,16 @@ import (
 )
 
 // @Summary Create new user admin
-// @Description Create new customer. This process is not required to make an order
+// @Description Create new admin. Only the admins can create other admins, and the first one is created by the 'cmd/admin' command
 // @Tags UserAdmin
 // @Accept json
 // @Produce json
 // @Param product body dto.UserAdmin true "user admin"
+// @Param Authorization header string true "Bearer token of the admin login"
 // @Success 200 {object} dto.UserAdminResponse
 // @Failure 400 "Customer has required fields"
+// @Failure 401 "The Bearer token is required"
+// @Failure 403 "The user is not allowed to access this resource"
 // @Failure 409 "This user is already added"
 // @Router /auth/admin/signup [post]
 func CreateUserHandler(createUserAdmin *usecases.CreateUserUseCase) http.HandlerFunc {
@@ -161,52 +164,14 @@ func GetUserByIdHandler(getUserById *usecases.GetUserByIdUseCase) http.HandlerFu
 	}
 }
 
-// @Summary Get user by CPF
-// @Description Get user by CPF. This Endpoint can be used as a Login
-// @Tags UserAdmin
-// @Accept json
-// @Produce json
-// @Param user body dto.UserAdminForm true "UserAdminForm"
-// @Success 200 {object} dto.UserAdmin
-// @Failure 404 "User not found"
-// @Router /api/users/login [post]
-func GetUserByCPFHandler(getUserByCPF *usecases.GetUserByCPFUseCase) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var userForm dto.UserAdminForm
-
-		err := httpserver.DecodeJSONBody(w, r, &userForm)
-
-		if err != nil {
-			log.Print("decoding user form body", map[string]interface{}{
-				"error":  err.Error(),
-				"status": httpserver.GetStatusCodeFromError(err),
-			})
-			httpserver.SendBadRequestError(w, err)
-			return
-		}
-
-		user, err := getUserByCPF.Execute(r.Context(), userForm.CPF)
-
-		if err != nil {
-			log.Print("get user by id", map[string]interface{}{
-				"error":  err.Error(),
-				"status": httpserver.GetStatusCodeFromError(err),
-			})
-			httpserver.SendResponseError(w, err)
-			return
-		}
-
-		httpserver.SendResponseSuccess(w, user)
-	}
-}
-
 // @Summary Login
-// @Description Login the user by its CPF
+// @Description Start the login of the user by its CPF. A one-time code is sent to the user email,
+// @Description and it must be sent with the 'session' to the '/auth/login/verify' to get the tokens
 // @Tags UserAdmin
 // @Accept json
 // @Produce json
 // @Param customer body dto.UserAdminForm true "user form"
-// @Success 200 {object} dto.Token
+// @Success 200 {object} dto.LoginChallenge
 // @Failure 404 "User not found"
 // @Router /auth/admin/login [post]
 func LoginUserHandler(loginUserUseCase *usecases.LoginUserUseCase) http.HandlerFunc {
@@ -224,7 +189,7 @@ func LoginUserHandler(loginUserUseCase *usecases.LoginUserUseCase) http.HandlerF
 			return
 		}
 
-		token, err := loginUserUseCase.Execute(r.Context(), userForm.CPF)
+		challenge, err := loginUserUseCase.Execute(r.Context(), userForm.CPF)
 
 		if err != nil {
 			log.Print("login user", map[string]interface{}{
@@ -235,6 +200,6 @@ func LoginUserHandler(loginUserUseCase *usecases.LoginUserUseCase) http.HandlerF
 			return
 		}
 
-		httpserver.SendResponseSuccess(w, token)
+		httpserver.SendResponseSuccess(w, challenge)
 	}
 }
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"reflect"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+// RegisterValidations adds the domain types to the validation of the request bodies
+func RegisterValidations() {
+	httpserver.RegisterValidationType(moneyCents, entity.Money{})
+}
+
+// moneyCents lets the 'required' tag reject the zero amounts, which the validator does not check in structs
+func moneyCents(field reflect.Value) any {
+	if money, ok := field.Interface().(entity.Money); ok {
+		return money.Cents
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
 	"github.com/thiagoluis88git/tech1/pkg/environment"
 	"github.com/thiagoluis88git/tech1/pkg/httpserver"
@@ -12,15 +13,24 @@ import (
 
 // @Summary Payment Webhook
 // @Description Payment Webhook. This endpoint will be called when the user pays
-// @Description the QRCode generated by /api/qrcode/generate [post]
+// @Description the QRCode generated by /api/qrcode/generate [post]. The notification must be signed by Mercado Livre
+// @Description in the 'x-signature' header. The paid, expired, canceled and rejected payments finish the order and the payment,
+// @Description and the duplicated notifications are ignored
 // @Tags Webhook
 // @Accept json
 // @Produce json
+// @Param x-signature header string true "ts=<timestamp>,v1=<hash>"
+// @Param x-request-id header string false "Notification request id"
 // @Param externalPaymentEvent body dto.ExternalPaymentEvent true "externalPaymentEvent"
 // @Success 204
+// @Failure 401 "Invalid signature"
 // @Failure 406 "StatusNotAcceptable - Topic is not 'merchant_order'"
+// @Failure 422 "Invalid external reference"
 // @Router /api/webhook/ml/payment [post]
-func PostExternalPaymentEventWebhook(finishOrderForQRCode *usecases.FinishOrderForQRCodeUseCase) http.HandlerFunc {
+func PostExternalPaymentEventWebhook(
+	verifySignature *usecases.VerifyWebhookSignatureUseCase,
+	finishOrderForQRCode *usecases.FinishOrderForQRCodeUseCase,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var form dto.ExternalPaymentEvent
 
@@ -35,6 +45,21 @@ func PostExternalPaymentEventWebhook(finishOrderForQRCode *usecases.FinishOrderF
 			return
 		}
 
+		err = verifySignature.Execute(dto.WebhookSignature{
+			Signature: r.Header.Get("x-signature"),
+			RequestID: r.Header.Get("x-request-id"),
+			DataID:    entity.QRCodeNotificationDataID(r.URL.Query(), form.Resource),
+		})
+
+		if err != nil {
+			log.Print("verifying mercado livre webhook signature", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
 		token := environment.GetQRCodeGatewayToken()
 		err = finishOrderForQRCode.Execute(r.Context(), token, form)
 
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/usecases"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+const paymentWebhookMaxBodySize = 1048576
+
+// @Summary Payment provider Webhook
+// @Description Payment provider Webhook. Each registered payment provider sends its notifications to this endpoint
+// @Description with its own name, like 'sandbox' or 'mercadopago', and reads them in its own format.
+// @Description The finished payments finish the paying orders and the duplicated notifications are ignored
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param provider path string true "sandbox"
+// @Success 204
+// @Failure 400 "Invalid notification"
+// @Failure 404 "Payment provider not registered"
+// @Failure 422 "The payment does not belong to the order"
+// @Router /api/webhook/payments/{provider} [post]
+func PostPaymentProviderWebhook(handlePaymentWebhook *usecases.HandlePaymentWebhookUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, err := httpserver.GetPathParamFromRequest(r, "provider")
+
+		if err != nil {
+			log.Print("payment provider webhook", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, paymentWebhookMaxBodySize))
+
+		if err != nil {
+			log.Print("reading payment provider webhook body", map[string]interface{}{
+				"error":  err.Error(),
+				"status": httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendBadRequestError(w, err)
+			return
+		}
+
+		err = handlePaymentWebhook.Execute(r.Context(), provider, dto.PaymentWebhook{
+			Header: r.Header,
+			Query:  r.URL.Query(),
+			Body:   body,
+		})
+
+		if err != nil {
+			log.Print("post payment provider webhook", map[string]interface{}{
+				"provider": provider,
+				"error":    err.Error(),
+				"status":   httpserver.GetStatusCodeFromError(err),
+			})
+			httpserver.SendResponseError(w, err)
+			return
+		}
+
+		httpserver.SendResponseNoContentSuccess(w)
+	}
+}
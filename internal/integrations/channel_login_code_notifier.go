@@ -0,0 +1,32 @@
+package external
+
+import (
+	"context"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+)
+
+// ChannelLoginCodeNotifier sends the login code by SMS to the users with a phone, and by email
+// to the others
+type ChannelLoginCodeNotifier struct {
+	email repository.LoginCodeNotifier
+	sms   repository.LoginCodeNotifier
+}
+
+func NewChannelLoginCodeNotifier(email repository.LoginCodeNotifier, sms repository.LoginCodeNotifier) *ChannelLoginCodeNotifier {
+	return &ChannelLoginCodeNotifier{
+		email: email,
+		sms:   sms,
+	}
+}
+
+var _ repository.LoginCodeNotifier = (*ChannelLoginCodeNotifier)(nil)
+
+func (notifier *ChannelLoginCodeNotifier) SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error) {
+	if message.Phone != "" {
+		return notifier.sms.SendLoginCode(ctx, message)
+	}
+
+	return notifier.email.SendLoginCode(ctx, message)
+}
@@ -0,0 +1,56 @@
+package external
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+)
+
+const memoryEventPublisherSize = 1000
+
+// MemoryEventPublisher keeps the last published events in memory. It is used when there is
+// no webhook to send the events, like in the local development
+type MemoryEventPublisher struct {
+	mutex  sync.RWMutex
+	events []dto.OutboxEvent
+}
+
+func NewMemoryEventPublisher() *MemoryEventPublisher {
+	return &MemoryEventPublisher{
+		events: []dto.OutboxEvent{},
+	}
+}
+
+var _ repository.EventPublisher = (*MemoryEventPublisher)(nil)
+
+func (publisher *MemoryEventPublisher) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event)
+
+	if len(publisher.events) > memoryEventPublisherSize {
+		publisher.events = publisher.events[len(publisher.events)-memoryEventPublisherSize:]
+	}
+
+	log.Print("domain event published", map[string]interface{}{
+		"id":          event.ID,
+		"type":        event.EventType,
+		"aggregateId": event.AggregateID,
+	})
+
+	return nil
+}
+
+func (publisher *MemoryEventPublisher) Events() []dto.OutboxEvent {
+	publisher.mutex.RLock()
+	defer publisher.mutex.RUnlock()
+
+	events := make([]dto.OutboxEvent, len(publisher.events))
+	copy(events, publisher.events)
+
+	return events
+}
@@ -0,0 +1,376 @@
+package external
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	extModel "github.com/thiagoluis88git/tech1/internal/integrations/model"
+	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+// LocalIdentityProvider replaces Cognito when the API runs without AWS, like in the local development
+// and in the CI. The users, the login sessions and the refresh tokens are kept in Postgres and the
+// access tokens are signed with the configured key, with the same claims of the Cognito tokens.
+// The login code has a single attempt, like the custom auth challenge configured in Cognito
+type LocalIdentityProvider struct {
+	db         *gorm.DB
+	signingKey *rsa.PrivateKey
+	keyID      string
+	groupUser  string
+	groupAdmin string
+}
+
+// LocalIdentityModels are the tables of the local identity provider, migrated with the other models
+var LocalIdentityModels = []any{
+	&extModel.LocalIdentityUser{},
+	&extModel.LocalIdentityLoginSession{},
+	&extModel.LocalIdentityRefreshToken{},
+}
+
+func NewLocalIdentityProvider(db *gorm.DB, signingKey *rsa.PrivateKey, groupUser string, groupAdmin string) *LocalIdentityProvider {
+	return &LocalIdentityProvider{
+		db:         db,
+		signingKey: signingKey,
+		keyID:      localIdentityKeyID(&signingKey.PublicKey),
+		groupUser:  groupUser,
+		groupAdmin: groupAdmin,
+	}
+}
+
+var _ remote.CognitoRemoteDataSource = (*LocalIdentityProvider)(nil)
+
+// NewLocalSigningKey parses the PEM key, in PKCS #1 or PKCS #8. Without key, a new one is created,
+// so the tokens are not valid anymore after the API restarts
+func NewLocalSigningKey(pemKey string) (*rsa.PrivateKey, error) {
+	if pemKey == "" {
+		log.Print("local identity provider without signing key, using a temporary one", map[string]interface{}{})
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	block, _ := pem.Decode([]byte(pemKey))
+
+	if block == nil {
+		return nil, errors.New("the local identity signing key is not a PEM key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("the local identity signing key is not valid: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+
+	if !ok {
+		return nil, errors.New("the local identity signing key is not a RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// KeySet has the public key of the tokens, to validate them like the Cognito ones
+func (provider *LocalIdentityProvider) KeySet() *LocalKeySet {
+	return NewLocalKeySet(map[string]*rsa.PublicKey{
+		provider.keyID: &provider.signingKey.PublicKey,
+	})
+}
+
+func (provider *LocalIdentityProvider) SignUp(user *model.Customer) error {
+	return provider.signUp(user.CPF, user.Name, user.Email, provider.groupUser)
+}
+
+func (provider *LocalIdentityProvider) SignUpAdmin(user *model.UserAdmin) error {
+	return provider.signUp(user.CPF, user.Name, user.Email, provider.groupAdmin)
+}
+
+// The errors have the names of the Cognito exceptions, so the repositories map them to the same status codes
+func (provider *LocalIdentityProvider) signUp(cpf, name, email, group string) error {
+	var count int64
+
+	err := provider.db.Model(&extModel.LocalIdentityUser{}).Where("username = ?", cpf).Count(&count).Error
+
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("UsernameExistsException: the user %v already exists", cpf)
+	}
+
+	subject, err := randomIdentifier()
+
+	if err != nil {
+		return err
+	}
+
+	return provider.db.Create(&extModel.LocalIdentityUser{
+		Subject:  subject,
+		Username: cpf,
+		Name:     name,
+		Email:    email,
+		Group:    group,
+	}).Error
+}
+
+func (provider *LocalIdentityProvider) StartLogin(cpf string, code string) (string, error) {
+	_, err := provider.getUser(cpf)
+
+	if err != nil {
+		return "", err
+	}
+
+	session, err := randomIdentifier()
+
+	if err != nil {
+		return "", err
+	}
+
+	err = provider.db.Create(&extModel.LocalIdentityLoginSession{
+		Session:   session,
+		Username:  cpf,
+		CodeHash:  hashSecret(code),
+		ExpiresAt: time.Now().Add(entity.LoginCodeDuration),
+	}).Error
+
+	if err != nil {
+		return "", err
+	}
+
+	return session, nil
+}
+
+// RespondToLogin deletes the session before comparing the code, so a wrong code ends it
+func (provider *LocalIdentityProvider) RespondToLogin(cpf string, session string, code string) (extModel.CognitoAuthentication, error) {
+	var loginSession extModel.LocalIdentityLoginSession
+
+	err := provider.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("session = ? AND username = ?", session, cpf).
+			First(&loginSession).
+			Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&loginSession).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return extModel.CognitoAuthentication{}, localIdentityUnauthorized("The login session is not valid or is expired")
+	}
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	if time.Now().After(loginSession.ExpiresAt) {
+		return extModel.CognitoAuthentication{}, localIdentityUnauthorized("The login session is not valid or is expired")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(loginSession.CodeHash), []byte(hashSecret(code))) != 1 {
+		return extModel.CognitoAuthentication{}, localIdentityUnauthorized("The login code is not valid")
+	}
+
+	user, err := provider.getUser(cpf)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	originJTI, err := randomIdentifier()
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	accessToken, err := provider.signAccessToken(user.Subject, user.Username, []string{user.Group}, originJTI)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	refreshToken, err := randomIdentifier()
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	err = provider.db.Create(&extModel.LocalIdentityRefreshToken{
+		TokenHash: hashSecret(refreshToken),
+		Username:  user.Username,
+		OriginJTI: originJTI,
+		ExpiresAt: time.Now().Add(entity.LocalIdentityRefreshTokenDuration),
+	}).Error
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	return extModel.CognitoAuthentication{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(entity.LocalIdentityAccessTokenDuration.Seconds()),
+	}, nil
+}
+
+func (provider *LocalIdentityProvider) RefreshToken(refreshToken string) (extModel.CognitoAuthentication, error) {
+	var token extModel.LocalIdentityRefreshToken
+
+	err := provider.db.
+		Where("token_hash = ? AND expires_at > ?", hashSecret(refreshToken), time.Now()).
+		First(&token).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return extModel.CognitoAuthentication{}, localIdentityUnauthorized("The refresh token is not valid")
+	}
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	user, err := provider.getUser(token.Username)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	accessToken, err := provider.signAccessToken(user.Subject, user.Username, []string{user.Group}, token.OriginJTI)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	return extModel.CognitoAuthentication{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(entity.LocalIdentityAccessTokenDuration.Seconds()),
+	}, nil
+}
+
+// RevokeToken succeeds for an unknown token, like Cognito
+func (provider *LocalIdentityProvider) RevokeToken(refreshToken string) error {
+	return provider.db.
+		Where("token_hash = ?", hashSecret(refreshToken)).
+		Delete(&extModel.LocalIdentityRefreshToken{}).
+		Error
+}
+
+// LoginUnknown signs a token of the shared user of the customers who do not identify themselves.
+// It has no refresh token, like the Cognito one
+func (provider *LocalIdentityProvider) LoginUnknown() (string, error) {
+	originJTI, err := randomIdentifier()
+
+	if err != nil {
+		return "", err
+	}
+
+	return provider.signAccessToken(entity.UnknownCustomerUsername, entity.UnknownCustomerUsername, []string{provider.groupUser}, originJTI)
+}
+
+// DeleteUser removes the user with its login sessions and refresh tokens. A user already removed is not an error
+func (provider *LocalIdentityProvider) DeleteUser(cpf string) error {
+	return provider.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("username = ?", cpf).Delete(&extModel.LocalIdentityRefreshToken{}).Error
+
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("username = ?", cpf).Delete(&extModel.LocalIdentityLoginSession{}).Error
+
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("username = ?", cpf).Delete(&extModel.LocalIdentityUser{}).Error
+	})
+}
+
+func (provider *LocalIdentityProvider) getUser(cpf string) (extModel.LocalIdentityUser, error) {
+	var user extModel.LocalIdentityUser
+
+	err := provider.db.Where("username = ?", cpf).First(&user).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return extModel.LocalIdentityUser{}, fmt.Errorf("UserNotFoundException: the user %v does not exist", cpf)
+	}
+
+	return user, err
+}
+
+func (provider *LocalIdentityProvider) signAccessToken(subject string, username string, groups []string, originJTI string) (string, error) {
+	jti, err := randomIdentifier()
+
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := entity.AccessTokenClaims{
+		Subject:   subject,
+		Username:  username,
+		Groups:    groups,
+		TokenUse:  entity.AccessTokenUse,
+		ClientID:  entity.LocalIdentityClientID,
+		Issuer:    entity.LocalIdentityIssuer,
+		ExpiresAt: now.Add(entity.LocalIdentityAccessTokenDuration).Unix(),
+		IssuedAt:  now.Unix(),
+		JTI:       jti,
+		OriginJTI: originJTI,
+	}
+
+	return claims.Sign(provider.signingKey, provider.keyID)
+}
+
+func localIdentityKeyID(key *rsa.PublicKey) string {
+	der := x509.MarshalPKCS1PublicKey(key)
+	hash := sha256.Sum256(der)
+
+	return "local-" + hex.EncodeToString(hash[:8])
+}
+
+func randomIdentifier() (string, error) {
+	random := make([]byte, 32)
+
+	_, err := rand.Read(random)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func localIdentityUnauthorized(message string) error {
+	return &responses.NetworkError{
+		Code:    http.StatusUnauthorized,
+		Message: message,
+	}
+}
@@ -0,0 +1,38 @@
+package external
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// LocalKeySet has fixed public keys, without fetching them from Cognito. It validates the
+// tokens signed in the tests and by identity providers which sign their own tokens
+type LocalKeySet struct {
+	keys map[string]*rsa.PublicKey
+}
+
+func NewLocalKeySet(keys map[string]*rsa.PublicKey) *LocalKeySet {
+	return &LocalKeySet{
+		keys: keys,
+	}
+}
+
+var _ repository.KeySetRepository = (*LocalKeySet)(nil)
+
+func (keySet *LocalKeySet) GetPublicKey(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	key, ok := keySet.keys[keyID]
+
+	if !ok {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    fmt.Sprintf("The Bearer token key %v is unknown", keyID),
+		}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,34 @@
+package external
+
+import (
+	"context"
+	"log"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+)
+
+// LogLoginCodeNotifier writes the login codes in the log instead of sending them. Anyone who reads
+// the log can log in with them, so it is allowed only with the local identity provider
+type LogLoginCodeNotifier struct{}
+
+func NewLogLoginCodeNotifier() *LogLoginCodeNotifier {
+	return &LogLoginCodeNotifier{}
+}
+
+var _ repository.LoginCodeNotifier = (*LogLoginCodeNotifier)(nil)
+
+func (notifier *LogLoginCodeNotifier) SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error) {
+	log.Print("login code", map[string]interface{}{
+		"email": message.Email,
+		"phone": message.Phone,
+		"code":  message.Code,
+	})
+
+	if message.Phone != "" {
+		return entity.MaskPhone(message.Phone), nil
+	}
+
+	return entity.MaskEmail(message.Email), nil
+}
@@ -0,0 +1,9 @@
+package model
+
+// CognitoAuthentication is the result of a login or a refresh. The refresh does not return
+// a new RefreshToken, so it is empty
+type CognitoAuthentication struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JSONWebKeySet is the document of the public keys of the user pool, as published by Cognito
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+func (key JSONWebKey) RSAPublicKey() (*rsa.PublicKey, error) {
+	if key.KeyType != "RSA" {
+		return nil, fmt.Errorf("the key %v is not a RSA key", key.KeyID)
+	}
+
+	modulus, err := base64.RawURLEncoding.DecodeString(key.Modulus)
+
+	if err != nil {
+		return nil, fmt.Errorf("the key %v modulus is not valid: %w", key.KeyID, err)
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(key.Exponent)
+
+	if err != nil || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, fmt.Errorf("the key %v exponent is not valid", key.KeyID)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
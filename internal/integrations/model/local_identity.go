@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// LocalIdentityUser is the user of the local identity provider. The Username is the CPF,
+// like in Cognito
+type LocalIdentityUser struct {
+	ID        uint   `gorm:"primarykey"`
+	Subject   string `gorm:"uniqueIndex"`
+	Username  string `gorm:"uniqueIndex"`
+	Name      string
+	Email     string
+	Group     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// LocalIdentityLoginSession keeps only the hash of the login code
+type LocalIdentityLoginSession struct {
+	ID        uint   `gorm:"primarykey"`
+	Session   string `gorm:"uniqueIndex"`
+	Username  string
+	CodeHash  string
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// LocalIdentityRefreshToken keeps only the hash of the refresh token. The OriginJTI is the
+// 'origin_jti' of all the access tokens got with it
+type LocalIdentityRefreshToken struct {
+	ID        uint   `gorm:"primarykey"`
+	TokenHash string `gorm:"uniqueIndex"`
+	Username  string
+	OriginJTI string
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
@@ -10,23 +10,25 @@ type QRCodeData struct {
 }
 
 type QRCodeInput struct {
-	ExpirationDate    string `json:"expiration_date"`
-	ExternalReference string `json:"external_reference"`
-	Description       string `json:"description"`
-	Title             string `json:"title"`
-	NotificationUrl   string `json:"notification_url"`
-	Items             []Item `json:"items"`
-	TotalAmount       int    `json:"total_amount"`
+	ExpirationDate    string      `json:"expiration_date"`
+	ExternalReference string      `json:"external_reference"`
+	Description       string      `json:"description"`
+	Title             string      `json:"title"`
+	NotificationUrl   string      `json:"notification_url"`
+	Items             []Item      `json:"items"`
+	TotalAmount       json.Number `json:"total_amount"`
 }
 
+// Item has the amounts in reais with the cents, like 12.90, as the Mercado Livre API expects.
+// They are written by the entity.Money, so they are never rounded as floats
 type Item struct {
-	Description string `json:"description"`
-	SkuNumber   string `json:"sku_number"`
-	Title       string `json:"title"`
-	UnitMeasure string `json:"unit_measure"`
-	Quantity    int    `json:"quantity"`
-	UnitPrice   int    `json:"unit_price"`
-	TotalAmount int    `json:"total_amount"`
+	Description string      `json:"description"`
+	SkuNumber   string      `json:"sku_number"`
+	Title       string      `json:"title"`
+	UnitMeasure string      `json:"unit_measure"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   json.Number `json:"unit_price"`
+	TotalAmount json.Number `json:"total_amount"`
 }
 
 func (input *QRCodeInput) GetJSONBody() (*bytes.Buffer, error) {
@@ -1,28 +1,72 @@
 package external
 
 import (
+	"context"
+	"net/http"
 	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
 
 	"github.com/google/uuid"
 )
 
+// PaymentGateway simulates a credit card acquirer. It takes some time to authorize the payment,
+// like a real one, and approves all of them
 type PaymentGateway struct {
 }
 
-func NewPaymentGateway() repository.PaymentGateway {
+func NewPaymentGateway() repository.PaymentProvider {
 	return &PaymentGateway{}
 }
 
-func (p *PaymentGateway) Pay(paymentResonse dto.PaymentResponse, payment dto.Payment) (dto.PaymentGatewayResponse, error) {
+func (p *PaymentGateway) Name() string {
+	return entity.PaymentProviderCreditCard
+}
+
+func (p *PaymentGateway) Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error) {
 	id := uuid.New()
 
 	time.Sleep(3 * time.Second)
 
 	return dto.PaymentGatewayResponse{
 		PaymentGatewayId: id.String(),
+		PaymentStatus:    entity.PaymentPayingStatus,
 		PaymentDate:      time.Now(),
 	}, nil
 }
+
+func (p *PaymentGateway) Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{
+		PaymentGatewayId: authorized.PaymentGatewayId,
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentDate:      time.Now(),
+	}, nil
+}
+
+func (p *PaymentGateway) Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	id := uuid.New()
+
+	return dto.PaymentGatewayResponse{
+		PaymentGatewayId: id.String(),
+		PaymentStatus:    entity.PaymentRefundedStatus,
+		PaymentDate:      time.Now(),
+	}, nil
+}
+
+// GetStatus returns the status saved in the payment, because the card payments are finished in the request
+func (p *PaymentGateway) GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{
+		PaymentGatewayId: payment.PaymentGatewayId,
+		PaymentStatus:    payment.PaymentStatus,
+	}, nil
+}
+
+func (p *PaymentGateway) ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error) {
+	return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+		Code:    http.StatusNotFound,
+		Message: "The credit card payments do not have webhooks",
+	}
+}
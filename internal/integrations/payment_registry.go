@@ -0,0 +1,64 @@
+package external
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// PaymentProviderRegistry keeps the provider of each payment type. A new payment type only needs
+// a provider registered in the main, without new handlers
+type PaymentProviderRegistry struct {
+	mutex     sync.RWMutex
+	providers map[string]repository.PaymentProvider
+}
+
+func NewPaymentProviderRegistry() *PaymentProviderRegistry {
+	return &PaymentProviderRegistry{
+		providers: map[string]repository.PaymentProvider{},
+	}
+}
+
+var _ repository.PaymentProviderRegistry = (*PaymentProviderRegistry)(nil)
+
+func (registry *PaymentProviderRegistry) Register(paymentType string, provider repository.PaymentProvider) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.providers[paymentType] = provider
+}
+
+func (registry *PaymentProviderRegistry) GetProvider(paymentType string) (repository.PaymentProvider, error) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	provider, ok := registry.providers[paymentType]
+
+	if !ok {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("The payment type %q is not supported", paymentType),
+		}
+	}
+
+	return provider, nil
+}
+
+func (registry *PaymentProviderRegistry) GetProviderByName(name string) (repository.PaymentProvider, error) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	for _, provider := range registry.providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+
+	return nil, &responses.BusinessResponse{
+		StatusCode: http.StatusNotFound,
+		Message:    fmt.Sprintf("The payment provider %q is not registered", name),
+	}
+}
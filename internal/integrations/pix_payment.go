@@ -0,0 +1,173 @@
+package external
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// The header with the secret shared with the PSP which sends the PIX webhook
+const PIXWebhookSecretHeader = "X-Webhook-Secret"
+
+const pixQRCodeSize = 256
+
+// PIXPaymentProvider generates the PIX BR Code locally, with the merchant key, so no PSP is called
+// to charge the customer. The payment is finished by the PSP webhook when the PIX is received
+type PIXPaymentProvider struct {
+	key           string
+	merchantName  string
+	merchantCity  string
+	webhookSecret string
+}
+
+// pixWebhookBody is the notification defined by the Banco Central PIX API
+type pixWebhookBody struct {
+	PIX []pixWebhookPayment `json:"pix"`
+}
+
+type pixWebhookPayment struct {
+	EndToEndID string `json:"endToEndId"`
+	TxID       string `json:"txid"`
+	Amount     string `json:"valor"`
+}
+
+func NewPIXPaymentProvider(key string, merchantName string, merchantCity string, webhookSecret string) repository.PaymentProvider {
+	return &PIXPaymentProvider{
+		key:           key,
+		merchantName:  merchantName,
+		merchantCity:  merchantCity,
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (provider *PIXPaymentProvider) Name() string {
+	return entity.PaymentProviderPIX
+}
+
+// Authorize creates a static BR Code with the order total, so the customer can not change the amount.
+// The txid identifies the order and the payment when the PSP notifies the PIX
+func (provider *PIXPaymentProvider) Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error) {
+	if authorization.OrderID == 0 {
+		return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "The PIX QR Code needs the order",
+		}
+	}
+
+	payload, err := entity.PIXBRCode{
+		Key:          provider.key,
+		Description:  fmt.Sprintf("Pedido %v", authorization.OrderID),
+		MerchantName: provider.merchantName,
+		MerchantCity: provider.merchantCity,
+		Amount:       authorization.TotalPrice,
+		TxID:         entity.PIXTxID(authorization.OrderID, authorization.PaymentID),
+	}.Encode()
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, pixQRCodeSize)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("The PIX QR Code could not be rendered: %v", err.Error()),
+		}
+	}
+
+	return dto.PaymentGatewayResponse{
+		PaymentStatus: entity.PaymentPayingStatus,
+		PaymentDate:   time.Now(),
+		QRCodeData:    payload,
+		QRCodePNG:     png,
+	}, nil
+}
+
+func (provider *PIXPaymentProvider) Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "The PIX is captured when the customer pays it",
+	}
+}
+
+// Refund is not supported, because the PIX devolution is made in the PSP with the end to end id
+func (provider *PIXPaymentProvider) Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: fmt.Sprintf("The PIX %v must be refunded in the PSP", payment.PaymentGatewayId),
+	}
+}
+
+// GetStatus is not supported, because the static BR Code is only known by the PSP when it is paid
+func (provider *PIXPaymentProvider) GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "The PIX status is only sent by the webhook",
+	}
+}
+
+// ParseWebhook reads the '{"pix": [{"endToEndId": "E...", "txid": "O1P1", "valor": "10.00"}]}' body.
+// The PSP must send one PIX per notification, so each one is finished or retried alone
+func (provider *PIXPaymentProvider) ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error) {
+	secret := webhook.Header.Get(PIXWebhookSecretHeader)
+
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(provider.webhookSecret)) != 1 {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusUnauthorized,
+			Message: "The PIX webhook secret is not valid",
+		}
+	}
+
+	var body pixWebhookBody
+
+	err := json.Unmarshal(webhook.Body, &body)
+
+	if err != nil || len(body.PIX) != 1 {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: "The PIX webhook body must have one PIX",
+		}
+	}
+
+	pix := body.PIX[0]
+
+	orderID, paymentID, ok := entity.ParsePIXTxID(pix.TxID)
+
+	if !ok || pix.EndToEndID == "" {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("The PIX txid %q is not valid", pix.TxID),
+		}
+	}
+
+	amount, err := entity.ParseMoney(pix.Amount)
+
+	if err != nil || amount.Cents <= 0 {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("The PIX amount %q is not valid", pix.Amount),
+		}
+	}
+
+	return dto.PaymentWebhookEvent{
+		EventKey:         fmt.Sprintf("pix:%v", pix.EndToEndID),
+		OrderID:          orderID,
+		PaymentID:        paymentID,
+		GatewayPaymentID: pix.EndToEndID,
+		PaymentStatus:    entity.PaymentPayedStatus,
+		OrderStatus:      entity.OrderStatusCreated,
+		Reason:           "Payment confirmed",
+		PaidAmount:       amount,
+		Finished:         true,
+	}, nil
+}
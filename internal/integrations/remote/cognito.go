@@ -1,32 +1,40 @@
 package remote
 
 import (
-	"fmt"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	cognito "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/thiagoluis88git/tech1/internal/core/data/model"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	extModel "github.com/thiagoluis88git/tech1/internal/integrations/model"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
-const (
-	passwordSufixTemp = "12!@Az"
-	passwordSufix     = "1234&$sWa"
-)
-
+// CognitoRemoteDataSource has no passwords: the users log in with a one-time code. The admin
+// APIs are used, so only the API, with its AWS credentials, can start a login or pass the code
 type CognitoRemoteDataSource interface {
 	SignUp(user *model.Customer) error
 	SignUpAdmin(user *model.UserAdmin) error
-	Login(cpf string) (string, error)
+	StartLogin(cpf string, code string) (string, error)
+	RespondToLogin(cpf string, session string, code string) (extModel.CognitoAuthentication, error)
+	RefreshToken(refreshToken string) (extModel.CognitoAuthentication, error)
+	RevokeToken(refreshToken string) error
 	LoginUnknown() (string, error)
+	DeleteUser(cpf string) error
 }
 
 type CognitoRemoteDataSourceImpl struct {
-	cognitoClient *cognito.CognitoIdentityProvider
-	appClientID   string
-	userPoolID    string
-	groupUser     string
-	groupAdmin    string
+	cognitoClient       *cognito.CognitoIdentityProvider
+	appClientID         string
+	userPoolID          string
+	groupUser           string
+	groupAdmin          string
+	unknownUserPassword string
 }
 
 func NewCognitoRemoteDataSource(
@@ -35,6 +43,7 @@ func NewCognitoRemoteDataSource(
 	appClientId string,
 	groupUser string,
 	groupAdmin string,
+	unknownUserPassword string,
 ) CognitoRemoteDataSource {
 	config := &aws.Config{Region: aws.String(region)}
 	sess, err := session.NewSession(config)
@@ -43,22 +52,13 @@ func NewCognitoRemoteDataSource(
 	}
 	client := cognito.New(sess)
 
-	client.AdminUpdateUserAttributes(&cognito.AdminUpdateUserAttributesInput{
-		UserAttributes: []*cognito.AttributeType{
-			{
-				Name:  aws.String("email_verified"),
-				Value: aws.String("true"),
-			},
-		},
-	})
-
-	// teste, testee := client.CreateUserPoolRequest()
 	return &CognitoRemoteDataSourceImpl{
-		cognitoClient: client,
-		appClientID:   appClientId,
-		userPoolID:    userPoolID,
-		groupUser:     groupUser,
-		groupAdmin:    groupAdmin,
+		cognitoClient:       client,
+		appClientID:         appClientId,
+		userPoolID:          userPoolID,
+		groupUser:           groupUser,
+		groupAdmin:          groupAdmin,
+		unknownUserPassword: unknownUserPassword,
 	}
 }
 
@@ -73,13 +73,10 @@ func (ds *CognitoRemoteDataSourceImpl) SignUp(user *model.Customer) error {
 func (ds *CognitoRemoteDataSourceImpl) signUp(cpf, name, email, groupName string) error {
 	messageAction := "SUPPRESS"
 
-	pass := fmt.Sprintf("%v%v", cpf, passwordSufixTemp)
-
 	userCognito := &cognito.AdminCreateUserInput{
-		UserPoolId:        aws.String(ds.userPoolID),
-		Username:          aws.String(cpf),
-		MessageAction:     &messageAction,
-		TemporaryPassword: &pass,
+		UserPoolId:    aws.String(ds.userPoolID),
+		Username:      aws.String(cpf),
+		MessageAction: &messageAction,
 		UserAttributes: []*cognito.AttributeType{
 			{
 				Name:  aws.String("name"),
@@ -102,7 +99,14 @@ func (ds *CognitoRemoteDataSourceImpl) signUp(cpf, name, email, groupName string
 		return err
 	}
 
-	password := fmt.Sprintf("%v%v", cpf, passwordSufix)
+	// Cognito only confirms the user with a permanent password. It is random and never
+	// stored, since the login is by the one-time code
+	password, err := randomPassword()
+
+	if err != nil {
+		return err
+	}
+
 	permanent := true
 
 	setPasswordInput := &cognito.AdminSetUserPasswordInput{
@@ -133,40 +137,177 @@ func (ds *CognitoRemoteDataSourceImpl) signUp(cpf, name, email, groupName string
 	return nil
 }
 
-func (ds *CognitoRemoteDataSourceImpl) Login(cpf string) (string, error) {
-	password := fmt.Sprintf("%v%v", cpf, passwordSufix)
+// StartLogin keeps the hash of the code in the user and starts the custom auth challenge, returning
+// the login session. The CreateAuthChallenge Lambda reads the hash from the user attributes, since
+// Cognito does not send the client metadata of the AdminInitiateAuth to it
+func (ds *CognitoRemoteDataSourceImpl) StartLogin(cpf string, code string) (string, error) {
+	_, err := ds.cognitoClient.AdminUpdateUserAttributes(&cognito.AdminUpdateUserAttributesInput{
+		UserPoolId: aws.String(ds.userPoolID),
+		Username:   aws.String(cpf),
+		UserAttributes: []*cognito.AttributeType{
+			{
+				Name:  aws.String(entity.LoginCodeAttribute),
+				Value: aws.String(entity.HashLoginCode(code)),
+			},
+		},
+	})
+
+	if err != nil {
+		return "", err
+	}
 
-	authInput := &cognito.InitiateAuthInput{
-		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+	authInput := &cognito.AdminInitiateAuthInput{
+		AuthFlow: aws.String(cognito.AuthFlowTypeCustomAuth),
 		AuthParameters: aws.StringMap(map[string]string{
 			"USERNAME": cpf,
-			"PASSWORD": password,
 		}),
-		ClientId: aws.String(ds.appClientID),
+		ClientId:   aws.String(ds.appClientID),
+		UserPoolId: aws.String(ds.userPoolID),
 	}
-	result, err := ds.cognitoClient.InitiateAuth(authInput)
+	result, err := ds.cognitoClient.AdminInitiateAuth(authInput)
 
 	if err != nil {
 		return "", err
 	}
 
-	return *result.AuthenticationResult.AccessToken, nil
+	if aws.StringValue(result.ChallengeName) != entity.LoginCodeChallenge || result.Session == nil {
+		return "", &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: "Cognito did not start the custom auth challenge",
+		}
+	}
+
+	return *result.Session, nil
 }
 
-func (ds *CognitoRemoteDataSourceImpl) LoginUnknown() (string, error) {
-	authInput := &cognito.InitiateAuthInput{
-		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
+// RespondToLogin answers the challenge with the code. A wrong code does not return the tokens,
+// and Cognito ends the session after the attempts allowed by the DefineAuthChallenge
+func (ds *CognitoRemoteDataSourceImpl) RespondToLogin(cpf string, session string, code string) (extModel.CognitoAuthentication, error) {
+	challengeInput := &cognito.AdminRespondToAuthChallengeInput{
+		ChallengeName: aws.String(entity.LoginCodeChallenge),
+		ChallengeResponses: aws.StringMap(map[string]string{
+			"USERNAME": cpf,
+			"ANSWER":   code,
+		}),
+		Session:    aws.String(session),
+		ClientId:   aws.String(ds.appClientID),
+		UserPoolId: aws.String(ds.userPoolID),
+	}
+	result, err := ds.cognitoClient.AdminRespondToAuthChallenge(challengeInput)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	if result.AuthenticationResult == nil {
+		return extModel.CognitoAuthentication{}, &responses.NetworkError{
+			Code:    http.StatusUnauthorized,
+			Message: "The login code is not valid",
+		}
+	}
+
+	return cognitoAuthentication(result.AuthenticationResult), nil
+}
+
+func (ds *CognitoRemoteDataSourceImpl) RefreshToken(refreshToken string) (extModel.CognitoAuthentication, error) {
+	authInput := &cognito.AdminInitiateAuthInput{
+		AuthFlow: aws.String(cognito.AuthFlowTypeRefreshTokenAuth),
 		AuthParameters: aws.StringMap(map[string]string{
-			"USERNAME": "unknown-user",
-			"PASSWORD": "unknown-user",
+			"REFRESH_TOKEN": refreshToken,
 		}),
+		ClientId:   aws.String(ds.appClientID),
+		UserPoolId: aws.String(ds.userPoolID),
+	}
+	result, err := ds.cognitoClient.AdminInitiateAuth(authInput)
+
+	if err != nil {
+		return extModel.CognitoAuthentication{}, err
+	}
+
+	if result.AuthenticationResult == nil {
+		return extModel.CognitoAuthentication{}, &responses.NetworkError{
+			Code:    http.StatusUnauthorized,
+			Message: "The refresh token is not valid",
+		}
+	}
+
+	return cognitoAuthentication(result.AuthenticationResult), nil
+}
+
+// RevokeToken revokes the refresh token and all the access tokens got with it
+func (ds *CognitoRemoteDataSourceImpl) RevokeToken(refreshToken string) error {
+	_, err := ds.cognitoClient.RevokeToken(&cognito.RevokeTokenInput{
 		ClientId: aws.String(ds.appClientID),
+		Token:    aws.String(refreshToken),
+	})
+
+	return err
+}
+
+// DeleteUser removes the user and its refresh tokens. A user already removed is not an error,
+// so the erasure of the customer can be repeated
+func (ds *CognitoRemoteDataSourceImpl) DeleteUser(cpf string) error {
+	_, err := ds.cognitoClient.AdminDeleteUser(&cognito.AdminDeleteUserInput{
+		UserPoolId: aws.String(ds.userPoolID),
+		Username:   aws.String(cpf),
+	})
+
+	var notFound *cognito.UserNotFoundException
+
+	if errors.As(err, &notFound) {
+		return nil
 	}
-	result, err := ds.cognitoClient.InitiateAuth(authInput)
+
+	return err
+}
+
+// LoginUnknown logs in the shared user of the customers who do not identify themselves. It uses
+// the admin flow, so the app client does not need the USER_PASSWORD_AUTH, which accepts any password login.
+// Its password is only known by the API
+func (ds *CognitoRemoteDataSourceImpl) LoginUnknown() (string, error) {
+	authInput := &cognito.AdminInitiateAuthInput{
+		AuthFlow: aws.String(cognito.AuthFlowTypeAdminUserPasswordAuth),
+		AuthParameters: aws.StringMap(map[string]string{
+			"USERNAME": entity.UnknownCustomerUsername,
+			"PASSWORD": ds.unknownUserPassword,
+		}),
+		ClientId:   aws.String(ds.appClientID),
+		UserPoolId: aws.String(ds.userPoolID),
+	}
+	result, err := ds.cognitoClient.AdminInitiateAuth(authInput)
 
 	if err != nil {
 		return "", err
 	}
 
+	if result.AuthenticationResult == nil {
+		return "", &responses.NetworkError{
+			Code:    http.StatusInternalServerError,
+			Message: "Cognito did not log in the unknown user",
+		}
+	}
+
 	return *result.AuthenticationResult.AccessToken, nil
 }
+
+func cognitoAuthentication(result *cognito.AuthenticationResultType) extModel.CognitoAuthentication {
+	return extModel.CognitoAuthentication{
+		AccessToken:  aws.StringValue(result.AccessToken),
+		RefreshToken: aws.StringValue(result.RefreshToken),
+		ExpiresIn:    aws.Int64Value(result.ExpiresIn),
+	}
+}
+
+// randomPassword has the upper and lower case letters, the number and the symbol required by
+// the default password policy, after the random part
+func randomPassword() (string, error) {
+	random := make([]byte, 32)
+
+	_, err := rand.Read(random)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random) + "Aa1!", nil
+}
@@ -0,0 +1,43 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/thiagoluis88git/tech1/internal/integrations/model"
+	"github.com/thiagoluis88git/tech1/pkg/httpserver"
+)
+
+type KeySetDataSource interface {
+	GetKeySet(ctx context.Context) (model.JSONWebKeySet, error)
+}
+
+type KeySetRemoteDataSource struct {
+	client   *http.Client
+	endpoint string
+}
+
+func NewKeySetRemoteDataSource(client *http.Client, endpoint string) KeySetDataSource {
+	return &KeySetRemoteDataSource{
+		client:   client,
+		endpoint: endpoint,
+	}
+}
+
+func (ds *KeySetRemoteDataSource) GetKeySet(ctx context.Context) (model.JSONWebKeySet, error) {
+	response, err := httpserver.DoRequest(
+		ctx,
+		ds.client,
+		ds.endpoint,
+		nil,
+		nil,
+		http.MethodGet,
+		model.JSONWebKeySet{},
+	)
+
+	if err != nil {
+		return model.JSONWebKeySet{}, err
+	}
+
+	return response, nil
+}
@@ -2,7 +2,9 @@ package remote
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/thiagoluis88git/tech1/internal/integrations/model"
 	"github.com/thiagoluis88git/tech1/pkg/environment"
@@ -10,9 +12,16 @@ import (
 	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
+const (
+	mercadoPagoRefundURL              = "https://api.mercadopago.com/v1/payments/%v/refunds"
+	mercadoPagoMerchantOrderSearchURL = "https://api.mercadopago.com/merchant_orders/search?external_reference=%v"
+)
+
 type MercadoLivreDataSource interface {
 	Generate(ctx context.Context, token string, input model.QRCodeInput) (string, error)
 	GetPaymentData(ctx context.Context, token string, endpoint string) (model.MercadoLivrePaymentResponse, error)
+	SearchPaymentData(ctx context.Context, token string, externalReference string) (model.MercadoLivreMerchantOrderSearchResponse, error)
+	Refund(ctx context.Context, token string, paymentID string) (model.MercadoLivreRefundResponse, error)
 }
 
 type MercadoLivreRemoteDataSource struct {
@@ -71,3 +80,39 @@ func (ds *MercadoLivreRemoteDataSource) GetPaymentData(ctx context.Context, toke
 
 	return response, nil
 }
+
+func (ds *MercadoLivreRemoteDataSource) SearchPaymentData(ctx context.Context, token string, externalReference string) (model.MercadoLivreMerchantOrderSearchResponse, error) {
+	response, err := httpserver.DoRequest(
+		ctx,
+		ds.client,
+		fmt.Sprintf(mercadoPagoMerchantOrderSearchURL, url.QueryEscape(externalReference)),
+		&token,
+		nil,
+		http.MethodGet,
+		model.MercadoLivreMerchantOrderSearchResponse{},
+	)
+
+	if err != nil {
+		return model.MercadoLivreMerchantOrderSearchResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (ds *MercadoLivreRemoteDataSource) Refund(ctx context.Context, token string, paymentID string) (model.MercadoLivreRefundResponse, error) {
+	response, err := httpserver.DoRequest(
+		ctx,
+		ds.client,
+		fmt.Sprintf(mercadoPagoRefundURL, paymentID),
+		&token,
+		nil,
+		http.MethodPost,
+		model.MercadoLivreRefundResponse{},
+	)
+
+	if err != nil {
+		return model.MercadoLivreRefundResponse{}, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// CachedKeySetRepository keeps the Cognito public keys in memory, so the tokens are validated
+// without a request to Cognito each time. The keys are fetched again when the cache expires or
+// when a token is signed by an unknown key, which happens when Cognito rotates them.
+// The key set is fetched outside the cache lock, so the other requests are not blocked by it
+type CachedKeySetRepository struct {
+	ds           remote.KeySetDataSource
+	mutex        sync.RWMutex
+	refreshMutex sync.Mutex
+	keys         map[string]*rsa.PublicKey
+	fetchedAt    time.Time
+}
+
+func NewCachedKeySetRepository(ds remote.KeySetDataSource) repository.KeySetRepository {
+	return &CachedKeySetRepository{
+		ds:   ds,
+		keys: map[string]*rsa.PublicKey{},
+	}
+}
+
+func (repo *CachedKeySetRepository) GetPublicKey(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	key, found, fetchedAt := repo.getCachedKey(keyID)
+
+	if found && time.Since(fetchedAt) <= entity.KeySetCacheDuration {
+		return key, nil
+	}
+
+	// A known key of an expired cache is still used while another request fetches the key set
+	if found && !repo.refreshMutex.TryLock() {
+		return key, nil
+	}
+
+	if !found {
+		repo.refreshMutex.Lock()
+	}
+
+	defer repo.refreshMutex.Unlock()
+
+	// Another request may have fetched the key set while this one was waiting
+	key, found, fetchedAt = repo.getCachedKey(keyID)
+
+	now := time.Now()
+	expired := now.Sub(fetchedAt) > entity.KeySetCacheDuration
+
+	if (!found || expired) && (expired || now.Sub(fetchedAt) > entity.KeySetMinRefreshInterval) {
+		err := repo.refresh(ctx, now)
+
+		if err != nil && !found && !repo.hasKeys() {
+			return nil, err
+		}
+
+		if err != nil {
+			log.Print("refreshing the key set, using the cached keys", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		key, found, _ = repo.getCachedKey(keyID)
+	}
+
+	if !found {
+		return nil, &responses.BusinessResponse{
+			StatusCode: http.StatusUnauthorized,
+			Message:    fmt.Sprintf("The Bearer token key %v is unknown", keyID),
+		}
+	}
+
+	return key, nil
+}
+
+func (repo *CachedKeySetRepository) getCachedKey(keyID string) (*rsa.PublicKey, bool, time.Time) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	key, found := repo.keys[keyID]
+
+	return key, found, repo.fetchedAt
+}
+
+func (repo *CachedKeySetRepository) hasKeys() bool {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return len(repo.keys) > 0
+}
+
+func (repo *CachedKeySetRepository) refresh(ctx context.Context, now time.Time) error {
+	keySet, err := repo.ds.GetKeySet(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, jsonWebKey := range keySet.Keys {
+		if jsonWebKey.Use != "" && jsonWebKey.Use != "sig" {
+			continue
+		}
+
+		key, err := jsonWebKey.RSAPublicKey()
+
+		if err != nil {
+			log.Print("ignoring key of the key set", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		keys[jsonWebKey.KeyID] = key
+	}
+
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	repo.keys = keys
+	repo.fetchedAt = now
+
+	return nil
+}
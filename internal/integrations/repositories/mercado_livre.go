@@ -3,14 +3,17 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
 	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
 	"github.com/thiagoluis88git/tech1/internal/integrations/model"
 	"github.com/thiagoluis88git/tech1/internal/integrations/remote"
 	"github.com/thiagoluis88git/tech1/pkg/environment"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
 )
 
 type MercadoLivreRepositoryImpl struct {
@@ -28,28 +31,66 @@ func NewMercadoLivreRepository(ds remote.MercadoLivreDataSource) repository.QRCo
 func (repo *MercadoLivreRepositoryImpl) Generate(ctx context.Context, token string, form dto.Order, orderID int) (dto.QRCodeDataResponse, error) {
 	items := make([]model.Item, 0)
 
-	totalAmount := 0
+	var totalAmount entity.Money
 
 	for _, value := range form.OrderProduct {
 		productId := strconv.Itoa(int(value.ProductID))
-		totalAmount += int(value.ProductPrice)
+		unitPrice := value.ProductPrice
+
+		for _, modifier := range value.Modifiers {
+			unitPrice = unitPrice.Add(modifier.Price)
+		}
+
+		itemAmount := unitPrice.Times(value.Quantity)
+		totalAmount = totalAmount.Add(itemAmount)
 
 		items = append(items, model.Item{
 			Description: fmt.Sprintf("FastFood Pagamento - Produto: %v", productId),
 			SkuNumber:   productId,
 			Title:       fmt.Sprintf("FastFood Pagamento - Produto: %v", productId),
 			UnitMeasure: "unit",
-			Quantity:    1,
-			UnitPrice:   int(value.ProductPrice),
-			TotalAmount: int(value.ProductPrice),
+			Quantity:    value.Quantity,
+			UnitPrice:   unitPrice.Number(),
+			TotalAmount: itemAmount.Number(),
 		})
 	}
 
-	expirationDate := time.Now().Local().Add(time.Hour * 12)
+	// The products of a split order were partially paid by other payments, so only the rest is charged.
+	// The discounts and the redeemed points are not products either, so the discounted order is charged as a single item
+	if len(form.PaymentIDs) > 0 || len(form.Discounts) > 0 || !form.LoyaltyDiscount.IsZero() {
+		totalAmount = form.TotalPrice
+		title := fmt.Sprintf("FastFood Pagamento - Total do pedido com desconto: %v", orderID)
+
+		if len(form.PaymentIDs) > 0 {
+			title = fmt.Sprintf("FastFood Pagamento - Restante do pedido: %v", orderID)
+		}
+
+		items = []model.Item{
+			{
+				Description: title,
+				SkuNumber:   strconv.Itoa(orderID),
+				Title:       title,
+				UnitMeasure: "unit",
+				Quantity:    1,
+				UnitPrice:   totalAmount.Number(),
+				TotalAmount: totalAmount.Number(),
+			},
+		}
+	}
+
+	// The Mercado Livre QR Code charges only in reais
+	if currency, _ := entity.CommonCurrency(totalAmount); currency != entity.CurrencyBRL {
+		return dto.QRCodeDataResponse{}, &responses.BusinessResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "The QR Code payment must be in reais",
+		}
+	}
+
+	expirationDate := time.Now().Local().Add(entity.QRCodeExpiration)
 
 	input := model.QRCodeInput{
 		Description:       fmt.Sprintf("Order: %v", orderID),
-		TotalAmount:       totalAmount,
+		TotalAmount:       totalAmount.Number(),
 		ExpirationDate:    expirationDate.Format("2006-01-02T15:04:05.999Z07:00"),
 		ExternalReference: fmt.Sprintf("%v|%v", strconv.Itoa(orderID), strconv.Itoa(int(form.PaymentID))),
 		Items:             items,
@@ -75,7 +116,69 @@ func (repo *MercadoLivreRepositoryImpl) GetQRCodePaymentData(ctx context.Context
 		return dto.ExternalPaymentInformation{}, err
 	}
 
-	mercadoLivrePayment := dto.ExternalPaymentInformation(response)
+	return toExternalPaymentInformation(response), nil
+}
+
+// SearchQRCodePaymentData finds the merchant order of a QR Code by the external reference. It is used when
+// the webhook does not arrive, so there is no resource URL to read
+func (repo *MercadoLivreRepositoryImpl) SearchQRCodePaymentData(ctx context.Context, token string, externalReference string) (dto.ExternalPaymentInformation, error) {
+	response, err := repo.ds.SearchPaymentData(ctx, token, externalReference)
+
+	if err != nil {
+		return dto.ExternalPaymentInformation{}, err
+	}
+
+	if len(response.Elements) == 0 {
+		return dto.ExternalPaymentInformation{}, &responses.LocalError{
+			Code:    responses.NOT_FOUND_ERROR,
+			Message: fmt.Sprintf("Merchant order not found for the external reference %v", externalReference),
+		}
+	}
+
+	// The newest merchant order is the one with the current status
+	merchantOrder := response.Elements[0]
+
+	for _, element := range response.Elements {
+		if element.ID > merchantOrder.ID {
+			merchantOrder = element
+		}
+	}
+
+	return toExternalPaymentInformation(merchantOrder), nil
+}
+
+func (repo *MercadoLivreRepositoryImpl) Refund(ctx context.Context, token string, gatewayPaymentID string) error {
+	_, err := repo.ds.Refund(ctx, token, gatewayPaymentID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func toExternalPaymentInformation(response model.MercadoLivrePaymentResponse) dto.ExternalPaymentInformation {
+	mercadoLivrePayment := dto.ExternalPaymentInformation{
+		ID:                response.ID,
+		Status:            response.Status,
+		ExternalReference: response.ExternalReference,
+		PreferenceID:      response.PreferenceID,
+		Marketplace:       response.Marketplace,
+		NotificationURL:   response.NotificationURL,
+		DateCreated:       response.DateCreated,
+		LastUpdated:       response.LastUpdated,
+		OrderStatus:       response.OrderStatus,
+		ClientID:          response.ClientID,
+	}
+
+	// A merchant order can have rejected payments before the approved one
+	for _, payment := range response.Payments {
+		if payment.Status == "approved" {
+			mercadoLivrePayment.ApprovedPaymentID = strconv.FormatInt(payment.ID, 10)
+		}
+
+		mercadoLivrePayment.LastPaymentStatus = payment.Status
+	}
 
-	return mercadoLivrePayment, nil
+	return mercadoLivrePayment
 }
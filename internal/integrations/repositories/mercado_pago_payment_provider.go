@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// MercadoPagoPaymentProvider pays with the Mercado Pago QR Code. The payment is authorized when
+// the QR Code is generated and it is finished by the webhook, when the customer pays it
+type MercadoPagoPaymentProvider struct {
+	repository      repository.QRCodePaymentRepository
+	token           string
+	verifySignature func(signature dto.WebhookSignature) error
+}
+
+func NewMercadoPagoPaymentProvider(
+	repository repository.QRCodePaymentRepository,
+	token string,
+	verifySignature func(signature dto.WebhookSignature) error,
+) repository.PaymentProvider {
+	return &MercadoPagoPaymentProvider{
+		repository:      repository,
+		token:           token,
+		verifySignature: verifySignature,
+	}
+}
+
+func (provider *MercadoPagoPaymentProvider) Name() string {
+	return entity.PaymentProviderMercadoPago
+}
+
+func (provider *MercadoPagoPaymentProvider) Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error) {
+	if authorization.Order == nil {
+		return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "The Mercado Pago QR Code needs the order items",
+		}
+	}
+
+	order := *authorization.Order
+
+	// The QR Code of a split order only charges what the other payments did not pay
+	if len(order.PaymentIDs) > 0 {
+		order.TotalPrice = authorization.TotalPrice
+	}
+
+	qrCode, err := provider.repository.Generate(ctx, provider.token, order, int(authorization.OrderID))
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	return dto.PaymentGatewayResponse{
+		PaymentStatus: entity.PaymentPayingStatus,
+		PaymentDate:   time.Now(),
+		QRCodeData:    qrCode.Data,
+	}, nil
+}
+
+func (provider *MercadoPagoPaymentProvider) Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error) {
+	return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "The Mercado Pago QR Code is captured when the customer pays it",
+	}
+}
+
+func (provider *MercadoPagoPaymentProvider) Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	err := provider.repository.Refund(ctx, provider.token, payment.PaymentGatewayId)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	return dto.PaymentGatewayResponse{
+		PaymentGatewayId: payment.PaymentGatewayId,
+		PaymentStatus:    entity.PaymentRefundedStatus,
+		PaymentDate:      time.Now(),
+	}, nil
+}
+
+func (provider *MercadoPagoPaymentProvider) GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	reference := fmt.Sprintf("%v|%v", orderID, payment.PaymentId)
+
+	mercadoLivrePayment, err := provider.repository.SearchQRCodePaymentData(ctx, provider.token, reference)
+
+	if err != nil {
+		return dto.PaymentGatewayResponse{}, err
+	}
+
+	response := dto.PaymentGatewayResponse{
+		PaymentGatewayId: mercadoLivrePayment.ApprovedPaymentID,
+		PaymentStatus:    entity.PaymentPayingStatus,
+	}
+
+	outcome, finished := entity.ResolveQRCodePayment(
+		mercadoLivrePayment.Status,
+		mercadoLivrePayment.OrderStatus,
+		mercadoLivrePayment.LastPaymentStatus,
+	)
+
+	if finished {
+		response.PaymentStatus = outcome.PaymentStatus
+	}
+
+	return response, nil
+}
+
+// ParseWebhook verifies the 'x-signature' header before reading the merchant order, so a forged
+// notification can not finish an order
+func (provider *MercadoPagoPaymentProvider) ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error) {
+	var form dto.ExternalPaymentEvent
+
+	err := json.Unmarshal(webhook.Body, &form)
+
+	if err != nil {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: "The Mercado Pago webhook body is not valid",
+		}
+	}
+
+	err = provider.verifySignature(dto.WebhookSignature{
+		Signature: webhook.Header.Get("x-signature"),
+		RequestID: webhook.Header.Get("x-request-id"),
+		DataID:    entity.QRCodeNotificationDataID(webhook.Query, form.Resource),
+	})
+
+	if err != nil {
+		return dto.PaymentWebhookEvent{}, err
+	}
+
+	if form.Topic != "merchant_order" {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code: http.StatusNotAcceptable,
+		}
+	}
+
+	mercadoLivrePayment, err := provider.repository.GetQRCodePaymentData(ctx, provider.token, form.Resource)
+
+	if err != nil {
+		return dto.PaymentWebhookEvent{}, err
+	}
+
+	outcome, finished := entity.ResolveQRCodePayment(
+		mercadoLivrePayment.Status,
+		mercadoLivrePayment.OrderStatus,
+		mercadoLivrePayment.LastPaymentStatus,
+	)
+
+	if !finished {
+		return dto.PaymentWebhookEvent{}, nil
+	}
+
+	orderID, paymentID, ok := entity.ParseQRCodeExternalReference(mercadoLivrePayment.ExternalReference)
+
+	if !ok {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("The external reference %q is not valid", mercadoLivrePayment.ExternalReference),
+		}
+	}
+
+	event := dto.PaymentWebhookEvent{
+		EventKey:      fmt.Sprintf("merchant_order:%v:%v", mercadoLivrePayment.ID, outcome.Result),
+		OrderID:       orderID,
+		PaymentID:     paymentID,
+		PaymentStatus: outcome.PaymentStatus,
+		OrderStatus:   outcome.OrderStatus,
+		Reason:        outcome.Reason,
+		Finished:      true,
+	}
+
+	if outcome.PaymentStatus == entity.PaymentPayedStatus {
+		event.GatewayPaymentID = mercadoLivrePayment.ApprovedPaymentID
+	}
+
+	return event, nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+const (
+	loginCodeEmailSubject = "Seu código de acesso"
+	loginCodeEmailBody    = "Olá, %v. Seu código de acesso é %v. Ele vale por %v minutos."
+)
+
+// SESLoginCodeNotifier sends the login code by email with Amazon SES. The sender must be
+// a verified identity of SES
+type SESLoginCodeNotifier struct {
+	client *ses.SES
+	sender string
+}
+
+func NewSESLoginCodeNotifier(region string, sender string) repository.LoginCodeNotifier {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+
+	if err != nil {
+		panic(err)
+	}
+
+	return &SESLoginCodeNotifier{
+		client: ses.New(sess),
+		sender: sender,
+	}
+}
+
+func (notifier *SESLoginCodeNotifier) SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error) {
+	body := fmt.Sprintf(loginCodeEmailBody, message.Name, message.Code, int(entity.LoginCodeDuration.Minutes()))
+
+	_, err := notifier.client.SendEmailWithContext(ctx, &ses.SendEmailInput{
+		Source: aws.String(notifier.sender),
+		Destination: &ses.Destination{
+			ToAddresses: aws.StringSlice([]string{message.Email}),
+		},
+		Message: &ses.Message{
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String(loginCodeEmailSubject),
+			},
+			Body: &ses.Body{
+				Text: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(body),
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		return "", &responses.NetworkError{
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		}
+	}
+
+	return entity.MaskEmail(message.Email), nil
+}
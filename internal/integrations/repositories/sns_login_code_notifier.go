@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+const loginCodeSMSBody = "Seu código de acesso é %v. Ele vale por %v minutos."
+
+// SNSLoginCodeNotifier sends the login code by SMS with Amazon SNS to the E.164 phone of the user.
+// The messages are transactional, so they are delivered even to the phones which opted out of promotions
+type SNSLoginCodeNotifier struct {
+	client *sns.SNS
+}
+
+func NewSNSLoginCodeNotifier(region string) repository.LoginCodeNotifier {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+
+	if err != nil {
+		panic(err)
+	}
+
+	return &SNSLoginCodeNotifier{
+		client: sns.New(sess),
+	}
+}
+
+func (notifier *SNSLoginCodeNotifier) SendLoginCode(ctx context.Context, message dto.LoginCodeMessage) (string, error) {
+	body := fmt.Sprintf(loginCodeSMSBody, message.Code, int(entity.LoginCodeDuration.Minutes()))
+
+	_, err := notifier.client.PublishWithContext(ctx, &sns.PublishInput{
+		PhoneNumber: aws.String(message.Phone),
+		Message:     aws.String(body),
+		MessageAttributes: map[string]*sns.MessageAttributeValue{
+			"AWS.SNS.SMS.SMSType": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String("Transactional"),
+			},
+		},
+	})
+
+	if err != nil {
+		return "", &responses.NetworkError{
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		}
+	}
+
+	return entity.MaskPhone(message.Phone), nil
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+)
+
+// WebhookEventPublisher posts the events to an HTTP endpoint. Any status out of the 2xx range
+// is an error, so the relay sends the event again later
+type WebhookEventPublisher struct {
+	httpClient *http.Client
+	url        string
+}
+
+func NewWebhookEventPublisher(httpClient *http.Client, url string) repository.EventPublisher {
+	return &WebhookEventPublisher{
+		httpClient: httpClient,
+		url:        url,
+	}
+}
+
+func (publisher *WebhookEventPublisher) Publish(ctx context.Context, event dto.OutboxEvent) error {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.FormatUint(uint64(event.ID), 10))
+	req.Header.Set("X-Event-Type", event.EventType)
+
+	response, err := publisher.httpClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("webhook returned %v: %s", response.StatusCode, message)
+	}
+
+	return nil
+}
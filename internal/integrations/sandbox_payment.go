@@ -0,0 +1,192 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thiagoluis88git/tech1/internal/core/domain/dto"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/entity"
+	"github.com/thiagoluis88git/tech1/internal/core/domain/repository"
+	"github.com/thiagoluis88git/tech1/pkg/responses"
+)
+
+// The cents of the total price which make the sandbox fail. Every other amount is approved
+const (
+	SandboxDeclinedCents    = 51
+	SandboxUnavailableCents = 52
+)
+
+// The statuses accepted in the body of the sandbox webhook
+const (
+	SandboxWebhookApproved = "approved"
+	SandboxWebhookRejected = "rejected"
+	SandboxWebhookExpired  = "expired"
+	SandboxWebhookPending  = "pending"
+)
+
+// SandboxPaymentProvider is a deterministic provider for the local development and the tests. The result only
+// depends on the payment, so the same request always gets the same response, without any external call
+type SandboxPaymentProvider struct {
+	mutex    sync.RWMutex
+	statuses map[string]string
+}
+
+type sandboxWebhookBody struct {
+	OrderID   uint   `json:"orderId"`
+	PaymentID uint   `json:"paymentId"`
+	Status    string `json:"status"`
+}
+
+func NewSandboxPaymentProvider() *SandboxPaymentProvider {
+	return &SandboxPaymentProvider{
+		statuses: map[string]string{},
+	}
+}
+
+var _ repository.PaymentProvider = (*SandboxPaymentProvider)(nil)
+
+func (provider *SandboxPaymentProvider) Name() string {
+	return entity.PaymentProviderSandbox
+}
+
+func (provider *SandboxPaymentProvider) Authorize(ctx context.Context, authorization dto.PaymentAuthorization) (dto.PaymentGatewayResponse, error) {
+	cents := int(authorization.TotalPrice.Cents % 100)
+
+	if cents == SandboxUnavailableCents {
+		return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "The sandbox provider is unavailable",
+		}
+	}
+
+	response := dto.PaymentGatewayResponse{
+		PaymentGatewayId: fmt.Sprintf("sandbox-%v", authorization.PaymentID),
+		PaymentStatus:    entity.PaymentPayingStatus,
+		PaymentDate:      time.Now(),
+	}
+
+	if cents == SandboxDeclinedCents {
+		response.PaymentStatus = entity.PaymentErrorStatus
+	}
+
+	// The QR Code is paid later, calling the sandbox webhook
+	if authorization.Order != nil {
+		response.QRCodeData = fmt.Sprintf("sandbox|%v|%v", authorization.OrderID, authorization.PaymentID)
+	}
+
+	provider.saveStatus(response)
+
+	return response, nil
+}
+
+func (provider *SandboxPaymentProvider) Capture(ctx context.Context, authorized dto.PaymentGatewayResponse) (dto.PaymentGatewayResponse, error) {
+	response := dto.PaymentGatewayResponse{
+		PaymentGatewayId: authorized.PaymentGatewayId,
+		PaymentStatus:    entity.PaymentPayedStatus,
+		PaymentDate:      time.Now(),
+	}
+
+	provider.saveStatus(response)
+
+	return response, nil
+}
+
+func (provider *SandboxPaymentProvider) Refund(ctx context.Context, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	response := dto.PaymentGatewayResponse{
+		PaymentGatewayId: payment.PaymentGatewayId,
+		PaymentStatus:    entity.PaymentRefundedStatus,
+		PaymentDate:      time.Now(),
+	}
+
+	provider.saveStatus(response)
+
+	return response, nil
+}
+
+func (provider *SandboxPaymentProvider) GetStatus(ctx context.Context, orderID uint, payment dto.PaymentDetails) (dto.PaymentGatewayResponse, error) {
+	gatewayPaymentID := payment.PaymentGatewayId
+
+	if gatewayPaymentID == "" {
+		gatewayPaymentID = fmt.Sprintf("sandbox-%v", payment.PaymentId)
+	}
+
+	provider.mutex.RLock()
+	defer provider.mutex.RUnlock()
+
+	status, ok := provider.statuses[gatewayPaymentID]
+
+	if !ok {
+		return dto.PaymentGatewayResponse{}, &responses.NetworkError{
+			Code:    http.StatusNotFound,
+			Message: fmt.Sprintf("The sandbox payment %v was not found", gatewayPaymentID),
+		}
+	}
+
+	return dto.PaymentGatewayResponse{
+		PaymentGatewayId: gatewayPaymentID,
+		PaymentStatus:    status,
+	}, nil
+}
+
+// ParseWebhook reads the '{"orderId": 1, "paymentId": 1, "status": "approved"}' body. The orderId
+// is only sent for the QR Code payments
+func (provider *SandboxPaymentProvider) ParseWebhook(ctx context.Context, webhook dto.PaymentWebhook) (dto.PaymentWebhookEvent, error) {
+	var body sandboxWebhookBody
+
+	err := json.Unmarshal(webhook.Body, &body)
+
+	if err != nil || body.PaymentID == 0 {
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: "The sandbox webhook body is not valid",
+		}
+	}
+
+	event := dto.PaymentWebhookEvent{
+		EventKey:         fmt.Sprintf("sandbox:%v:%v", body.PaymentID, body.Status),
+		OrderID:          body.OrderID,
+		PaymentID:        body.PaymentID,
+		GatewayPaymentID: fmt.Sprintf("sandbox-%v", body.PaymentID),
+		Finished:         true,
+	}
+
+	switch body.Status {
+	case SandboxWebhookApproved:
+		event.PaymentStatus = entity.PaymentPayedStatus
+		event.OrderStatus = entity.OrderStatusCreated
+		event.Reason = "Payment confirmed"
+	case SandboxWebhookRejected:
+		event.PaymentStatus = entity.PaymentErrorStatus
+		event.OrderStatus = entity.OrderStatusCanceled
+		event.Reason = "Sandbox payment rejected"
+	case SandboxWebhookExpired:
+		event.PaymentStatus = entity.PaymentVoidedStatus
+		event.OrderStatus = entity.OrderStatusCanceled
+		event.Reason = "Sandbox payment expired"
+	case SandboxWebhookPending:
+		return dto.PaymentWebhookEvent{}, nil
+	default:
+		return dto.PaymentWebhookEvent{}, &responses.NetworkError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("The sandbox webhook status %q is not valid", body.Status),
+		}
+	}
+
+	provider.saveStatus(dto.PaymentGatewayResponse{
+		PaymentGatewayId: event.GatewayPaymentID,
+		PaymentStatus:    event.PaymentStatus,
+	})
+
+	return event, nil
+}
+
+func (provider *SandboxPaymentProvider) saveStatus(response dto.PaymentGatewayResponse) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	provider.statuses[response.PaymentGatewayId] = response.PaymentStatus
+}
@@ -10,7 +10,8 @@ import (
 	"gorm.io/gorm"
 )
 
-func ConfigDatabase() *gorm.DB {
+// ConfigDatabase migrates the models of the core and the extraModels of the integrations, like the local identity provider
+func ConfigDatabase(extraModels ...any) *gorm.DB {
 	dsn := fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v",
 		environment.GetDBHost(),
 		environment.GetDBUser(),
@@ -24,17 +25,57 @@ func ConfigDatabase() *gorm.DB {
 		panic(fmt.Sprintf("could not open database: %v", err.Error()))
 	}
 
-	db.AutoMigrate(
+	for _, migration := range model.MoneyMigrations {
+		err = db.Exec(migration).Error
+
+		if err != nil {
+			panic(fmt.Sprintf("could not migrate the money columns: %v", err.Error()))
+		}
+	}
+
+	models := []any{
 		&model.UserAdmin{},
 		&model.Customer{},
 		&model.Order{},
+		&model.OrderPayment{},
 		&model.OrderProduct{},
+		&model.OrderProductModifier{},
+		&model.OrderDiscount{},
 		&model.Payment{},
 		&model.Product{},
 		&model.ProductImage{},
 		&model.ComboProduct{},
+		&model.Promotion{},
+		&model.LoyaltyTransaction{},
 		&model.OrderTicketNumber{},
-	)
+		&model.OrderStatusHistory{},
+		&model.OrderEvent{},
+		&model.OutboxEvent{},
+		&model.IdempotencyKey{},
+		&model.ProcessedWebhookEvent{},
+		&model.RevokedToken{},
+		&model.DataSubjectRequest{},
+	}
+
+	err = db.AutoMigrate(append(models, extraModels...)...)
+
+	if err != nil {
+		panic(fmt.Sprintf("could not migrate database: %v", err.Error()))
+	}
+
+	for _, index := range model.OrderIndexes {
+		err = db.Exec(index).Error
+
+		if err != nil {
+			panic(fmt.Sprintf("could not create the order indexes: %v", err.Error()))
+		}
+	}
+
+	err = db.Exec(model.OrderPaymentsBackfill).Error
+
+	if err != nil {
+		panic(fmt.Sprintf("could not link the orders to their payments: %v", err.Error()))
+	}
 
 	return db
 }
@@ -21,6 +21,7 @@ const (
 	QRCodeGatewayRootURL          = "QR_CODE_GATEWAY_ROOT_URL"
 	QRCodeGatewayToken            = "QR_CODE_GATEWAY_TOKEN"
 	WebhookMercadoLivrePaymentURL = "WEBHOOK_MERCADO_LIVRE_PAYMENT"
+	WebhookMercadoLivreSecret     = "WEBHOOK_MERCADO_LIVRE_SECRET"
 	DBHost                        = "DB_HOST"
 	DBUser                        = "POSTGRES_USER"
 	DBPassword                    = "POSTGRES_PASSWORD"
@@ -30,13 +31,28 @@ const (
 	CognitoGroupUser              = "AWS_COGNITO_GROUP_USER"
 	CognitoGroupAdmin             = "AWS_COGNITO_GROUP_ADMIN"
 	CognitoUserPoolID             = "AWS_COGNITO_USER_POOL_ID"
+	CognitoUnknownUserPassword    = "AWS_COGNITO_UNKNOWN_USER_PASSWORD"
 	Region                        = "AWS_REGION"
+	OutboxWebhookURL              = "OUTBOX_WEBHOOK_URL"
+	PaymentSandbox                = "PAYMENT_SANDBOX"
+	PIXKey                        = "PIX_KEY"
+	PIXMerchantName               = "PIX_MERCHANT_NAME"
+	PIXMerchantCity               = "PIX_MERCHANT_CITY"
+	PIXWebhookSecret              = "PIX_WEBHOOK_SECRET"
+	LoginCodeEmailSender          = "LOGIN_CODE_EMAIL_SENDER"
+	LoginCodeSMS                  = "LOGIN_CODE_SMS"
+	IdentityProvider              = "IDENTITY_PROVIDER"
+	LocalIdentitySigningKey       = "LOCAL_IDENTITY_SIGNING_KEY"
+
+	// IdentityProviderLocal replaces Cognito with the users kept in the database
+	IdentityProviderLocal = "local"
 )
 
 type Environment struct {
 	qrCodeGatewayRootURL          string
 	qrCodeGatewayToken            string
 	webhookMercadoLivrePaymentURL string
+	webhookMercadoLivreSecret     string
 	dbHost                        string
 	dbPort                        string
 	dbName                        string
@@ -46,7 +62,18 @@ type Environment struct {
 	cognitoGroupUser              string
 	cognitoGroupAdmin             string
 	cognitoUserPoolID             string
+	cognitoUnknownUserPassword    string
 	region                        string
+	outboxWebhookURL              string
+	loginCodeEmailSender          string
+	loginCodeSMS                  bool
+	identityProvider              string
+	localIdentitySigningKey       string
+	paymentSandbox                bool
+	pixKey                        string
+	pixMerchantName               string
+	pixMerchantCity               string
+	pixWebhookSecret              string
 }
 
 func LoadEnvironmentVariables() {
@@ -63,16 +90,51 @@ func LoadEnvironmentVariables() {
 	qrCodeGatewayRootURL := getEnvironmentVariable(QRCodeGatewayRootURL)
 	qrCodeGatewayToken := getEnvironmentVariable(QRCodeGatewayToken)
 	webhookMercadoLivrePaymentURL := getEnvironmentVariable(WebhookMercadoLivrePaymentURL)
+	webhookMercadoLivreSecret := getEnvironmentVariable(WebhookMercadoLivreSecret)
 	dbHost := getEnvironmentVariable(DBHost)
 	dbPort := getEnvironmentVariable(DBPort)
 	dbUser := getEnvironmentVariable(DBUser)
 	dbPassword := getEnvironmentVariable(DBPassword)
 	dbName := getEnvironmentVariable(DBName)
-	cognitoClientID := getEnvironmentVariable(CognitoClientID)
 	cognitoGroupUser := getEnvironmentVariable(CognitoGroupUser)
 	cognitoGroupAdmin := getEnvironmentVariable(CognitoGroupAdmin)
-	cognitoUserPoolID := getEnvironmentVariable(CognitoUserPoolID)
-	region := getEnvironmentVariable(Region)
+	identityProvider := getOptionalEnvironmentVariable(IdentityProvider)
+	localIdentitySigningKey := getOptionalEnvironmentVariable(LocalIdentitySigningKey)
+
+	var cognitoClientID, cognitoUserPoolID, cognitoUnknownUserPassword, region string
+
+	// The local identity provider runs without AWS, so the Cognito user pool is not needed
+	if identityProvider == IdentityProviderLocal {
+		cognitoClientID = getOptionalEnvironmentVariable(CognitoClientID)
+		cognitoUserPoolID = getOptionalEnvironmentVariable(CognitoUserPoolID)
+		cognitoUnknownUserPassword = getOptionalEnvironmentVariable(CognitoUnknownUserPassword)
+		region = getOptionalEnvironmentVariable(Region)
+	} else {
+		cognitoClientID = getEnvironmentVariable(CognitoClientID)
+		cognitoUserPoolID = getEnvironmentVariable(CognitoUserPoolID)
+		cognitoUnknownUserPassword = getEnvironmentVariable(CognitoUnknownUserPassword)
+		region = getEnvironmentVariable(Region)
+	}
+	outboxWebhookURL := getOptionalEnvironmentVariable(OutboxWebhookURL)
+	loginCodeEmailSender := getOptionalEnvironmentVariable(LoginCodeEmailSender)
+	loginCodeSMS := getOptionalEnvironmentVariable(LoginCodeSMS) == "true"
+
+	// The SMS are sent by Amazon SNS, which needs the region even with the local identity provider
+	if loginCodeSMS && region == "" {
+		region = getEnvironmentVariable(Region)
+	}
+
+	paymentSandbox := getOptionalEnvironmentVariable(PaymentSandbox) == "true"
+	pixKey := getOptionalEnvironmentVariable(PIXKey)
+
+	var pixMerchantName, pixMerchantCity, pixWebhookSecret string
+
+	// The PIX payment needs the merchant data only when it is enabled with the key
+	if pixKey != "" {
+		pixMerchantName = getEnvironmentVariable(PIXMerchantName)
+		pixMerchantCity = getEnvironmentVariable(PIXMerchantCity)
+		pixWebhookSecret = getEnvironmentVariable(PIXWebhookSecret)
+	}
 
 	once := &sync.Once{}
 
@@ -86,11 +148,23 @@ func LoadEnvironmentVariables() {
 			dbPassword:                    dbPassword,
 			dbName:                        dbName,
 			webhookMercadoLivrePaymentURL: webhookMercadoLivrePaymentURL,
+			webhookMercadoLivreSecret:     webhookMercadoLivreSecret,
 			cognitoClientID:               cognitoClientID,
 			cognitoGroupUser:              cognitoGroupUser,
 			cognitoGroupAdmin:             cognitoGroupAdmin,
 			cognitoUserPoolID:             cognitoUserPoolID,
+			cognitoUnknownUserPassword:    cognitoUnknownUserPassword,
 			region:                        region,
+			outboxWebhookURL:              outboxWebhookURL,
+			loginCodeEmailSender:          loginCodeEmailSender,
+			loginCodeSMS:                  loginCodeSMS,
+			identityProvider:              identityProvider,
+			localIdentitySigningKey:       localIdentitySigningKey,
+			paymentSandbox:                paymentSandbox,
+			pixKey:                        pixKey,
+			pixMerchantName:               pixMerchantName,
+			pixMerchantCity:               pixMerchantCity,
+			pixWebhookSecret:              pixWebhookSecret,
 		}
 	})
 }
@@ -105,6 +179,11 @@ func getEnvironmentVariable(key string) string {
 	return value
 }
 
+func getOptionalEnvironmentVariable(key string) string {
+	value, _ := os.LookupEnv(key)
+	return value
+}
+
 func GetWebhookMercadoLivrePaymentURL() string {
 	if singleton != nil {
 		return singleton.webhookMercadoLivrePaymentURL
@@ -113,6 +192,14 @@ func GetWebhookMercadoLivrePaymentURL() string {
 	return getEnvironmentVariable(WebhookMercadoLivrePaymentURL)
 }
 
+func GetWebhookMercadoLivreSecret() string {
+	if singleton != nil {
+		return singleton.webhookMercadoLivreSecret
+	}
+
+	return getEnvironmentVariable(WebhookMercadoLivreSecret)
+}
+
 func GetQRCodeGatewayRootURL() string {
 	if singleton != nil {
 		return singleton.qrCodeGatewayRootURL
@@ -201,6 +288,15 @@ func GetCognitoUserPoolID() string {
 	return getEnvironmentVariable(CognitoUserPoolID)
 }
 
+// GetCognitoUnknownUserPassword is the password of the shared user of the customers who do not identify themselves
+func GetCognitoUnknownUserPassword() string {
+	if singleton != nil {
+		return singleton.cognitoUnknownUserPassword
+	}
+
+	return getEnvironmentVariable(CognitoUnknownUserPassword)
+}
+
 func GetRegion() string {
 	if singleton != nil {
 		return singleton.region
@@ -208,3 +304,93 @@ func GetRegion() string {
 
 	return getEnvironmentVariable(Region)
 }
+
+// GetOutboxWebhookURL is optional. Without it, the domain events are kept in memory
+func GetOutboxWebhookURL() string {
+	if singleton != nil {
+		return singleton.outboxWebhookURL
+	}
+
+	return getOptionalEnvironmentVariable(OutboxWebhookURL)
+}
+
+// GetLoginCodeEmailSender is optional only with the local identity provider, which writes the login
+// codes in the log instead of sending them by email
+func GetLoginCodeEmailSender() string {
+	if singleton != nil {
+		return singleton.loginCodeEmailSender
+	}
+
+	return getOptionalEnvironmentVariable(LoginCodeEmailSender)
+}
+
+// IsLoginCodeSMS is optional. When it is 'true', the login codes of the customers with a phone are sent by SMS
+func IsLoginCodeSMS() bool {
+	if singleton != nil {
+		return singleton.loginCodeSMS
+	}
+
+	return getOptionalEnvironmentVariable(LoginCodeSMS) == "true"
+}
+
+// IsLocalIdentityProvider is optional. When the IDENTITY_PROVIDER is 'local', the users and the tokens
+// are managed by the API instead of Cognito
+func IsLocalIdentityProvider() bool {
+	if singleton != nil {
+		return singleton.identityProvider == IdentityProviderLocal
+	}
+
+	return getOptionalEnvironmentVariable(IdentityProvider) == IdentityProviderLocal
+}
+
+// GetLocalIdentitySigningKey is the PEM RSA key of the local identity provider. Without it,
+// a temporary key is created when the API starts
+func GetLocalIdentitySigningKey() string {
+	if singleton != nil {
+		return singleton.localIdentitySigningKey
+	}
+
+	return getOptionalEnvironmentVariable(LocalIdentitySigningKey)
+}
+
+// IsPaymentSandbox is optional. When it is 'true', all the payment types use the sandbox provider
+func IsPaymentSandbox() bool {
+	if singleton != nil {
+		return singleton.paymentSandbox
+	}
+
+	return getOptionalEnvironmentVariable(PaymentSandbox) == "true"
+}
+
+// GetPIXKey is optional. Without it, the PIX payment type is not available
+func GetPIXKey() string {
+	if singleton != nil {
+		return singleton.pixKey
+	}
+
+	return getOptionalEnvironmentVariable(PIXKey)
+}
+
+func GetPIXMerchantName() string {
+	if singleton != nil {
+		return singleton.pixMerchantName
+	}
+
+	return getEnvironmentVariable(PIXMerchantName)
+}
+
+func GetPIXMerchantCity() string {
+	if singleton != nil {
+		return singleton.pixMerchantCity
+	}
+
+	return getEnvironmentVariable(PIXMerchantCity)
+}
+
+func GetPIXWebhookSecret() string {
+	if singleton != nil {
+		return singleton.pixWebhookSecret
+	}
+
+	return getEnvironmentVariable(PIXWebhookSecret)
+}
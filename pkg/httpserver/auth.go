@@ -0,0 +1,17 @@
+package httpserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// GetBearerTokenFromRequest returns the 'Authorization: Bearer' token and false when the request has none
+func GetBearerTokenFromRequest(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	if !found || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
@@ -0,0 +1,100 @@
+package httpserver
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WantsCSV checks if the client asked for CSV with the 'format' query param or the Accept header
+func WantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// SendCSVResponse writes a struct or a slice of structs as CSV. The header uses the json
+// tags of the fields, so the columns have the same names of the JSON response
+func SendCSVResponse(w http.ResponseWriter, filename string, data any) error {
+	value := reflect.Indirect(reflect.ValueOf(data))
+
+	rows := []reflect.Value{}
+	rowType := value.Type()
+
+	if value.Kind() == reflect.Slice {
+		rowType = rowType.Elem()
+
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, value.Index(i))
+		}
+	} else {
+		rows = append(rows, value)
+	}
+
+	if rowType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: %v is not a struct", rowType)
+	}
+
+	header := make([]string, rowType.NumField())
+
+	for i := range header {
+		field := rowType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if name == "" {
+			name = field.Name
+		}
+
+		header[i] = name
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(header)
+
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, row.NumField())
+
+		for i := range record {
+			record[i] = formatCSVValue(row.Field(i))
+		}
+
+		err = writer.Write(record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func formatCSVValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Pointer:
+		if value.IsNil() {
+			return ""
+		}
+
+		return formatCSVValue(value.Elem())
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
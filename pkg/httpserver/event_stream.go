@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventStreamHeartbeat = 15 * time.Second
+	eventStreamRetry     = 3 * time.Second
+)
+
+// EventStream writes Server-Sent Events to the client. The server write timeout is disabled
+// for this response, since the stream stays open until the client disconnects
+type EventStream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	controller := http.NewResponseController(w)
+
+	err := controller.SetWriteDeadline(time.Time{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &EventStream{
+		w:          w,
+		controller: controller,
+	}
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetry.Milliseconds())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, controller.Flush()
+}
+
+func (stream *EventStream) Send(id int64, event string, data any) error {
+	body, err := json.Marshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stream.w, "id: %d\nevent: %v\ndata: %s\n\n", id, event, body)
+
+	if err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
+
+// Heartbeat sends a comment line to keep proxies and load balancers from closing an idle stream
+func (stream *EventStream) Heartbeat() error {
+	_, err := fmt.Fprint(stream.w, ": heartbeat\n\n")
+
+	if err != nil {
+		return err
+	}
+
+	return stream.controller.Flush()
+}
+
+// GetLastEventID reads the Last-Event-ID header sent by the browser when it reconnects. The
+// 'lastEventId' query param can be used by clients which can not set headers
+func GetLastEventID(r *http.Request) int64 {
+	value := r.Header.Get("Last-Event-ID")
+
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+
+	lastEventID, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil || lastEventID < 0 {
+		return 0
+	}
+
+	return lastEventID
+}
@@ -15,6 +15,14 @@ import (
 	"github.com/golang/gddo/httputil/header"
 )
 
+var validate = validator.New()
+
+// RegisterValidationType lets the validation tags, like 'required', check the value returned by the function
+// for the struct types, which the validator does not check. It must be called before the server starts
+func RegisterValidationType(value validator.CustomTypeFunc, types ...any) {
+	validate.RegisterCustomTypeFunc(value, types...)
+}
+
 func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
 	if r.Header.Get("Content-Type") == "" {
 		msg := "Content-Type header is not application/json"
@@ -75,7 +83,6 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
 		return &responses.BusinessResponse{StatusCode: http.StatusBadRequest, Message: msg}
 	}
 
-	validate := validator.New()
 	err = validate.Struct(dst)
 	if err != nil {
 		msg := fmt.Sprintf("Error JSON required fields: %v", err.Error())
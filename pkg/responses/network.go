@@ -23,6 +23,21 @@ func GetCognitoError(err error) *NetworkError {
 		code = http.StatusConflict
 	}
 
+	if strings.Contains(err.Error(), "UserNotFoundException") {
+		code = http.StatusNotFound
+	}
+
+	if strings.Contains(err.Error(), "NotAuthorizedException") ||
+		strings.Contains(err.Error(), "CodeMismatchException") ||
+		strings.Contains(err.Error(), "ExpiredCodeException") {
+		code = http.StatusUnauthorized
+	}
+
+	if strings.Contains(err.Error(), "TooManyRequestsException") ||
+		strings.Contains(err.Error(), "LimitExceededException") {
+		code = http.StatusTooManyRequests
+	}
+
 	return &NetworkError{
 		Code:    code,
 		Message: message,